@@ -0,0 +1,416 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/orderio"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/orderclient"
+)
+
+var orderHeader = []string{"ID", "PRICE", "TAX", "FINAL PRICE"}
+
+func orderRow(order orderclient.Order) []string {
+	return []string{order.ID, formatFloat(order.Price), formatFloat(order.Tax), formatFloat(order.FinalPrice)}
+}
+
+func ordersTable(orders []orderclient.Order) table {
+	t := table{header: orderHeader}
+	for _, order := range orders {
+		t.rows = append(t.rows, orderRow(order))
+	}
+	return t
+}
+
+func (a *app) create(ctx context.Context, args []string) error {
+	flags, global := a.newFlagSet("create", "--id <id> --price <price> --tax <tax>")
+	id := flags.String("id", "", "ID do pedido")
+	price := flags.Float64("price", 0, "preço")
+	tax := flags.Float64("tax", 0, "imposto")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if *id == "" || flags.NArg() > 0 {
+		flags.Usage()
+		return errUsage
+	}
+	client, out, err := a.dial(global)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	order, err := client.CreateOrder(ctx, orderclient.OrderInput{ID: *id, Price: *price, Tax: *tax})
+	if err != nil {
+		return err
+	}
+	return out.print(order, ordersTable([]orderclient.Order{order}))
+}
+
+func (a *app) get(ctx context.Context, args []string) error {
+	flags, global := a.newFlagSet("get", "<id> [--history]")
+	history := flags.Bool("history", false, "mostra a auditoria do pedido")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errUsage
+	}
+	client, out, err := a.dial(global)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if !*history {
+		order, err := client.GetOrder(ctx, flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		return out.print(order, ordersTable([]orderclient.Order{order}))
+	}
+	entries, err := client.OrderHistory(ctx, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	t := table{header: []string{"TIMESTAMP", "ACTION", "ACTOR", "TRANSPORT", "REQUEST ID", "BEFORE", "AFTER"}}
+	for _, entry := range entries {
+		t.rows = append(t.rows, []string{
+			entry.Timestamp.Format(time.RFC3339), entry.Action, entry.Actor, entry.Transport, entry.RequestID,
+			formatState(entry.Before), formatState(entry.After),
+		})
+	}
+	return out.print(entries, t)
+}
+
+func formatState(state *orderclient.OrderState) string {
+	if state == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%s+%s=%s", formatFloat(state.Price), formatFloat(state.Tax), formatFloat(state.FinalPrice))
+}
+
+// orderFilter seleciona os pedidos do list.
+type orderFilter struct {
+	idPrefix string
+	minPrice float64
+	maxPrice float64
+}
+
+func (f orderFilter) match(order orderclient.Order) bool {
+	return strings.HasPrefix(order.ID, f.idPrefix) &&
+		(f.minPrice == 0 || order.Price >= f.minPrice) &&
+		(f.maxPrice == 0 || order.Price <= f.maxPrice)
+}
+
+// list lê os pedidos página a página do StreamOrders, filtra e mostra só a
+// página pedida; o total vai para a saída de erro, para não sujar o JSON/YAML.
+func (a *app) list(ctx context.Context, args []string) error {
+	flags, global := a.newFlagSet("list", "[--id-prefix <prefixo>] [--min-price <n>] [--max-price <n>] [--page <n>] [--page-size <n>]")
+	var filter orderFilter
+	flags.StringVar(&filter.idPrefix, "id-prefix", "", "só os pedidos cujo ID começa com o prefixo")
+	flags.Float64Var(&filter.minPrice, "min-price", 0, "preço mínimo")
+	flags.Float64Var(&filter.maxPrice, "max-price", 0, "preço máximo")
+	page := flags.Int("page", 1, "página, a partir de 1")
+	pageSize := flags.Int("page-size", 20, "pedidos por página; 0 mostra todos")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 || *page < 1 || *pageSize < 0 {
+		flags.Usage()
+		return errUsage
+	}
+	client, out, err := a.dial(global)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	first, last := (*page-1)**pageSize, *page**pageSize
+	orders := []orderclient.Order{}
+	matched := 0
+	for order, err := range client.Orders(ctx, usecase.MaxPageSize) {
+		if err != nil {
+			return err
+		}
+		if !filter.match(order) {
+			continue
+		}
+		if *pageSize == 0 || (matched >= first && matched < last) {
+			orders = append(orders, order)
+		}
+		matched++
+	}
+	if err := out.print(orders, ordersTable(orders)); err != nil {
+		return err
+	}
+	if *pageSize > 0 && matched > 0 {
+		pages := (matched + *pageSize - 1) / *pageSize
+		fmt.Fprintf(a.stderr, "page %d of %d, %d order(s)\n", *page, pages, matched)
+	}
+	return nil
+}
+
+// formatOf escolhe o formato pelo --format ou pela extensão do arquivo.
+func formatOf(format, path string) (string, error) {
+	if format != "" {
+		return format, nil
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return orderio.CSV, nil
+	case ".ndjson", ".jsonl":
+		return orderio.NDJSON, nil
+	}
+	return "", errors.New("can't tell the format from the file name, use --format csv or ndjson")
+}
+
+func (a *app) importOrders(ctx context.Context, args []string) error {
+	flags, global := a.newFlagSet("import", "<arquivo> [--format csv|ndjson] [--dry-run]")
+	format := flags.String("format", "", "csv ou ndjson (padrão: pela extensão do arquivo)")
+	dryRun := flags.Bool("dry-run", false, "só valida, sem criar os pedidos")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errUsage
+	}
+	path := flags.Arg(0)
+	var input io.Reader = a.stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	} else if *format == "" {
+		*format = orderio.CSV
+	}
+	rowFormat, err := formatOf(*format, path)
+	if err != nil {
+		return err
+	}
+	rows, err := orderio.NewReader(rowFormat, input)
+	if err != nil {
+		return err
+	}
+	var orders []orderclient.OrderInput
+	for row := 1; ; row++ {
+		input, err := rows.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("row %d: %w", row, err)
+		}
+		orders = append(orders, orderclient.OrderInput{ID: input.ID, Price: input.Price, Tax: input.Tax})
+	}
+
+	client, out, err := a.dial(global)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	report, err := client.ImportOrders(ctx, orders, *dryRun)
+	if err != nil {
+		return err
+	}
+	t := table{header: []string{"ROWS", "IMPORTED", "FAILED", "DRY RUN"}, rows: [][]string{{
+		strconv.Itoa(report.Rows), strconv.Itoa(report.Imported), strconv.Itoa(report.Failed), strconv.FormatBool(report.DryRun),
+	}}}
+	if err := out.print(report, t); err != nil {
+		return err
+	}
+	if out.format == formatTable {
+		for _, rowErr := range report.Errors {
+			fmt.Fprintf(a.stderr, "row %d (%s): %s\n", rowErr.Row, rowErr.ID, rowErr.Error)
+		}
+	}
+	return nil
+}
+
+// export escreve os pedidos em CSV ou NDJSON, como o GET /orders/export; o
+// --output não se aplica.
+func (a *app) export(ctx context.Context, args []string) error {
+	flags, global := a.newFlagSet("export", "[--format csv|ndjson] [--file <arquivo>]")
+	format := flags.String("format", orderio.CSV, "csv ou ndjson")
+	path := flags.String("file", "", "arquivo de saída (padrão: a saída padrão)")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return errUsage
+	}
+	output := a.stdout
+	if *path != "" {
+		file, err := os.Create(*path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		output = file
+	}
+	writer, err := orderio.NewWriter(*format, output)
+	if err != nil {
+		return err
+	}
+	client, _, err := a.dial(global)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	for order, err := range client.Orders(ctx, usecase.MaxPageSize) {
+		if err != nil {
+			return err
+		}
+		err = writer.Write(usecase.ListOrdersOutputDTO{ID: order.ID, Price: order.Price, Tax: order.Tax, FinalPrice: order.FinalPrice})
+		if err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// watch termina sem erro no Ctrl-C.
+func (a *app) watch(ctx context.Context, args []string) error {
+	flags, global := a.newFlagSet("watch", "")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return errUsage
+	}
+	client, out, err := a.dial(global)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	orders, err := client.WatchOrders(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(a.stderr, "watching new orders, press Ctrl-C to stop")
+	for order, err := range orders {
+		if err != nil {
+			return err
+		}
+		if err := out.stream(order, orderHeader, orderRow(order)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *app) events(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] != "replay" {
+		fmt.Fprint(a.stderr, "usage: orderctl events replay [--order <id>]\n")
+		return errUsage
+	}
+	flags, global := a.newFlagSet("events replay", "[--order <id>]")
+	orderID := flags.String("order", "", "só os eventos desse pedido")
+	if err := parse(flags, args[1:]); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return errUsage
+	}
+	client, out, err := a.dial(global)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	header := []string{"ORDER ID", "VERSION", "TYPE", "PRICE", "TAX", "FINAL PRICE", "TIMESTAMP"}
+	for event, err := range client.ReplayEvents(ctx, *orderID) {
+		if err != nil {
+			return err
+		}
+		row := []string{
+			event.OrderID, strconv.Itoa(event.Version), event.Type,
+			formatFloat(event.Data.Price), formatFloat(event.Data.Tax), formatFloat(event.Data.FinalPrice),
+			event.Timestamp.Format(time.RFC3339),
+		}
+		if err := out.stream(event, header, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// profiles lista os perfis do arquivo, ou troca o atual com "use <nome>".
+func (a *app) profiles(_ context.Context, args []string) error {
+	flags, global := a.newFlagSet("profiles", "[use <nome>]")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	path, err := configPath(global.config)
+	if err != nil {
+		return err
+	}
+	config, err := loadConfig(path)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case flags.NArg() == 2 && flags.Arg(0) == "use":
+		if _, ok := config.Profiles[flags.Arg(1)]; !ok {
+			return fmt.Errorf("profile %q not found, use one of %v", flags.Arg(1), config.names())
+		}
+		config.CurrentProfile = flags.Arg(1)
+		return saveConfig(path, config)
+	case flags.NArg() > 0:
+		flags.Usage()
+		return errUsage
+	}
+
+	out, err := newPrinter(global.output, a.stdout)
+	if err != nil {
+		return err
+	}
+	// sem os tokens
+	type summary struct {
+		Name    string `json:"name"`
+		Current bool   `json:"current"`
+		Address string `json:"address"`
+		Actor   string `json:"actor,omitempty"`
+		TLS     bool   `json:"tls"`
+	}
+	current, _, _ := config.profile(global.profile)
+	summaries := []summary{}
+	t := table{header: []string{"CURRENT", "NAME", "ADDRESS", "ACTOR", "TLS"}}
+	for _, name := range config.names() {
+		profile := config.Profiles[name]
+		s := summary{
+			Name:    name,
+			Current: name == current,
+			Address: cmp.Or(profile.Address, defaultAddress),
+			Actor:   profile.Actor,
+			TLS:     profile.TLS || profile.CAFile != "" || profile.CertFile != "",
+		}
+		summaries = append(summaries, s)
+		marker := ""
+		if s.Current {
+			marker = "*"
+		}
+		t.rows = append(t.rows, []string{marker, s.Name, s.Address, s.Actor, strconv.FormatBool(s.TLS)})
+	}
+	return out.print(summaries, t)
+}
@@ -0,0 +1,165 @@
+// Comando orderctl: cliente de linha de comando do ordersystem, falando com a
+// API gRPC pelo pkg/orderclient.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/orderclient"
+
+	"github.com/spf13/pflag"
+)
+
+const usage = `usage: orderctl <command> [flags]
+
+create            cria um pedido (--id, --price, --tax)
+get <id>          mostra um pedido; com --history, a auditoria dele
+list              lista os pedidos, com filtros e paginação
+import <arquivo>  importa pedidos de um CSV ou NDJSON ("-" lê da entrada padrão)
+export            exporta os pedidos em CSV ou NDJSON
+watch             mostra os pedidos criados a partir de agora, até o Ctrl-C
+events replay     mostra os eventos gravados dos pedidos
+profiles          lista os perfis de conexão; "profiles use <nome>" troca o atual
+
+Flags de todos os comandos:
+  --profile     perfil de conexão (padrão: ORDERCTL_PROFILE ou o current_profile do arquivo)
+  --config      arquivo de perfis (padrão: ORDERCTL_CONFIG ou ~/.config/orderctl/config.yaml)
+  --address     endereço do gRPC, no lugar do perfil
+  -o, --output  table, json ou yaml (padrão table)
+
+Use "orderctl <command> --help" para as flags de cada comando.
+`
+
+// app roda os comandos. connect é trocado nos testes.
+type app struct {
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+	connect func(profile Profile) (*orderclient.Client, error)
+}
+
+func newApp() *app {
+	return &app{
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		connect: func(profile Profile) (*orderclient.Client, error) {
+			options, err := profile.options()
+			if err != nil {
+				return nil, err
+			}
+			return orderclient.NewGRPC(profile.Address, options...)
+		},
+	}
+}
+
+// errUsage já foi explicado ao usuário, que viu o uso do comando.
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := newApp().run(ctx, os.Args[1:])
+	if errors.Is(err, pflag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		if !errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, "orderctl:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func (a *app) run(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(a.stderr, usage)
+		if len(args) == 0 {
+			return errUsage
+		}
+		return nil
+	}
+	commands := map[string]func(ctx context.Context, args []string) error{
+		"create":   a.create,
+		"get":      a.get,
+		"list":     a.list,
+		"import":   a.importOrders,
+		"export":   a.export,
+		"watch":    a.watch,
+		"events":   a.events,
+		"profiles": a.profiles,
+	}
+	command, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(a.stderr, "unknown command %q\n\n%s", args[0], usage)
+		return errUsage
+	}
+	return command(ctx, args[1:])
+}
+
+// globalFlags são as flags aceitas por todos os comandos.
+type globalFlags struct {
+	profile string
+	config  string
+	address string
+	output  string
+}
+
+// newFlagSet cria as flags de um comando, já com as globais.
+func (a *app) newFlagSet(name, args string) (*pflag.FlagSet, *globalFlags) {
+	flags := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	flags.SetOutput(a.stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(a.stderr, "usage: orderctl %s %s\n\n%s", name, args, flags.FlagUsages())
+	}
+	global := &globalFlags{}
+	flags.StringVar(&global.profile, "profile", "", "perfil de conexão")
+	flags.StringVar(&global.config, "config", "", "arquivo de perfis")
+	flags.StringVar(&global.address, "address", "", "endereço do gRPC, no lugar do perfil")
+	flags.StringVarP(&global.output, "output", "o", formatTable, "table, json ou yaml")
+	return flags, global
+}
+
+// parse lê as flags; um erro de flag mostra o uso do comando.
+func parse(flags *pflag.FlagSet, args []string) error {
+	err := flags.Parse(args)
+	if err != nil && !errors.Is(err, pflag.ErrHelp) {
+		return errUsage
+	}
+	return err
+}
+
+// dial conecta com o perfil escolhido pelas flags globais.
+func (a *app) dial(global *globalFlags) (*orderclient.Client, *printer, error) {
+	out, err := newPrinter(global.output, a.stdout)
+	if err != nil {
+		return nil, nil, err
+	}
+	path, err := configPath(global.config)
+	if err != nil {
+		return nil, nil, err
+	}
+	config, err := loadConfig(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, profile, err := config.profile(global.profile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if global.address != "" {
+		profile.Address = global.address
+	}
+	client, err := a.connect(profile)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, out, nil
+}
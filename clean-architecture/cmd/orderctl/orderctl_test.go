@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/event/handler"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/database"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/grpc/pb"
+	grpcserver "github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/grpc/server"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/grpc/service"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/orderclient"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/pubsub"
+	"github.com/alexandreti/posGoExpert/clean-architecture/sql/migrations"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	// sqlite3
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// OrderctlTestSuite runs the commands against the order service served in
+// process over bufconn, on top of SQLite.
+type OrderctlTestSuite struct {
+	suite.Suite
+	Db     *sql.DB
+	Server *grpc.Server
+	App    *app
+	Stdout *bytes.Buffer
+	Stderr *bytes.Buffer
+	Config string
+	// Dialed is the profile of the last connection.
+	Dialed Profile
+}
+
+func (suite *OrderctlTestSuite) SetupTest() {
+	db, err := sql.Open("sqlite3", ":memory:")
+	suite.Require().NoError(err)
+	db.SetMaxOpenConns(1)
+	source, err := migrations.Source(database.SQLite.Name())
+	suite.Require().NoError(err)
+	_, err = database.NewMigrator(db, database.SQLite, source).Up(context.Background())
+	suite.Require().NoError(err)
+	suite.Db = db
+
+	orders := database.NewOrderRepository(db, database.SQLite)
+	views := database.NewOrderViewRepository(db, database.SQLite)
+	dispatcher := events.NewEventDispatcher()
+	dispatcher.Register("OrderCreated", handler.NewOrderProjectionHandler(views))
+	importOrders := usecase.NewImportOrdersUseCase(orders, dispatcher)
+	orderService := service.NewOrderService(
+		*usecase.NewCreateOrderUseCase(orders, dispatcher),
+		*usecase.NewListOrdersUseCase(views),
+		*usecase.NewGetOrderHistoryUseCase(database.NewOrderAuditRepository(db, database.SQLite)),
+		*importOrders,
+		*usecase.NewStreamOrdersUseCase(views),
+		*usecase.NewCreateOrdersUseCase(importOrders),
+		*usecase.NewGetOrderUseCase(views),
+		*usecase.NewReplayOrderEventsUseCase(orders),
+		*usecase.NewSearchOrdersUseCase(views),
+		*usecase.NewReportOrdersUseCase(orders),
+		*usecase.NewDeleteOrderUseCase(orders, dispatcher),
+		*usecase.NewRestoreOrderUseCase(orders, dispatcher),
+		pubsub.NewBroker[usecase.OrderOutputDTO](),
+	)
+	suite.Server = grpcserver.New(grpcserver.Config{Validate: true})
+	pb.RegisterOrderServiceServer(suite.Server, orderService)
+	listener := bufconn.Listen(1024 * 1024)
+	go suite.Server.Serve(listener)
+
+	suite.Stdout, suite.Stderr = &bytes.Buffer{}, &bytes.Buffer{}
+	suite.Config = filepath.Join(suite.T().TempDir(), "config.yaml")
+	suite.T().Setenv("ORDERCTL_CONFIG", suite.Config)
+	suite.T().Setenv("ORDERCTL_PROFILE", "")
+	suite.App = &app{
+		stdin:  strings.NewReader(""),
+		stdout: suite.Stdout,
+		stderr: suite.Stderr,
+		connect: func(profile Profile) (*orderclient.Client, error) {
+			suite.Dialed = profile
+			options, err := profile.options()
+			if err != nil {
+				return nil, err
+			}
+			return orderclient.NewGRPC("passthrough:///bufnet", append(options, orderclient.WithDialOptions(
+				grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+			))...)
+		},
+	}
+}
+
+func (suite *OrderctlTestSuite) TearDownTest() {
+	suite.Server.Stop()
+	suite.Db.Close()
+}
+
+func TestOrderctlSuite(t *testing.T) {
+	suite.Run(t, new(OrderctlTestSuite))
+}
+
+// run runs a command, returning its standard output.
+func (suite *OrderctlTestSuite) run(args ...string) (string, error) {
+	suite.Stdout.Reset()
+	suite.Stderr.Reset()
+	err := suite.App.run(context.Background(), args)
+	return suite.Stdout.String(), err
+}
+
+func (suite *OrderctlTestSuite) createOrders(ids ...string) {
+	for _, id := range ids {
+		_, err := suite.run("create", "--id", id, "--price", "10", "--tax", "1")
+		suite.Require().NoError(err)
+	}
+}
+
+func (suite *OrderctlTestSuite) TestGivenACreatedOrder_WhenGet_ThenShouldPrintItInEachFormat() {
+	out, err := suite.run("create", "--id", "01", "--price", "10.5", "--tax", "0.5")
+	suite.Require().NoError(err)
+	suite.Equal("ID  PRICE  TAX  FINAL PRICE\n01  10.5   0.5  11\n", out)
+
+	out, err = suite.run("get", "01", "-o", "json")
+	suite.Require().NoError(err)
+	suite.JSONEq(`{"id":"01","price":10.5,"tax":0.5,"final_price":11}`, out)
+
+	out, err = suite.run("get", "01", "--output", "yaml")
+	suite.Require().NoError(err)
+	// the ID stays a string
+	suite.Equal("id: \"01\"\nprice: 10.5\ntax: 0.5\nfinal_price: 11\n", out)
+}
+
+func (suite *OrderctlTestSuite) TestGivenAnUnknownOrder_WhenGet_ThenShouldFailWithNotFound() {
+	_, err := suite.run("get", "missing")
+	suite.ErrorIs(err, orderclient.ErrNotFound)
+}
+
+func (suite *OrderctlTestSuite) TestGivenAnOrder_WhenGetItsHistory_ThenShouldPrintTheEntries() {
+	suite.createOrders("01")
+
+	out, err := suite.run("get", "01", "--history", "-o", "json")
+	suite.Require().NoError(err)
+	var entries []orderclient.HistoryEntry
+	suite.Require().NoError(json.Unmarshal([]byte(out), &entries))
+	suite.Require().Len(entries, 1)
+	suite.Equal("orderctl", entries[0].Actor)
+}
+
+func (suite *OrderctlTestSuite) TestGivenFiveOrders_WhenListWithFiltersAndPages_ThenShouldPrintThePage() {
+	for i, id := range []string{"a1", "a2", "a3", "b1", "a4"} {
+		_, err := suite.run("create", "--id", id, "--price", []string{"10", "20", "30", "40", "50"}[i], "--tax", "1")
+		suite.Require().NoError(err)
+	}
+
+	out, err := suite.run("list", "--id-prefix", "a", "--min-price", "20", "--page-size", "2", "--page", "2", "-o", "json")
+	suite.Require().NoError(err)
+	var orders []orderclient.Order
+	suite.Require().NoError(json.Unmarshal([]byte(out), &orders))
+	suite.Require().Len(orders, 1)
+	suite.Equal("a4", orders[0].ID)
+	suite.Equal("page 2 of 2, 3 order(s)\n", suite.Stderr.String())
+
+	out, err = suite.run("list", "--page-size", "0")
+	suite.Require().NoError(err)
+	suite.Len(strings.Split(strings.TrimSpace(out), "\n"), 6)
+}
+
+func (suite *OrderctlTestSuite) TestGivenAnNDJSONFile_WhenImportAndExport_ThenShouldRoundTripTheOrders() {
+	file := filepath.Join(suite.T().TempDir(), "orders.ndjson")
+	suite.Require().NoError(os.WriteFile(file, []byte(`{"id":"a","price":10,"tax":1}
+{"id":"b","price":-1,"tax":1}
+`), 0o600))
+
+	out, err := suite.run("import", file, "--dry-run", "-o", "json")
+	suite.Require().NoError(err)
+	var report orderclient.ImportReport
+	suite.Require().NoError(json.Unmarshal([]byte(out), &report))
+	suite.True(report.DryRun)
+	suite.Equal(1, report.Failed)
+
+	_, err = suite.run("import", file)
+	suite.Require().NoError(err)
+	suite.Contains(suite.Stderr.String(), "row 2 (b):")
+
+	out, err = suite.run("export")
+	suite.Require().NoError(err)
+	suite.Equal("id,price,tax,final_price\na,10,1,11\n", out)
+}
+
+func (suite *OrderctlTestSuite) TestGivenOrders_WhenReplayEvents_ThenShouldPrintOneLinePerEvent() {
+	suite.createOrders("01", "02")
+
+	out, err := suite.run("events", "replay", "--order", "02", "-o", "json")
+	suite.Require().NoError(err)
+	var replayed orderclient.OrderEvent
+	suite.Require().NoError(json.Unmarshal([]byte(out), &replayed))
+	suite.Equal("02", replayed.OrderID)
+	suite.Equal("OrderCreated", replayed.Type)
+
+	out, err = suite.run("events", "replay", "-o", "yaml")
+	suite.Require().NoError(err)
+	suite.Equal(1, strings.Count(out, "---\n"))
+}
+
+func (suite *OrderctlTestSuite) TestGivenProfiles_WhenSelectOne_ThenShouldConnectWithIt() {
+	suite.Require().NoError(os.WriteFile(suite.Config, []byte(`current_profile: local
+profiles:
+  local:
+    address: localhost:50051
+  prod:
+    address: orders.internal:50051
+    actor: alice
+    timeout: 5s
+`), 0o600))
+	suite.createOrders("01")
+
+	_, err := suite.run("get", "01", "--profile", "prod")
+	suite.Require().NoError(err)
+	suite.Equal("orders.internal:50051", suite.Dialed.Address)
+	out, err := suite.run("get", "01", "--history", "-o", "json")
+	suite.Require().NoError(err)
+	suite.Contains(out, `"actor": "orderctl"`)
+
+	_, err = suite.run("profiles", "use", "prod")
+	suite.Require().NoError(err)
+	out, err = suite.run("profiles", "-o", "json")
+	suite.Require().NoError(err)
+	suite.JSONEq(`[
+		{"name":"local","current":false,"address":"localhost:50051","tls":false},
+		{"name":"prod","current":true,"address":"orders.internal:50051","actor":"alice","tls":false}
+	]`, out)
+
+	_, err = suite.run("list", "--profile", "missing")
+	suite.ErrorContains(err, `profile "missing" not found`)
+}
+
+func (suite *OrderctlTestSuite) TestGivenBadArguments_WhenRun_ThenShouldPrintTheUsage() {
+	_, err := suite.run("unknown")
+	suite.ErrorIs(err, errUsage)
+	suite.Contains(suite.Stderr.String(), "usage: orderctl")
+
+	_, err = suite.run("create", "--price", "10")
+	suite.ErrorIs(err, errUsage)
+	_, err = suite.run("list", "-o", "xml")
+	suite.EqualError(err, `invalid output "xml", use table, json or yaml`)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formatos do --output.
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+// table é a forma tabular de um valor: cabeçalho e linhas.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// printer escreve os resultados no formato do --output. Em JSON e YAML sai o
+// valor inteiro; em tabela, as linhas montadas pelo comando.
+type printer struct {
+	format string
+	w      io.Writer
+	// streaming: já escreveu o cabeçalho da tabela ou o primeiro documento
+	started bool
+}
+
+func newPrinter(format string, w io.Writer) (*printer, error) {
+	switch format {
+	case formatTable, formatJSON, formatYAML:
+		return &printer{format: format, w: w}, nil
+	}
+	return nil, fmt.Errorf("invalid output %q, use table, json or yaml", format)
+}
+
+// print escreve um resultado completo.
+func (p *printer) print(value any, t table) error {
+	switch p.format {
+	case formatJSON:
+		encoder := json.NewEncoder(p.w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case formatYAML:
+		data, err := toYAML(value)
+		if err != nil {
+			return err
+		}
+		_, err = p.w.Write(data)
+		return err
+	default:
+		tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+		for _, row := range t.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	}
+}
+
+// stream escreve um item de um resultado que chega aos poucos (watch, events
+// replay): uma linha de JSON por item, documentos YAML separados por ---, ou
+// uma linha da tabela, com o cabeçalho antes da primeira.
+func (p *printer) stream(value any, header, row []string) error {
+	started := p.started
+	p.started = true
+	switch p.format {
+	case formatJSON:
+		return json.NewEncoder(p.w).Encode(value)
+	case formatYAML:
+		data, err := toYAML(value)
+		if err != nil {
+			return err
+		}
+		if started {
+			data = append([]byte("---\n"), data...)
+		}
+		_, err = p.w.Write(data)
+		return err
+	default:
+		// as colunas não se alinham entre linhas que ainda não chegaram
+		tw := tabwriter.NewWriter(p.w, 12, 0, 2, ' ', 0)
+		if !started {
+			fmt.Fprintln(tw, strings.Join(header, "\t"))
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+		return tw.Flush()
+	}
+}
+
+// toYAML passa o valor pelo JSON, para que o YAML use os mesmos nomes de
+// campo (as tags json) e a mesma ordem.
+func toYAML(value any) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	blockStyle(&node)
+	return yaml.Marshal(&node)
+}
+
+// blockStyle troca o estilo de fluxo do JSON ({...}, [...], "...") pelo de
+// bloco do YAML.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/orderclient"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultAddress é o endereço do gRPC do ordersystem rodando localmente.
+const defaultAddress = "localhost:50051"
+
+// Profile é uma conexão nomeada com um ordersystem.
+type Profile struct {
+	Address string `yaml:"address"`
+	// Actor vai no x-actor e aparece na auditoria (padrão "orderctl"); com
+	// mTLS, o servidor usa o common name do certificado.
+	Actor string `yaml:"actor,omitempty"`
+	Token string `yaml:"token,omitempty"`
+	// TLS liga o TLS mesmo sem CAFile (usando as CAs do sistema).
+	TLS        bool          `yaml:"tls,omitempty"`
+	CAFile     string        `yaml:"ca_file,omitempty"`
+	CertFile   string        `yaml:"cert_file,omitempty"`
+	KeyFile    string        `yaml:"key_file,omitempty"`
+	ServerName string        `yaml:"server_name,omitempty"`
+	Timeout    time.Duration `yaml:"timeout,omitempty"`
+}
+
+// Config é o arquivo de perfis do orderctl.
+type Config struct {
+	CurrentProfile string             `yaml:"current_profile,omitempty"`
+	Profiles       map[string]Profile `yaml:"profiles"`
+}
+
+// configPath é o --config, o ORDERCTL_CONFIG ou ~/.config/orderctl/config.yaml.
+func configPath(flag string) (string, error) {
+	if flag != "" {
+		return flag, nil
+	}
+	if path := os.Getenv("ORDERCTL_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "orderctl", "config.yaml"), nil
+}
+
+// loadConfig lê o arquivo de perfis; sem arquivo, há só o perfil "default",
+// apontando para o ordersystem local.
+func loadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Config{Profiles: map[string]Profile{"default": {Address: defaultAddress}}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if config.Profiles == nil {
+		config.Profiles = map[string]Profile{}
+	}
+	return &config, nil
+}
+
+func saveConfig(path string, config *Config) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	// o arquivo pode ter tokens
+	return os.WriteFile(path, data, 0o600)
+}
+
+// profile escolhe o perfil pelo nome: o --profile, o ORDERCTL_PROFILE, o
+// current_profile do arquivo ou "default".
+func (c *Config) profile(name string) (string, Profile, error) {
+	for _, candidate := range []string{name, os.Getenv("ORDERCTL_PROFILE"), c.CurrentProfile} {
+		if candidate != "" {
+			name = candidate
+			break
+		}
+	}
+	if name == "" {
+		name = "default"
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return "", Profile{}, fmt.Errorf("profile %q not found, use one of %v", name, c.names())
+	}
+	if profile.Address == "" {
+		profile.Address = defaultAddress
+	}
+	return name, profile, nil
+}
+
+func (c *Config) names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// options converte o perfil nas opções do orderclient.
+func (p Profile) options() ([]orderclient.Option, error) {
+	metadata := map[string]string{"x-actor": cmp.Or(p.Actor, "orderctl")}
+	if p.Token != "" {
+		metadata["authorization"] = "Bearer " + p.Token
+	}
+	options := []orderclient.Option{orderclient.WithCredentials(orderclient.CredentialsFunc(func(ctx context.Context) (map[string]string, error) {
+		return metadata, nil
+	}))}
+	if p.Timeout > 0 {
+		options = append(options, orderclient.WithTimeout(p.Timeout))
+	}
+	if !p.TLS && p.CAFile == "" && p.CertFile == "" {
+		return options, nil
+	}
+	config := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: p.ServerName}
+	if p.CAFile != "" {
+		pem, err := os.ReadFile(p.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", p.CAFile)
+		}
+	}
+	if p.CertFile != "" || p.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(p.CertFile, p.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return append(options, orderclient.WithTLS(config)), nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/configs"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/database"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/logging"
+	"github.com/alexandreti/posGoExpert/clean-architecture/sql/migrations"
+)
+
+const migrateUsage = `usage: ordersystem migrate <command> [flags]
+
+commands:
+  up                aplica as migrations pendentes
+  down [N|all]      desfaz as últimas N migrations (padrão 1)
+  status            mostra a versão do banco e as migrations aplicadas
+  force VERSION     marca o banco como limpo na VERSION, sem rodar nada
+  create NAME       cria os arquivos da próxima migration para todos os bancos
+                    (-dir define a pasta das migrations, padrão sql/migrations)
+
+up, down, status e force aceitam as mesmas flags/variáveis do servidor.
+`
+
+// runMigrate implementa o subcomando "ordersystem migrate".
+func runMigrate(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return errors.New("missing migrate command")
+	}
+	if args[0] == "create" {
+		return createMigration(args[1:], stdout)
+	}
+
+	cfg, db, dialect, err := setupCommand(args[1:])
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	migrator, err := newMigrator(db, dialect)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		fmt.Fprintf(stdout, "%d migration(s) applied\n", applied)
+		return err
+	case "down":
+		steps, err := downSteps(cfg.Args)
+		if err != nil {
+			return err
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		fmt.Fprintf(stdout, "%d migration(s) reverted\n", reverted)
+		return err
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		printStatus(stdout, status)
+		return nil
+	case "force":
+		if len(cfg.Args) != 1 {
+			return errors.New("usage: ordersystem migrate force VERSION")
+		}
+		version, err := strconv.ParseUint(cfg.Args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", cfg.Args[0])
+		}
+		return migrator.Force(ctx, version)
+	default:
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+}
+
+func createMigration(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("migrate create", flag.ContinueOnError)
+	dir := flags.String("dir", "sql/migrations", "migrations directory")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("usage: ordersystem migrate create [-dir DIR] NAME")
+	}
+	created, err := migrations.Create(*dir, flags.Arg(0))
+	for _, file := range created {
+		fmt.Fprintln(stdout, file)
+	}
+	return err
+}
+
+func downSteps(args []string) (int, error) {
+	if len(args) == 0 {
+		return 1, nil
+	}
+	if args[0] == "all" {
+		return 0, nil
+	}
+	steps, err := strconv.Atoi(args[0])
+	if err != nil || steps < 1 {
+		return 0, fmt.Errorf("invalid number of migrations %q", args[0])
+	}
+	return steps, nil
+}
+
+func printStatus(w io.Writer, status database.Status) {
+	fmt.Fprintf(w, "version: %d", status.Version)
+	if status.Dirty {
+		fmt.Fprint(w, " (dirty)")
+	}
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tNAME\tSTATUS")
+	for _, migration := range status.Migrations {
+		state := "pending"
+		if migration.Applied {
+			state = "applied"
+		}
+		fmt.Fprintf(tw, "%06d\t%s\t%s\n", migration.Version, migration.Name, state)
+	}
+	tw.Flush()
+}
+
+func newMigrator(db *sql.DB, dialect database.Dialect) (*database.Migrator, error) {
+	source, err := migrations.Source(dialect.Name())
+	if err != nil {
+		return nil, err
+	}
+	return database.NewMigrator(db, dialect, source), nil
+}
+
+// setupCommand carrega a configuração, o log e o banco dos subcomandos.
+func setupCommand(args []string) (*configs.Config, *sql.DB, database.Dialect, error) {
+	cfg, err := configs.LoadConfig(".", args)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	logger, err := logging.New(os.Stderr, cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	slog.SetDefault(logger)
+
+	db, dialect, err := openDatabase(cfg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return cfg, db, dialect, nil
+}
+
+func openDatabase(cfg *configs.Config) (*sql.DB, database.Dialect, error) {
+	dialect, err := database.DialectFor(cfg.DBDriver)
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := sql.Open(dialect.Name(), cfg.DatabaseDSN())
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, dialect, nil
+}
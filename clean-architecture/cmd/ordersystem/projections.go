@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/database"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+)
+
+const projectionsUsage = `usage: ordersystem projections rebuild [flags]
+
+rebuild   apaga o read model (order_views) e reprojeta toda a história dos pedidos,
+          lida do event store ou da tabela orders, conforme ORDER_STORE
+
+Aceita as mesmas flags/variáveis do servidor.
+`
+
+// runProjections implementa o subcomando "ordersystem projections".
+func runProjections(args []string, stdout io.Writer) error {
+	if len(args) == 0 || args[0] != "rebuild" {
+		fmt.Fprint(os.Stderr, projectionsUsage)
+		return errors.New("unknown or missing projections command")
+	}
+
+	cfg, db, dialect, err := setupCommand(args[1:])
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// sem dispatcher: a reconstrução não deve republicar os eventos
+	history := newOrderRepository(cfg.OrderStore, db, dialect, nil)
+	rebuild := usecase.NewRebuildOrderViewsUseCase(history, database.NewOrderViewRepository(db, dialect))
+	output, err := rebuild.Execute(context.Background())
+	fmt.Fprintf(stdout, "%d event(s) replayed, %d projected\n", output.Events, output.Applied)
+	return err
+}
@@ -4,11 +4,8 @@
 package main
 
 import (
-	"database/sql"
-
 	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
 	"github.com/alexandreti/posGoExpert/clean-architecture/internal/event"
-	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/database"
 	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/web"
 	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
 	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
@@ -16,11 +13,6 @@ import (
 	"github.com/google/wire"
 )
 
-var setOrderRepositoryDependency = wire.NewSet(
-	database.NewOrderRepository,
-	wire.Bind(new(entity.OrderRepositoryInterface), new(*database.OrderRepository)),
-)
-
 var setEventDispatcherDependency = wire.NewSet(
 	events.NewEventDispatcher,
 	event.NewOrderCreated,
@@ -33,27 +25,108 @@ var setOrderCreatedEvent = wire.NewSet(
 	wire.Bind(new(events.EventInterface), new(*event.OrderCreated)),
 )
 
-func NewCreateOrderUseCase(db *sql.DB, eventDispatcher events.EventDispatcherInterface) *usecase.CreateOrderUseCase {
+func NewCreateOrderUseCase(orderRepository entity.OrderRepositoryInterface, eventDispatcher events.EventDispatcherInterface) *usecase.CreateOrderUseCase {
 	wire.Build(
-		setOrderRepositoryDependency,
-		setOrderCreatedEvent,
 		usecase.NewCreateOrderUseCase,
 	)
 	return &usecase.CreateOrderUseCase{}
 }
 
-func NewListOrdersUseCase(db *sql.DB) *usecase.ListOrdersUseCase {
+func NewListOrdersUseCase(orderViewRepository entity.OrderViewRepositoryInterface) *usecase.ListOrdersUseCase {
 	wire.Build(
-		setOrderRepositoryDependency,
 		usecase.NewListOrdersUseCase,
 	)
 	return &usecase.ListOrdersUseCase{}
 }
 
-func NewWebOrderHandler(db *sql.DB, eventDispatcher events.EventDispatcherInterface) *web.WebOrderHandler {
+func NewImportOrdersUseCase(orderRepository entity.OrderRepositoryInterface, eventDispatcher events.EventDispatcherInterface) *usecase.ImportOrdersUseCase {
+	wire.Build(
+		usecase.NewImportOrdersUseCase,
+	)
+	return &usecase.ImportOrdersUseCase{}
+}
+
+func NewCreateOrdersUseCase(orderRepository entity.OrderRepositoryInterface, eventDispatcher events.EventDispatcherInterface) *usecase.CreateOrdersUseCase {
+	wire.Build(
+		usecase.NewImportOrdersUseCase,
+		usecase.NewCreateOrdersUseCase,
+	)
+	return &usecase.CreateOrdersUseCase{}
+}
+
+func NewStreamOrdersUseCase(orderViewRepository entity.OrderViewRepositoryInterface) *usecase.StreamOrdersUseCase {
+	wire.Build(
+		usecase.NewStreamOrdersUseCase,
+	)
+	return &usecase.StreamOrdersUseCase{}
+}
+
+func NewGetOrderUseCase(orderViewRepository entity.OrderViewRepositoryInterface) *usecase.GetOrderUseCase {
+	wire.Build(
+		usecase.NewGetOrderUseCase,
+	)
+	return &usecase.GetOrderUseCase{}
+}
+
+func NewReportOrdersUseCase(orderTotalsRepository entity.OrderTotalsRepositoryInterface) *usecase.ReportOrdersUseCase {
+	wire.Build(
+		usecase.NewReportOrdersUseCase,
+	)
+	return &usecase.ReportOrdersUseCase{}
+}
+
+func NewDeleteOrderUseCase(orderDeletionRepository entity.OrderDeletionRepositoryInterface, eventDispatcher events.EventDispatcherInterface) *usecase.DeleteOrderUseCase {
+	wire.Build(
+		usecase.NewDeleteOrderUseCase,
+	)
+	return &usecase.DeleteOrderUseCase{}
+}
+
+func NewRestoreOrderUseCase(orderDeletionRepository entity.OrderDeletionRepositoryInterface, eventDispatcher events.EventDispatcherInterface) *usecase.RestoreOrderUseCase {
+	wire.Build(
+		usecase.NewRestoreOrderUseCase,
+	)
+	return &usecase.RestoreOrderUseCase{}
+}
+
+func NewApplyRetentionUseCase(orderRetentionRepository entity.OrderRetentionRepositoryInterface, orderArchive entity.OrderArchiveInterface) *usecase.ApplyRetentionUseCase {
+	wire.Build(
+		usecase.NewApplyRetentionUseCase,
+	)
+	return &usecase.ApplyRetentionUseCase{}
+}
+
+func NewSearchOrdersUseCase(orderViewRepository entity.OrderViewRepositoryInterface) *usecase.SearchOrdersUseCase {
+	wire.Build(
+		usecase.NewSearchOrdersUseCase,
+	)
+	return &usecase.SearchOrdersUseCase{}
+}
+
+func NewReplayOrderEventsUseCase(orderHistory entity.OrderHistoryInterface) *usecase.ReplayOrderEventsUseCase {
+	wire.Build(
+		usecase.NewReplayOrderEventsUseCase,
+	)
+	return &usecase.ReplayOrderEventsUseCase{}
+}
+
+func NewReconcileOrderEventsUseCase(publishedEvents entity.PublishedEventRepositoryInterface, publisher usecase.OrderEventPublisher) *usecase.ReconcileOrderEventsUseCase {
 	wire.Build(
-		setOrderRepositoryDependency,
 		setOrderCreatedEvent,
+		usecase.NewReconcileOrderEventsUseCase,
+	)
+	return &usecase.ReconcileOrderEventsUseCase{}
+}
+
+func NewGetOrderHistoryUseCase(orderAuditRepository entity.OrderAuditRepositoryInterface) *usecase.GetOrderHistoryUseCase {
+	wire.Build(
+		usecase.NewGetOrderHistoryUseCase,
+	)
+	return &usecase.GetOrderHistoryUseCase{}
+}
+
+func NewWebOrderHandler(orderRepository entity.OrderRepositoryInterface, orderViewRepository entity.OrderViewRepositoryInterface, orderAuditRepository entity.OrderAuditRepositoryInterface, eventDispatcher events.EventDispatcherInterface) *web.WebOrderHandler {
+	wire.Build(
 		web.NewWebOrderHandler,
 	)
 	return &web.WebOrderHandler{}
@@ -7,10 +7,8 @@
 package main
 
 import (
-	"database/sql"
 	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
 	"github.com/alexandreti/posGoExpert/clean-architecture/internal/event"
-	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/database"
 	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/web"
 	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
 	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
@@ -19,34 +17,91 @@ import (
 
 import (
 	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 )
 
 // Injectors from wire.go:
 
-func NewCreateOrderUseCase(db *sql.DB, eventDispatcher events.EventDispatcherInterface) *usecase.CreateOrderUseCase {
-	orderRepository := database.NewOrderRepository(db)
-	orderCreated := event.NewOrderCreated()
-	createOrderUseCase := usecase.NewCreateOrderUseCase(orderRepository, orderCreated, eventDispatcher)
+func NewCreateOrderUseCase(orderRepository entity.OrderRepositoryInterface, eventDispatcher events.EventDispatcherInterface) *usecase.CreateOrderUseCase {
+	createOrderUseCase := usecase.NewCreateOrderUseCase(orderRepository, eventDispatcher)
 	return createOrderUseCase
 }
 
-func NewListOrdersUseCase(db *sql.DB) *usecase.ListOrdersUseCase {
-	orderRepository := database.NewOrderRepository(db)
-	listOrdersUseCase := usecase.NewListOrdersUseCase(orderRepository)
+func NewListOrdersUseCase(orderViewRepository entity.OrderViewRepositoryInterface) *usecase.ListOrdersUseCase {
+	listOrdersUseCase := usecase.NewListOrdersUseCase(orderViewRepository)
 	return listOrdersUseCase
 }
 
-func NewWebOrderHandler(db *sql.DB, eventDispatcher events.EventDispatcherInterface) *web.WebOrderHandler {
-	orderRepository := database.NewOrderRepository(db)
+func NewImportOrdersUseCase(orderRepository entity.OrderRepositoryInterface, eventDispatcher events.EventDispatcherInterface) *usecase.ImportOrdersUseCase {
+	importOrdersUseCase := usecase.NewImportOrdersUseCase(orderRepository, eventDispatcher)
+	return importOrdersUseCase
+}
+
+func NewCreateOrdersUseCase(orderRepository entity.OrderRepositoryInterface, eventDispatcher events.EventDispatcherInterface) *usecase.CreateOrdersUseCase {
+	importOrdersUseCase := usecase.NewImportOrdersUseCase(orderRepository, eventDispatcher)
+	createOrdersUseCase := usecase.NewCreateOrdersUseCase(importOrdersUseCase)
+	return createOrdersUseCase
+}
+
+func NewStreamOrdersUseCase(orderViewRepository entity.OrderViewRepositoryInterface) *usecase.StreamOrdersUseCase {
+	streamOrdersUseCase := usecase.NewStreamOrdersUseCase(orderViewRepository)
+	return streamOrdersUseCase
+}
+
+func NewGetOrderUseCase(orderViewRepository entity.OrderViewRepositoryInterface) *usecase.GetOrderUseCase {
+	getOrderUseCase := usecase.NewGetOrderUseCase(orderViewRepository)
+	return getOrderUseCase
+}
+
+func NewReportOrdersUseCase(orderTotalsRepository entity.OrderTotalsRepositoryInterface) *usecase.ReportOrdersUseCase {
+	reportOrdersUseCase := usecase.NewReportOrdersUseCase(orderTotalsRepository)
+	return reportOrdersUseCase
+}
+
+func NewDeleteOrderUseCase(orderDeletionRepository entity.OrderDeletionRepositoryInterface, eventDispatcher events.EventDispatcherInterface) *usecase.DeleteOrderUseCase {
+	deleteOrderUseCase := usecase.NewDeleteOrderUseCase(orderDeletionRepository, eventDispatcher)
+	return deleteOrderUseCase
+}
+
+func NewRestoreOrderUseCase(orderDeletionRepository entity.OrderDeletionRepositoryInterface, eventDispatcher events.EventDispatcherInterface) *usecase.RestoreOrderUseCase {
+	restoreOrderUseCase := usecase.NewRestoreOrderUseCase(orderDeletionRepository, eventDispatcher)
+	return restoreOrderUseCase
+}
+
+func NewApplyRetentionUseCase(orderRetentionRepository entity.OrderRetentionRepositoryInterface, orderArchive entity.OrderArchiveInterface) *usecase.ApplyRetentionUseCase {
+	applyRetentionUseCase := usecase.NewApplyRetentionUseCase(orderRetentionRepository, orderArchive)
+	return applyRetentionUseCase
+}
+
+func NewSearchOrdersUseCase(orderViewRepository entity.OrderViewRepositoryInterface) *usecase.SearchOrdersUseCase {
+	searchOrdersUseCase := usecase.NewSearchOrdersUseCase(orderViewRepository)
+	return searchOrdersUseCase
+}
+
+func NewReplayOrderEventsUseCase(orderHistory entity.OrderHistoryInterface) *usecase.ReplayOrderEventsUseCase {
+	replayOrderEventsUseCase := usecase.NewReplayOrderEventsUseCase(orderHistory)
+	return replayOrderEventsUseCase
+}
+
+func NewReconcileOrderEventsUseCase(publishedEvents entity.PublishedEventRepositoryInterface, publisher usecase.OrderEventPublisher) *usecase.ReconcileOrderEventsUseCase {
 	orderCreated := event.NewOrderCreated()
-	webOrderHandler := web.NewWebOrderHandler(eventDispatcher, orderRepository, orderCreated)
+	reconcileOrderEventsUseCase := usecase.NewReconcileOrderEventsUseCase(publishedEvents, orderCreated, publisher)
+	return reconcileOrderEventsUseCase
+}
+
+func NewGetOrderHistoryUseCase(orderAuditRepository entity.OrderAuditRepositoryInterface) *usecase.GetOrderHistoryUseCase {
+	getOrderHistoryUseCase := usecase.NewGetOrderHistoryUseCase(orderAuditRepository)
+	return getOrderHistoryUseCase
+}
+
+func NewWebOrderHandler(orderRepository entity.OrderRepositoryInterface, orderViewRepository entity.OrderViewRepositoryInterface, orderAuditRepository entity.OrderAuditRepositoryInterface, eventDispatcher events.EventDispatcherInterface) *web.WebOrderHandler {
+	webOrderHandler := web.NewWebOrderHandler(eventDispatcher, orderRepository, orderViewRepository, orderAuditRepository)
 	return webOrderHandler
 }
 
 // wire.go:
 
-var setOrderRepositoryDependency = wire.NewSet(database.NewOrderRepository, wire.Bind(new(entity.OrderRepositoryInterface), new(*database.OrderRepository)))
-
 var setEventDispatcherDependency = wire.NewSet(events.NewEventDispatcher, event.NewOrderCreated, wire.Bind(new(events.EventInterface), new(*event.OrderCreated)), wire.Bind(new(events.EventDispatcherInterface), new(*events.EventDispatcher)))
 
 var setOrderCreatedEvent = wire.NewSet(event.NewOrderCreated, wire.Bind(new(events.EventInterface), new(*event.OrderCreated)))
@@ -1,6 +1,120 @@
 package entity
 
+import (
+	"context"
+	"time"
+)
+
 type OrderRepositoryInterface interface {
-	Save(order *Order) error
-	ListOrders() ([]Order, error)
+	Save(ctx context.Context, order *Order) error
+	// SaveBatch saves new orders in a single transaction: either all of them
+	// are saved or none is.
+	SaveBatch(ctx context.Context, orders []*Order) error
+	ListOrders(ctx context.Context) ([]Order, error)
+	// ExistingIDs returns which of ids are taken by a stored order, deleted
+	// or not; saving a new order with one of them fails with
+	// ErrOrderAlreadyExists.
+	ExistingIDs(ctx context.Context, ids []string) ([]string, error)
+}
+
+// OrderDeletionRepositoryInterface soft deletes and restores the stored orders.
+// Both record the change, with its audit entry, and return its event.
+type OrderDeletionRepositoryInterface interface {
+	// Delete fails with ErrOrderNotFound for an unknown order and with
+	// ErrOrderDeleted for one already deleted.
+	Delete(ctx context.Context, id string) (OrderEvent, error)
+	// Restore fails with ErrOrderNotFound for an unknown order and with
+	// ErrOrderNotDeleted for one that isn't deleted.
+	Restore(ctx context.Context, id string) (OrderEvent, error)
+}
+
+// OrderRetentionRepositoryInterface finds and purges the orders past their
+// retention window.
+type OrderRetentionRepositoryInterface interface {
+	// Expired returns up to limit orders with an ID greater than afterID,
+	// ordered by ID, created before createdBefore or soft deleted before
+	// deletedBefore. A zero time turns that rule off.
+	Expired(ctx context.Context, createdBefore, deletedBefore time.Time, afterID string, limit int) ([]ArchivedOrder, error)
+	// Purge removes those of the orders that still match the rules of
+	// Expired, and everything recorded about them, and returns their IDs.
+	Purge(ctx context.Context, createdBefore, deletedBefore time.Time, ids []string) ([]string, error)
+}
+
+// OrderArchiveInterface keeps the orders the retention purges. An order may be
+// archived again when its purge failed.
+type OrderArchiveInterface interface {
+	Archive(ctx context.Context, orders []ArchivedOrder) error
+}
+
+// OrderTotalsRepositoryInterface sums the stored orders, for the reports.
+type OrderTotalsRepositoryInterface interface {
+	// GetTotals sums, for each period, the orders created in it, with the
+	// values they have now. The periods must be sorted and contiguous, as
+	// OrderReportGrouping.Split cuts them; the range they span is read once.
+	GetTotals(ctx context.Context, periods []OrderPeriod) ([]OrderTotals, error)
+}
+
+// OrderViewRepositoryInterface is the read model of the orders, kept up to date
+// by projecting the order events. Apply must be idempotent: an event at or
+// below the version already projected is skipped and reported as not applied.
+// The queries leave the deleted orders out.
+type OrderViewRepositoryInterface interface {
+	Apply(ctx context.Context, event OrderEvent) (applied bool, err error)
+	Reset(ctx context.Context) error
+	// FindOrder returns ErrOrderNotFound for an order not in the read model.
+	FindOrder(ctx context.Context, id string) (OrderView, error)
+	ListOrders(ctx context.Context) ([]OrderView, error)
+	// ListOrdersPage returns up to limit orders with an ID greater than
+	// afterID, ordered by ID.
+	ListOrdersPage(ctx context.Context, afterID string, limit int) ([]OrderView, error)
+	// SearchOrders is ListOrdersPage restricted to the orders matching query.
+	SearchOrders(ctx context.Context, query OrderQuery, afterID string, limit int) ([]OrderView, error)
+	// ForEach calls fn for every order of the read model, ordered by ID,
+	// reading them in batches instead of all at once.
+	ForEach(ctx context.Context, fn func(view OrderView) error) error
+}
+
+// OrderHistoryInterface replays every recorded order event, in version order
+// within each order, to rebuild the read model.
+type OrderHistoryInterface interface {
+	ReplayEvents(ctx context.Context, fn func(event OrderEvent) error) error
+	// ReplayOrderEvents replays the events of the order id only, in version
+	// order; an unknown order has none.
+	ReplayOrderEvents(ctx context.Context, id string, fn func(event OrderEvent) error) error
+}
+
+// OrderAuditRepositoryInterface reads the audit trail written along with every
+// order mutation.
+type OrderAuditRepositoryInterface interface {
+	History(ctx context.Context, orderID string) ([]OrderAuditEntry, error)
+	// Histories returns the entries of several orders in one query, keyed by
+	// order ID. Orders without entries are left out.
+	Histories(ctx context.Context, orderIDs []string) (map[string][]OrderAuditEntry, error)
+}
+
+// PublishedEventRepositoryInterface is the log of the order events published to
+// the message broker. Comparing it with the stored orders shows the events that
+// were lost on the way.
+type PublishedEventRepositoryInterface interface {
+	// MarkPublished records that the eventName event of the order was
+	// published. Marking it again is not an error.
+	MarkPublished(ctx context.Context, eventName, orderID string) error
+	// Unpublished returns up to limit stored orders with an ID greater than
+	// afterID, ordered by ID, whose eventName event was never published.
+	Unpublished(ctx context.Context, eventName, afterID string, limit int) ([]Order, error)
+	// MarkMissing records at as the time the eventName event of each order
+	// was first found missing, unless it's already recorded, and returns the
+	// recorded time of each order by ID.
+	MarkMissing(ctx context.Context, eventName string, orderIDs []string, at time.Time) (map[string]time.Time, error)
+	// PruneMissing forgets the orders marked missing whose eventName event
+	// was published since.
+	PruneMissing(ctx context.Context, eventName string) error
+}
+
+// LeaseRepositoryInterface hands out named leases, so that a job runs on one
+// replica at a time.
+type LeaseRepositoryInterface interface {
+	// Acquire takes the lease for holder, or renews it when holder already has
+	// it, until ttl from now. It returns false while another holder keeps it.
+	Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
 }
@@ -1,12 +1,23 @@
 package entity
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 type Order struct {
 	ID         string
 	Price      float64
 	Tax        float64
 	FinalPrice float64
+	// Version is the number of events applied to the order. The event store
+	// uses it for optimistic concurrency.
+	Version int
+	// DeletedAt is set while the order is soft deleted.
+	DeletedAt *time.Time
+	// CreatedAt is set by Save and SaveBatch when they create the order; the
+	// orders read back leave it zero.
+	CreatedAt time.Time
 }
 
 func NewOrder(id string, price float64, tax float64) (*Order, error) {
@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// ArchivedOrder is an order as the retention archives it, before purging it.
+// CreatedAt is zero for an order saved without a creation time.
+type ArchivedOrder struct {
+	ID         string     `json:"id"`
+	Price      float64    `json:"price"`
+	Tax        float64    `json:"tax"`
+	FinalPrice float64    `json:"final_price"`
+	CreatedAt  time.Time  `json:"created_at"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+	ArchivedAt time.Time  `json:"archived_at"`
+}
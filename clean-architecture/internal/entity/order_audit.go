@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrOrderNotFound = errors.New("order not found")
+
+// ErrOrderAlreadyExists is returned when saving a new order whose ID is taken
+// by a stored one, deleted or not.
+var ErrOrderAlreadyExists = errors.New("order id already exists")
+
+// OrderAuditEntry records one mutation of an order: who made it, through which
+// transport and request, and the state before and after it. Before is nil when
+// the order was created.
+type OrderAuditEntry struct {
+	OrderID   string
+	Action    string
+	Actor     string
+	Transport string
+	RequestID string
+	Before    *OrderEventData
+	After     *OrderEventData
+	Timestamp time.Time
+}
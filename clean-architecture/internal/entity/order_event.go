@@ -0,0 +1,121 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	OrderCreatedEvent  = "OrderCreated"
+	OrderUpdatedEvent  = "OrderUpdated"
+	OrderDeletedEvent  = "OrderDeleted"
+	OrderRestoredEvent = "OrderRestored"
+)
+
+var (
+	ErrInvalidEventVersion = errors.New("invalid event version")
+	// ErrOrderDeleted is returned for a change to a deleted order.
+	ErrOrderDeleted = errors.New("order is deleted")
+	// ErrOrderNotDeleted is returned when restoring an order that isn't deleted.
+	ErrOrderNotDeleted = errors.New("order is not deleted")
+)
+
+// OrderEvent is one change in the history of an order. Data holds the state of
+// the order after the change.
+type OrderEvent struct {
+	OrderID   string
+	Version   int
+	Type      string
+	Data      OrderEventData
+	Timestamp time.Time
+}
+
+type OrderEventData struct {
+	Price      float64 `json:"price"`
+	Tax        float64 `json:"tax"`
+	FinalPrice float64 `json:"final_price"`
+	// DeletedAt is set while the order is soft deleted.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// Change returns the event that takes the stored order to the current state:
+// OrderCreated for a new order (Version 0) and OrderUpdated otherwise.
+func (o *Order) Change(at time.Time) OrderEvent {
+	eventType := OrderUpdatedEvent
+	if o.Version == 0 {
+		eventType = OrderCreatedEvent
+	}
+	return o.next(eventType, o.DeletedAt, at)
+}
+
+// Delete returns the OrderDeleted event that soft deletes the order at the
+// given time. It fails with ErrOrderDeleted when the order is already deleted.
+func (o *Order) Delete(at time.Time) (OrderEvent, error) {
+	if o.DeletedAt != nil {
+		return OrderEvent{}, fmt.Errorf("%w: %s", ErrOrderDeleted, o.ID)
+	}
+	return o.next(OrderDeletedEvent, &at, at), nil
+}
+
+// Restore returns the OrderRestored event that undoes the soft delete of the
+// order. It fails with ErrOrderNotDeleted when the order isn't deleted.
+func (o *Order) Restore(at time.Time) (OrderEvent, error) {
+	if o.DeletedAt == nil {
+		return OrderEvent{}, fmt.Errorf("%w: %s", ErrOrderNotDeleted, o.ID)
+	}
+	return o.next(OrderRestoredEvent, nil, at), nil
+}
+
+func (o *Order) next(eventType string, deletedAt *time.Time, at time.Time) OrderEvent {
+	return OrderEvent{
+		OrderID: o.ID,
+		Version: o.Version + 1,
+		Type:    eventType,
+		Data: OrderEventData{
+			Price:      o.Price,
+			Tax:        o.Tax,
+			FinalPrice: o.FinalPrice,
+			DeletedAt:  deletedAt,
+		},
+		Timestamp: at,
+	}
+}
+
+// Apply moves the order to the state of the event, which must be the next one.
+func (o *Order) Apply(event OrderEvent) error {
+	if event.Version != o.Version+1 {
+		return fmt.Errorf("%w: order %s is at version %d, got %d", ErrInvalidEventVersion, event.OrderID, o.Version, event.Version)
+	}
+	switch event.Type {
+	case OrderCreatedEvent:
+		o.ID = event.OrderID
+	case OrderUpdatedEvent, OrderDeletedEvent, OrderRestoredEvent:
+		if o.Version == 0 {
+			return fmt.Errorf("order %s changed before being created", event.OrderID)
+		}
+	default:
+		return fmt.Errorf("unknown order event %q", event.Type)
+	}
+	o.Price = event.Data.Price
+	o.Tax = event.Data.Tax
+	o.FinalPrice = event.Data.FinalPrice
+	o.DeletedAt = event.Data.DeletedAt
+	o.Version = event.Version
+	return nil
+}
+
+// RebuildOrder replays the events over the snapshot, or over an empty order
+// when there is no snapshot.
+func RebuildOrder(snapshot *Order, events []OrderEvent) (*Order, error) {
+	order := &Order{}
+	if snapshot != nil {
+		*order = *snapshot
+	}
+	for _, event := range events {
+		if err := order.Apply(event); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
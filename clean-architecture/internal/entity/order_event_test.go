@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGivenANewOrder_WhenICallChange_ThenIShouldReceiveAnOrderCreatedEvent(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	order := Order{ID: "123", Price: 10.0, Tax: 2.0, FinalPrice: 12.0}
+
+	event := order.Change(at)
+	assert.Equal(t, OrderEvent{
+		OrderID:   "123",
+		Version:   1,
+		Type:      OrderCreatedEvent,
+		Data:      OrderEventData{Price: 10.0, Tax: 2.0, FinalPrice: 12.0},
+		Timestamp: at,
+	}, event)
+
+	order.Version = 1
+	assert.Equal(t, OrderUpdatedEvent, order.Change(at).Type)
+	assert.Equal(t, 2, order.Change(at).Version)
+}
+
+func TestGivenEvents_WhenICallRebuildOrder_ThenIShouldReceiveTheLastState(t *testing.T) {
+	events := []OrderEvent{
+		{OrderID: "123", Version: 1, Type: OrderCreatedEvent, Data: OrderEventData{Price: 10.0, Tax: 2.0, FinalPrice: 12.0}},
+		{OrderID: "123", Version: 2, Type: OrderUpdatedEvent, Data: OrderEventData{Price: 20.0, Tax: 2.0, FinalPrice: 22.0}},
+	}
+
+	order, err := RebuildOrder(nil, events)
+	assert.Nil(t, err)
+	assert.Equal(t, &Order{ID: "123", Price: 20.0, Tax: 2.0, FinalPrice: 22.0, Version: 2}, order)
+
+	order, err = RebuildOrder(&Order{ID: "123", Price: 10.0, Tax: 2.0, FinalPrice: 12.0, Version: 1}, events[1:])
+	assert.Nil(t, err)
+	assert.Equal(t, 22.0, order.FinalPrice)
+}
+
+func TestGivenEventsOutOfOrder_WhenICallRebuildOrder_ThenIShouldReceiveAnError(t *testing.T) {
+	_, err := RebuildOrder(nil, []OrderEvent{{OrderID: "123", Version: 2, Type: OrderCreatedEvent}})
+	assert.ErrorIs(t, err, ErrInvalidEventVersion)
+
+	_, err = RebuildOrder(nil, []OrderEvent{{OrderID: "123", Version: 1, Type: OrderUpdatedEvent}})
+	assert.Error(t, err)
+}
+
+func TestGivenAnOrder_WhenICallDeleteAndRestore_ThenIShouldReceiveTheirEvents(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	order := &Order{ID: "123", Price: 10.0, Tax: 2.0, FinalPrice: 12.0, Version: 1}
+
+	_, err := order.Restore(at)
+	assert.ErrorIs(t, err, ErrOrderNotDeleted)
+
+	deleted, err := order.Delete(at)
+	assert.NoError(t, err)
+	assert.Equal(t, OrderEvent{
+		OrderID:   "123",
+		Version:   2,
+		Type:      OrderDeletedEvent,
+		Data:      OrderEventData{Price: 10.0, Tax: 2.0, FinalPrice: 12.0, DeletedAt: &at},
+		Timestamp: at,
+	}, deleted)
+	assert.NoError(t, order.Apply(deleted))
+	assert.Equal(t, &at, order.DeletedAt)
+
+	_, err = order.Delete(at)
+	assert.ErrorIs(t, err, ErrOrderDeleted)
+
+	restored, err := order.Restore(at.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, OrderRestoredEvent, restored.Type)
+	assert.Nil(t, restored.Data.DeletedAt)
+	assert.NoError(t, order.Apply(restored))
+	assert.Equal(t, &Order{ID: "123", Price: 10.0, Tax: 2.0, FinalPrice: 12.0, Version: 3}, order)
+}
@@ -0,0 +1,259 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidOrderQuery is returned by ParseOrderQuery for a query it can't
+// parse; the wrapping error tells which term is wrong.
+var ErrInvalidOrderQuery = errors.New("invalid order query")
+
+const (
+	// MaxOrderQueryLength is the longest query accepted, in bytes.
+	MaxOrderQueryLength = 1024
+	// MaxOrderQueryTerms is how many terms a query may have.
+	MaxOrderQueryTerms = 16
+)
+
+// OrderQueryField is a field of the order a term filters on.
+type OrderQueryField string
+
+const (
+	OrderQueryID         OrderQueryField = "id"
+	OrderQueryPrice      OrderQueryField = "price"
+	OrderQueryTax        OrderQueryField = "tax"
+	OrderQueryFinalPrice OrderQueryField = "final_price"
+	OrderQueryCreated    OrderQueryField = "created"
+)
+
+// OrderIDMatch tells how an id term matches the order ID.
+type OrderIDMatch int
+
+const (
+	OrderIDExact OrderIDMatch = iota
+	OrderIDPrefix
+	OrderIDSuffix
+	OrderIDContains
+)
+
+// OrderQueryBound is one end of a range. Value is a float64, or a time.Time
+// for the created field.
+type OrderQueryBound struct {
+	Value     any
+	Inclusive bool
+}
+
+// OrderQueryTerm is one condition of an OrderQuery. The id terms match Text as
+// told by Match; the other fields are bounded by Lower and/or Upper.
+type OrderQueryTerm struct {
+	Field OrderQueryField
+	Match OrderIDMatch
+	Text  string
+	Lower *OrderQueryBound
+	Upper *OrderQueryBound
+}
+
+// OrderQuery is a parsed search: an order matches when it matches every term.
+// A query without terms matches every order.
+type OrderQuery struct {
+	Terms []OrderQueryTerm
+}
+
+// ParseOrderQuery parses a search made of terms separated by spaces:
+//
+//	id:ABC        the order ID is ABC
+//	id:ABC*       the ID starts with ABC; *ABC ends with it and *ABC* contains it
+//	ABC           a bare word, same as id:*ABC*
+//	price>100     also >=, < and <=, on price, tax and final_price
+//	price:100     the price is 100; price:10..20 is between 10 and 20, inclusive
+//	created:2026-01..2026-02
+//	              created from January to February 2026, inclusive
+//
+// Dates are UTC and may be a year (2026), a month (2026-01), a day
+// (2026-01-15) or an RFC 3339 instant. A date stands for its whole period, so
+// created>2026-01 means created from February 2026 on. Either side of a range
+// may be left open: price:10.. or created:..2026-01. Field names are case
+// insensitive; the wildcard id matches are too.
+func ParseOrderQuery(query string) (OrderQuery, error) {
+	if len(query) > MaxOrderQueryLength {
+		return OrderQuery{}, fmt.Errorf("%w: longer than %d bytes", ErrInvalidOrderQuery, MaxOrderQueryLength)
+	}
+	words := strings.Fields(query)
+	if len(words) > MaxOrderQueryTerms {
+		return OrderQuery{}, fmt.Errorf("%w: more than %d terms", ErrInvalidOrderQuery, MaxOrderQueryTerms)
+	}
+
+	var parsed OrderQuery
+	for _, word := range words {
+		term, err := parseOrderQueryTerm(word)
+		if err != nil {
+			return OrderQuery{}, fmt.Errorf("%w: %q: %s", ErrInvalidOrderQuery, word, err)
+		}
+		parsed.Terms = append(parsed.Terms, term)
+	}
+	return parsed, nil
+}
+
+func parseOrderQueryTerm(word string) (OrderQueryTerm, error) {
+	i := strings.IndexAny(word, ":<>")
+	if i < 0 {
+		return parseIDTerm("*" + word + "*")
+	}
+	field := OrderQueryField(strings.ToLower(word[:i]))
+	op, value := word[i:i+1], word[i+1:]
+	if op != ":" && strings.HasPrefix(value, "=") {
+		op, value = op+"=", value[1:]
+	}
+	if value == "" {
+		return OrderQueryTerm{}, errors.New("missing value")
+	}
+
+	switch field {
+	case OrderQueryID:
+		if op != ":" {
+			return OrderQueryTerm{}, errors.New("id only supports id:VALUE")
+		}
+		return parseIDTerm(value)
+	case OrderQueryPrice, OrderQueryTax, OrderQueryFinalPrice:
+		return parseRangeTerm(field, op, value, parseAmount)
+	case OrderQueryCreated:
+		return parseRangeTerm(field, op, value, parseDate)
+	case "":
+		return OrderQueryTerm{}, errors.New("missing field")
+	default:
+		return OrderQueryTerm{}, fmt.Errorf("unknown field, expected one of %s, %s, %s, %s or %s",
+			OrderQueryID, OrderQueryPrice, OrderQueryTax, OrderQueryFinalPrice, OrderQueryCreated)
+	}
+}
+
+func parseIDTerm(value string) (OrderQueryTerm, error) {
+	term := OrderQueryTerm{Field: OrderQueryID, Match: OrderIDExact}
+	prefix, suffix := strings.HasSuffix(value, "*"), strings.HasPrefix(value, "*")
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "*"), "*")
+	switch {
+	case prefix && suffix:
+		term.Match = OrderIDContains
+	case prefix:
+		term.Match = OrderIDPrefix
+	case suffix:
+		term.Match = OrderIDSuffix
+	}
+	if value == "" {
+		return OrderQueryTerm{}, errors.New("missing value")
+	}
+	if strings.Contains(value, "*") {
+		return OrderQueryTerm{}, errors.New("* is only allowed at the start or the end")
+	}
+	term.Text = value
+	return term, nil
+}
+
+// period is what a value of the query stands for, from start to end. Inclusive
+// tells whether end itself is part of it: an amount is a single point, a date a
+// half-open period.
+type period struct {
+	start, end any
+	inclusive  bool
+}
+
+func parseRangeTerm(field OrderQueryField, op, value string, parse func(string) (period, error)) (OrderQueryTerm, error) {
+	term := OrderQueryTerm{Field: field}
+	if op != ":" {
+		p, err := parse(value)
+		if err != nil {
+			return OrderQueryTerm{}, err
+		}
+		switch op {
+		case ">":
+			term.Lower = &OrderQueryBound{Value: p.end, Inclusive: !p.inclusive}
+		case ">=":
+			term.Lower = &OrderQueryBound{Value: p.start, Inclusive: true}
+		case "<":
+			term.Upper = &OrderQueryBound{Value: p.start}
+		case "<=":
+			term.Upper = &OrderQueryBound{Value: p.end, Inclusive: p.inclusive}
+		}
+		return term, nil
+	}
+
+	from, to, isRange := strings.Cut(value, "..")
+	if !isRange {
+		to = from
+	}
+	if from == "" && to == "" {
+		return OrderQueryTerm{}, errors.New("missing value")
+	}
+	if from != "" {
+		p, err := parse(from)
+		if err != nil {
+			return OrderQueryTerm{}, err
+		}
+		term.Lower = &OrderQueryBound{Value: p.start, Inclusive: true}
+	}
+	if to != "" {
+		p, err := parse(to)
+		if err != nil {
+			return OrderQueryTerm{}, err
+		}
+		term.Upper = &OrderQueryBound{Value: p.end, Inclusive: p.inclusive}
+	}
+	if term.Lower != nil && term.Upper != nil && empty(term.Lower.Value, *term.Upper) {
+		return OrderQueryTerm{}, errors.New("the range ends before it starts")
+	}
+	return term, nil
+}
+
+func parseAmount(value string) (period, error) {
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return period{}, errors.New("expected a number")
+	}
+	return period{start: amount, end: amount, inclusive: true}, nil
+}
+
+func parseDate(value string) (period, error) {
+	for _, layout := range []struct {
+		layout string
+		next   func(t time.Time) time.Time
+	}{
+		{"2006", func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }},
+		{"2006-01", func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }},
+		{"2006-01-02", func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }},
+	} {
+		if len(value) != len(layout.layout) {
+			continue
+		}
+		if t, err := time.Parse(layout.layout, value); err == nil {
+			return period{start: t, end: layout.next(t)}, nil
+		}
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return period{}, errors.New("expected a date like 2026, 2026-01, 2026-01-15 or 2026-01-15T10:00:00Z")
+	}
+	t = t.UTC()
+	return period{start: t, end: t, inclusive: true}, nil
+}
+
+// empty tells whether nothing is between lower and upper.
+func empty(lower any, upper OrderQueryBound) bool {
+	if upper.Inclusive {
+		return after(lower, upper.Value)
+	}
+	return !after(upper.Value, lower)
+}
+
+func after(a, b any) bool {
+	switch a := a.(type) {
+	case float64:
+		return a > b.(float64)
+	case time.Time:
+		return a.After(b.(time.Time))
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestGivenAQuery_WhenICallParseOrderQuery_ThenIShouldReceiveItsTerms(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		terms []OrderQueryTerm
+	}{
+		{"", nil},
+		{"id:ABC", []OrderQueryTerm{{Field: OrderQueryID, Match: OrderIDExact, Text: "ABC"}}},
+		{"ID:ABC*", []OrderQueryTerm{{Field: OrderQueryID, Match: OrderIDPrefix, Text: "ABC"}}},
+		{"id:*ABC", []OrderQueryTerm{{Field: OrderQueryID, Match: OrderIDSuffix, Text: "ABC"}}},
+		{"ABC", []OrderQueryTerm{{Field: OrderQueryID, Match: OrderIDContains, Text: "ABC"}}},
+		{"price>100", []OrderQueryTerm{{Field: OrderQueryPrice, Lower: &OrderQueryBound{Value: 100.0}}}},
+		{"tax>=1.5", []OrderQueryTerm{{Field: OrderQueryTax, Lower: &OrderQueryBound{Value: 1.5, Inclusive: true}}}},
+		{"final_price<10", []OrderQueryTerm{{Field: OrderQueryFinalPrice, Upper: &OrderQueryBound{Value: 10.0}}}},
+		{"price<=10", []OrderQueryTerm{{Field: OrderQueryPrice, Upper: &OrderQueryBound{Value: 10.0, Inclusive: true}}}},
+		{"price:10", []OrderQueryTerm{{Field: OrderQueryPrice,
+			Lower: &OrderQueryBound{Value: 10.0, Inclusive: true}, Upper: &OrderQueryBound{Value: 10.0, Inclusive: true}}}},
+		{"price:10..20", []OrderQueryTerm{{Field: OrderQueryPrice,
+			Lower: &OrderQueryBound{Value: 10.0, Inclusive: true}, Upper: &OrderQueryBound{Value: 20.0, Inclusive: true}}}},
+		{"price:10..", []OrderQueryTerm{{Field: OrderQueryPrice, Lower: &OrderQueryBound{Value: 10.0, Inclusive: true}}}},
+		{"created:2026-01..2026-02", []OrderQueryTerm{{Field: OrderQueryCreated,
+			Lower: &OrderQueryBound{Value: date(2026, 1, 1), Inclusive: true}, Upper: &OrderQueryBound{Value: date(2026, 3, 1)}}}},
+		{"created:2026", []OrderQueryTerm{{Field: OrderQueryCreated,
+			Lower: &OrderQueryBound{Value: date(2026, 1, 1), Inclusive: true}, Upper: &OrderQueryBound{Value: date(2027, 1, 1)}}}},
+		{"created:..2026-01-15", []OrderQueryTerm{{Field: OrderQueryCreated, Upper: &OrderQueryBound{Value: date(2026, 1, 16)}}}},
+		{"created>2026-01", []OrderQueryTerm{{Field: OrderQueryCreated, Lower: &OrderQueryBound{Value: date(2026, 2, 1), Inclusive: true}}}},
+		{"created<2026-01", []OrderQueryTerm{{Field: OrderQueryCreated, Upper: &OrderQueryBound{Value: date(2026, 1, 1)}}}},
+		{"created>=2026-01-15T10:00:00-03:00", []OrderQueryTerm{{Field: OrderQueryCreated,
+			Lower: &OrderQueryBound{Value: time.Date(2026, 1, 15, 13, 0, 0, 0, time.UTC), Inclusive: true}}}},
+	} {
+		query, err := ParseOrderQuery(tc.query)
+		assert.NoError(t, err, tc.query)
+		assert.Equal(t, tc.terms, query.Terms, tc.query)
+	}
+}
+
+func TestGivenTheExampleQuery_WhenICallParseOrderQuery_ThenIShouldReceiveEveryTerm(t *testing.T) {
+	query, err := ParseOrderQuery("price>100 id:ABC*  created:2026-01..2026-02")
+	assert.NoError(t, err)
+	assert.Len(t, query.Terms, 3)
+	assert.Equal(t, []OrderQueryField{OrderQueryPrice, OrderQueryID, OrderQueryCreated},
+		[]OrderQueryField{query.Terms[0].Field, query.Terms[1].Field, query.Terms[2].Field})
+}
+
+func TestGivenAnInvalidQuery_WhenICallParseOrderQuery_ThenIShouldReceiveAnError(t *testing.T) {
+	for _, query := range []string{
+		"status:paid",
+		":10",
+		"price>",
+		"price:abc",
+		"price>NaN",
+		"price:Inf",
+		"price:20..10",
+		"price:..",
+		"id>ABC",
+		"id:*",
+		"id:A*B",
+		"created:2026-13",
+		"created:yesterday",
+		"created:2026-02..2026-01",
+		strings.Repeat("a ", MaxOrderQueryTerms+1),
+		strings.Repeat("a", MaxOrderQueryLength+1),
+	} {
+		_, err := ParseOrderQuery(query)
+		assert.ErrorIs(t, err, ErrInvalidOrderQuery, query)
+	}
+}
+
+func FuzzParseOrderQuery(f *testing.F) {
+	for _, seed := range []string{
+		"price>100 id:ABC* created:2026-01..2026-02",
+		"id:*a%b_c*", "tax<=1e308", "final_price:..-1", "created>=2026-01-15T10:00:00Z", "price:1..2..3", "ID:x:y",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, query string) {
+		parsed, err := ParseOrderQuery(query)
+		if err != nil {
+			assert.ErrorIs(t, err, ErrInvalidOrderQuery)
+			return
+		}
+		assert.LessOrEqual(t, len(parsed.Terms), MaxOrderQueryTerms)
+		for _, term := range parsed.Terms {
+			if term.Field == OrderQueryID {
+				assert.NotEmpty(t, term.Text)
+				assert.NotContains(t, term.Text, "*")
+				continue
+			}
+			assert.True(t, term.Lower != nil || term.Upper != nil, "term without bounds in %q", query)
+			for _, bound := range []*OrderQueryBound{term.Lower, term.Upper} {
+				if bound == nil {
+					continue
+				}
+				switch bound.Value.(type) {
+				case float64:
+					assert.NotEqual(t, OrderQueryCreated, term.Field)
+				case time.Time:
+					assert.Equal(t, OrderQueryCreated, term.Field)
+				default:
+					t.Fatalf("unexpected bound %#v in %q", bound.Value, query)
+				}
+			}
+		}
+	})
+}
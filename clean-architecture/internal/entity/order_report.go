@@ -0,0 +1,130 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidOrderReport is returned for a report with an invalid range or
+// grouping; the wrapping error tells what is wrong.
+var ErrInvalidOrderReport = errors.New("invalid order report")
+
+// OrderPeriod is the half-open interval [From, To) of the creation time of the
+// orders. A zero From or To leaves that side open, so the zero OrderPeriod
+// holds every order, even those stored without a creation time.
+type OrderPeriod struct {
+	From time.Time
+	To   time.Time
+}
+
+// OrderTotals sums the orders of a period.
+type OrderTotals struct {
+	Count      int
+	Price      float64
+	Tax        float64
+	FinalPrice float64
+}
+
+// Add returns the totals of both t and other.
+func (t OrderTotals) Add(other OrderTotals) OrderTotals {
+	return OrderTotals{
+		Count:      t.Count + other.Count,
+		Price:      t.Price + other.Price,
+		Tax:        t.Tax + other.Tax,
+		FinalPrice: t.FinalPrice + other.FinalPrice,
+	}
+}
+
+// AverageTicket is the mean final price of the orders, 0 when there are none.
+func (t OrderTotals) AverageTicket() float64 {
+	if t.Count == 0 {
+		return 0
+	}
+	return t.FinalPrice / float64(t.Count)
+}
+
+// ParseOrderReportRange parses the range of a report. Both ends take the dates
+// of ParseOrderQuery and stand for their whole period, so from 2026-01 to
+// 2026-03 is the first quarter of 2026, and from 2026-01-15 to 2026-01-15 is
+// that one day.
+func ParseOrderReportRange(from, to string) (OrderPeriod, error) {
+	if from == "" || to == "" {
+		return OrderPeriod{}, fmt.Errorf("%w: from and to are required", ErrInvalidOrderReport)
+	}
+	start, err := parseDate(from)
+	if err != nil {
+		return OrderPeriod{}, fmt.Errorf("%w: from: %s", ErrInvalidOrderReport, err)
+	}
+	end, err := parseDate(to)
+	if err != nil {
+		return OrderPeriod{}, fmt.Errorf("%w: to: %s", ErrInvalidOrderReport, err)
+	}
+	period := OrderPeriod{From: start.start.(time.Time), To: end.end.(time.Time)}
+	if end.inclusive {
+		// an instant ends the range right after it
+		period.To = period.To.Add(time.Nanosecond)
+	}
+	if !period.From.Before(period.To) {
+		return OrderPeriod{}, fmt.Errorf("%w: the range ends before it starts", ErrInvalidOrderReport)
+	}
+	return period, nil
+}
+
+// OrderReportGrouping is the length of the periods a report is split into.
+type OrderReportGrouping string
+
+const (
+	OrderReportDay   OrderReportGrouping = "day"
+	OrderReportWeek  OrderReportGrouping = "week"
+	OrderReportMonth OrderReportGrouping = "month"
+)
+
+// ParseOrderReportGrouping parses day, week or month, in any case; empty is
+// day.
+func ParseOrderReportGrouping(value string) (OrderReportGrouping, error) {
+	switch grouping := OrderReportGrouping(strings.ToLower(value)); grouping {
+	case "":
+		return OrderReportDay, nil
+	case OrderReportDay, OrderReportWeek, OrderReportMonth:
+		return grouping, nil
+	default:
+		return "", fmt.Errorf("%w: group by %q, expected %s, %s or %s",
+			ErrInvalidOrderReport, value, OrderReportDay, OrderReportWeek, OrderReportMonth)
+	}
+}
+
+// Split cuts the range into the UTC calendar days, weeks (from Monday) or
+// months it spans. The first and the last periods are cut to the range. It
+// fails when that makes more than limit periods.
+func (g OrderReportGrouping) Split(period OrderPeriod, limit int) ([]OrderPeriod, error) {
+	var periods []OrderPeriod
+	for start := period.From; start.Before(period.To); {
+		if len(periods) == limit {
+			return nil, fmt.Errorf("%w: more than %d periods, group by a longer period or shorten the range", ErrInvalidOrderReport, limit)
+		}
+		end := g.next(start)
+		if end.After(period.To) {
+			end = period.To
+		}
+		periods = append(periods, OrderPeriod{From: start, To: end})
+		start = end
+	}
+	return periods, nil
+}
+
+// next returns the start of the period after the one t is in.
+func (g OrderReportGrouping) next(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch g {
+	case OrderReportWeek:
+		// days since Monday
+		return day.AddDate(0, 0, 7-(int(day.Weekday())+6)%7)
+	case OrderReportMonth:
+		return time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day.AddDate(0, 0, 1)
+	}
+}
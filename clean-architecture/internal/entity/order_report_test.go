@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGivenARange_WhenICallParseOrderReportRange_ThenIShouldReceiveItsPeriod(t *testing.T) {
+	for _, tc := range []struct {
+		from, to string
+		period   OrderPeriod
+	}{
+		{"2026-01", "2026-03", OrderPeriod{From: date(2026, 1, 1), To: date(2026, 4, 1)}},
+		{"2026-01-15", "2026-01-15", OrderPeriod{From: date(2026, 1, 15), To: date(2026, 1, 16)}},
+		{"2026", "2026", OrderPeriod{From: date(2026, 1, 1), To: date(2027, 1, 1)}},
+		{"2026-01-15T10:00:00Z", "2026-01-15T12:00:00Z", OrderPeriod{
+			From: time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC), To: time.Date(2026, 1, 15, 12, 0, 0, 1, time.UTC)}},
+	} {
+		period, err := ParseOrderReportRange(tc.from, tc.to)
+		assert.NoError(t, err, tc.from)
+		assert.Equal(t, tc.period, period, tc.from)
+	}
+}
+
+func TestGivenAnInvalidRange_WhenICallParseOrderReportRange_ThenIShouldReceiveAnError(t *testing.T) {
+	for _, tc := range [][2]string{{"", "2026-01"}, {"2026-01", ""}, {"yesterday", "2026-01"}, {"2026-02", "2026-01"}} {
+		_, err := ParseOrderReportRange(tc[0], tc[1])
+		assert.ErrorIs(t, err, ErrInvalidOrderReport, tc)
+	}
+}
+
+func TestGivenAGrouping_WhenICallParseOrderReportGrouping_ThenIShouldReceiveIt(t *testing.T) {
+	for value, expected := range map[string]OrderReportGrouping{"": OrderReportDay, "WEEK": OrderReportWeek, "month": OrderReportMonth} {
+		grouping, err := ParseOrderReportGrouping(value)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, grouping)
+	}
+	_, err := ParseOrderReportGrouping("year")
+	assert.ErrorIs(t, err, ErrInvalidOrderReport)
+}
+
+func TestGivenARange_WhenICallSplit_ThenIShouldReceiveTheCalendarPeriodsCutToTheRange(t *testing.T) {
+	// 2026-01-07 is a Wednesday
+	period := OrderPeriod{From: date(2026, 1, 7), To: date(2026, 2, 10)}
+
+	weeks, err := OrderReportWeek.Split(period, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []OrderPeriod{
+		{From: date(2026, 1, 7), To: date(2026, 1, 12)},
+		{From: date(2026, 1, 12), To: date(2026, 1, 19)},
+		{From: date(2026, 1, 19), To: date(2026, 1, 26)},
+		{From: date(2026, 1, 26), To: date(2026, 2, 2)},
+		{From: date(2026, 2, 2), To: date(2026, 2, 9)},
+		{From: date(2026, 2, 9), To: date(2026, 2, 10)},
+	}, weeks)
+
+	months, err := OrderReportMonth.Split(period, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []OrderPeriod{
+		{From: date(2026, 1, 7), To: date(2026, 2, 1)},
+		{From: date(2026, 2, 1), To: date(2026, 2, 10)},
+	}, months)
+
+	days, err := OrderReportDay.Split(period, 100)
+	assert.NoError(t, err)
+	assert.Len(t, days, 34)
+	assert.Equal(t, OrderPeriod{From: date(2026, 2, 9), To: date(2026, 2, 10)}, days[33])
+
+	_, err = OrderReportDay.Split(period, 33)
+	assert.ErrorIs(t, err, ErrInvalidOrderReport)
+}
+
+func TestGivenTotals_WhenICallAverageTicket_ThenIShouldReceiveTheMeanFinalPrice(t *testing.T) {
+	totals := OrderTotals{Count: 1, Price: 10, Tax: 1, FinalPrice: 11}.Add(OrderTotals{Count: 3, Price: 30, Tax: 3, FinalPrice: 33})
+	assert.Equal(t, OrderTotals{Count: 4, Price: 40, Tax: 4, FinalPrice: 44}, totals)
+	assert.Equal(t, 11.0, totals.AverageTicket())
+	assert.Zero(t, OrderTotals{}.AverageTicket())
+}
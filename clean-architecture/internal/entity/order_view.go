@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// OrderView is an order as the queries see it, denormalized in the read model.
+type OrderView struct {
+	ID         string
+	Price      float64
+	Tax        float64
+	FinalPrice float64
+	Version    int
+	UpdatedAt  time.Time
+	// CreatedAt is the time of the OrderCreated event of the order.
+	CreatedAt time.Time
+}
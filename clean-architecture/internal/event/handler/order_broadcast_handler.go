@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/pubsub"
+)
+
+// OrderBroadcastHandler publishes each OrderCreated to the in-process
+// subscribers of Broker, such as the gRPC WatchOrders streams.
+type OrderBroadcastHandler struct {
+	Broker *pubsub.Broker[usecase.OrderOutputDTO]
+}
+
+func NewOrderBroadcastHandler(broker *pubsub.Broker[usecase.OrderOutputDTO]) *OrderBroadcastHandler {
+	return &OrderBroadcastHandler{
+		Broker: broker,
+	}
+}
+
+func (h *OrderBroadcastHandler) Handle(ctx context.Context, event events.EventInterface, wg *sync.WaitGroup) {
+	defer wg.Done()
+	order, ok := event.GetPayload().(usecase.OrderOutputDTO)
+	if !ok {
+		slog.WarnContext(ctx, "event can't be broadcast", slog.String("event", event.GetName()))
+		return
+	}
+	h.Broker.Publish(order)
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/event"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/pubsub"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGivenAnOrderCreated_WhenHandle_ThenShouldPublishTheOrder(t *testing.T) {
+	broker := pubsub.NewBroker[usecase.OrderOutputDTO]()
+	subscription := broker.Subscribe(1)
+	defer subscription.Close()
+	order := usecase.OrderOutputDTO{ID: "a", Price: 10, Tax: 1, FinalPrice: 11}
+	created := event.NewOrderCreated()
+	created.SetPayload(order)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	NewOrderBroadcastHandler(broker).Handle(context.Background(), created, wg)
+	wg.Wait()
+
+	assert.Equal(t, order, <-subscription.C)
+}
@@ -1,40 +1,107 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
+	"log/slog"
 	"sync"
 
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/telemetry"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
 	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/requestctx"
 
 	"github.com/streadway/amqp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const (
+	instrumentationName  = "github.com/alexandreti/posGoExpert/clean-architecture/internal/event/handler"
+	orderCreatedExchange = "amq.direct"
+)
+
+var publishFailuresCounter, _ = otel.Meter(instrumentationName).Int64Counter("rabbitmq_publish_failures",
+	metric.WithDescription("Number of events that could not be published to RabbitMQ"))
+
+// RabbitMQPublisher is implemented by *amqp.Channel.
+type RabbitMQPublisher interface {
+	Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+}
+
+// OrderCreatedHandler publishes the created orders to RabbitMQ. When
+// PublishedEvents is set, every event published is recorded there, so the
+// reconciler can find and republish the ones that failed.
 type OrderCreatedHandler struct {
-	RabbitMQChannel *amqp.Channel
+	RabbitMQChannel RabbitMQPublisher
+	PublishedEvents entity.PublishedEventRepositoryInterface
 }
 
-func NewOrderCreatedHandler(rabbitMQChannel *amqp.Channel) *OrderCreatedHandler {
+func NewOrderCreatedHandler(rabbitMQChannel RabbitMQPublisher) *OrderCreatedHandler {
 	return &OrderCreatedHandler{
 		RabbitMQChannel: rabbitMQChannel,
 	}
 }
 
-func (h *OrderCreatedHandler) Handle(event events.EventInterface, wg *sync.WaitGroup) {
+func (h *OrderCreatedHandler) Handle(ctx context.Context, event events.EventInterface, wg *sync.WaitGroup) {
 	defer wg.Done()
-	fmt.Printf("Order created: %v", event.GetPayload())
+	slog.InfoContext(ctx, "order created", slog.Any("payload", event.GetPayload()))
+	if err := h.Publish(ctx, event); err != nil {
+		slog.ErrorContext(ctx, "failed to publish event", slog.String("event", event.GetName()), slog.Any("error", err))
+	}
+}
+
+// Publish sends the event to RabbitMQ and records it in PublishedEvents. A
+// failure to record it is only logged: the event was delivered, at worst the
+// reconciler publishes it again.
+func (h *OrderCreatedHandler) Publish(ctx context.Context, event events.EventInterface) error {
 	jsonOutput, _ := json.Marshal(event.GetPayload())
 
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, orderCreatedExchange+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.destination.name", orderCreatedExchange),
+			attribute.String("messaging.operation", "publish"),
+		),
+	)
+	defer span.End()
+
+	headers := amqp.Table{}
+	otel.GetTextMapPropagator().Inject(ctx, telemetry.AMQPHeadersCarrier(headers))
+	if requestID := requestctx.RequestID(ctx); requestID != "" {
+		headers[requestctx.RequestIDMetadata] = requestID
+	}
+
 	msgRabbitmq := amqp.Publishing{
+		Headers:     headers,
 		ContentType: "application/json",
 		Body:        jsonOutput,
 	}
 
-	h.RabbitMQChannel.Publish(
-		"amq.direct", // exchange
-		"",           // key name
-		false,        // mandatory
-		false,        // immediate
-		msgRabbitmq,  // message to publish
+	err := h.RabbitMQChannel.Publish(
+		orderCreatedExchange, // exchange
+		"",                   // key name
+		false,                // mandatory
+		false,                // immediate
+		msgRabbitmq,          // message to publish
 	)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		publishFailuresCounter.Add(ctx, 1, metric.WithAttributes(attribute.String("event", event.GetName())))
+		return err
+	}
+
+	if order, ok := event.GetPayload().(usecase.OrderOutputDTO); ok && h.PublishedEvents != nil {
+		if err := h.PublishedEvents.MarkPublished(ctx, event.GetName(), order.ID); err != nil {
+			slog.WarnContext(ctx, "failed to record the published event", slog.String("event", event.GetName()),
+				slog.String("order_id", order.ID), slog.Any("error", err))
+		}
+	}
+	return nil
 }
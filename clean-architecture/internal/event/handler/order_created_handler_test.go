@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/event"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/requestctx"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type PublisherStub struct {
+	Published []amqp.Publishing
+	Err       error
+}
+
+func (p *PublisherStub) Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	p.Published = append(p.Published, msg)
+	return p.Err
+}
+
+type OrderCreatedHandlerTestSuite struct {
+	suite.Suite
+	spanRecorder *tracetest.SpanRecorder
+	publisher    *PublisherStub
+	handler      *OrderCreatedHandler
+}
+
+func (suite *OrderCreatedHandlerTestSuite) SetupTest() {
+	suite.spanRecorder = tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(suite.spanRecorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	suite.publisher = &PublisherStub{}
+	suite.handler = NewOrderCreatedHandler(suite.publisher)
+}
+
+func TestOrderCreatedHandlerSuite(t *testing.T) {
+	suite.Run(t, new(OrderCreatedHandlerTestSuite))
+}
+
+func (suite *OrderCreatedHandlerTestSuite) handle(ctx context.Context) {
+	orderCreated := event.NewOrderCreated()
+	orderCreated.SetPayload(map[string]string{"id": "123"})
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	suite.handler.Handle(ctx, orderCreated, wg)
+	wg.Wait()
+}
+
+func (suite *OrderCreatedHandlerTestSuite) TestGivenATracedContext_WhenHandle_ThenShouldInjectTheTraceIntoTheHeaders() {
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	suite.handle(ctx)
+	parent.End()
+
+	suite.Len(suite.publisher.Published, 1)
+	published := suite.publisher.Published[0]
+	suite.Equal(`{"id":"123"}`, string(published.Body))
+	suite.Contains(published.Headers, "traceparent")
+
+	spans := suite.spanRecorder.Ended()
+	suite.Len(spans, 2)
+	publishSpan := spans[0]
+	suite.Equal("amq.direct publish", publishSpan.Name())
+	suite.Equal(trace.SpanKindProducer, publishSpan.SpanKind())
+	suite.Equal(parent.SpanContext().SpanID(), publishSpan.Parent().SpanID())
+	suite.Contains(published.Headers["traceparent"], publishSpan.SpanContext().SpanID().String())
+}
+
+func (suite *OrderCreatedHandlerTestSuite) TestGivenARequestID_WhenHandle_ThenShouldCopyItIntoTheHeaders() {
+	suite.handle(requestctx.WithRequestID(context.Background(), "req-123"))
+
+	suite.Len(suite.publisher.Published, 1)
+	suite.Equal("req-123", suite.publisher.Published[0].Headers["x-request-id"])
+}
+
+func (suite *OrderCreatedHandlerTestSuite) TestGivenAPublishError_WhenHandle_ThenShouldMarkTheSpanAsError() {
+	suite.publisher.Err = errors.New("channel closed")
+	suite.handle(context.Background())
+
+	spans := suite.spanRecorder.Ended()
+	suite.Len(spans, 1)
+	suite.Equal(codes.Error, spans[0].Status().Code)
+}
+
+type PublishedEventsStub struct {
+	Marked []string
+}
+
+func (p *PublishedEventsStub) MarkPublished(ctx context.Context, eventName, orderID string) error {
+	p.Marked = append(p.Marked, eventName+"/"+orderID)
+	return nil
+}
+
+func (p *PublishedEventsStub) Unpublished(ctx context.Context, eventName, afterID string, limit int) ([]entity.Order, error) {
+	return nil, nil
+}
+
+func (p *PublishedEventsStub) MarkMissing(ctx context.Context, eventName string, orderIDs []string, at time.Time) (map[string]time.Time, error) {
+	return nil, nil
+}
+
+func (p *PublishedEventsStub) PruneMissing(ctx context.Context, eventName string) error {
+	return nil
+}
+
+func (suite *OrderCreatedHandlerTestSuite) TestGivenAPublishedEventLog_WhenPublish_ThenShouldRecordOnlyTheEventsDelivered() {
+	published := &PublishedEventsStub{}
+	suite.handler.PublishedEvents = published
+	orderCreated := event.NewOrderCreated()
+	orderCreated.SetPayload(usecase.OrderOutputDTO{ID: "a", Price: 10.0, Tax: 1.0, FinalPrice: 11.0})
+
+	suite.NoError(suite.handler.Publish(context.Background(), orderCreated))
+	suite.publisher.Err = errors.New("channel closed")
+	suite.Error(suite.handler.Publish(context.Background(), orderCreated))
+
+	suite.Equal([]string{"OrderCreated/a"}, published.Marked)
+	suite.Len(suite.publisher.Published, 2)
+}
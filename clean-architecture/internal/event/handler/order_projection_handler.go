@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	projectionLag, _ = otel.Meter(instrumentationName).Float64Histogram("order_projection_lag",
+		metric.WithDescription("Time between an order event being recorded and its projection in the read model"),
+		metric.WithUnit("s"))
+	projectionFailuresCounter, _ = otel.Meter(instrumentationName).Int64Counter("order_projection_failures",
+		metric.WithDescription("Number of order events that could not be projected in the read model"))
+)
+
+// OrderProjectionHandler keeps the read model up to date. It projects the
+// OrderEventAppended events of the event store and, when orders are kept in the
+// orders table, the OrderCreated events of CreateOrderUseCase.
+type OrderProjectionHandler struct {
+	OrderViewRepository entity.OrderViewRepositoryInterface
+	now                 func() time.Time
+}
+
+func NewOrderProjectionHandler(orderViewRepository entity.OrderViewRepositoryInterface) *OrderProjectionHandler {
+	return &OrderProjectionHandler{
+		OrderViewRepository: orderViewRepository,
+		now:                 time.Now,
+	}
+}
+
+func (h *OrderProjectionHandler) Handle(ctx context.Context, event events.EventInterface, wg *sync.WaitGroup) {
+	defer wg.Done()
+	change, ok := orderEventOf(event)
+	if !ok {
+		slog.WarnContext(ctx, "event can't be projected", slog.String("event", event.GetName()))
+		return
+	}
+
+	if _, err := h.OrderViewRepository.Apply(ctx, change); err != nil {
+		projectionFailuresCounter.Add(ctx, 1, metric.WithAttributes(attribute.String("event", change.Type)))
+		slog.ErrorContext(ctx, "failed to project order event",
+			slog.String("order_id", change.OrderID), slog.Int("version", change.Version), slog.Any("error", err))
+		return
+	}
+	projectionLag.Record(ctx, h.now().Sub(change.Timestamp).Seconds(), metric.WithAttributes(attribute.String("event", change.Type)))
+}
+
+func orderEventOf(event events.EventInterface) (entity.OrderEvent, bool) {
+	switch payload := event.GetPayload().(type) {
+	case entity.OrderEvent:
+		return payload, true
+	case usecase.OrderOutputDTO:
+		timestamp := payload.CreatedAt
+		if timestamp.IsZero() {
+			timestamp = event.GetDateTime()
+		}
+		// the orders table has no versions: a created order is always version 1
+		return entity.OrderEvent{
+			OrderID: payload.ID,
+			Version: 1,
+			Type:    entity.OrderCreatedEvent,
+			Data: entity.OrderEventData{
+				Price:      payload.Price,
+				Tax:        payload.Tax,
+				FinalPrice: payload.FinalPrice,
+			},
+			Timestamp: timestamp,
+		}, true
+	default:
+		return entity.OrderEvent{}, false
+	}
+}
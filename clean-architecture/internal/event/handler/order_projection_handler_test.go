@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/event"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
+
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+type OrderViewRepositoryStub struct {
+	Applied []entity.OrderEvent
+	Err     error
+}
+
+func (s *OrderViewRepositoryStub) Apply(ctx context.Context, event entity.OrderEvent) (bool, error) {
+	if s.Err != nil {
+		return false, s.Err
+	}
+	s.Applied = append(s.Applied, event)
+	return true, nil
+}
+
+func (s *OrderViewRepositoryStub) Reset(ctx context.Context) error { return nil }
+
+func (s *OrderViewRepositoryStub) FindOrder(ctx context.Context, id string) (entity.OrderView, error) {
+	return entity.OrderView{}, entity.ErrOrderNotFound
+}
+
+func (s *OrderViewRepositoryStub) ListOrders(ctx context.Context) ([]entity.OrderView, error) {
+	return nil, nil
+}
+
+func (s *OrderViewRepositoryStub) ListOrdersPage(ctx context.Context, afterID string, limit int) ([]entity.OrderView, error) {
+	return nil, nil
+}
+
+func (s *OrderViewRepositoryStub) SearchOrders(ctx context.Context, query entity.OrderQuery, afterID string, limit int) ([]entity.OrderView, error) {
+	return nil, nil
+}
+
+func (s *OrderViewRepositoryStub) ForEach(ctx context.Context, fn func(view entity.OrderView) error) error {
+	return nil
+}
+
+type OrderProjectionHandlerTestSuite struct {
+	suite.Suite
+	reader  *sdkmetric.ManualReader
+	views   *OrderViewRepositoryStub
+	handler *OrderProjectionHandler
+}
+
+// the global instruments bind to the first meter provider set, so it's set once
+func (suite *OrderProjectionHandlerTestSuite) SetupSuite() {
+	suite.reader = sdkmetric.NewManualReader(sdkmetric.WithTemporalitySelector(func(sdkmetric.InstrumentKind) metricdata.Temporality {
+		return metricdata.DeltaTemporality
+	}))
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(suite.reader)))
+}
+
+func (suite *OrderProjectionHandlerTestSuite) SetupTest() {
+	suite.views = &OrderViewRepositoryStub{}
+	suite.handler = NewOrderProjectionHandler(suite.views)
+}
+
+func TestOrderProjectionHandlerSuite(t *testing.T) {
+	suite.Run(t, new(OrderProjectionHandlerTestSuite))
+}
+
+func (suite *OrderProjectionHandlerTestSuite) handle(e events.EventInterface) {
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	suite.handler.Handle(context.Background(), e, wg)
+	wg.Wait()
+}
+
+func (suite *OrderProjectionHandlerTestSuite) TestGivenAnOrderEventAppended_WhenHandle_ThenShouldProjectTheEvent() {
+	change := entity.OrderEvent{OrderID: "a", Version: 2, Type: entity.OrderUpdatedEvent, Data: entity.OrderEventData{Price: 10.0, Tax: 2.0, FinalPrice: 12.0}}
+	appended := event.NewOrderEventAppended()
+	appended.SetPayload(change)
+
+	suite.handle(appended)
+	suite.Equal([]entity.OrderEvent{change}, suite.views.Applied)
+}
+
+func (suite *OrderProjectionHandlerTestSuite) TestGivenAnOrderCreated_WhenHandle_ThenShouldProjectTheFirstVersion() {
+	created := event.NewOrderCreated()
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	created.SetPayload(usecase.OrderOutputDTO{ID: "a", Price: 10.0, Tax: 2.0, FinalPrice: 12.0, CreatedAt: createdAt})
+
+	suite.handle(created)
+	suite.Len(suite.views.Applied, 1)
+	suite.Equal("a", suite.views.Applied[0].OrderID)
+	suite.Equal(1, suite.views.Applied[0].Version)
+	suite.Equal(entity.OrderCreatedEvent, suite.views.Applied[0].Type)
+	suite.Equal(entity.OrderEventData{Price: 10.0, Tax: 2.0, FinalPrice: 12.0}, suite.views.Applied[0].Data)
+	suite.Equal(createdAt, suite.views.Applied[0].Timestamp)
+}
+
+func (suite *OrderProjectionHandlerTestSuite) TestGivenAFailingReadModel_WhenHandle_ThenShouldNotPanic() {
+	suite.views.Err = errors.New("db down")
+	created := event.NewOrderCreated()
+	created.SetPayload(usecase.OrderOutputDTO{ID: "a"})
+
+	suite.handle(created)
+	suite.Empty(suite.views.Applied)
+
+	unknown := event.NewOrderCreated()
+	unknown.SetPayload("not an order")
+	suite.handle(unknown)
+}
+
+func (suite *OrderProjectionHandlerTestSuite) TestGivenAnOldEvent_WhenHandle_ThenShouldRecordTheProjectionLag() {
+	recorded := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	suite.handler.now = func() time.Time { return recorded.Add(1500 * time.Millisecond) }
+	appended := event.NewOrderEventAppended()
+	appended.SetPayload(entity.OrderEvent{OrderID: "a", Version: 1, Type: entity.OrderCreatedEvent, Timestamp: recorded})
+
+	var metrics metricdata.ResourceMetrics
+	// drops what the other tests recorded
+	suite.NoError(suite.reader.Collect(context.Background(), &metrics))
+	suite.handle(appended)
+	suite.NoError(suite.reader.Collect(context.Background(), &metrics))
+	var lag *metricdata.Histogram[float64]
+	for _, scope := range metrics.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name == "order_projection_lag" {
+				histogram := m.Data.(metricdata.Histogram[float64])
+				lag = &histogram
+			}
+		}
+	}
+	suite.Require().NotNil(lag)
+	suite.Equal(uint64(1), lag.DataPoints[0].Count)
+	suite.Equal(1.5, lag.DataPoints[0].Sum)
+}
@@ -0,0 +1,32 @@
+package event
+
+import "time"
+
+// OrderEventAppended is dispatched by the event store for every event appended
+// to an order stream; the payload is the entity.OrderEvent.
+type OrderEventAppended struct {
+	Name    string
+	Payload interface{}
+}
+
+func NewOrderEventAppended() *OrderEventAppended {
+	return &OrderEventAppended{
+		Name: "OrderEventAppended",
+	}
+}
+
+func (e *OrderEventAppended) GetName() string {
+	return e.Name
+}
+
+func (e *OrderEventAppended) GetPayload() interface{} {
+	return e.Payload
+}
+
+func (e *OrderEventAppended) SetPayload(payload interface{}) {
+	e.Payload = payload
+}
+
+func (e *OrderEventAppended) GetDateTime() time.Time {
+	return time.Now()
+}
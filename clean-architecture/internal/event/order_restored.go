@@ -0,0 +1,32 @@
+package event
+
+import "time"
+
+// OrderRestored is dispatched when an order is restored; the payload
+// is the entity.OrderEvent.
+type OrderRestored struct {
+	Name    string
+	Payload interface{}
+}
+
+func NewOrderRestored() *OrderRestored {
+	return &OrderRestored{
+		Name: "OrderRestored",
+	}
+}
+
+func (e *OrderRestored) GetName() string {
+	return e.Name
+}
+
+func (e *OrderRestored) GetPayload() interface{} {
+	return e.Payload
+}
+
+func (e *OrderRestored) SetPayload(payload interface{}) {
+	e.Payload = payload
+}
+
+func (e *OrderRestored) GetDateTime() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,58 @@
+// Package archive keeps the orders purged by the retention policy in an NDJSON
+// file, one order per line.
+package archive
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+)
+
+// FileArchive implements entity.OrderArchiveInterface, appending to the file
+// at Path. Each Archive is synced to disk before it returns, so an order is
+// never purged before it's archived; one archived again after a failed purge
+// shows up in a second line.
+type FileArchive struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func NewFileArchive(path string) *FileArchive {
+	return &FileArchive{Path: path}
+}
+
+func (a *FileArchive) Archive(ctx context.Context, orders []entity.ArchivedOrder) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	file, err := os.OpenFile(a.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	if err := write(file, orders); err != nil {
+		file.Close()
+		return err
+	}
+	// Close once and report it: a failed close may mean the lines never
+	// reached the disk, and the orders must not be purged then.
+	return file.Close()
+}
+
+// write appends orders to file and syncs it.
+func write(file *os.File, orders []entity.ArchivedOrder) error {
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, order := range orders {
+		if err := encoder.Encode(order); err != nil {
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	return file.Sync()
+}
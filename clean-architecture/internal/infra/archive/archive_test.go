@@ -0,0 +1,52 @@
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGivenTwoBatches_WhenArchive_ThenShouldAppendALinePerOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.ndjson")
+	archive := NewFileArchive(path)
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	require.NoError(t, archive.Archive(context.Background(), []entity.ArchivedOrder{
+		{ID: "a", Price: 10, Tax: 1, FinalPrice: 11, CreatedAt: at, ArchivedAt: at},
+	}))
+	require.NoError(t, archive.Archive(context.Background(), []entity.ArchivedOrder{
+		{ID: "b", Price: 20, Tax: 2, FinalPrice: 22, CreatedAt: at, DeletedAt: &at, ArchivedAt: at},
+	}))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t,
+		`{"id":"a","price":10,"tax":1,"final_price":11,"created_at":"2026-01-02T03:04:05Z","archived_at":"2026-01-02T03:04:05Z"}`+"\n"+
+			`{"id":"b","price":20,"tax":2,"final_price":22,"created_at":"2026-01-02T03:04:05Z","deleted_at":"2026-01-02T03:04:05Z","archived_at":"2026-01-02T03:04:05Z"}`+"\n",
+		string(content))
+}
+
+func TestGivenAMissingDirectory_WhenArchive_ThenShouldReturnAnError(t *testing.T) {
+	archive := NewFileArchive(filepath.Join(t.TempDir(), "missing", "orders.ndjson"))
+	assert.Error(t, archive.Archive(context.Background(), []entity.ArchivedOrder{{ID: "a"}}))
+}
+
+func TestGivenAnOrderThatCantBeEncoded_WhenArchive_ThenShouldReturnTheError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.ndjson")
+	err := NewFileArchive(path).Archive(context.Background(), []entity.ArchivedOrder{{ID: "a", Price: math.Inf(1)}})
+	var unsupported *json.UnsupportedValueError
+	assert.ErrorAs(t, err, &unsupported)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Empty(t, content)
+}
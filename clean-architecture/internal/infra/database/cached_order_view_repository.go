@@ -0,0 +1,149 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync/atomic"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/cache"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/sync/singleflight"
+)
+
+var (
+	cacheMeter               = otel.Meter(tracerName)
+	orderCacheHitsCounter, _ = cacheMeter.Int64Counter("order_cache_hits",
+		metric.WithDescription("Number of order reads served by the cache"))
+	orderCacheMissesCounter, _ = cacheMeter.Int64Counter("order_cache_misses",
+		metric.WithDescription("Number of order reads that went to the read model"))
+)
+
+// CachedOrderViewRepository decorates an entity.OrderViewRepositoryInterface,
+// the read model the queries read from, caching FindOrder, ListOrders and
+// ListOrdersPage. It doesn't wrap the entity.OrderRepositoryInterface: a Save
+// reaches the read model through the projection handler, which calls Apply, so
+// Apply and Reset purge the cache. SearchOrders and ForEach always go to the
+// repository. Concurrent misses for the same key share a single read. What
+// deletes the read model rows directly, like the retention purge, must call
+// Invalidate afterwards.
+type CachedOrderViewRepository struct {
+	Views entity.OrderViewRepositoryInterface
+	Cache cache.Cache[[]entity.OrderView]
+
+	group singleflight.Group
+	// generation is bumped on every purge, so that a read started before it
+	// neither stores what it read nor is shared with the reads after it.
+	generation atomic.Uint64
+}
+
+func NewCachedOrderViewRepository(views entity.OrderViewRepositoryInterface, cache cache.Cache[[]entity.OrderView]) *CachedOrderViewRepository {
+	return &CachedOrderViewRepository{Views: views, Cache: cache}
+}
+
+func (r *CachedOrderViewRepository) Apply(ctx context.Context, event entity.OrderEvent) (bool, error) {
+	defer r.purge(ctx)
+	return r.Views.Apply(ctx, event)
+}
+
+func (r *CachedOrderViewRepository) Reset(ctx context.Context) error {
+	defer r.purge(ctx)
+	return r.Views.Reset(ctx)
+}
+
+// FindOrder caches the orders found; ErrOrderNotFound is not cached.
+func (r *CachedOrderViewRepository) FindOrder(ctx context.Context, id string) (entity.OrderView, error) {
+	views, err := r.load(ctx, "FindOrder", "order:"+id, func(ctx context.Context) ([]entity.OrderView, error) {
+		view, err := r.Views.FindOrder(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return []entity.OrderView{view}, nil
+	})
+	if err != nil {
+		return entity.OrderView{}, err
+	}
+	return views[0], nil
+}
+
+func (r *CachedOrderViewRepository) ListOrders(ctx context.Context) ([]entity.OrderView, error) {
+	return r.load(ctx, "ListOrders", "list", r.Views.ListOrders)
+}
+
+func (r *CachedOrderViewRepository) ListOrdersPage(ctx context.Context, afterID string, limit int) ([]entity.OrderView, error) {
+	return r.load(ctx, "ListOrdersPage", fmt.Sprintf("page:%d:%s", limit, afterID), func(ctx context.Context) ([]entity.OrderView, error) {
+		return r.Views.ListOrdersPage(ctx, afterID, limit)
+	})
+}
+
+func (r *CachedOrderViewRepository) SearchOrders(ctx context.Context, query entity.OrderQuery, afterID string, limit int) ([]entity.OrderView, error) {
+	return r.Views.SearchOrders(ctx, query, afterID, limit)
+}
+
+func (r *CachedOrderViewRepository) ForEach(ctx context.Context, fn func(view entity.OrderView) error) error {
+	return r.Views.ForEach(ctx, fn)
+}
+
+// load returns a copy of the views cached under key, reading them with read
+// on a miss. A cache that can't be reached counts as a miss. A caller whose ctx
+// is done stops waiting for a shared read, which goes on for the others.
+func (r *CachedOrderViewRepository) load(ctx context.Context, method, key string, read func(ctx context.Context) ([]entity.OrderView, error)) ([]entity.OrderView, error) {
+	attributes := metric.WithAttributes(attribute.String("method", method))
+	views, ok, err := r.Cache.Get(ctx, key)
+	if err != nil {
+		slog.WarnContext(ctx, "order cache read failed", slog.String("key", key), slog.Any("error", err))
+	}
+	if ok {
+		orderCacheHitsCounter.Add(ctx, 1, attributes)
+		return slices.Clone(views), nil
+	}
+	orderCacheMissesCounter.Add(ctx, 1, attributes)
+
+	generation := r.generation.Load()
+	// The shared read outlives the caller that started it, so it doesn't use
+	// its cancellation; each caller stops waiting when its own ctx is done.
+	shared := context.WithoutCancel(ctx)
+	results := r.group.DoChan(fmt.Sprintf("%d/%s", generation, key), func() (any, error) {
+		views, err := read(shared)
+		if err != nil {
+			return nil, err
+		}
+		if r.generation.Load() != generation {
+			return views, nil
+		}
+		if err := r.Cache.Set(shared, key, views); err != nil {
+			slog.WarnContext(shared, "order cache write failed", slog.String("key", key), slog.Any("error", err))
+		} else if r.generation.Load() != generation {
+			// purged while storing: what was read may be stale
+			r.purge(shared)
+		}
+		return views, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-results:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return slices.Clone(result.Val.([]entity.OrderView)), nil
+	}
+}
+
+// Invalidate purges the cache, for the writes to the read model that don't go
+// through Apply.
+func (r *CachedOrderViewRepository) Invalidate(ctx context.Context) {
+	r.purge(ctx)
+}
+
+func (r *CachedOrderViewRepository) purge(ctx context.Context) {
+	r.generation.Add(1)
+	if err := r.Cache.Purge(ctx); err != nil {
+		slog.WarnContext(ctx, "order cache purge failed", slog.Any("error", err))
+	}
+}
@@ -0,0 +1,316 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/event"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/event/handler"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/cache"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
+	"github.com/alexandreti/posGoExpert/clean-architecture/sql/migrations"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// countingViews counts the reads that reach the read model. When gate is set,
+// ListOrders waits on it after reading, to hold the read in flight, and then
+// fails if its ctx was cancelled meanwhile, as a query would.
+type countingViews struct {
+	entity.OrderViewRepositoryInterface
+	reads atomic.Int32
+	gate  chan struct{}
+}
+
+func (v *countingViews) FindOrder(ctx context.Context, id string) (entity.OrderView, error) {
+	v.reads.Add(1)
+	return v.OrderViewRepositoryInterface.FindOrder(ctx, id)
+}
+
+func (v *countingViews) ListOrders(ctx context.Context) ([]entity.OrderView, error) {
+	v.reads.Add(1)
+	views, err := v.OrderViewRepositoryInterface.ListOrders(ctx)
+	if v.gate != nil {
+		<-v.gate
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	return views, err
+}
+
+func (v *countingViews) ListOrdersPage(ctx context.Context, afterID string, limit int) ([]entity.OrderView, error) {
+	v.reads.Add(1)
+	return v.OrderViewRepositoryInterface.ListOrdersPage(ctx, afterID, limit)
+}
+
+// failingCache is a cache that can't be reached.
+type failingCache struct{}
+
+func (failingCache) Get(ctx context.Context, key string) ([]entity.OrderView, bool, error) {
+	return nil, false, errors.New("cache is down")
+}
+
+func (failingCache) Set(ctx context.Context, key string, value []entity.OrderView) error {
+	return errors.New("cache is down")
+}
+
+func (failingCache) Purge(ctx context.Context) error {
+	return errors.New("cache is down")
+}
+
+type CachedOrderViewRepositoryTestSuite struct {
+	suite.Suite
+	Db     *sql.DB
+	Views  *countingViews
+	Cache  *cache.LRU[[]entity.OrderView]
+	Cached *CachedOrderViewRepository
+}
+
+func (suite *CachedOrderViewRepositoryTestSuite) SetupTest() {
+	db, err := sql.Open("sqlite3", ":memory:")
+	suite.Require().NoError(err)
+	db.SetMaxOpenConns(1)
+	source, err := migrations.Source(SQLite.Name())
+	suite.Require().NoError(err)
+	_, err = NewMigrator(db, SQLite, source).Up(context.Background())
+	suite.Require().NoError(err)
+	suite.Db = db
+	suite.Views = &countingViews{OrderViewRepositoryInterface: NewOrderViewRepository(db, SQLite)}
+	suite.Cache = cache.NewLRU[[]entity.OrderView](100, time.Minute)
+	suite.Cached = NewCachedOrderViewRepository(suite.Views, suite.Cache)
+
+	_, err = suite.Cached.Apply(context.Background(), orderEvent("a", 1, 10.0))
+	suite.Require().NoError(err)
+}
+
+func (suite *CachedOrderViewRepositoryTestSuite) TearDownTest() {
+	suite.Db.Close()
+}
+
+func TestCachedOrderViewRepositorySuite(t *testing.T) {
+	suite.Run(t, new(CachedOrderViewRepositoryTestSuite))
+}
+
+func (suite *CachedOrderViewRepositoryTestSuite) TestGivenACachedList_WhenListOrdersAgain_ThenShouldNotReadTheRepository() {
+	ctx := context.Background()
+	first, err := suite.Cached.ListOrders(ctx)
+	suite.NoError(err)
+	second, err := suite.Cached.ListOrders(ctx)
+	suite.NoError(err)
+
+	suite.Equal(first, second)
+	suite.Len(second, 1)
+	suite.Equal(int32(1), suite.Views.reads.Load())
+}
+
+func (suite *CachedOrderViewRepositoryTestSuite) TestGivenACachedList_WhenAnEventIsApplied_ThenShouldReadTheNewList() {
+	ctx := context.Background()
+	_, err := suite.Cached.ListOrders(ctx)
+	suite.NoError(err)
+
+	_, err = suite.Cached.Apply(ctx, orderEvent("b", 1, 20.0))
+	suite.NoError(err)
+	views, err := suite.Cached.ListOrders(ctx)
+	suite.NoError(err)
+
+	suite.Len(views, 2)
+	suite.Equal(int32(2), suite.Views.reads.Load())
+}
+
+func (suite *CachedOrderViewRepositoryTestSuite) TestGivenACachedOrder_WhenItIsDeleted_ThenShouldNotFindIt() {
+	ctx := context.Background()
+	view, err := suite.Cached.FindOrder(ctx, "a")
+	suite.NoError(err)
+	suite.Equal(10.0, view.Price)
+
+	deleted := orderEvent("a", 2, 10.0)
+	deleted.Type = entity.OrderDeletedEvent
+	now := time.Now().UTC()
+	deleted.Data.DeletedAt = &now
+	_, err = suite.Cached.Apply(ctx, deleted)
+	suite.NoError(err)
+
+	_, err = suite.Cached.FindOrder(ctx, "a")
+	suite.ErrorIs(err, entity.ErrOrderNotFound)
+}
+
+func (suite *CachedOrderViewRepositoryTestSuite) TestGivenAMissingOrder_WhenFindOrder_ThenShouldNotCacheTheMiss() {
+	ctx := context.Background()
+	_, err := suite.Cached.FindOrder(ctx, "b")
+	suite.ErrorIs(err, entity.ErrOrderNotFound)
+	suite.Equal(0, suite.Cache.Len())
+
+	_, err = suite.Cached.FindOrder(ctx, "b")
+	suite.ErrorIs(err, entity.ErrOrderNotFound)
+	suite.Equal(int32(2), suite.Views.reads.Load())
+}
+
+func (suite *CachedOrderViewRepositoryTestSuite) TestGivenPages_WhenListOrdersPage_ThenShouldCacheEachPage() {
+	ctx := context.Background()
+	_, err := suite.Cached.Apply(ctx, orderEvent("b", 1, 20.0))
+	suite.NoError(err)
+
+	first, err := suite.Cached.ListOrdersPage(ctx, "", 1)
+	suite.NoError(err)
+	second, err := suite.Cached.ListOrdersPage(ctx, "a", 1)
+	suite.NoError(err)
+	again, err := suite.Cached.ListOrdersPage(ctx, "", 1)
+	suite.NoError(err)
+
+	suite.Equal("a", first[0].ID)
+	suite.Equal("b", second[0].ID)
+	suite.Equal(first, again)
+	suite.Equal(int32(2), suite.Views.reads.Load())
+}
+
+func (suite *CachedOrderViewRepositoryTestSuite) TestGivenACachedList_WhenTheCallerChangesIt_ThenShouldNotChangeTheCache() {
+	ctx := context.Background()
+	views, err := suite.Cached.ListOrders(ctx)
+	suite.NoError(err)
+	views[0].Price = 99.0
+
+	views, err = suite.Cached.ListOrders(ctx)
+	suite.NoError(err)
+	suite.Equal(10.0, views[0].Price)
+}
+
+func (suite *CachedOrderViewRepositoryTestSuite) TestGivenConcurrentMisses_WhenListOrders_ThenShouldReadTheRepositoryOnce() {
+	suite.Views.gate = make(chan struct{})
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	results := make([][]entity.OrderView, 10)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			views, err := suite.Cached.ListOrders(ctx)
+			suite.NoError(err)
+			results[i] = views
+		}()
+	}
+	suite.Eventually(func() bool { return suite.Views.reads.Load() == 1 }, time.Second, time.Millisecond)
+	// give the other callers time to join the read in flight
+	time.Sleep(50 * time.Millisecond)
+	close(suite.Views.gate)
+	wg.Wait()
+
+	suite.Equal(int32(1), suite.Views.reads.Load())
+	for _, views := range results {
+		suite.Len(views, 1)
+	}
+}
+
+func (suite *CachedOrderViewRepositoryTestSuite) TestGivenAReadInFlight_WhenAnEventIsApplied_ThenShouldNotCacheWhatWasRead() {
+	suite.Views.gate = make(chan struct{})
+	ctx := context.Background()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		views, err := suite.Cached.ListOrders(ctx)
+		suite.NoError(err)
+		suite.Len(views, 1)
+	}()
+	suite.Eventually(func() bool { return suite.Views.reads.Load() == 1 }, time.Second, time.Millisecond)
+	_, err := suite.Cached.Apply(ctx, orderEvent("b", 1, 20.0))
+	suite.NoError(err)
+	close(suite.Views.gate)
+	<-done
+
+	suite.Equal(0, suite.Cache.Len())
+	views, err := suite.Cached.ListOrders(ctx)
+	suite.NoError(err)
+	suite.Len(views, 2)
+}
+
+func (suite *CachedOrderViewRepositoryTestSuite) TestGivenASharedRead_WhenTheCallerThatStartedItIsCancelled_ThenShouldStillServeTheOthers() {
+	suite.Views.gate = make(chan struct{})
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := suite.Cached.ListOrders(leaderCtx)
+		leaderErr <- err
+	}()
+	suite.Eventually(func() bool { return suite.Views.reads.Load() == 1 }, time.Second, time.Millisecond)
+
+	type result struct {
+		views []entity.OrderView
+		err   error
+	}
+	follower := make(chan result, 1)
+	go func() {
+		views, err := suite.Cached.ListOrders(context.Background())
+		follower <- result{views, err}
+	}()
+	// give the follower time to join the read in flight
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	close(suite.Views.gate)
+
+	suite.ErrorIs(<-leaderErr, context.Canceled)
+	got := <-follower
+	suite.NoError(got.err)
+	suite.Len(got.views, 1)
+	suite.Equal(int32(1), suite.Views.reads.Load())
+	suite.Equal(1, suite.Cache.Len())
+}
+
+func (suite *CachedOrderViewRepositoryTestSuite) TestGivenACacheThatIsDown_WhenListOrders_ThenShouldReadTheRepository() {
+	suite.Cached.Cache = failingCache{}
+	ctx := context.Background()
+
+	views, err := suite.Cached.ListOrders(ctx)
+	suite.NoError(err)
+	suite.Len(views, 1)
+	views, err = suite.Cached.ListOrders(ctx)
+	suite.NoError(err)
+	suite.Len(views, 1)
+	suite.Equal(int32(2), suite.Views.reads.Load())
+}
+
+func (suite *CachedOrderViewRepositoryTestSuite) TestGivenACachedOrder_WhenTheRetentionPurgesIt_ThenShouldNotFindIt() {
+	ctx := context.Background()
+	_, err := suite.Db.Exec("INSERT INTO orders (id, price, tax, final_price, created_at) VALUES ('a', 10, 1, 11, '2026-01-01 00:00:00')")
+	suite.Require().NoError(err)
+	_, err = suite.Cached.FindOrder(ctx, "a")
+	suite.Require().NoError(err)
+
+	retention := usecase.NewApplyRetentionUseCase(NewOrderRetentionRepository(suite.Db, SQLite, false), NewOrderArchiveRepository(suite.Db, SQLite))
+	retention.MaxAge = time.Nanosecond
+	retention.OnPurged = suite.Cached.Invalidate
+	output, err := retention.Execute(ctx)
+	suite.Require().NoError(err)
+	suite.Equal(1, output.Purged)
+
+	_, err = suite.Cached.FindOrder(ctx, "a")
+	suite.ErrorIs(err, entity.ErrOrderNotFound)
+}
+
+func (suite *CachedOrderViewRepositoryTestSuite) TestGivenTheAppWiring_WhenAnOrderIsSaved_ThenShouldReadTheRepositoryAgain() {
+	// as in cmd/ordersystem: the store dispatches what it saves and the
+	// projection handler writes it to the read model through the cache
+	dispatcher := events.NewEventDispatcher()
+	suite.Require().NoError(dispatcher.Register(event.NewOrderEventAppended().GetName(), handler.NewOrderProjectionHandler(suite.Cached)))
+	store := NewOrderEventStore(suite.Db, SQLite, dispatcher)
+	ctx := context.Background()
+	_, err := suite.Cached.ListOrders(ctx)
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(store.Save(ctx, &entity.Order{ID: "b", Price: 20.0, Tax: 2.0, FinalPrice: 22.0}))
+	views, err := suite.Cached.ListOrders(ctx)
+	suite.NoError(err)
+
+	suite.Len(views, 2)
+	suite.Equal(int32(2), suite.Views.reads.Load())
+}
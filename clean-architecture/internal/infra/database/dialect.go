@@ -0,0 +1,84 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// Dialect holds what changes in the SQL between the supported databases.
+// Queries are written with "?" placeholders and rebound for the dialect.
+type Dialect interface {
+	// Name is the database/sql driver name.
+	Name() string
+	Rebind(query string) string
+	// IsUniqueViolation tells if err comes from a primary key or unique index.
+	IsUniqueViolation(err error) bool
+}
+
+var (
+	MySQL    Dialect = mysqlDialect{}
+	Postgres Dialect = postgresDialect{}
+	SQLite   Dialect = sqliteDialect{}
+)
+
+// DialectFor returns the dialect of a database/sql driver name.
+func DialectFor(driver string) (Dialect, error) {
+	for _, dialect := range []Dialect{MySQL, Postgres, SQLite} {
+		if dialect.Name() == driver {
+			return dialect, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported database driver %q", driver)
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string { return "mysql" }
+
+func (mysqlDialect) Rebind(query string) string { return query }
+
+func (mysqlDialect) IsUniqueViolation(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string { return "postgres" }
+
+// Rebind replaces each "?" with $1, $2, ...
+func (postgresDialect) Rebind(query string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (postgresDialect) IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string { return "sqlite3" }
+
+func (sqliteDialect) Rebind(query string) string { return query }
+
+// IsUniqueViolation matches the message because the error type of go-sqlite3
+// only exists in cgo builds.
+func (sqliteDialect) IsUniqueViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
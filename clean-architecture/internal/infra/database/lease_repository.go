@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// LeaseRepository implements entity.LeaseRepositoryInterface on the leases
+// table, shared by every replica of the service.
+type LeaseRepository struct {
+	Db      *sql.DB
+	Dialect Dialect
+	now     func() time.Time
+}
+
+func NewLeaseRepository(db *sql.DB, dialect Dialect) *LeaseRepository {
+	return &LeaseRepository{Db: db, Dialect: dialect, now: time.Now}
+}
+
+// Acquire renews the lease when holder has it or it has expired, and creates
+// it when it doesn't exist yet. When two replicas race for a new lease, the
+// primary key lets only one of them in.
+func (r *LeaseRepository) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (acquired bool, err error) {
+	const query = "UPDATE leases SET holder = ?, expires_at = ? WHERE name = ? AND (holder = ? OR expires_at < ?)"
+	ctx, end := startQuery(ctx, r.Dialect, "LeaseRepository.Acquire", query)
+	defer func() { end(err) }()
+
+	now := r.now().UTC()
+	result, err := r.Db.ExecContext(ctx, r.Dialect.Rebind(query), holder, now.Add(ttl), name, holder, now)
+	if err != nil {
+		return false, err
+	}
+	if updated, err := result.RowsAffected(); err != nil || updated > 0 {
+		return err == nil, err
+	}
+
+	_, err = r.Db.ExecContext(ctx, r.Dialect.Rebind("INSERT INTO leases (name, holder, expires_at) VALUES (?, ?, ?)"), name, holder, now.Add(ttl))
+	if r.Dialect.IsUniqueViolation(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,326 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/sql/migrations"
+)
+
+// ErrDirty is returned when a previous migration failed halfway. The schema has
+// to be fixed by hand and the version set with Force before migrating again.
+var ErrDirty = errors.New("database is dirty")
+
+const (
+	migrationsTable = "schema_migrations"
+	lockName        = "ordersystem_schema_migrations"
+)
+
+// Migration is a pair of up/down scripts read from the migrations source.
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus tells if a migration of the source was applied.
+type MigrationStatus struct {
+	Version uint64
+	Name    string
+	Applied bool
+}
+
+// Status is the schema version of the database and the state of each migration.
+type Status struct {
+	Version    uint64
+	Dirty      bool
+	Migrations []MigrationStatus
+}
+
+// Migrator applies the migrations of Source to Db. The version is kept in a
+// single row schema_migrations(version, dirty) table, the same layout used by
+// golang-migrate, so databases migrated with `make migrate` keep working.
+// Every command holds a database lock (GET_LOCK on MySQL, an advisory lock on
+// PostgreSQL) so replicas migrating on start don't race; SQLite needs none.
+type Migrator struct {
+	Db          *sql.DB
+	Dialect     Dialect
+	Source      fs.FS
+	LockTimeout time.Duration
+}
+
+func NewMigrator(db *sql.DB, dialect Dialect, source fs.FS) *Migrator {
+	return &Migrator{
+		Db:          db,
+		Dialect:     dialect,
+		Source:      source,
+		LockTimeout: time.Minute,
+	}
+}
+
+// Up applies every pending migration and returns how many were applied.
+func (m *Migrator) Up(ctx context.Context) (applied int, err error) {
+	err = m.withLock(ctx, func(conn *sql.Conn) error {
+		all, version, err := m.prepare(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range all {
+			if migration.Version <= version {
+				continue
+			}
+			if err := m.run(ctx, conn, migration.Up, migration.Version, migration.Version); err != nil {
+				return fmt.Errorf("migration %d_%s up: %w", migration.Version, migration.Name, err)
+			}
+			slog.InfoContext(ctx, "migration applied", slog.Uint64("version", migration.Version), slog.String("name", migration.Name))
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last steps migrations, or all of them when steps <= 0, and
+// returns how many were reverted.
+func (m *Migrator) Down(ctx context.Context, steps int) (reverted int, err error) {
+	err = m.withLock(ctx, func(conn *sql.Conn) error {
+		all, version, err := m.prepare(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(all) - 1; i >= 0; i-- {
+			migration := all[i]
+			if migration.Version > version {
+				continue
+			}
+			if steps > 0 && reverted == steps {
+				break
+			}
+			var previous uint64
+			if i > 0 {
+				previous = all[i-1].Version
+			}
+			if err := m.run(ctx, conn, migration.Down, migration.Version, previous); err != nil {
+				return fmt.Errorf("migration %d_%s down: %w", migration.Version, migration.Name, err)
+			}
+			slog.InfoContext(ctx, "migration reverted", slog.Uint64("version", migration.Version), slog.String("name", migration.Name))
+			reverted++
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status reports the current version and which migrations were applied.
+func (m *Migrator) Status(ctx context.Context) (status Status, err error) {
+	err = m.withLock(ctx, func(conn *sql.Conn) error {
+		all, err := m.Migrations()
+		if err != nil {
+			return err
+		}
+		if err := m.createTable(ctx, conn); err != nil {
+			return err
+		}
+		status.Version, status.Dirty, err = m.version(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range all {
+			status.Migrations = append(status.Migrations, MigrationStatus{
+				Version: migration.Version,
+				Name:    migration.Name,
+				Applied: migration.Version <= status.Version,
+			})
+		}
+		return nil
+	})
+	return status, err
+}
+
+// Force sets the version and clears the dirty flag without running anything.
+func (m *Migrator) Force(ctx context.Context, version uint64) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		if err := m.createTable(ctx, conn); err != nil {
+			return err
+		}
+		return m.setVersion(ctx, conn, version, false)
+	})
+}
+
+// Migrations reads the migrations of Source ordered by version.
+func (m *Migrator) Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(m.Source, ".")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[uint64]*Migration{}
+	for _, entry := range entries {
+		version, name, direction, ok := migrations.ParseFileName(entry.Name())
+		if !ok {
+			continue
+		}
+		content, err := fs.ReadFile(m.Source, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+		if direction == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	all := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		all = append(all, *migration)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Version < all[j].Version })
+	return all, nil
+}
+
+// prepare loads the migrations and the current version, refusing to go on if
+// the database is dirty.
+func (m *Migrator) prepare(ctx context.Context, conn *sql.Conn) ([]Migration, uint64, error) {
+	all, err := m.Migrations()
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := m.createTable(ctx, conn); err != nil {
+		return nil, 0, err
+	}
+	version, dirty, err := m.version(ctx, conn)
+	if err != nil {
+		return nil, 0, err
+	}
+	if dirty {
+		return nil, 0, fmt.Errorf("%w at version %d", ErrDirty, version)
+	}
+	return all, version, nil
+}
+
+// run marks the database dirty at version, runs the script and then records
+// target as the clean version.
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, script string, version, target uint64) error {
+	if err := m.setVersion(ctx, conn, version, true); err != nil {
+		return err
+	}
+	for _, statement := range SplitStatements(script) {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return m.setVersion(ctx, conn, target, false)
+}
+
+func (m *Migrator) createTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+migrationsTable+" (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)")
+	return err
+}
+
+func (m *Migrator) version(ctx context.Context, conn *sql.Conn) (version uint64, dirty bool, err error) {
+	err = conn.QueryRowContext(ctx, "SELECT version, dirty FROM "+migrationsTable+" LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// setVersion replaces the single row of the table; version 0 leaves it empty.
+func (m *Migrator) setVersion(ctx context.Context, conn *sql.Conn, version uint64, dirty bool) error {
+	if _, err := conn.ExecContext(ctx, "DELETE FROM "+migrationsTable); err != nil {
+		return err
+	}
+	if version == 0 && !dirty {
+		return nil
+	}
+	_, err := conn.ExecContext(ctx, m.Dialect.Rebind("INSERT INTO "+migrationsTable+" (version, dirty) VALUES (?, ?)"), version, dirty)
+	return err
+}
+
+// withLock runs fn on a single connection holding the migrations lock, since
+// both MySQL and PostgreSQL locks belong to the session that took them.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.Db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	lockCtx, cancel := context.WithTimeout(ctx, m.LockTimeout)
+	defer cancel()
+	switch m.Dialect {
+	case MySQL:
+		var acquired sql.NullInt64
+		if err := conn.QueryRowContext(lockCtx, "SELECT GET_LOCK(?, ?)", lockName, int(m.LockTimeout.Seconds())).Scan(&acquired); err != nil {
+			return fmt.Errorf("acquiring migrations lock: %w", err)
+		}
+		if acquired.Int64 != 1 {
+			return fmt.Errorf("acquiring migrations lock: timed out after %s", m.LockTimeout)
+		}
+		defer conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName)
+	case Postgres:
+		key := int64(crc32.ChecksumIEEE([]byte(lockName)))
+		if _, err := conn.ExecContext(lockCtx, "SELECT pg_advisory_lock($1)", key); err != nil {
+			return fmt.Errorf("acquiring migrations lock: %w", err)
+		}
+		defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key)
+	}
+	return fn(conn)
+}
+
+// SplitStatements splits a script on the ";" that end statements, ignoring the
+// ones inside quotes and comments, since the MySQL driver runs one statement
+// per call.
+func SplitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := i + 1
+			for end < len(script) && script[end] != c {
+				if script[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end, len(script)-1)
+			current.WriteString(script[i : end+1])
+			i = end
+		case c == '-' && strings.HasPrefix(script[i:], "--"):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				end = len(script) - i
+			}
+			// keeps the line break
+			i += end - 1
+		case c == ';':
+			flush()
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+	return statements
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"testing/fstest"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/sql/migrations"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MigratorTestSuite struct {
+	suite.Suite
+	Db *sql.DB
+}
+
+func (suite *MigratorTestSuite) SetupTest() {
+	db, err := sql.Open("sqlite3", ":memory:")
+	suite.Require().NoError(err)
+	db.SetMaxOpenConns(1)
+	suite.Db = db
+}
+
+func (suite *MigratorTestSuite) TearDownTest() {
+	suite.Db.Close()
+}
+
+func TestMigratorSuite(t *testing.T) {
+	suite.Run(t, new(MigratorTestSuite))
+}
+
+func (suite *MigratorTestSuite) source() fstest.MapFS {
+	return fstest.MapFS{
+		"000001_init.up.sql":     {Data: []byte("CREATE TABLE a (id int);")},
+		"000001_init.down.sql":   {Data: []byte("DROP TABLE a;")},
+		"000002_add_b.up.sql":    {Data: []byte("CREATE TABLE b (id int);\n-- comment; not a statement\nINSERT INTO b VALUES (1);")},
+		"000002_add_b.down.sql":  {Data: []byte("DROP TABLE b;")},
+		"000003_broken.up.sql":   {Data: []byte("CREATE TABLE c (id int); INSERT INTO missing VALUES (1);")},
+		"000003_broken.down.sql": {Data: []byte("DROP TABLE c;")},
+		"README.md":              {Data: []byte("ignored")},
+	}
+}
+
+func (suite *MigratorTestSuite) tables() []string {
+	rows, err := suite.Db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name != 'schema_migrations' ORDER BY name")
+	suite.Require().NoError(err)
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		suite.Require().NoError(rows.Scan(&name))
+		names = append(names, name)
+	}
+	return names
+}
+
+func (suite *MigratorTestSuite) TestGivenEmbeddedMigrations_WhenUpAndDown_ThenShouldCreateAndDropOrders() {
+	ctx := context.Background()
+	source, err := migrations.Source(SQLite.Name())
+	suite.Require().NoError(err)
+	migrator := NewMigrator(suite.Db, SQLite, source)
+	all, err := migrator.Migrations()
+	suite.NoError(err)
+
+	applied, err := migrator.Up(ctx)
+	suite.NoError(err)
+	suite.Equal(len(all), applied)
+	suite.Contains(suite.tables(), "orders")
+
+	applied, err = migrator.Up(ctx)
+	suite.NoError(err)
+	suite.Equal(0, applied)
+
+	reverted, err := migrator.Down(ctx, 0)
+	suite.NoError(err)
+	suite.Equal(len(all), reverted)
+	suite.Empty(suite.tables())
+}
+
+func (suite *MigratorTestSuite) TestGivenBrokenMigration_WhenUp_ThenShouldStopDirty() {
+	ctx := context.Background()
+	migrator := NewMigrator(suite.Db, SQLite, suite.source())
+
+	applied, err := migrator.Up(ctx)
+	suite.Error(err)
+	suite.Equal(2, applied)
+
+	status, err := migrator.Status(ctx)
+	suite.NoError(err)
+	suite.Equal(uint64(3), status.Version)
+	suite.True(status.Dirty)
+	suite.Equal([]MigrationStatus{
+		{Version: 1, Name: "init", Applied: true},
+		{Version: 2, Name: "add_b", Applied: true},
+		{Version: 3, Name: "broken", Applied: true},
+	}, status.Migrations)
+
+	_, err = migrator.Up(ctx)
+	suite.ErrorIs(err, ErrDirty)
+	_, err = migrator.Down(ctx, 1)
+	suite.ErrorIs(err, ErrDirty)
+
+	suite.NoError(migrator.Force(ctx, 2))
+	reverted, err := migrator.Down(ctx, 1)
+	suite.NoError(err)
+	suite.Equal(1, reverted)
+	suite.Equal([]string{"a", "c"}, suite.tables())
+
+	status, err = migrator.Status(ctx)
+	suite.NoError(err)
+	suite.Equal(uint64(1), status.Version)
+	suite.False(status.Dirty)
+	suite.False(status.Migrations[1].Applied)
+}
+
+func (suite *MigratorTestSuite) TestGivenScript_WhenSplitStatements_ThenShouldIgnoreQuotedSemicolons() {
+	statements := SplitStatements("INSERT INTO t VALUES ('a;b', \"c;d\");\n-- x; y\nSELECT 1;;\n")
+	suite.Equal([]string{"INSERT INTO t VALUES ('a;b', \"c;d\")", "SELECT 1"}, statements)
+}
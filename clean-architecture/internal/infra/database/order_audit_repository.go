@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/requestctx"
+)
+
+// OrderAuditRepository implements entity.OrderAuditRepositoryInterface on the
+// order_audit table. The entries are written by the order repositories, in the
+// same transaction as the change, through insertAuditEntry.
+type OrderAuditRepository struct {
+	Db      *sql.DB
+	Dialect Dialect
+}
+
+func NewOrderAuditRepository(db *sql.DB, dialect Dialect) *OrderAuditRepository {
+	return &OrderAuditRepository{Db: db, Dialect: dialect}
+}
+
+const selectAuditQuery = "SELECT order_id, action, actor, transport, request_id, before_value, after_value, created_at FROM order_audit "
+
+// History returns the audit entries of the order, oldest first.
+func (r *OrderAuditRepository) History(ctx context.Context, orderID string) (entries []entity.OrderAuditEntry, err error) {
+	const query = selectAuditQuery + "WHERE order_id = ? ORDER BY id"
+	ctx, end := startQuery(ctx, r.Dialect, "OrderAuditRepository.History", query)
+	defer func() { end(err) }()
+
+	rows, err := r.Db.QueryContext(ctx, r.Dialect.Rebind(query), orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	err = scanAuditEntries(rows, func(entry entity.OrderAuditEntry) {
+		entries = append(entries, entry)
+	})
+	return entries, err
+}
+
+// Histories returns the audit entries of every given order, oldest first.
+func (r *OrderAuditRepository) Histories(ctx context.Context, orderIDs []string) (histories map[string][]entity.OrderAuditEntry, err error) {
+	histories = map[string][]entity.OrderAuditEntry{}
+	if len(orderIDs) == 0 {
+		return histories, nil
+	}
+	query := selectAuditQuery + "WHERE order_id IN (?" + strings.Repeat(", ?", len(orderIDs)-1) + ") ORDER BY id"
+	ctx, end := startQuery(ctx, r.Dialect, "OrderAuditRepository.Histories", query)
+	defer func() { end(err) }()
+
+	args := make([]any, len(orderIDs))
+	for i, id := range orderIDs {
+		args[i] = id
+	}
+	rows, err := r.Db.QueryContext(ctx, r.Dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	err = scanAuditEntries(rows, func(entry entity.OrderAuditEntry) {
+		histories[entry.OrderID] = append(histories[entry.OrderID], entry)
+	})
+	return histories, err
+}
+
+func scanAuditEntries(rows *sql.Rows, fn func(entry entity.OrderAuditEntry)) error {
+	for rows.Next() {
+		var entry entity.OrderAuditEntry
+		var before, after sql.NullString
+		err := rows.Scan(&entry.OrderID, &entry.Action, &entry.Actor, &entry.Transport, &entry.RequestID, &before, &after, &entry.Timestamp)
+		if err != nil {
+			return err
+		}
+		if entry.Before, err = decodeAuditValue(before); err != nil {
+			return fmt.Errorf("decoding audit of order %s: %w", entry.OrderID, err)
+		}
+		if entry.After, err = decodeAuditValue(after); err != nil {
+			return fmt.Errorf("decoding audit of order %s: %w", entry.OrderID, err)
+		}
+		fn(entry)
+	}
+	return rows.Err()
+}
+
+// insertAuditEntry records a mutation of the order in tx, taking the actor,
+// transport and request ID from ctx.
+func insertAuditEntry(ctx context.Context, tx *sql.Tx, dialect Dialect, orderID, action string, before, after *entity.OrderEventData, at time.Time) error {
+	beforeValue, err := encodeAuditValue(before)
+	if err != nil {
+		return err
+	}
+	afterValue, err := encodeAuditValue(after)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, dialect.Rebind("INSERT INTO order_audit (order_id, action, actor, transport, request_id, before_value, after_value, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"),
+		orderID, action, requestctx.Actor(ctx), requestctx.Transport(ctx), requestctx.RequestID(ctx), beforeValue, afterValue, at)
+	return err
+}
+
+func encodeAuditValue(data *entity.OrderEventData) (sql.NullString, error) {
+	if data == nil {
+		return sql.NullString{}, nil
+	}
+	value, err := json.Marshal(data)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(value), Valid: true}, nil
+}
+
+func decodeAuditValue(value sql.NullString) (*entity.OrderEventData, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+	var data entity.OrderEventData
+	if err := json.Unmarshal([]byte(value.String), &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/requestctx"
+	"github.com/alexandreti/posGoExpert/clean-architecture/sql/migrations"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type OrderAuditRepositoryTestSuite struct {
+	suite.Suite
+	Db    *sql.DB
+	Audit *OrderAuditRepository
+}
+
+func (suite *OrderAuditRepositoryTestSuite) SetupTest() {
+	db, err := sql.Open("sqlite3", ":memory:")
+	suite.Require().NoError(err)
+	db.SetMaxOpenConns(1)
+	source, err := migrations.Source(SQLite.Name())
+	suite.Require().NoError(err)
+	_, err = NewMigrator(db, SQLite, source).Up(context.Background())
+	suite.Require().NoError(err)
+	suite.Db = db
+	suite.Audit = NewOrderAuditRepository(db, SQLite)
+}
+
+func (suite *OrderAuditRepositoryTestSuite) TearDownTest() {
+	suite.Db.Close()
+}
+
+func TestOrderAuditRepositorySuite(t *testing.T) {
+	suite.Run(t, new(OrderAuditRepositoryTestSuite))
+}
+
+func auditContext(actor, transport, requestID string) context.Context {
+	ctx := requestctx.WithActor(context.Background(), actor)
+	ctx = requestctx.WithTransport(ctx, transport)
+	return requestctx.WithRequestID(ctx, requestID)
+}
+
+func (suite *OrderAuditRepositoryTestSuite) TestGivenASavedOrder_WhenHistory_ThenShouldReturnTheCreation() {
+	repo := NewOrderRepository(suite.Db, SQLite)
+	ctx := auditContext("alice", requestctx.TransportREST, "req-1")
+	suite.NoError(repo.Save(ctx, &entity.Order{ID: "a", Price: 10.0, Tax: 2.0, FinalPrice: 12.0}))
+
+	entries, err := suite.Audit.History(context.Background(), "a")
+	suite.NoError(err)
+	suite.Require().Len(entries, 1)
+	suite.False(entries[0].Timestamp.IsZero())
+	entries[0].Timestamp = time.Time{}
+	suite.Equal(entity.OrderAuditEntry{
+		OrderID:   "a",
+		Action:    entity.OrderCreatedEvent,
+		Actor:     "alice",
+		Transport: requestctx.TransportREST,
+		RequestID: "req-1",
+		After:     &entity.OrderEventData{Price: 10.0, Tax: 2.0, FinalPrice: 12.0},
+	}, entries[0])
+}
+
+func (suite *OrderAuditRepositoryTestSuite) TestGivenAnEventSourcedOrder_WhenHistory_ThenShouldReturnBeforeAndAfter() {
+	store := NewOrderEventStore(suite.Db, SQLite, nil)
+	suite.NoError(store.Save(auditContext("alice", requestctx.TransportGRPC, "req-1"), &entity.Order{ID: "a", Price: 10.0, Tax: 2.0, FinalPrice: 12.0}))
+	order, err := store.Load(context.Background(), "a")
+	suite.NoError(err)
+	order.Price, order.FinalPrice = 20.0, 22.0
+	suite.NoError(store.Save(auditContext("bob", requestctx.TransportGraphQL, "req-2"), order))
+
+	entries, err := suite.Audit.History(context.Background(), "a")
+	suite.NoError(err)
+	suite.Require().Len(entries, 2)
+	suite.Equal(entity.OrderCreatedEvent, entries[0].Action)
+	suite.Nil(entries[0].Before)
+	suite.Equal("bob", entries[1].Actor)
+	suite.Equal(requestctx.TransportGraphQL, entries[1].Transport)
+	suite.Equal("req-2", entries[1].RequestID)
+	suite.Equal(entity.OrderUpdatedEvent, entries[1].Action)
+	suite.Equal(&entity.OrderEventData{Price: 10.0, Tax: 2.0, FinalPrice: 12.0}, entries[1].Before)
+	suite.Equal(&entity.OrderEventData{Price: 20.0, Tax: 2.0, FinalPrice: 22.0}, entries[1].After)
+}
+
+func (suite *OrderAuditRepositoryTestSuite) TestGivenAFailedSave_WhenHistory_ThenShouldNotRecordIt() {
+	repo := NewOrderRepository(suite.Db, SQLite)
+	suite.NoError(repo.Save(context.Background(), &entity.Order{ID: "a", Price: 10.0, Tax: 2.0, FinalPrice: 12.0}))
+	suite.Error(repo.Save(context.Background(), &entity.Order{ID: "a", Price: 20.0, Tax: 2.0, FinalPrice: 22.0}))
+
+	entries, err := suite.Audit.History(context.Background(), "a")
+	suite.NoError(err)
+	suite.Len(entries, 1)
+	suite.Equal(requestctx.AnonymousActor, entries[0].Actor)
+}
+
+func (suite *OrderAuditRepositoryTestSuite) TestGivenSeveralOrders_WhenHistories_ThenShouldGroupTheEntriesByOrder() {
+	repo := NewOrderRepository(suite.Db, SQLite)
+	suite.NoError(repo.SaveBatch(context.Background(), []*entity.Order{
+		{ID: "a", Price: 10.0, Tax: 2.0, FinalPrice: 12.0},
+		{ID: "b", Price: 20.0, Tax: 2.0, FinalPrice: 22.0},
+		{ID: "c", Price: 30.0, Tax: 2.0, FinalPrice: 32.0},
+	}))
+
+	histories, err := suite.Audit.Histories(context.Background(), []string{"a", "c", "missing"})
+	suite.NoError(err)
+	suite.Len(histories, 2)
+	suite.Require().Len(histories["a"], 1)
+	suite.Equal(&entity.OrderEventData{Price: 10.0, Tax: 2.0, FinalPrice: 12.0}, histories["a"][0].After)
+	suite.Require().Len(histories["c"], 1)
+	suite.Equal("c", histories["c"][0].OrderID)
+
+	histories, err = suite.Audit.Histories(context.Background(), nil)
+	suite.NoError(err)
+	suite.Empty(histories)
+}
@@ -0,0 +1,479 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/event"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
+)
+
+var (
+	// ErrConcurrencyConflict is returned when the order was changed after it was
+	// loaded, or when a new order uses an ID that already exists.
+	ErrConcurrencyConflict = errors.New("order was changed concurrently")
+	ErrOrderNotFound       = entity.ErrOrderNotFound
+)
+
+// DefaultSnapshotEvery is how many events are appended to a stream between two
+// snapshots.
+const DefaultSnapshotEvery = 50
+
+// OrderEventStore implements entity.OrderRepositoryInterface keeping every
+// change of an order as an event in the order_events table, instead of
+// overwriting a row. The stream of an order is its ID and each event has the
+// next version of the stream; the (stream_id, version) primary key gives the
+// optimistic concurrency. Every SnapshotEvery events the state is saved in
+// order_snapshots, so loading an order replays at most that many events.
+//
+// After the transaction commits, each appended event is dispatched to the
+// EventDispatcher as an OrderEventAppended.
+type OrderEventStore struct {
+	Db              *sql.DB
+	Dialect         Dialect
+	EventDispatcher events.EventDispatcherInterface
+	SnapshotEvery   int
+	now             func() time.Time
+}
+
+func NewOrderEventStore(db *sql.DB, dialect Dialect, eventDispatcher events.EventDispatcherInterface) *OrderEventStore {
+	return &OrderEventStore{
+		Db:              db,
+		Dialect:         dialect,
+		EventDispatcher: eventDispatcher,
+		SnapshotEvery:   DefaultSnapshotEvery,
+		now:             time.Now,
+	}
+}
+
+// Save appends the change of the order to its stream: OrderCreated when
+// order.Version is 0 and OrderUpdated otherwise. The audit entry, with the
+// state before and after the change, is written in the same transaction. It
+// fails with entity.ErrOrderAlreadyExists when a new order reuses the ID of a
+// stream, and with ErrConcurrencyConflict when the stream is no longer at
+// order.Version.
+func (s *OrderEventStore) Save(ctx context.Context, order *entity.Order) (err error) {
+	ctx, end := startQuery(ctx, s.Dialect, "OrderEventStore.Save", appendEventQuery)
+	defer func() { end(err) }()
+
+	return s.append(ctx, []*entity.Order{order})
+}
+
+// SaveBatch appends the changes of all the orders in one transaction, like
+// Save does for one.
+func (s *OrderEventStore) SaveBatch(ctx context.Context, orders []*entity.Order) (err error) {
+	ctx, end := startQuery(ctx, s.Dialect, "OrderEventStore.SaveBatch", appendEventQuery)
+	defer func() { end(err) }()
+
+	return s.append(ctx, orders)
+}
+
+// ExistingIDs implements entity.OrderRepositoryInterface: an order exists
+// while its stream does.
+func (s *OrderEventStore) ExistingIDs(ctx context.Context, ids []string) (existing []string, err error) {
+	const query = "SELECT DISTINCT stream_id FROM order_events WHERE stream_id IN (?, ...)"
+	ctx, end := startQuery(ctx, s.Dialect, "OrderEventStore.ExistingIDs", query)
+	defer func() { end(err) }()
+
+	return selectExistingIDs(ctx, s.Db, s.Dialect, "SELECT DISTINCT stream_id FROM order_events WHERE stream_id", ids)
+}
+
+const appendEventQuery = "INSERT INTO order_events (stream_id, version, type, payload, created_at) VALUES (?, ?, ?, ?, ?)"
+
+func (s *OrderEventStore) append(ctx context.Context, orders []*entity.Order) error {
+	tx, err := s.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := s.now().UTC()
+	changes := make([]entity.OrderEvent, 0, len(orders))
+	for _, order := range orders {
+		change, err := s.appendChange(ctx, tx, order, now)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, change)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for i, order := range orders {
+		order.Version = changes[i].Version
+		if changes[i].Type == entity.OrderCreatedEvent {
+			order.CreatedAt = changes[i].Timestamp
+		}
+		s.dispatch(ctx, changes[i])
+	}
+	return nil
+}
+
+func (s *OrderEventStore) dispatch(ctx context.Context, change entity.OrderEvent) {
+	if s.EventDispatcher != nil {
+		appended := event.NewOrderEventAppended()
+		appended.SetPayload(change)
+		s.EventDispatcher.Dispatch(ctx, appended)
+	}
+}
+
+// Delete implements entity.OrderDeletionRepositoryInterface, appending an
+// OrderDeleted event.
+func (s *OrderEventStore) Delete(ctx context.Context, id string) (change entity.OrderEvent, err error) {
+	ctx, end := startQuery(ctx, s.Dialect, "OrderEventStore.Delete", appendEventQuery)
+	defer func() { end(err) }()
+
+	return s.appendDeletion(ctx, id, (*entity.Order).Delete)
+}
+
+// Restore implements entity.OrderDeletionRepositoryInterface, appending an
+// OrderRestored event.
+func (s *OrderEventStore) Restore(ctx context.Context, id string) (change entity.OrderEvent, err error) {
+	ctx, end := startQuery(ctx, s.Dialect, "OrderEventStore.Restore", appendEventQuery)
+	defer func() { end(err) }()
+
+	return s.appendDeletion(ctx, id, (*entity.Order).Restore)
+}
+
+// appendDeletion loads the order and appends the event fn returns for it.
+func (s *OrderEventStore) appendDeletion(ctx context.Context, id string, fn func(*entity.Order, time.Time) (entity.OrderEvent, error)) (entity.OrderEvent, error) {
+	order, err := s.Load(ctx, id)
+	if err != nil {
+		return entity.OrderEvent{}, err
+	}
+	change, err := fn(order, s.now().UTC())
+	if err != nil {
+		return entity.OrderEvent{}, err
+	}
+
+	tx, err := s.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return entity.OrderEvent{}, err
+	}
+	defer tx.Rollback()
+	if err := s.appendEvent(ctx, tx, change); err != nil {
+		return entity.OrderEvent{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return entity.OrderEvent{}, err
+	}
+	s.dispatch(ctx, change)
+	return change, nil
+}
+
+// appendChange appends the change of one order.
+func (s *OrderEventStore) appendChange(ctx context.Context, tx *sql.Tx, order *entity.Order, at time.Time) (entity.OrderEvent, error) {
+	change := order.Change(at)
+	return change, s.appendEvent(ctx, tx, change)
+}
+
+// appendEvent writes the event, the audit entry and, when due, the snapshot.
+// The stream must be at the version before the event.
+func (s *OrderEventStore) appendEvent(ctx context.Context, tx *sql.Tx, change entity.OrderEvent) error {
+	payload, err := json.Marshal(change.Data)
+	if err != nil {
+		return err
+	}
+
+	var current int
+	err = tx.QueryRowContext(ctx, s.Dialect.Rebind("SELECT COALESCE(MAX(version), 0) FROM order_events WHERE stream_id = ?"), change.OrderID).Scan(&current)
+	if err != nil {
+		return err
+	}
+	if change.Version == 1 && current != 0 {
+		return fmt.Errorf("%w: %s", entity.ErrOrderAlreadyExists, change.OrderID)
+	}
+	if current != change.Version-1 {
+		return fmt.Errorf("%w: order %s is at version %d, expected %d", ErrConcurrencyConflict, change.OrderID, current, change.Version-1)
+	}
+	_, err = tx.ExecContext(ctx, s.Dialect.Rebind(appendEventQuery), change.OrderID, change.Version, change.Type, string(payload), change.Timestamp)
+	if s.Dialect.IsUniqueViolation(err) && change.Version == 1 {
+		return fmt.Errorf("%w: %s", entity.ErrOrderAlreadyExists, change.OrderID)
+	}
+	if s.Dialect.IsUniqueViolation(err) {
+		return fmt.Errorf("%w: order %s", ErrConcurrencyConflict, change.OrderID)
+	}
+	if err != nil {
+		return err
+	}
+	before, err := s.stateAt(ctx, tx, change.OrderID, current)
+	if err != nil {
+		return err
+	}
+	if err := insertAuditEntry(ctx, tx, s.Dialect, change.OrderID, change.Type, before, &change.Data, change.Timestamp); err != nil {
+		return err
+	}
+	if s.SnapshotEvery > 0 && change.Version%s.SnapshotEvery == 0 {
+		return s.saveSnapshot(ctx, tx, change)
+	}
+	return nil
+}
+
+// stateAt returns the data of the event at version, or nil for version 0.
+func (s *OrderEventStore) stateAt(ctx context.Context, tx *sql.Tx, id string, version int) (*entity.OrderEventData, error) {
+	if version == 0 {
+		return nil, nil
+	}
+	var payload string
+	err := tx.QueryRowContext(ctx, s.Dialect.Rebind("SELECT payload FROM order_events WHERE stream_id = ? AND version = ?"), id, version).Scan(&payload)
+	if err != nil {
+		return nil, err
+	}
+	var data entity.OrderEventData
+	if err := json.Unmarshal([]byte(payload), &data); err != nil {
+		return nil, fmt.Errorf("decoding event %d of order %s: %w", version, id, err)
+	}
+	return &data, nil
+}
+
+// saveSnapshot keeps only the last snapshot of the stream. Every event holds
+// the whole state, so the snapshot is the data of the event just appended.
+func (s *OrderEventStore) saveSnapshot(ctx context.Context, tx *sql.Tx, change entity.OrderEvent) error {
+	payload, err := json.Marshal(change.Data)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, s.Dialect.Rebind("DELETE FROM order_snapshots WHERE stream_id = ?"), change.OrderID); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, s.Dialect.Rebind("INSERT INTO order_snapshots (stream_id, version, payload, created_at) VALUES (?, ?, ?, ?)"),
+		change.OrderID, change.Version, string(payload), change.Timestamp)
+	return err
+}
+
+// Load rebuilds the order from its last snapshot and the events after it.
+func (s *OrderEventStore) Load(ctx context.Context, id string) (order *entity.Order, err error) {
+	const query = "SELECT version, payload FROM order_snapshots WHERE stream_id = ?"
+	ctx, end := startQuery(ctx, s.Dialect, "OrderEventStore.Load", query)
+	defer func() { end(err) }()
+
+	var snapshot *entity.Order
+	var version int
+	var payload string
+	err = s.Db.QueryRowContext(ctx, s.Dialect.Rebind(query), id).Scan(&version, &payload)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return nil, err
+	default:
+		var data entity.OrderEventData
+		if err := json.Unmarshal([]byte(payload), &data); err != nil {
+			return nil, fmt.Errorf("decoding snapshot of order %s: %w", id, err)
+		}
+		snapshot = &entity.Order{ID: id, Price: data.Price, Tax: data.Tax, FinalPrice: data.FinalPrice, DeletedAt: data.DeletedAt, Version: version}
+	}
+
+	events, err := s.Events(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot == nil && len(events) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, id)
+	}
+	return entity.RebuildOrder(snapshot, events)
+}
+
+// Events returns the events of the order after the given version.
+func (s *OrderEventStore) Events(ctx context.Context, id string, after int) ([]entity.OrderEvent, error) {
+	rows, err := s.Db.QueryContext(ctx, s.Dialect.Rebind("SELECT stream_id, version, type, payload, created_at FROM order_events WHERE stream_id = ? AND version > ? ORDER BY version"), id, after)
+	if err != nil {
+		return nil, err
+	}
+	return scanOrderEvents(rows)
+}
+
+// ListOrders rebuilds every order from its snapshot and the events after it,
+// reading each table once, and leaves the deleted orders out.
+func (s *OrderEventStore) ListOrders(ctx context.Context) (orders []entity.Order, err error) {
+	const query = "SELECT e.stream_id, e.version, e.type, e.payload, e.created_at FROM order_events e " +
+		"LEFT JOIN order_snapshots s ON s.stream_id = e.stream_id " +
+		"WHERE s.version IS NULL OR e.version > s.version ORDER BY e.stream_id, e.version"
+	ctx, end := startQuery(ctx, s.Dialect, "OrderEventStore.ListOrders", query)
+	defer func() { end(err) }()
+
+	// a snapshot written between the two queries would make the second one skip
+	// events the first one didn't read, so both read the same state
+	tx, err := s.Db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	snapshots := map[string]*entity.Order{}
+	var ids []string
+	rows, err := tx.QueryContext(ctx, "SELECT stream_id, version, payload FROM order_snapshots ORDER BY stream_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		order := &entity.Order{}
+		var data entity.OrderEventData
+		var payload string
+		if err := rows.Scan(&order.ID, &order.Version, &payload); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(payload), &data); err != nil {
+			return nil, fmt.Errorf("decoding snapshot of order %s: %w", order.ID, err)
+		}
+		order.Price, order.Tax, order.FinalPrice, order.DeletedAt = data.Price, data.Tax, data.FinalPrice, data.DeletedAt
+		snapshots[order.ID] = order
+		ids = append(ids, order.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows.Close()
+
+	eventRows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := scanOrderEvents(eventRows)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		order, ok := snapshots[change.OrderID]
+		if !ok {
+			order = &entity.Order{}
+			snapshots[change.OrderID] = order
+			ids = append(ids, change.OrderID)
+		}
+		if err := order.Apply(change); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(ids)
+	for _, id := range ids {
+		if snapshots[id].DeletedAt == nil {
+			orders = append(orders, *snapshots[id])
+		}
+	}
+	return orders, nil
+}
+
+// lastEventCondition keeps only the last event of the stream of e, which holds
+// the current state of the order.
+const lastEventCondition = "e.version = (SELECT MAX(m.version) FROM order_events m WHERE m.stream_id = e.stream_id)"
+
+// GetTotals implements entity.OrderTotalsRepositoryInterface with a single
+// scan over the range the periods span. An order is created at the time of its
+// first event and every event holds the whole state, so each order found is
+// summed, in the period of its first event, with its last event, unless that
+// is an OrderDeleted.
+func (s *OrderEventStore) GetTotals(ctx context.Context, periods []entity.OrderPeriod) (totals []entity.OrderTotals, err error) {
+	totals = make([]entity.OrderTotals, len(periods))
+	if len(periods) == 0 {
+		return totals, nil
+	}
+	conditions, args := periodConditions("c.created_at", spanOf(periods))
+	query := "SELECT e.stream_id, e.version, e.payload, c.created_at FROM order_events e " +
+		"JOIN order_events c ON c.stream_id = e.stream_id AND c.version = 1 WHERE " +
+		strings.Join(append(conditions, lastEventCondition, "e.type <> ?"), " AND ")
+	args = append(args, entity.OrderDeletedEvent)
+	ctx, end := startQuery(ctx, s.Dialect, "OrderEventStore.GetTotals", query)
+	defer func() { end(err) }()
+
+	rows, err := s.Db.QueryContext(ctx, s.Dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, payload string
+		var version int
+		var createdAt time.Time
+		if err := rows.Scan(&id, &version, &payload, &createdAt); err != nil {
+			return nil, err
+		}
+		var data entity.OrderEventData
+		if err := json.Unmarshal([]byte(payload), &data); err != nil {
+			return nil, fmt.Errorf("decoding event %d of order %s: %w", version, id, err)
+		}
+		// the first period that ends after the creation
+		i := sort.Search(len(periods)-1, func(i int) bool { return createdAt.Before(periods[i].To) })
+		totals[i] = totals[i].Add(entity.OrderTotals{Count: 1, Price: data.Price, Tax: data.Tax, FinalPrice: data.FinalPrice})
+	}
+	return totals, rows.Err()
+}
+
+// ReplayEvents implements entity.OrderHistoryInterface, reading the whole
+// order_events table in batches ordered by stream and version.
+func (s *OrderEventStore) ReplayEvents(ctx context.Context, fn func(event entity.OrderEvent) error) (err error) {
+	const query = "SELECT stream_id, version, type, payload, created_at FROM order_events " +
+		"WHERE stream_id > ? OR (stream_id = ? AND version > ?) ORDER BY stream_id, version LIMIT ?"
+	ctx, end := startQuery(ctx, s.Dialect, "OrderEventStore.ReplayEvents", query)
+	defer func() { end(err) }()
+
+	lastStream, lastVersion := "", 0
+	for {
+		rows, err := s.Db.QueryContext(ctx, s.Dialect.Rebind(query), lastStream, lastStream, lastVersion, replayBatchSize)
+		if err != nil {
+			return err
+		}
+		changes, err := scanOrderEvents(rows)
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			if err := fn(change); err != nil {
+				return err
+			}
+			lastStream, lastVersion = change.OrderID, change.Version
+		}
+		if len(changes) < replayBatchSize {
+			return nil
+		}
+	}
+}
+
+// ReplayOrderEvents implements entity.OrderHistoryInterface, reading the
+// stream of the order id only.
+func (s *OrderEventStore) ReplayOrderEvents(ctx context.Context, id string, fn func(event entity.OrderEvent) error) (err error) {
+	const query = "SELECT stream_id, version, type, payload, created_at FROM order_events WHERE stream_id = ? ORDER BY version"
+	ctx, end := startQuery(ctx, s.Dialect, "OrderEventStore.ReplayOrderEvents", query)
+	defer func() { end(err) }()
+
+	rows, err := s.Db.QueryContext(ctx, s.Dialect.Rebind(query), id)
+	if err != nil {
+		return err
+	}
+	changes, err := scanOrderEvents(rows)
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		if err := fn(change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scanOrderEvents(rows *sql.Rows) ([]entity.OrderEvent, error) {
+	defer rows.Close()
+	var changes []entity.OrderEvent
+	for rows.Next() {
+		var change entity.OrderEvent
+		var payload string
+		if err := rows.Scan(&change.OrderID, &change.Version, &change.Type, &payload, &change.Timestamp); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(payload), &change.Data); err != nil {
+			return nil, fmt.Errorf("decoding event %d of order %s: %w", change.Version, change.OrderID, err)
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
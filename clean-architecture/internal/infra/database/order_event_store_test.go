@@ -0,0 +1,323 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
+	"github.com/alexandreti/posGoExpert/clean-architecture/sql/migrations"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type appendedEventsHandler struct {
+	mu     sync.Mutex
+	events []entity.OrderEvent
+}
+
+func (h *appendedEventsHandler) Handle(ctx context.Context, event events.EventInterface, wg *sync.WaitGroup) {
+	defer wg.Done()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, event.GetPayload().(entity.OrderEvent))
+}
+
+type OrderEventStoreTestSuite struct {
+	suite.Suite
+	Db      *sql.DB
+	Store   *OrderEventStore
+	Handler *appendedEventsHandler
+}
+
+func (suite *OrderEventStoreTestSuite) SetupTest() {
+	db, err := sql.Open("sqlite3", ":memory:")
+	suite.Require().NoError(err)
+	db.SetMaxOpenConns(1)
+	source, err := migrations.Source(SQLite.Name())
+	suite.Require().NoError(err)
+	_, err = NewMigrator(db, SQLite, source).Up(context.Background())
+	suite.Require().NoError(err)
+
+	dispatcher := events.NewEventDispatcher()
+	suite.Handler = &appendedEventsHandler{}
+	dispatcher.Register("OrderEventAppended", suite.Handler)
+
+	suite.Db = db
+	suite.Store = NewOrderEventStore(db, SQLite, dispatcher)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	suite.Store.now = func() time.Time { return now }
+}
+
+func (suite *OrderEventStoreTestSuite) TearDownTest() {
+	suite.Db.Close()
+}
+
+func TestOrderEventStoreSuite(t *testing.T) {
+	suite.Run(t, new(OrderEventStoreTestSuite))
+}
+
+func (suite *OrderEventStoreTestSuite) TestGivenAnOrder_WhenSave_ThenShouldAppendAndDispatchOrderCreated() {
+	ctx := context.Background()
+	order := &entity.Order{ID: "a", Price: 10.0, Tax: 2.0, FinalPrice: 12.0}
+	suite.NoError(suite.Store.Save(ctx, order))
+	suite.Equal(1, order.Version)
+
+	changes, err := suite.Store.Events(ctx, "a", 0)
+	suite.NoError(err)
+	expected := entity.OrderEvent{
+		OrderID:   "a",
+		Version:   1,
+		Type:      entity.OrderCreatedEvent,
+		Data:      entity.OrderEventData{Price: 10.0, Tax: 2.0, FinalPrice: 12.0},
+		Timestamp: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	suite.Len(changes, 1)
+	suite.Equal(expected.Type, changes[0].Type)
+	suite.Equal(expected.Data, changes[0].Data)
+	suite.True(expected.Timestamp.Equal(changes[0].Timestamp))
+	suite.Equal([]entity.OrderEvent{expected}, suite.Handler.events)
+	suite.Equal(expected.Timestamp, order.CreatedAt)
+
+	loaded, err := suite.Store.Load(ctx, "a")
+	suite.NoError(err)
+	order.CreatedAt = time.Time{}
+	suite.Equal(order, loaded)
+}
+
+func (suite *OrderEventStoreTestSuite) TestGivenAnExistingID_WhenSaveANewOrder_ThenShouldReturnAlreadyExists() {
+	ctx := context.Background()
+	suite.NoError(suite.Store.Save(ctx, &entity.Order{ID: "a", Price: 10.0, Tax: 2.0, FinalPrice: 12.0}))
+
+	err := suite.Store.Save(ctx, &entity.Order{ID: "a", Price: 20.0, Tax: 2.0, FinalPrice: 22.0})
+	suite.ErrorIs(err, entity.ErrOrderAlreadyExists)
+	suite.Len(suite.Handler.events, 1)
+}
+
+func (suite *OrderEventStoreTestSuite) TestGivenSavedOrders_WhenExistingIDs_ThenShouldReturnOnlyTheStoredOnes() {
+	ctx := context.Background()
+	suite.NoError(suite.Store.SaveBatch(ctx, []*entity.Order{
+		{ID: "a", Price: 10.0, Tax: 2.0, FinalPrice: 12.0},
+		{ID: "c", Price: 30.0, Tax: 2.0, FinalPrice: 32.0},
+	}))
+	_, err := suite.Store.Delete(ctx, "a")
+	suite.NoError(err)
+
+	ids, err := suite.Store.ExistingIDs(ctx, []string{"a", "b", "c"})
+	suite.NoError(err)
+	suite.ElementsMatch([]string{"a", "c"}, ids)
+}
+
+func (suite *OrderEventStoreTestSuite) TestGivenAStaleOrder_WhenSave_ThenShouldReturnAConflict() {
+	ctx := context.Background()
+	suite.NoError(suite.Store.Save(ctx, &entity.Order{ID: "a", Price: 10.0, Tax: 2.0, FinalPrice: 12.0}))
+	first, err := suite.Store.Load(ctx, "a")
+	suite.NoError(err)
+	second, err := suite.Store.Load(ctx, "a")
+	suite.NoError(err)
+
+	first.Price, first.FinalPrice = 20.0, 22.0
+	suite.NoError(suite.Store.Save(ctx, first))
+	second.Price, second.FinalPrice = 30.0, 32.0
+	suite.ErrorIs(suite.Store.Save(ctx, second), ErrConcurrencyConflict)
+
+	loaded, err := suite.Store.Load(ctx, "a")
+	suite.NoError(err)
+	suite.Equal(&entity.Order{ID: "a", Price: 20.0, Tax: 2.0, FinalPrice: 22.0, Version: 2}, loaded)
+}
+
+func (suite *OrderEventStoreTestSuite) TestGivenManyChanges_WhenLoad_ThenShouldStartFromTheSnapshot() {
+	ctx := context.Background()
+	suite.Store.SnapshotEvery = 2
+	order := &entity.Order{ID: "a", Price: 1.0, Tax: 1.0, FinalPrice: 2.0}
+	for i := 0; i < 5; i++ {
+		order.Price = float64(i + 1)
+		order.FinalPrice = order.Price + order.Tax
+		suite.NoError(suite.Store.Save(ctx, order))
+	}
+
+	var version int
+	suite.NoError(suite.Db.QueryRow("SELECT version FROM order_snapshots WHERE stream_id = 'a'").Scan(&version))
+	suite.Equal(4, version)
+
+	// the events covered by the snapshot aren't replayed
+	_, err := suite.Db.Exec("DELETE FROM order_events WHERE version <= 4")
+	suite.NoError(err)
+	loaded, err := suite.Store.Load(ctx, "a")
+	suite.NoError(err)
+	suite.Equal(&entity.Order{ID: "a", Price: 5.0, Tax: 1.0, FinalPrice: 6.0, Version: 5}, loaded)
+
+	orders, err := suite.Store.ListOrders(ctx)
+	suite.NoError(err)
+	suite.Equal([]entity.Order{*loaded}, orders)
+}
+
+func (suite *OrderEventStoreTestSuite) TestGivenSavedOrders_WhenListOrders_ThenShouldRebuildEachOne() {
+	ctx := context.Background()
+	suite.NoError(suite.Store.Save(ctx, &entity.Order{ID: "b", Price: 100.5, Tax: 0.5, FinalPrice: 101.0}))
+	order := &entity.Order{ID: "a", Price: 10.0, Tax: 2.0, FinalPrice: 12.0}
+	suite.NoError(suite.Store.Save(ctx, order))
+	order.Tax, order.FinalPrice = 3.0, 13.0
+	suite.NoError(suite.Store.Save(ctx, order))
+
+	orders, err := suite.Store.ListOrders(ctx)
+	suite.NoError(err)
+	suite.Equal([]entity.Order{
+		{ID: "a", Price: 10.0, Tax: 3.0, FinalPrice: 13.0, Version: 2},
+		{ID: "b", Price: 100.5, Tax: 0.5, FinalPrice: 101.0, Version: 1},
+	}, orders)
+}
+
+func (suite *OrderEventStoreTestSuite) TestGivenAnUnknownID_WhenLoad_ThenShouldReturnNotFound() {
+	_, err := suite.Store.Load(context.Background(), "missing")
+	suite.ErrorIs(err, ErrOrderNotFound)
+}
+
+func (suite *OrderEventStoreTestSuite) TestGivenABatch_WhenSaveBatch_ThenShouldAppendAllOrNone() {
+	ctx := context.Background()
+	suite.NoError(suite.Store.Save(ctx, &entity.Order{ID: "a", Price: 10.0, Tax: 2.0, FinalPrice: 12.0}))
+
+	err := suite.Store.SaveBatch(ctx, []*entity.Order{
+		{ID: "b", Price: 20.0, Tax: 2.0, FinalPrice: 22.0},
+		{ID: "a", Price: 30.0, Tax: 2.0, FinalPrice: 32.0},
+	})
+	suite.ErrorIs(err, entity.ErrOrderAlreadyExists)
+	_, err = suite.Store.Load(ctx, "b")
+	suite.ErrorIs(err, ErrOrderNotFound)
+
+	batch := []*entity.Order{
+		{ID: "b", Price: 20.0, Tax: 2.0, FinalPrice: 22.0},
+		{ID: "c", Price: 30.0, Tax: 2.0, FinalPrice: 32.0},
+	}
+	suite.NoError(suite.Store.SaveBatch(ctx, batch))
+	suite.Equal(1, batch[0].Version)
+	suite.Equal(1, batch[1].Version)
+	suite.Len(suite.Handler.events, 3)
+}
+
+func (suite *OrderEventStoreTestSuite) TestGivenChangedOrders_WhenGetTotals_ThenShouldSumTheLastStateOfThoseCreatedInEachPeriod() {
+	ctx := context.Background()
+	january, february := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 2, 0, 0, 0, 0, time.UTC)
+	suite.Store.now = func() time.Time { return january }
+	order := &entity.Order{ID: "a", Price: 10.0, Tax: 2.0, FinalPrice: 12.0}
+	suite.NoError(suite.Store.Save(ctx, order))
+	suite.NoError(suite.Store.Save(ctx, &entity.Order{ID: "b", Price: 20.0, Tax: 1.0, FinalPrice: 21.0}))
+	// changed in February, still created in January
+	suite.Store.now = func() time.Time { return february }
+	order.Tax, order.FinalPrice = 3.0, 13.0
+	suite.NoError(suite.Store.Save(ctx, order))
+	suite.NoError(suite.Store.Save(ctx, &entity.Order{ID: "c", Price: 100.0, Tax: 1.0, FinalPrice: 101.0}))
+
+	totals, err := suite.Store.GetTotals(ctx, []entity.OrderPeriod{
+		{From: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{From: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+	})
+	suite.NoError(err)
+	suite.Equal([]entity.OrderTotals{
+		{Count: 2, Price: 30.0, Tax: 4.0, FinalPrice: 34.0},
+		{Count: 1, Price: 100.0, Tax: 1.0, FinalPrice: 101.0},
+	}, totals)
+
+	totals, err = suite.Store.GetTotals(ctx, []entity.OrderPeriod{{}})
+	suite.NoError(err)
+	suite.Equal(3, totals[0].Count)
+	suite.Equal(135.0, totals[0].FinalPrice)
+}
+
+func (suite *OrderEventStoreTestSuite) TestGivenAnOrder_WhenDeleteAndRestore_ThenShouldAppendAndDispatchBothEvents() {
+	ctx := context.Background()
+	suite.NoError(suite.Store.Save(ctx, &entity.Order{ID: "a", Price: 10.0, Tax: 2.0, FinalPrice: 12.0}))
+	suite.NoError(suite.Store.Save(ctx, &entity.Order{ID: "b", Price: 20.0, Tax: 1.0, FinalPrice: 21.0}))
+
+	deleted, err := suite.Store.Delete(ctx, "a")
+	suite.Require().NoError(err)
+	suite.Equal(entity.OrderDeletedEvent, deleted.Type)
+	suite.Equal(2, deleted.Version)
+	suite.Equal(12.0, deleted.Data.FinalPrice)
+	_, err = suite.Store.Delete(ctx, "a")
+	suite.ErrorIs(err, entity.ErrOrderDeleted)
+
+	order, err := suite.Store.Load(ctx, "a")
+	suite.NoError(err)
+	suite.NotNil(order.DeletedAt)
+	orders, err := suite.Store.ListOrders(ctx)
+	suite.NoError(err)
+	suite.Equal([]string{"b"}, orderIDs(orders))
+	totals, err := suite.Store.GetTotals(ctx, []entity.OrderPeriod{{}})
+	suite.NoError(err)
+	suite.Equal(1, totals[0].Count)
+
+	expired, err := NewOrderRetentionRepository(suite.Db, SQLite, true).Expired(ctx, time.Time{}, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), "", 10)
+	suite.NoError(err)
+	suite.Require().Len(expired, 1)
+	suite.Equal("a", expired[0].ID)
+	suite.Equal(12.0, expired[0].FinalPrice)
+	suite.Equal(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), expired[0].CreatedAt)
+
+	restored, err := suite.Store.Restore(ctx, "a")
+	suite.Require().NoError(err)
+	suite.Equal(entity.OrderRestoredEvent, restored.Type)
+	suite.Equal(3, restored.Version)
+	_, err = suite.Store.Restore(ctx, "a")
+	suite.ErrorIs(err, entity.ErrOrderNotDeleted)
+	_, err = suite.Store.Delete(ctx, "missing")
+	suite.ErrorIs(err, ErrOrderNotFound)
+
+	suite.Len(suite.Handler.events, 4)
+	suite.Equal(deleted, suite.Handler.events[2])
+	suite.Equal(restored, suite.Handler.events[3])
+}
+
+func (suite *OrderEventStoreTestSuite) TestGivenAnOrderRestoredAfterExpired_WhenPurge_ThenShouldKeepItsStream() {
+	ctx := context.Background()
+	suite.NoError(suite.Store.Save(ctx, &entity.Order{ID: "a", Price: 10.0, Tax: 2.0, FinalPrice: 12.0}))
+	suite.NoError(suite.Store.Save(ctx, &entity.Order{ID: "b", Price: 20.0, Tax: 1.0, FinalPrice: 21.0}))
+	for _, id := range []string{"a", "b"} {
+		_, err := suite.Store.Delete(ctx, id)
+		suite.Require().NoError(err)
+	}
+	retention := NewOrderRetentionRepository(suite.Db, SQLite, true)
+	deletedBefore := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	expired, err := retention.Expired(ctx, time.Time{}, deletedBefore, "", 10)
+	suite.Require().NoError(err)
+	suite.Require().Len(expired, 2)
+	_, err = suite.Store.Restore(ctx, "a")
+	suite.Require().NoError(err)
+
+	purged, err := retention.Purge(ctx, time.Time{}, deletedBefore, []string{"a", "b"})
+	suite.NoError(err)
+	suite.Equal([]string{"b"}, purged)
+	order, err := suite.Store.Load(ctx, "a")
+	suite.NoError(err)
+	suite.Nil(order.DeletedAt)
+	changes, err := suite.Store.Events(ctx, "a", 0)
+	suite.NoError(err)
+	suite.Len(changes, 3)
+	_, err = suite.Store.Load(ctx, "b")
+	suite.ErrorIs(err, ErrOrderNotFound)
+}
+
+func (suite *OrderEventStoreTestSuite) TestGivenOldOrders_WhenPurge_ThenShouldRemoveTheirStreams() {
+	ctx := context.Background()
+	suite.NoError(suite.Store.Save(ctx, &entity.Order{ID: "a", Price: 10.0, Tax: 2.0, FinalPrice: 12.0}))
+	suite.NoError(suite.Store.Save(ctx, &entity.Order{ID: "b", Price: 20.0, Tax: 1.0, FinalPrice: 21.0}))
+	retention := NewOrderRetentionRepository(suite.Db, SQLite, true)
+
+	expired, err := retention.Expired(ctx, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), time.Time{}, "a", 10)
+	suite.NoError(err)
+	suite.Require().Len(expired, 1)
+	suite.Equal("b", expired[0].ID)
+	suite.Nil(expired[0].DeletedAt)
+
+	purged, err := retention.Purge(ctx, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), time.Time{}, []string{"b"})
+	suite.NoError(err)
+	suite.Equal([]string{"b"}, purged)
+	_, err = suite.Store.Load(ctx, "b")
+	suite.ErrorIs(err, ErrOrderNotFound)
+	orders, err := suite.Store.ListOrders(ctx)
+	suite.NoError(err)
+	suite.Equal([]string{"a"}, orderIDs(orders))
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+)
+
+// orderQueryColumns maps the fields of an entity.OrderQuery to the columns of
+// order_views. Only these names ever reach the SQL; every value of the query
+// is sent as an argument.
+var orderQueryColumns = map[entity.OrderQueryField]string{
+	entity.OrderQueryID:         "id",
+	entity.OrderQueryPrice:      "price",
+	entity.OrderQueryTax:        "tax",
+	entity.OrderQueryFinalPrice: "final_price",
+	entity.OrderQueryCreated:    "created_at",
+}
+
+// likeEscape is the ESCAPE character of the LIKE patterns. A backslash would
+// need escaping differently in each database.
+const likeEscape = "!"
+
+var likeEscaper = strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_")
+
+// compileOrderQuery turns the query into the conditions of a WHERE clause,
+// joined by AND, with "?" placeholders, and their arguments. A query without
+// terms gives no conditions.
+func compileOrderQuery(query entity.OrderQuery) (conditions []string, args []any, err error) {
+	for _, term := range query.Terms {
+		column, ok := orderQueryColumns[term.Field]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: unknown field %q", entity.ErrInvalidOrderQuery, term.Field)
+		}
+		if term.Field == entity.OrderQueryID {
+			condition, arg := compileIDMatch(column, term)
+			conditions, args = append(conditions, condition), append(args, arg)
+			continue
+		}
+		if term.Lower != nil {
+			op := ">"
+			if term.Lower.Inclusive {
+				op = ">="
+			}
+			conditions, args = append(conditions, column+" "+op+" ?"), append(args, term.Lower.Value)
+		}
+		if term.Upper != nil {
+			op := "<"
+			if term.Upper.Inclusive {
+				op = "<="
+			}
+			conditions, args = append(conditions, column+" "+op+" ?"), append(args, term.Upper.Value)
+		}
+	}
+	return conditions, args, nil
+}
+
+// compileIDMatch matches the exact ID as is and the wildcard ones ignoring
+// case, which LIKE does differently in each database.
+func compileIDMatch(column string, term entity.OrderQueryTerm) (string, any) {
+	pattern := likeEscaper.Replace(strings.ToLower(term.Text))
+	switch term.Match {
+	case entity.OrderIDPrefix:
+		pattern = pattern + "%"
+	case entity.OrderIDSuffix:
+		pattern = "%" + pattern
+	case entity.OrderIDContains:
+		pattern = "%" + pattern + "%"
+	default:
+		return column + " = ?", term.Text
+	}
+	return "LOWER(" + column + ") LIKE ? ESCAPE '" + likeEscape + "'", pattern
+}
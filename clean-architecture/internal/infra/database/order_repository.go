@@ -1,36 +1,158 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const tracerName = "github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/database"
+
+// OrderRepository implements entity.OrderRepositoryInterface for MySQL, PostgreSQL
+// and SQLite; the Dialect adapts the SQL to each of them.
 type OrderRepository struct {
-	Db *sql.DB
+	Db      *sql.DB
+	Dialect Dialect
+}
+
+func NewOrderRepository(db *sql.DB, dialect Dialect) *OrderRepository {
+	return &OrderRepository{Db: db, Dialect: dialect}
+}
+
+func NewMySQLOrderRepository(db *sql.DB) *OrderRepository {
+	return NewOrderRepository(db, MySQL)
+}
+
+func NewPostgresOrderRepository(db *sql.DB) *OrderRepository {
+	return NewOrderRepository(db, Postgres)
 }
 
-func NewOrderRepository(db *sql.DB) *OrderRepository {
-	return &OrderRepository{Db: db}
+func NewSQLiteOrderRepository(db *sql.DB) *OrderRepository {
+	return NewOrderRepository(db, SQLite)
 }
 
-func (r *OrderRepository) Save(order *entity.Order) error {
-	stmt, err := r.Db.Prepare("INSERT INTO orders (id, price, tax, final_price) VALUES (?, ?, ?, ?)")
+const insertOrderQuery = "INSERT INTO orders (id, price, tax, final_price, created_at) VALUES (?, ?, ?, ?, ?)"
+
+// Save inserts the order and its audit entry in one transaction.
+func (r *OrderRepository) Save(ctx context.Context, order *entity.Order) (err error) {
+	ctx, end := r.startQuery(ctx, "OrderRepository.Save", insertOrderQuery)
+	defer func() { end(err) }()
+
+	return r.insert(ctx, []*entity.Order{order})
+}
+
+// SaveBatch inserts the orders and their audit entries in one transaction.
+func (r *OrderRepository) SaveBatch(ctx context.Context, orders []*entity.Order) (err error) {
+	ctx, end := r.startQuery(ctx, "OrderRepository.SaveBatch", insertOrderQuery)
+	defer func() { end(err) }()
+
+	return r.insert(ctx, orders)
+}
+
+func (r *OrderRepository) insert(ctx context.Context, orders []*entity.Order) error {
+	tx, err := r.Db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	_, err = stmt.Exec(order.ID, order.Price, order.Tax, order.FinalPrice)
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, r.Dialect.Rebind(insertOrderQuery))
 	if err != nil {
 		return err
 	}
+	defer stmt.Close()
+	now := time.Now().UTC()
+	for _, order := range orders {
+		_, err := stmt.ExecContext(ctx, order.ID, order.Price, order.Tax, order.FinalPrice, now)
+		if r.Dialect.IsUniqueViolation(err) {
+			return fmt.Errorf("%w: %s", entity.ErrOrderAlreadyExists, order.ID)
+		}
+		if err != nil {
+			return fmt.Errorf("order %s: %w", order.ID, err)
+		}
+		after := &entity.OrderEventData{Price: order.Price, Tax: order.Tax, FinalPrice: order.FinalPrice}
+		if err := insertAuditEntry(ctx, tx, r.Dialect, order.ID, entity.OrderCreatedEvent, nil, after, now); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, order := range orders {
+		order.CreatedAt = now
+	}
 	return nil
 }
-func (r *OrderRepository) ListOrders() ([]entity.Order, error) {
-	var orders []entity.Order
-	rows, err := r.Db.Query("SELECT id, price, tax, final_price FROM orders")
+
+// ExistingIDs implements entity.OrderRepositoryInterface.
+func (r *OrderRepository) ExistingIDs(ctx context.Context, ids []string) (existing []string, err error) {
+	const query = "SELECT id FROM orders WHERE id IN (?, ...)"
+	ctx, end := r.startQuery(ctx, "OrderRepository.ExistingIDs", query)
+	defer func() { end(err) }()
+
+	return selectExistingIDs(ctx, r.Db, r.Dialect, "SELECT id FROM orders WHERE id", ids)
+}
+
+// selectExistingIDs runs selectIn, a query ending in the column compared to
+// the ids, once per existingIDsChunk ids, and returns the ids it finds.
+func selectExistingIDs(ctx context.Context, db queryer, dialect Dialect, selectIn string, ids []string) ([]string, error) {
+	var existing []string
+	for chunk := range slices.Chunk(ids, existingIDsChunk) {
+		args := make([]any, len(chunk))
+		for i, id := range chunk {
+			args[i] = id
+		}
+		query := selectIn + " IN (?" + strings.Repeat(", ?", len(chunk)-1) + ")"
+		rows, err := db.QueryContext(ctx, dialect.Rebind(query), args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			existing = append(existing, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return existing, nil
+}
+
+// queryer is a *sql.DB or a *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// existingIDsChunk keeps each ExistingIDs query under the parameter limits.
+const existingIDsChunk = 500
+
+// ListOrders leaves the deleted orders out.
+func (r *OrderRepository) ListOrders(ctx context.Context) (orders []entity.Order, err error) {
+	const query = "SELECT id, price, tax, final_price FROM orders WHERE deleted_at IS NULL"
+	ctx, end := r.startQuery(ctx, "OrderRepository.ListOrders", query)
+	defer func() { end(err) }()
+
+	rows, err := r.Db.QueryContext(ctx, r.Dialect.Rebind(query))
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 	for rows.Next() {
 		var order entity.Order
 		err := rows.Scan(&order.ID, &order.Price, &order.Tax, &order.FinalPrice)
@@ -39,13 +161,254 @@ func (r *OrderRepository) ListOrders() ([]entity.Order, error) {
 		}
 		orders = append(orders, order)
 	}
-	return orders, nil
+	return orders, rows.Err()
 }
-func (r *OrderRepository) GetTotal() (int, error) {
-	var total int
-	err := r.Db.QueryRow("Select count(*) from orders").Scan(&total)
+
+// GetTotals implements entity.OrderTotalsRepositoryInterface with a single
+// query over the range the periods span, grouped by the period each order was
+// created in. The deleted orders are left out. The orders saved before the
+// created_at column have no creation time, so only an open range counts them.
+func (r *OrderRepository) GetTotals(ctx context.Context, periods []entity.OrderPeriod) (totals []entity.OrderTotals, err error) {
+	totals = make([]entity.OrderTotals, len(periods))
+	if len(periods) == 0 {
+		return totals, nil
+	}
+	// the period of an order is the first one that ends after its creation
+	period, args := "0", []any{}
+	if len(periods) > 1 {
+		var b strings.Builder
+		b.WriteString("CASE")
+		for i, p := range periods[:len(periods)-1] {
+			fmt.Fprintf(&b, " WHEN created_at < ? THEN %d", i)
+			args = append(args, p.To.UTC())
+		}
+		fmt.Fprintf(&b, " ELSE %d END", len(periods)-1)
+		period = b.String()
+	}
+	conditions, spanArgs := periodConditions("created_at", spanOf(periods))
+	query := "SELECT " + period + ", COUNT(*), COALESCE(SUM(price), 0), COALESCE(SUM(tax), 0), COALESCE(SUM(final_price), 0) FROM orders " +
+		"WHERE " + strings.Join(append(conditions, "deleted_at IS NULL"), " AND ") + " GROUP BY 1"
+	ctx, end := r.startQuery(ctx, "OrderRepository.GetTotals", query)
+	defer func() { end(err) }()
+
+	rows, err := r.Db.QueryContext(ctx, r.Dialect.Rebind(query), append(args, spanArgs...)...)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var i int
+		var sum entity.OrderTotals
+		if err := rows.Scan(&i, &sum.Count, &sum.Price, &sum.Tax, &sum.FinalPrice); err != nil {
+			return nil, err
+		}
+		totals[i] = sum
+	}
+	return totals, rows.Err()
+}
+
+// spanOf returns the range of sorted, contiguous periods.
+func spanOf(periods []entity.OrderPeriod) entity.OrderPeriod {
+	return entity.OrderPeriod{From: periods[0].From, To: periods[len(periods)-1].To}
+}
+
+// periodConditions returns the conditions, none for an open period, that keep
+// the rows with column in period.
+func periodConditions(column string, period entity.OrderPeriod) ([]string, []any) {
+	var conditions []string
+	var args []any
+	if !period.From.IsZero() {
+		conditions = append(conditions, column+" >= ?")
+		args = append(args, period.From.UTC())
+	}
+	if !period.To.IsZero() {
+		conditions = append(conditions, column+" < ?")
+		args = append(args, period.To.UTC())
+	}
+	return conditions, args
+}
+
+const updateDeletedQuery = "UPDATE orders SET deleted_at = ?, version = ? WHERE id = ? AND version = ?"
+
+// Delete implements entity.OrderDeletionRepositoryInterface, setting deleted_at.
+func (r *OrderRepository) Delete(ctx context.Context, id string) (change entity.OrderEvent, err error) {
+	ctx, end := r.startQuery(ctx, "OrderRepository.Delete", updateDeletedQuery)
+	defer func() { end(err) }()
+
+	return r.changeDeletion(ctx, id, (*entity.Order).Delete)
+}
+
+// Restore implements entity.OrderDeletionRepositoryInterface, clearing
+// deleted_at.
+func (r *OrderRepository) Restore(ctx context.Context, id string) (change entity.OrderEvent, err error) {
+	ctx, end := r.startQuery(ctx, "OrderRepository.Restore", updateDeletedQuery)
+	defer func() { end(err) }()
+
+	return r.changeDeletion(ctx, id, (*entity.Order).Restore)
+}
+
+// changeDeletion writes the change made by fn and its audit entry in one
+// transaction. The version column orders the changes, so the read model
+// applies them once; a concurrent change fails with ErrConcurrencyConflict.
+func (r *OrderRepository) changeDeletion(ctx context.Context, id string, fn func(*entity.Order, time.Time) (entity.OrderEvent, error)) (entity.OrderEvent, error) {
+	tx, err := r.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return entity.OrderEvent{}, err
+	}
+	defer tx.Rollback()
+
+	order := entity.Order{ID: id}
+	var deletedAt sql.NullTime
+	err = tx.QueryRowContext(ctx, r.Dialect.Rebind("SELECT price, tax, final_price, version, deleted_at FROM orders WHERE id = ?"), id).
+		Scan(&order.Price, &order.Tax, &order.FinalPrice, &order.Version, &deletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.OrderEvent{}, fmt.Errorf("%w: %s", entity.ErrOrderNotFound, id)
+	}
+	if err != nil {
+		return entity.OrderEvent{}, err
+	}
+	if deletedAt.Valid {
+		t := deletedAt.Time.UTC()
+		order.DeletedAt = &t
+	}
+
+	now := time.Now().UTC()
+	change, err := fn(&order, now)
+	if err != nil {
+		return entity.OrderEvent{}, err
+	}
+	result, err := tx.ExecContext(ctx, r.Dialect.Rebind(updateDeletedQuery), nullTime(change.Data.DeletedAt), change.Version, id, order.Version)
+	if err != nil {
+		return entity.OrderEvent{}, err
+	}
+	if updated, err := result.RowsAffected(); err != nil {
+		return entity.OrderEvent{}, err
+	} else if updated == 0 {
+		return entity.OrderEvent{}, fmt.Errorf("%w: order %s", ErrConcurrencyConflict, id)
+	}
+	before := &entity.OrderEventData{Price: order.Price, Tax: order.Tax, FinalPrice: order.FinalPrice, DeletedAt: order.DeletedAt}
+	if err := insertAuditEntry(ctx, tx, r.Dialect, id, change.Type, before, &change.Data, now); err != nil {
+		return entity.OrderEvent{}, err
+	}
+	return change, tx.Commit()
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+// ReplayEvents implements entity.OrderHistoryInterface. The orders table keeps
+// no history, so each row is replayed as its OrderCreated, stamped with the
+// creation time of the order, or the current time for the orders created
+// before the created_at column. A row changed since then is followed by its
+// last change, an OrderDeleted or OrderRestored at the row version.
+func (r *OrderRepository) ReplayEvents(ctx context.Context, fn func(event entity.OrderEvent) error) (err error) {
+	const query = "SELECT id, price, tax, final_price, created_at, version, deleted_at FROM orders WHERE id > ? ORDER BY id LIMIT ?"
+	ctx, end := r.startQuery(ctx, "OrderRepository.ReplayEvents", query)
+	defer func() { end(err) }()
+
+	now := time.Now().UTC()
+	last := ""
+	for {
+		rows, err := r.Db.QueryContext(ctx, r.Dialect.Rebind(query), last, replayBatchSize)
+		if err != nil {
+			return err
+		}
+		changes, read, err := scanReplayedOrders(rows, now)
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			if err := fn(change); err != nil {
+				return err
+			}
+			last = change.OrderID
+		}
+		if read < replayBatchSize {
+			return nil
+		}
+	}
+}
+
+// ReplayOrderEvents implements entity.OrderHistoryInterface, replaying the row
+// of the order id as ReplayEvents does.
+func (r *OrderRepository) ReplayOrderEvents(ctx context.Context, id string, fn func(event entity.OrderEvent) error) (err error) {
+	const query = "SELECT id, price, tax, final_price, created_at, version, deleted_at FROM orders WHERE id = ?"
+	ctx, end := r.startQuery(ctx, "OrderRepository.ReplayOrderEvents", query)
+	defer func() { end(err) }()
+
+	rows, err := r.Db.QueryContext(ctx, r.Dialect.Rebind(query), id)
+	if err != nil {
+		return err
+	}
+	changes, _, err := scanReplayedOrders(rows, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		if err := fn(change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanReplayedOrders turns each orders row into its replayed events, returning
+// them with the number of rows read.
+func scanReplayedOrders(rows *sql.Rows, now time.Time) ([]entity.OrderEvent, int, error) {
+	defer rows.Close()
+	var changes []entity.OrderEvent
+	read := 0
+	for rows.Next() {
+		var order entity.Order
+		var createdAt, deletedAt sql.NullTime
+		var version int
+		if err := rows.Scan(&order.ID, &order.Price, &order.Tax, &order.FinalPrice, &createdAt, &version, &deletedAt); err != nil {
+			return nil, 0, err
+		}
+		read++
+		change := order.Change(now)
+		if createdAt.Valid {
+			change.Timestamp = createdAt.Time.UTC()
+		}
+		changes = append(changes, change)
+		if version > 1 {
+			order.Version = version - 1
+			lastChange := order.Change(now)
+			lastChange.Type = entity.OrderRestoredEvent
+			if deletedAt.Valid {
+				at := deletedAt.Time.UTC()
+				lastChange.Type = entity.OrderDeletedEvent
+				lastChange.Data.DeletedAt = &at
+				lastChange.Timestamp = at
+			}
+			changes = append(changes, lastChange)
+		}
+	}
+	return changes, read, rows.Err()
+}
+
+func (r *OrderRepository) startQuery(ctx context.Context, name, query string) (context.Context, func(err error)) {
+	return startQuery(ctx, r.Dialect, name, query)
+}
+
+// startQuery opens a client span for a SQL statement; the returned func ends it.
+func startQuery(ctx context.Context, dialect Dialect, name, query string) (context.Context, func(err error)) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", dialect.Name()),
+			attribute.String("db.statement", query),
+		),
+	)
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
 	}
-	return total, nil
 }
@@ -0,0 +1,376 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/sql/migrations"
+
+	"github.com/stretchr/testify/suite"
+
+	// mysql
+	_ "github.com/go-sql-driver/mysql"
+	// postgres
+	_ "github.com/lib/pq"
+)
+
+// OrderRepositoryContractTestSuite runs the same cases against every dialect.
+// SQLite always runs in memory; MySQL and PostgreSQL run when TEST_MYSQL_DSN /
+// TEST_POSTGRES_DSN point to an empty database.
+type OrderRepositoryContractTestSuite struct {
+	suite.Suite
+	Dialect  Dialect
+	DSN      string
+	Db       *sql.DB
+	Repo     *OrderRepository
+	Migrator *Migrator
+}
+
+func (suite *OrderRepositoryContractTestSuite) SetupSuite() {
+	db, err := sql.Open(suite.Dialect.Name(), suite.DSN)
+	suite.Require().NoError(err)
+	// each connection to ":memory:" is a new database
+	db.SetMaxOpenConns(1)
+	suite.Db = db
+	suite.Repo = NewOrderRepository(db, suite.Dialect)
+	source, err := migrations.Source(suite.Dialect.Name())
+	suite.Require().NoError(err)
+	suite.Migrator = NewMigrator(db, suite.Dialect, source)
+	_, err = suite.Migrator.Down(context.Background(), 0)
+	suite.Require().NoError(err)
+	_, err = suite.Migrator.Up(context.Background())
+	suite.Require().NoError(err)
+}
+
+func (suite *OrderRepositoryContractTestSuite) SetupTest() {
+	for _, table := range []string{"orders", "orders_archive", "order_audit", "order_views", "published_events"} {
+		_, err := suite.Db.Exec("DELETE FROM " + table)
+		suite.Require().NoError(err)
+	}
+}
+
+func (suite *OrderRepositoryContractTestSuite) TearDownSuite() {
+	_, err := suite.Migrator.Down(context.Background(), 0)
+	suite.NoError(err)
+	suite.Db.Close()
+}
+
+func runContractSuite(t *testing.T, dialect Dialect, dsn string) {
+	if dsn == "" {
+		t.Skipf("no DSN for %s", dialect.Name())
+	}
+	suite.Run(t, &OrderRepositoryContractTestSuite{Dialect: dialect, DSN: dsn})
+}
+
+func TestOrderRepositoryContract_SQLite(t *testing.T) {
+	runContractSuite(t, SQLite, ":memory:")
+}
+
+func TestOrderRepositoryContract_MySQL(t *testing.T) {
+	runContractSuite(t, MySQL, os.Getenv("TEST_MYSQL_DSN"))
+}
+
+func TestOrderRepositoryContract_Postgres(t *testing.T) {
+	runContractSuite(t, Postgres, os.Getenv("TEST_POSTGRES_DSN"))
+}
+
+func (suite *OrderRepositoryContractTestSuite) newOrder(id string, price, tax float64) *entity.Order {
+	order, err := entity.NewOrder(id, price, tax)
+	suite.Require().NoError(err)
+	suite.Require().NoError(order.CalculateFinalPrice())
+	return order
+}
+
+func (suite *OrderRepositoryContractTestSuite) TestGivenNoOrders_WhenListOrders_ThenShouldReturnEmpty() {
+	orders, err := suite.Repo.ListOrders(context.Background())
+	suite.NoError(err)
+	suite.Empty(orders)
+
+	total, err := suite.getTotal(context.Background(), entity.OrderPeriod{})
+	suite.NoError(err)
+	suite.Equal(entity.OrderTotals{}, total)
+}
+
+func (suite *OrderRepositoryContractTestSuite) TestGivenSavedOrders_WhenListOrders_ThenShouldReturnThem() {
+	ctx := context.Background()
+	suite.NoError(suite.Repo.Save(ctx, suite.newOrder("a", 10.0, 2.0)))
+	suite.NoError(suite.Repo.Save(ctx, suite.newOrder("b", 100.5, 0.5)))
+
+	orders, err := suite.Repo.ListOrders(ctx)
+	suite.NoError(err)
+	suite.ElementsMatch([]entity.Order{
+		{ID: "a", Price: 10.0, Tax: 2.0, FinalPrice: 12.0},
+		{ID: "b", Price: 100.5, Tax: 0.5, FinalPrice: 101.0},
+	}, orders)
+
+	total, err := suite.getTotal(ctx, entity.OrderPeriod{})
+	suite.NoError(err)
+	suite.Equal(entity.OrderTotals{Count: 2, Price: 110.5, Tax: 2.5, FinalPrice: 113.0}, total)
+}
+
+func (suite *OrderRepositoryContractTestSuite) TestGivenALargePrice_WhenSave_ThenShouldKeepEveryDigit() {
+	ctx := context.Background()
+	suite.NoError(suite.Repo.Save(ctx, suite.newOrder("a", 12345678.91, 0.07)))
+
+	orders, err := suite.Repo.ListOrders(ctx)
+	suite.NoError(err)
+	suite.Equal([]entity.Order{{ID: "a", Price: 12345678.91, Tax: 0.07, FinalPrice: 12345678.91 + 0.07}}, orders)
+}
+
+func (suite *OrderRepositoryContractTestSuite) TestGivenAnExistingID_WhenSave_ThenShouldReturnAlreadyExists() {
+	ctx := context.Background()
+	suite.NoError(suite.Repo.Save(ctx, suite.newOrder("a", 10.0, 2.0)))
+	suite.ErrorIs(suite.Repo.Save(ctx, suite.newOrder("a", 20.0, 2.0)), entity.ErrOrderAlreadyExists)
+}
+
+func (suite *OrderRepositoryContractTestSuite) TestGivenABatchWithAnExistingID_WhenSaveBatch_ThenShouldSaveNone() {
+	ctx := context.Background()
+	suite.NoError(suite.Repo.Save(ctx, suite.newOrder("a", 10.0, 2.0)))
+	err := suite.Repo.SaveBatch(ctx, []*entity.Order{suite.newOrder("b", 20.0, 2.0), suite.newOrder("a", 30.0, 2.0)})
+	suite.ErrorIs(err, entity.ErrOrderAlreadyExists)
+
+	total, err := suite.getTotal(ctx, entity.OrderPeriod{})
+	suite.NoError(err)
+	suite.Equal(1, total.Count)
+
+	suite.NoError(suite.Repo.SaveBatch(ctx, []*entity.Order{suite.newOrder("b", 20.0, 2.0), suite.newOrder("c", 30.0, 2.0)}))
+	total, err = suite.getTotal(ctx, entity.OrderPeriod{})
+	suite.NoError(err)
+	suite.Equal(3, total.Count)
+}
+
+func (suite *OrderRepositoryContractTestSuite) TestGivenSavedOrders_WhenExistingIDs_ThenShouldReturnOnlyTheStoredOnes() {
+	ctx := context.Background()
+	suite.NoError(suite.Repo.Save(ctx, suite.newOrder("a", 10.0, 2.0)))
+	suite.NoError(suite.Repo.Save(ctx, suite.newOrder("c", 30.0, 2.0)))
+
+	ids, err := suite.Repo.ExistingIDs(ctx, []string{"a", "b", "c"})
+	suite.NoError(err)
+	suite.ElementsMatch([]string{"a", "c"}, ids)
+
+	ids, err = suite.Repo.ExistingIDs(ctx, nil)
+	suite.NoError(err)
+	suite.Empty(ids)
+}
+
+func (suite *OrderRepositoryContractTestSuite) TestGivenSavedOrders_WhenGetTotalsOfAPeriod_ThenShouldSumOnlyThoseCreatedInIt() {
+	ctx := context.Background()
+	suite.NoError(suite.Repo.SaveBatch(ctx, []*entity.Order{suite.newOrder("a", 10.0, 2.0), suite.newOrder("b", 100.5, 0.5)}))
+	// saved before the created_at column
+	_, err := suite.Db.Exec("INSERT INTO orders (id, price, tax, final_price) VALUES ('old', 1, 1, 2)")
+	suite.Require().NoError(err)
+	now := time.Now().UTC()
+
+	total, err := suite.getTotal(ctx, entity.OrderPeriod{From: now.Add(-time.Hour), To: now.Add(time.Hour)})
+	suite.NoError(err)
+	suite.Equal(entity.OrderTotals{Count: 2, Price: 110.5, Tax: 2.5, FinalPrice: 113.0}, total)
+
+	total, err = suite.getTotal(ctx, entity.OrderPeriod{From: now.Add(time.Hour)})
+	suite.NoError(err)
+	suite.Equal(entity.OrderTotals{}, total)
+
+	total, err = suite.getTotal(ctx, entity.OrderPeriod{})
+	suite.NoError(err)
+	suite.Equal(3, total.Count)
+}
+
+func (suite *OrderRepositoryContractTestSuite) TestGivenOrdersOnSeveralDays_WhenGetTotals_ThenShouldSumEachPeriod() {
+	ctx := context.Background()
+	day := func(d, hour int) time.Time { return time.Date(2026, 1, d, hour, 0, 0, 0, time.UTC) }
+	for _, order := range []struct {
+		id        string
+		price     float64
+		createdAt time.Time
+	}{
+		{"a", 10, day(1, 0)},
+		{"b", 20, day(1, 23)},
+		{"c", 30, day(3, 10)},
+		// after the range
+		{"d", 40, day(3, 12)},
+	} {
+		_, err := suite.Db.Exec(suite.Dialect.Rebind("INSERT INTO orders (id, price, tax, final_price, created_at) VALUES (?, ?, 1, ?, ?)"),
+			order.id, order.price, order.price+1, order.createdAt)
+		suite.Require().NoError(err)
+	}
+
+	totals, err := suite.Repo.GetTotals(ctx, []entity.OrderPeriod{
+		{From: day(1, 0), To: day(2, 0)},
+		{From: day(2, 0), To: day(3, 0)},
+		{From: day(3, 0), To: day(3, 11)},
+	})
+	suite.Require().NoError(err)
+	suite.Equal([]entity.OrderTotals{
+		{Count: 2, Price: 30, Tax: 2, FinalPrice: 32},
+		{},
+		{Count: 1, Price: 30, Tax: 1, FinalPrice: 31},
+	}, totals)
+
+	totals, err = suite.Repo.GetTotals(ctx, nil)
+	suite.NoError(err)
+	suite.Empty(totals)
+}
+
+func (suite *OrderRepositoryContractTestSuite) TestGivenASavedOrder_WhenDeleteAndRestore_ThenShouldHideItOnlyWhileDeleted() {
+	ctx := context.Background()
+	suite.NoError(suite.Repo.SaveBatch(ctx, []*entity.Order{suite.newOrder("a", 10.0, 2.0), suite.newOrder("b", 100.5, 0.5)}))
+
+	deleted, err := suite.Repo.Delete(ctx, "a")
+	suite.Require().NoError(err)
+	suite.Equal(entity.OrderDeletedEvent, deleted.Type)
+	suite.Equal(2, deleted.Version)
+	suite.NotNil(deleted.Data.DeletedAt)
+	orders, err := suite.Repo.ListOrders(ctx)
+	suite.NoError(err)
+	suite.Equal([]string{"b"}, orderIDs(orders))
+	total, err := suite.getTotal(ctx, entity.OrderPeriod{})
+	suite.NoError(err)
+	suite.Equal(1, total.Count)
+
+	_, err = suite.Repo.Delete(ctx, "a")
+	suite.ErrorIs(err, entity.ErrOrderDeleted)
+
+	restored, err := suite.Repo.Restore(ctx, "a")
+	suite.Require().NoError(err)
+	suite.Equal(entity.OrderRestoredEvent, restored.Type)
+	suite.Equal(3, restored.Version)
+	suite.Nil(restored.Data.DeletedAt)
+	orders, err = suite.Repo.ListOrders(ctx)
+	suite.NoError(err)
+	suite.Len(orders, 2)
+
+	_, err = suite.Repo.Restore(ctx, "a")
+	suite.ErrorIs(err, entity.ErrOrderNotDeleted)
+	_, err = suite.Repo.Delete(ctx, "missing")
+	suite.ErrorIs(err, entity.ErrOrderNotFound)
+
+	history, err := NewOrderAuditRepository(suite.Db, suite.Dialect).History(ctx, "a")
+	suite.NoError(err)
+	suite.Len(history, 3)
+}
+
+func (suite *OrderRepositoryContractTestSuite) TestGivenExpiredOrders_WhenArchiveAndPurge_ThenShouldKeepOnlyTheArchivedCopy() {
+	ctx := context.Background()
+	suite.NoError(suite.Repo.SaveBatch(ctx, []*entity.Order{
+		suite.newOrder("a", 10.0, 2.0), suite.newOrder("b", 20.0, 2.0), suite.newOrder("c", 30.0, 2.0),
+	}))
+	old := time.Now().UTC().AddDate(0, 0, -100)
+	_, err := suite.Db.Exec(suite.Dialect.Rebind("UPDATE orders SET created_at = ? WHERE id = ?"), old, "a")
+	suite.Require().NoError(err)
+	_, err = suite.Repo.Delete(ctx, "c")
+	suite.Require().NoError(err)
+	retention := NewOrderRetentionRepository(suite.Db, suite.Dialect, false)
+
+	expired, err := retention.Expired(ctx, time.Now().AddDate(0, 0, -90), time.Now().Add(time.Hour), "", 10)
+	suite.Require().NoError(err)
+	suite.Require().Len(expired, 2)
+	suite.Equal("a", expired[0].ID)
+	suite.Equal(12.0, expired[0].FinalPrice)
+	suite.Nil(expired[0].DeletedAt)
+	suite.Equal("c", expired[1].ID)
+	suite.NotNil(expired[1].DeletedAt)
+
+	expired, err = retention.Expired(ctx, time.Now().AddDate(0, 0, -90), time.Time{}, "", 10)
+	suite.NoError(err)
+	suite.Len(expired, 1)
+	expired, err = retention.Expired(ctx, time.Time{}, time.Time{}, "", 10)
+	suite.NoError(err)
+	suite.Empty(expired)
+
+	archive := NewOrderArchiveRepository(suite.Db, suite.Dialect)
+	archivedAt := time.Now().UTC()
+	orders := []entity.ArchivedOrder{{ID: "a", Price: 10, Tax: 2, FinalPrice: 12, ArchivedAt: archivedAt}, {ID: "c", ArchivedAt: archivedAt}}
+	suite.NoError(archive.Archive(ctx, orders))
+	// archived again by a later run, after a failed purge
+	for i := range orders {
+		orders[i].ArchivedAt = archivedAt.Add(time.Hour)
+	}
+	suite.NoError(archive.Archive(ctx, orders))
+	purged, err := retention.Purge(ctx, time.Now().AddDate(0, 0, -90), time.Now().Add(time.Hour), []string{"a", "c"})
+	suite.NoError(err)
+	suite.Equal([]string{"a", "c"}, purged)
+
+	var archived int
+	suite.NoError(suite.Db.QueryRow("SELECT COUNT(*) FROM orders_archive").Scan(&archived))
+	suite.Equal(4, archived)
+	var stored int
+	suite.NoError(suite.Db.QueryRow("SELECT COUNT(*) FROM orders").Scan(&stored))
+	suite.Equal(1, stored)
+	history, err := NewOrderAuditRepository(suite.Db, suite.Dialect).History(ctx, "c")
+	suite.NoError(err)
+	suite.Empty(history)
+}
+
+func (suite *OrderRepositoryContractTestSuite) TestGivenAnOrderRestoredAfterExpired_WhenPurge_ThenShouldKeepIt() {
+	ctx := context.Background()
+	suite.NoError(suite.Repo.SaveBatch(ctx, []*entity.Order{suite.newOrder("a", 10.0, 2.0), suite.newOrder("b", 20.0, 2.0)}))
+	for _, id := range []string{"a", "b"} {
+		_, err := suite.Repo.Delete(ctx, id)
+		suite.Require().NoError(err)
+	}
+	retention := NewOrderRetentionRepository(suite.Db, suite.Dialect, false)
+	deletedBefore := time.Now().Add(time.Hour)
+	expired, err := retention.Expired(ctx, time.Time{}, deletedBefore, "", 10)
+	suite.Require().NoError(err)
+	suite.Require().Len(expired, 2)
+	_, err = suite.Repo.Restore(ctx, "a")
+	suite.Require().NoError(err)
+
+	purged, err := retention.Purge(ctx, time.Time{}, deletedBefore, []string{"a", "b"})
+	suite.NoError(err)
+	suite.Equal([]string{"b"}, purged)
+	orders, err := suite.Repo.ListOrders(ctx)
+	suite.NoError(err)
+	suite.Len(orders, 1)
+	history, err := NewOrderAuditRepository(suite.Db, suite.Dialect).History(ctx, "a")
+	suite.NoError(err)
+	suite.Len(history, 3)
+	history, err = NewOrderAuditRepository(suite.Db, suite.Dialect).History(ctx, "b")
+	suite.NoError(err)
+	suite.Empty(history)
+}
+
+func (suite *OrderRepositoryContractTestSuite) TestGivenAPurgedIDThatWasReused_WhenArchive_ThenShouldKeepBothOrders() {
+	ctx := context.Background()
+	archive := NewOrderArchiveRepository(suite.Db, suite.Dialect)
+	first := time.Now().UTC().AddDate(0, 0, -1)
+	suite.NoError(archive.Archive(ctx, []entity.ArchivedOrder{{ID: "a", Price: 10, Tax: 2, FinalPrice: 12, ArchivedAt: first}}))
+	suite.NoError(archive.Archive(ctx, []entity.ArchivedOrder{{ID: "a", Price: 20, Tax: 2, FinalPrice: 22, ArchivedAt: first.Add(24 * time.Hour)}}))
+
+	var prices []float64
+	rows, err := suite.Db.Query("SELECT price FROM orders_archive WHERE id = 'a' ORDER BY archived_at")
+	suite.Require().NoError(err)
+	defer rows.Close()
+	for rows.Next() {
+		var price float64
+		suite.Require().NoError(rows.Scan(&price))
+		prices = append(prices, price)
+	}
+	suite.Equal([]float64{10, 20}, prices)
+}
+
+func (suite *OrderRepositoryContractTestSuite) TestGivenMoreOrdersThanAnInsertHolds_WhenArchive_ThenShouldArchiveThemAll() {
+	orders := make([]entity.ArchivedOrder, 2*archiveInsertRows+1)
+	archivedAt := time.Now().UTC()
+	for i := range orders {
+		orders[i] = entity.ArchivedOrder{ID: fmt.Sprintf("order-%04d", i), Price: 10, Tax: 1, FinalPrice: 11, ArchivedAt: archivedAt}
+	}
+	suite.NoError(NewOrderArchiveRepository(suite.Db, suite.Dialect).Archive(context.Background(), orders))
+
+	var archived int
+	suite.NoError(suite.Db.QueryRow("SELECT COUNT(*) FROM orders_archive").Scan(&archived))
+	suite.Equal(len(orders), archived)
+}
+
+// getTotal reads the totals of a single period.
+func (suite *OrderRepositoryContractTestSuite) getTotal(ctx context.Context, period entity.OrderPeriod) (entity.OrderTotals, error) {
+	totals, err := suite.Repo.GetTotals(ctx, []entity.OrderPeriod{period})
+	if err != nil {
+		return entity.OrderTotals{}, err
+	}
+	return totals[0], nil
+}
@@ -1,12 +1,18 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 
 	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/sql/migrations"
 
 	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
 	// sqlite3
 	_ "github.com/mattn/go-sqlite3"
@@ -20,11 +26,15 @@ type OrderRepositoryTestSuite struct {
 func (suite *OrderRepositoryTestSuite) SetupSuite() {
 	db, err := sql.Open("sqlite3", ":memory:")
 	suite.NoError(err)
-	db.Exec("CREATE TABLE orders (id varchar(255) NOT NULL, price float NOT NULL, tax float NOT NULL, final_price float NOT NULL, PRIMARY KEY (id))")
+	db.SetMaxOpenConns(1)
+	source, err := migrations.Source(SQLite.Name())
+	suite.Require().NoError(err)
+	_, err = NewMigrator(db, SQLite, source).Up(context.Background())
+	suite.Require().NoError(err)
 	suite.Db = db
 }
 
-func (suite *OrderRepositoryTestSuite) TearDownTest() {
+func (suite *OrderRepositoryTestSuite) TearDownSuite() {
 	suite.Db.Close()
 }
 
@@ -36,8 +46,8 @@ func (suite *OrderRepositoryTestSuite) TestGivenAnOrder_WhenSave_ThenShouldSaveO
 	order, err := entity.NewOrder("123", 10.0, 2.0)
 	suite.NoError(err)
 	suite.NoError(order.CalculateFinalPrice())
-	repo := NewOrderRepository(suite.Db)
-	err = repo.Save(order)
+	repo := NewSQLiteOrderRepository(suite.Db)
+	err = repo.Save(context.Background(), order)
 	suite.NoError(err)
 
 	var orderResult entity.Order
@@ -50,3 +60,18 @@ func (suite *OrderRepositoryTestSuite) TestGivenAnOrder_WhenSave_ThenShouldSaveO
 	suite.Equal(order.Tax, orderResult.Tax)
 	suite.Equal(order.FinalPrice, orderResult.FinalPrice)
 }
+
+func (suite *OrderRepositoryTestSuite) TestGivenAnOrder_WhenSave_ThenShouldRecordAQuerySpan() {
+	spanRecorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))
+
+	order, err := entity.NewOrder("456", 10.0, 2.0)
+	suite.NoError(err)
+	repo := NewSQLiteOrderRepository(suite.Db)
+	suite.NoError(repo.Save(context.Background(), order))
+
+	spans := spanRecorder.Ended()
+	suite.Len(spans, 1)
+	suite.Equal("OrderRepository.Save", spans[0].Name())
+	suite.Contains(spans[0].Attributes(), attribute.String("db.statement", "INSERT INTO orders (id, price, tax, final_price, created_at) VALUES (?, ?, ?, ?, ?)"))
+}
@@ -0,0 +1,230 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+)
+
+// OrderRetentionRepository implements entity.OrderRetentionRepositoryInterface.
+// The orders are read from the orders table or, when EventSourced is set,
+// from the event store: an order is created at the time of its first event
+// and deleted at the time of its last one, when that is an OrderDeleted. An
+// order saved without a creation time only expires once deleted.
+type OrderRetentionRepository struct {
+	Db           *sql.DB
+	Dialect      Dialect
+	EventSourced bool
+}
+
+func NewOrderRetentionRepository(db *sql.DB, dialect Dialect, eventSourced bool) *OrderRetentionRepository {
+	return &OrderRetentionRepository{
+		Db:           db,
+		Dialect:      dialect,
+		EventSourced: eventSourced,
+	}
+}
+
+func (r *OrderRetentionRepository) Expired(ctx context.Context, createdBefore, deletedBefore time.Time, afterID string, limit int) (orders []entity.ArchivedOrder, err error) {
+	condition, conditionArgs := r.expiredCondition(createdBefore, deletedBefore)
+	if condition == "" {
+		return nil, nil
+	}
+	query := "SELECT id, price, tax, final_price, created_at, deleted_at FROM orders WHERE id > ? AND " + condition + " ORDER BY id LIMIT ?"
+	if r.EventSourced {
+		query = "SELECT e.stream_id, e.payload, c.created_at FROM " + expiredStreams + " AND e.stream_id > ? AND " + condition +
+			" ORDER BY e.stream_id LIMIT ?"
+	}
+	args := append(append([]any{afterID}, conditionArgs...), limit)
+	ctx, end := startQuery(ctx, r.Dialect, "OrderRetentionRepository.Expired", query)
+	defer func() { end(err) }()
+
+	rows, err := r.Db.QueryContext(ctx, r.Dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var order entity.ArchivedOrder
+		var createdAt, deletedAt sql.NullTime
+		if r.EventSourced {
+			var payload string
+			if err := rows.Scan(&order.ID, &payload, &createdAt); err != nil {
+				return nil, err
+			}
+			var data entity.OrderEventData
+			if err := json.Unmarshal([]byte(payload), &data); err != nil {
+				return nil, fmt.Errorf("decoding the last event of order %s: %w", order.ID, err)
+			}
+			order.Price, order.Tax, order.FinalPrice = data.Price, data.Tax, data.FinalPrice
+			deletedAt = nullTime(data.DeletedAt)
+		} else if err := rows.Scan(&order.ID, &order.Price, &order.Tax, &order.FinalPrice, &createdAt, &deletedAt); err != nil {
+			return nil, err
+		}
+		if createdAt.Valid {
+			order.CreatedAt = createdAt.Time.UTC()
+		}
+		if deletedAt.Valid {
+			t := deletedAt.Time.UTC()
+			order.DeletedAt = &t
+		}
+		orders = append(orders, order)
+	}
+	return orders, rows.Err()
+}
+
+// expiredStreams joins the last event of each stream, e, to its first one, c.
+const expiredStreams = "order_events e JOIN order_events c ON c.stream_id = e.stream_id AND c.version = 1 WHERE " + lastEventCondition
+
+// expiredCondition is the condition, with its arguments, that matches the
+// orders Expired returns, or "" when both rules are off. It names the columns
+// of orders or, when EventSourced is set, those of expiredStreams.
+func (r *OrderRetentionRepository) expiredCondition(createdBefore, deletedBefore time.Time) (string, []any) {
+	createdColumn, deletedCondition := "created_at", "deleted_at < ?"
+	var deletedArgs []any
+	if r.EventSourced {
+		createdColumn, deletedCondition = "c.created_at", "(e.type = ? AND e.created_at < ?)"
+		deletedArgs = []any{entity.OrderDeletedEvent}
+	}
+	var rules []string
+	var args []any
+	if !createdBefore.IsZero() {
+		rules = append(rules, createdColumn+" < ?")
+		args = append(args, createdBefore.UTC())
+	}
+	if !deletedBefore.IsZero() {
+		rules = append(rules, deletedCondition)
+		args = append(append(args, deletedArgs...), deletedBefore.UTC())
+	}
+	if len(rules) == 0 {
+		return "", nil
+	}
+	return "(" + strings.Join(rules, " OR ") + ")", args
+}
+
+// Purge deletes, in a single transaction, those of the orders that still
+// match the rules of Expired, with their views, audit entries and published
+// and missing events, and returns their IDs. The rules are checked by the
+// DELETE itself, so an order restored after Expired read it is kept.
+func (r *OrderRetentionRepository) Purge(ctx context.Context, createdBefore, deletedBefore time.Time, ids []string) (purged []string, err error) {
+	condition, conditionArgs := r.expiredCondition(createdBefore, deletedBefore)
+	if len(ids) == 0 || condition == "" {
+		return nil, nil
+	}
+	in := " IN (?" + strings.Repeat(", ?", len(ids)-1) + ")"
+	deleteOrders := "DELETE FROM orders WHERE id" + in + " AND " + condition
+	selectKept := "SELECT id FROM orders WHERE id"
+	if r.EventSourced {
+		// MySQL can't read the table a DELETE deletes from in a subquery, but
+		// it can read a derived table built from it
+		deleteOrders = "DELETE FROM order_events WHERE stream_id IN (SELECT stream_id FROM (SELECT e.stream_id FROM " +
+			expiredStreams + " AND e.stream_id" + in + " AND " + condition + ") expired)"
+		selectKept = "SELECT DISTINCT stream_id FROM order_events WHERE stream_id"
+	}
+	ctx, end := startQuery(ctx, r.Dialect, "OrderRetentionRepository.Purge", deleteOrders)
+	defer func() { end(err) }()
+
+	args := make([]any, len(ids), len(ids)+len(conditionArgs))
+	for i, id := range ids {
+		args[i] = id
+	}
+	tx, err := r.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, r.Dialect.Rebind(deleteOrders), append(args, conditionArgs...)...); err != nil {
+		return nil, err
+	}
+	kept, err := selectExistingIDs(ctx, tx, r.Dialect, selectKept, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if !slices.Contains(kept, id) {
+			purged = append(purged, id)
+		}
+	}
+	if len(purged) == 0 {
+		return nil, tx.Commit()
+	}
+
+	in = " IN (?" + strings.Repeat(", ?", len(purged)-1) + ")"
+	var queries []string
+	if r.EventSourced {
+		queries = append(queries, "DELETE FROM order_snapshots WHERE stream_id"+in)
+	}
+	queries = append(queries,
+		"DELETE FROM order_views WHERE id"+in,
+		"DELETE FROM order_audit WHERE order_id"+in,
+		"DELETE FROM published_events WHERE order_id"+in,
+		"DELETE FROM missing_events WHERE order_id"+in,
+	)
+	args = make([]any, len(purged))
+	for i, id := range purged {
+		args[i] = id
+	}
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, r.Dialect.Rebind(query), args...); err != nil {
+			return nil, err
+		}
+	}
+	return purged, tx.Commit()
+}
+
+// OrderArchiveRepository implements entity.OrderArchiveInterface on the
+// orders_archive table.
+type OrderArchiveRepository struct {
+	Db      *sql.DB
+	Dialect Dialect
+}
+
+func NewOrderArchiveRepository(db *sql.DB, dialect Dialect) *OrderArchiveRepository {
+	return &OrderArchiveRepository{
+		Db:      db,
+		Dialect: dialect,
+	}
+}
+
+// archiveInsertRows is how many orders each INSERT of Archive holds, to stay
+// well under the placeholder limit of every database.
+const archiveInsertRows = 500
+
+// Archive inserts the orders in a single transaction, a batch of rows per
+// INSERT. The archive is keyed by ID and archived_at, so an order archived
+// again by a later run, after its purge failed, or a purged ID that was
+// reused, gets a new row.
+func (r *OrderArchiveRepository) Archive(ctx context.Context, orders []entity.ArchivedOrder) (err error) {
+	const insert = "INSERT INTO orders_archive (id, price, tax, final_price, created_at, deleted_at, archived_at) VALUES "
+	ctx, end := startQuery(ctx, r.Dialect, "OrderArchiveRepository.Archive", insert+"(?, ?, ?, ?, ?, ?, ?), ...")
+	defer func() { end(err) }()
+
+	tx, err := r.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for batch := range slices.Chunk(orders, archiveInsertRows) {
+		values := make([]string, len(batch))
+		args := make([]any, 0, 7*len(batch))
+		for i, order := range batch {
+			var createdAt sql.NullTime
+			if !order.CreatedAt.IsZero() {
+				createdAt = sql.NullTime{Time: order.CreatedAt.UTC(), Valid: true}
+			}
+			values[i] = "(?, ?, ?, ?, ?, ?, ?)"
+			args = append(args, order.ID, order.Price, order.Tax, order.FinalPrice,
+				createdAt, nullTime(order.DeletedAt), order.ArchivedAt.UTC())
+		}
+		if _, err := tx.ExecContext(ctx, r.Dialect.Rebind(insert+strings.Join(values, ", ")), args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
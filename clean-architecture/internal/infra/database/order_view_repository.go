@@ -0,0 +1,176 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+)
+
+// replayBatchSize is how many rows ReplayEvents reads per query.
+const replayBatchSize = 500
+
+// OrderViewRepository implements entity.OrderViewRepositoryInterface on the
+// order_views table, the read model the query use cases read from.
+type OrderViewRepository struct {
+	Db      *sql.DB
+	Dialect Dialect
+}
+
+func NewOrderViewRepository(db *sql.DB, dialect Dialect) *OrderViewRepository {
+	return &OrderViewRepository{Db: db, Dialect: dialect}
+}
+
+// Apply writes the state carried by the event, unless the view is already at
+// the event version or past it. The creation time is the one of the first
+// event projected for the order. A deleted order keeps its view, with
+// deleted_at set, so that the queries leave it out until it's restored.
+func (r *OrderViewRepository) Apply(ctx context.Context, event entity.OrderEvent) (applied bool, err error) {
+	const query = "INSERT INTO order_views (id, price, tax, final_price, version, updated_at, created_at, deleted_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
+	ctx, end := startQuery(ctx, r.Dialect, "OrderViewRepository.Apply", query)
+	defer func() { end(err) }()
+
+	tx, err := r.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var version int
+	var createdAt sql.NullTime
+	err = tx.QueryRowContext(ctx, r.Dialect.Rebind("SELECT version, created_at FROM order_views WHERE id = ?"), event.OrderID).Scan(&version, &createdAt)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return false, err
+	case version >= event.Version:
+		return false, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, r.Dialect.Rebind("DELETE FROM order_views WHERE id = ?"), event.OrderID); err != nil {
+		return false, err
+	}
+	if !createdAt.Valid {
+		createdAt.Time = event.Timestamp
+	}
+	_, err = tx.ExecContext(ctx, r.Dialect.Rebind(query),
+		event.OrderID, event.Data.Price, event.Data.Tax, event.Data.FinalPrice, event.Version, event.Timestamp.UTC(), createdAt.Time.UTC(),
+		nullTime(event.Data.DeletedAt))
+	if err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// Reset empties the read model before a rebuild.
+func (r *OrderViewRepository) Reset(ctx context.Context) (err error) {
+	const query = "DELETE FROM order_views"
+	ctx, end := startQuery(ctx, r.Dialect, "OrderViewRepository.Reset", query)
+	defer func() { end(err) }()
+
+	_, err = r.Db.ExecContext(ctx, query)
+	return err
+}
+
+func (r *OrderViewRepository) FindOrder(ctx context.Context, id string) (view entity.OrderView, err error) {
+	const query = "SELECT " + orderViewColumns + " FROM order_views WHERE id = ? AND deleted_at IS NULL"
+	ctx, end := startQuery(ctx, r.Dialect, "OrderViewRepository.FindOrder", query)
+	defer func() { end(err) }()
+
+	rows, err := r.Db.QueryContext(ctx, r.Dialect.Rebind(query), id)
+	if err != nil {
+		return entity.OrderView{}, err
+	}
+	views, err := scanOrderViews(rows)
+	if err != nil {
+		return entity.OrderView{}, err
+	}
+	if len(views) == 0 {
+		return entity.OrderView{}, fmt.Errorf("%w: %s", entity.ErrOrderNotFound, id)
+	}
+	return views[0], nil
+}
+
+func (r *OrderViewRepository) ListOrders(ctx context.Context) (views []entity.OrderView, err error) {
+	const query = "SELECT " + orderViewColumns + " FROM order_views WHERE deleted_at IS NULL ORDER BY id"
+	ctx, end := startQuery(ctx, r.Dialect, "OrderViewRepository.ListOrders", query)
+	defer func() { end(err) }()
+
+	rows, err := r.Db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return scanOrderViews(rows)
+}
+
+func (r *OrderViewRepository) ListOrdersPage(ctx context.Context, afterID string, limit int) (views []entity.OrderView, err error) {
+	const query = "SELECT " + orderViewColumns + " FROM order_views WHERE id > ? AND deleted_at IS NULL ORDER BY id LIMIT ?"
+	ctx, end := startQuery(ctx, r.Dialect, "OrderViewRepository.ListOrdersPage", query)
+	defer func() { end(err) }()
+
+	rows, err := r.Db.QueryContext(ctx, r.Dialect.Rebind(query), afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanOrderViews(rows)
+}
+
+// SearchOrders returns up to limit orders matching the query with an ID greater
+// than afterID, ordered by ID.
+func (r *OrderViewRepository) SearchOrders(ctx context.Context, query entity.OrderQuery, afterID string, limit int) (views []entity.OrderView, err error) {
+	conditions, args, err := compileOrderQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	statement := "SELECT " + orderViewColumns + " FROM order_views WHERE " +
+		strings.Join(append([]string{"id > ?", "deleted_at IS NULL"}, conditions...), " AND ") + " ORDER BY id LIMIT ?"
+	ctx, end := startQuery(ctx, r.Dialect, "OrderViewRepository.SearchOrders", statement)
+	defer func() { end(err) }()
+
+	args = append(append([]any{afterID}, args...), limit)
+	rows, err := r.Db.QueryContext(ctx, r.Dialect.Rebind(statement), args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanOrderViews(rows)
+}
+
+// ForEach reads the read model in pages of replayBatchSize.
+func (r *OrderViewRepository) ForEach(ctx context.Context, fn func(view entity.OrderView) error) error {
+	last := ""
+	for {
+		views, err := r.ListOrdersPage(ctx, last, replayBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, view := range views {
+			if err := fn(view); err != nil {
+				return err
+			}
+			last = view.ID
+		}
+		if len(views) < replayBatchSize {
+			return nil
+		}
+	}
+}
+
+const orderViewColumns = "id, price, tax, final_price, version, updated_at, created_at"
+
+func scanOrderViews(rows *sql.Rows) ([]entity.OrderView, error) {
+	defer rows.Close()
+	var views []entity.OrderView
+	for rows.Next() {
+		var view entity.OrderView
+		var createdAt sql.NullTime
+		if err := rows.Scan(&view.ID, &view.Price, &view.Tax, &view.FinalPrice, &view.Version, &view.UpdatedAt, &createdAt); err != nil {
+			return nil, err
+		}
+		view.CreatedAt = createdAt.Time
+		views = append(views, view)
+	}
+	return views, rows.Err()
+}
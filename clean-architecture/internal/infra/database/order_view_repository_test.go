@@ -0,0 +1,340 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/sql/migrations"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type OrderViewRepositoryTestSuite struct {
+	suite.Suite
+	Db    *sql.DB
+	Views *OrderViewRepository
+}
+
+func (suite *OrderViewRepositoryTestSuite) SetupTest() {
+	db, err := sql.Open("sqlite3", ":memory:")
+	suite.Require().NoError(err)
+	db.SetMaxOpenConns(1)
+	source, err := migrations.Source(SQLite.Name())
+	suite.Require().NoError(err)
+	_, err = NewMigrator(db, SQLite, source).Up(context.Background())
+	suite.Require().NoError(err)
+	suite.Db = db
+	suite.Views = NewOrderViewRepository(db, SQLite)
+}
+
+func (suite *OrderViewRepositoryTestSuite) TearDownTest() {
+	suite.Db.Close()
+}
+
+func TestOrderViewRepositorySuite(t *testing.T) {
+	suite.Run(t, new(OrderViewRepositoryTestSuite))
+}
+
+func orderEvent(id string, version int, price float64) entity.OrderEvent {
+	return entity.OrderEvent{
+		OrderID:   id,
+		Version:   version,
+		Type:      entity.OrderUpdatedEvent,
+		Data:      entity.OrderEventData{Price: price, Tax: 1.0, FinalPrice: price + 1.0},
+		Timestamp: time.Date(2026, 1, 2, 3, 4, version, 0, time.UTC),
+	}
+}
+
+func (suite *OrderViewRepositoryTestSuite) TestGivenEvents_WhenApply_ThenShouldKeepTheLastVersionOnly() {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		event   entity.OrderEvent
+		applied bool
+	}{
+		{orderEvent("a", 1, 10.0), true},
+		{orderEvent("a", 3, 30.0), true},
+		{orderEvent("a", 2, 20.0), false},
+		{orderEvent("a", 3, 30.0), false},
+		{orderEvent("b", 1, 5.0), true},
+	} {
+		applied, err := suite.Views.Apply(ctx, tc.event)
+		suite.NoError(err)
+		suite.Equal(tc.applied, applied, "version %d of %s", tc.event.Version, tc.event.OrderID)
+	}
+
+	views, err := suite.Views.ListOrders(ctx)
+	suite.NoError(err)
+	suite.Len(views, 2)
+	suite.Equal("a", views[0].ID)
+	suite.Equal(30.0, views[0].Price)
+	suite.Equal(31.0, views[0].FinalPrice)
+	suite.Equal(3, views[0].Version)
+	suite.True(views[0].UpdatedAt.Equal(orderEvent("a", 3, 0).Timestamp))
+	suite.Equal("b", views[1].ID)
+
+	suite.NoError(suite.Views.Reset(ctx))
+	views, err = suite.Views.ListOrders(ctx)
+	suite.NoError(err)
+	suite.Empty(views)
+}
+
+func (suite *OrderViewRepositoryTestSuite) TestGivenTheOrdersTable_WhenReplayEvents_ThenShouldReplayEachRowAsOrderCreated() {
+	ctx := context.Background()
+	repository := NewSQLiteOrderRepository(suite.Db)
+	suite.NoError(repository.Save(ctx, &entity.Order{ID: "b", Price: 2.0, Tax: 1.0, FinalPrice: 3.0}))
+	suite.NoError(repository.Save(ctx, &entity.Order{ID: "a", Price: 1.0, Tax: 1.0, FinalPrice: 2.0}))
+
+	var replayed []entity.OrderEvent
+	suite.NoError(repository.ReplayEvents(ctx, func(event entity.OrderEvent) error {
+		replayed = append(replayed, event)
+		return nil
+	}))
+	suite.Len(replayed, 2)
+	suite.Equal("a", replayed[0].OrderID)
+	suite.Equal(1, replayed[0].Version)
+	suite.Equal(entity.OrderCreatedEvent, replayed[0].Type)
+	suite.Equal(entity.OrderEventData{Price: 2.0, Tax: 1.0, FinalPrice: 3.0}, replayed[1].Data)
+}
+
+func (suite *OrderViewRepositoryTestSuite) TestGivenTheEventStore_WhenReplayEvents_ThenShouldReplayEveryEventInOrder() {
+	ctx := context.Background()
+	store := NewOrderEventStore(suite.Db, SQLite, nil)
+	b := &entity.Order{ID: "b", Price: 2.0, Tax: 1.0, FinalPrice: 3.0}
+	suite.NoError(store.Save(ctx, b))
+	suite.NoError(store.Save(ctx, &entity.Order{ID: "a", Price: 1.0, Tax: 1.0, FinalPrice: 2.0}))
+	b.Price, b.FinalPrice = 4.0, 5.0
+	suite.NoError(store.Save(ctx, b))
+
+	var replayed []string
+	suite.NoError(store.ReplayEvents(ctx, func(event entity.OrderEvent) error {
+		applied, err := suite.Views.Apply(ctx, event)
+		suite.True(applied)
+		replayed = append(replayed, event.OrderID+"/"+event.Type)
+		return err
+	}))
+	suite.Equal([]string{"a/OrderCreated", "b/OrderCreated", "b/OrderUpdated"}, replayed)
+
+	views, err := suite.Views.ListOrders(ctx)
+	suite.NoError(err)
+	suite.Equal(5.0, views[1].FinalPrice)
+}
+
+func (suite *OrderViewRepositoryTestSuite) TestGivenTheOrdersTable_WhenReplayOrderEvents_ThenShouldReplayOnlyThatRow() {
+	ctx := context.Background()
+	repository := NewSQLiteOrderRepository(suite.Db)
+	suite.NoError(repository.Save(ctx, &entity.Order{ID: "a", Price: 1.0, Tax: 1.0, FinalPrice: 2.0}))
+	suite.NoError(repository.Save(ctx, &entity.Order{ID: "b", Price: 2.0, Tax: 1.0, FinalPrice: 3.0}))
+	_, err := repository.Delete(ctx, "b")
+	suite.Require().NoError(err)
+
+	var replayed []string
+	suite.NoError(repository.ReplayOrderEvents(ctx, "b", func(event entity.OrderEvent) error {
+		replayed = append(replayed, event.OrderID+"/"+event.Type)
+		return nil
+	}))
+	suite.Equal([]string{"b/OrderCreated", "b/OrderDeleted"}, replayed)
+
+	suite.NoError(repository.ReplayOrderEvents(ctx, "missing", func(event entity.OrderEvent) error {
+		suite.Fail("unexpected event", event.OrderID)
+		return nil
+	}))
+}
+
+func (suite *OrderViewRepositoryTestSuite) TestGivenTheEventStore_WhenReplayOrderEvents_ThenShouldReadOnlyItsStream() {
+	ctx := context.Background()
+	store := NewOrderEventStore(suite.Db, SQLite, nil)
+	b := &entity.Order{ID: "b", Price: 2.0, Tax: 1.0, FinalPrice: 3.0}
+	suite.NoError(store.Save(ctx, &entity.Order{ID: "a", Price: 1.0, Tax: 1.0, FinalPrice: 2.0}))
+	suite.NoError(store.Save(ctx, b))
+	b.Price, b.FinalPrice = 4.0, 5.0
+	suite.NoError(store.Save(ctx, b))
+	suite.NoError(store.Save(ctx, &entity.Order{ID: "c", Price: 1.0, Tax: 1.0, FinalPrice: 2.0}))
+
+	var replayed []string
+	suite.NoError(store.ReplayOrderEvents(ctx, "b", func(event entity.OrderEvent) error {
+		replayed = append(replayed, fmt.Sprintf("%s/%d/%s", event.OrderID, event.Version, event.Type))
+		return nil
+	}))
+	suite.Equal([]string{"b/1/OrderCreated", "b/2/OrderUpdated"}, replayed)
+}
+
+func (suite *OrderViewRepositoryTestSuite) TestGivenMoreViewsThanABatch_WhenForEach_ThenShouldVisitAllInOrder() {
+	ctx := context.Background()
+	for i := replayBatchSize; i >= 0; i-- {
+		_, err := suite.Views.Apply(ctx, orderEvent(fmt.Sprintf("%04d", i), 1, float64(i+1)))
+		suite.Require().NoError(err)
+	}
+
+	var ids []string
+	err := suite.Views.ForEach(ctx, func(view entity.OrderView) error {
+		ids = append(ids, view.ID)
+		return nil
+	})
+	suite.NoError(err)
+	suite.Len(ids, replayBatchSize+1)
+	suite.True(sort.StringsAreSorted(ids))
+}
+
+func (suite *OrderViewRepositoryTestSuite) TestGivenAView_WhenFindOrder_ThenShouldReturnIt() {
+	ctx := context.Background()
+	_, err := suite.Views.Apply(ctx, orderEvent("a", 1, 10.0))
+	suite.Require().NoError(err)
+
+	view, err := suite.Views.FindOrder(ctx, "a")
+	suite.Require().NoError(err)
+	suite.Equal(11.0, view.FinalPrice)
+	suite.Equal(1, view.Version)
+
+	_, err = suite.Views.FindOrder(ctx, "missing")
+	suite.ErrorIs(err, entity.ErrOrderNotFound)
+}
+
+func (suite *OrderViewRepositoryTestSuite) TestGivenADeletedOrder_WhenQueryTheViews_ThenShouldLeaveItOutUntilRestored() {
+	ctx := context.Background()
+	_, err := suite.Views.Apply(ctx, orderEvent("a", 1, 10.0))
+	suite.Require().NoError(err)
+	_, err = suite.Views.Apply(ctx, orderEvent("b", 1, 20.0))
+	suite.Require().NoError(err)
+	deleted := orderEvent("a", 2, 10.0)
+	deleted.Type, deleted.Data.DeletedAt = entity.OrderDeletedEvent, &deleted.Timestamp
+	_, err = suite.Views.Apply(ctx, deleted)
+	suite.Require().NoError(err)
+
+	_, err = suite.Views.FindOrder(ctx, "a")
+	suite.ErrorIs(err, entity.ErrOrderNotFound)
+	views, err := suite.Views.ListOrders(ctx)
+	suite.NoError(err)
+	suite.Len(views, 1)
+	views, err = suite.Views.ListOrdersPage(ctx, "", 10)
+	suite.NoError(err)
+	suite.Len(views, 1)
+	query, err := entity.ParseOrderQuery("price>=10")
+	suite.Require().NoError(err)
+	views, err = suite.Views.SearchOrders(ctx, query, "", 10)
+	suite.NoError(err)
+	suite.Len(views, 1)
+
+	restored := orderEvent("a", 3, 10.0)
+	restored.Type = entity.OrderRestoredEvent
+	_, err = suite.Views.Apply(ctx, restored)
+	suite.Require().NoError(err)
+	_, err = suite.Views.FindOrder(ctx, "a")
+	suite.NoError(err)
+}
+
+func (suite *OrderViewRepositoryTestSuite) TestGivenSeveralVersions_WhenApply_ThenShouldKeepTheCreationTimeOfTheFirst() {
+	ctx := context.Background()
+	_, err := suite.Views.Apply(ctx, orderEvent("a", 1, 10.0))
+	suite.NoError(err)
+	_, err = suite.Views.Apply(ctx, orderEvent("a", 2, 20.0))
+	suite.NoError(err)
+
+	view, err := suite.Views.FindOrder(ctx, "a")
+	suite.NoError(err)
+	suite.True(view.CreatedAt.Equal(orderEvent("a", 1, 0).Timestamp), view.CreatedAt)
+	suite.True(view.UpdatedAt.Equal(orderEvent("a", 2, 0).Timestamp), view.UpdatedAt)
+}
+
+func (suite *OrderViewRepositoryTestSuite) TestGivenAQuery_WhenSearchOrders_ThenShouldReturnTheMatchingPage() {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		id        string
+		price     float64
+		createdAt time.Time
+	}{
+		{"ABC-1", 150.0, time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)},
+		{"abc-2", 90.0, time.Date(2026, 2, 28, 23, 59, 59, 0, time.UTC)},
+		{"ABC-3", 300.0, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"XABC_%", 200.0, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"XYZ-1", 500.0, time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)},
+	} {
+		_, err := suite.Views.Apply(ctx, entity.OrderEvent{OrderID: tc.id, Version: 1, Type: entity.OrderCreatedEvent,
+			Data: entity.OrderEventData{Price: tc.price, Tax: 1.0, FinalPrice: tc.price + 1.0}, Timestamp: tc.createdAt})
+		suite.Require().NoError(err)
+	}
+
+	for _, tc := range []struct {
+		query   string
+		afterID string
+		limit   int
+		ids     []string
+	}{
+		{"", "", 10, []string{"ABC-1", "ABC-3", "XABC_%", "XYZ-1", "abc-2"}},
+		{"price>100 id:ABC* created:2026-01..2026-02", "", 10, []string{"ABC-1"}},
+		{"id:abc*", "", 10, []string{"ABC-1", "ABC-3", "abc-2"}},
+		{"id:abc*", "", 2, []string{"ABC-1", "ABC-3"}},
+		{"id:abc*", "ABC-3", 2, []string{"abc-2"}},
+		{"id:ABC-1", "", 10, []string{"ABC-1"}},
+		{"id:abc-1", "", 10, nil},
+		{"_%", "", 10, []string{"XABC_%"}},
+		{"created:2026-02", "", 10, []string{"XABC_%", "abc-2"}},
+		{"created>=2026-03-01T00:00:00Z final_price<=301", "", 10, []string{"ABC-3"}},
+		{"price:90..200 tax:1", "", 10, []string{"ABC-1", "XABC_%", "abc-2"}},
+	} {
+		query, err := entity.ParseOrderQuery(tc.query)
+		suite.Require().NoError(err)
+		views, err := suite.Views.SearchOrders(ctx, query, tc.afterID, tc.limit)
+		suite.NoError(err, tc.query)
+		var ids []string
+		for _, view := range views {
+			ids = append(ids, view.ID)
+		}
+		suite.Equal(tc.ids, ids, tc.query)
+	}
+}
+
+// safeCondition is every condition compileOrderQuery may write: nothing of
+// the query but the field gets into the SQL.
+var safeCondition = regexp.MustCompile(`^(LOWER\(id\) LIKE \? ESCAPE '!'|(id|price|tax|final_price|created_at) (=|>|>=|<|<=) \?)$`)
+
+func FuzzSearchOrders(f *testing.F) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		f.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	source, err := migrations.Source(SQLite.Name())
+	if err != nil {
+		f.Fatal(err)
+	}
+	if _, err := NewMigrator(db, SQLite, source).Up(context.Background()); err != nil {
+		f.Fatal(err)
+	}
+	views := NewOrderViewRepository(db, SQLite)
+
+	for _, seed := range []string{
+		"price>100 id:ABC* created:2026-01..2026-02", "id:'; DROP TABLE order_views; --", "id:*!%_*", "tax<=-1e308 final_price:1..",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		query, err := entity.ParseOrderQuery(input)
+		if err != nil {
+			return
+		}
+		conditions, args, err := compileOrderQuery(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		where := strings.Join(conditions, " AND ")
+		if strings.Count(where, "?") != len(args) {
+			t.Fatalf("%q: %d placeholders for %d arguments", where, strings.Count(where, "?"), len(args))
+		}
+		for _, condition := range conditions {
+			if !safeCondition.MatchString(condition) {
+				t.Fatalf("%q: unexpected condition %q", input, condition)
+			}
+		}
+		if _, err := views.SearchOrders(context.Background(), query, "", 10); err != nil {
+			t.Fatalf("%q: %v", input, err)
+		}
+	})
+}
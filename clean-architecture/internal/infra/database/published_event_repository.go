@@ -0,0 +1,158 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+)
+
+// PublishedEventRepository implements entity.PublishedEventRepositoryInterface
+// on the published_events and missing_events tables. The stored orders are read from the orders
+// table or, when EventSourced is set, from the OrderCreated events of the
+// event store. Deleted orders are never reported as unpublished.
+type PublishedEventRepository struct {
+	Db           *sql.DB
+	Dialect      Dialect
+	EventSourced bool
+	now          func() time.Time
+}
+
+func NewPublishedEventRepository(db *sql.DB, dialect Dialect, eventSourced bool) *PublishedEventRepository {
+	return &PublishedEventRepository{
+		Db:           db,
+		Dialect:      dialect,
+		EventSourced: eventSourced,
+		now:          time.Now,
+	}
+}
+
+func (r *PublishedEventRepository) MarkPublished(ctx context.Context, eventName, orderID string) (err error) {
+	const query = "INSERT INTO published_events (event_name, order_id, published_at) VALUES (?, ?, ?)"
+	ctx, end := startQuery(ctx, r.Dialect, "PublishedEventRepository.MarkPublished", query)
+	defer func() { end(err) }()
+
+	_, err = r.Db.ExecContext(ctx, r.Dialect.Rebind(query), eventName, orderID, r.now().UTC())
+	if r.Dialect.IsUniqueViolation(err) {
+		return nil
+	}
+	return err
+}
+
+const (
+	unpublishedOrdersQuery = "SELECT o.id, o.price, o.tax, o.final_price FROM orders o " +
+		"LEFT JOIN published_events p ON p.order_id = o.id AND p.event_name = ? " +
+		"WHERE p.order_id IS NULL AND o.deleted_at IS NULL AND o.id > ? ORDER BY o.id LIMIT ?"
+	unpublishedEventsQuery = "SELECT e.stream_id, e.payload FROM order_events e " +
+		"LEFT JOIN published_events p ON p.order_id = e.stream_id AND p.event_name = ? " +
+		"WHERE p.order_id IS NULL AND e.version = 1 AND e.stream_id > ? AND NOT EXISTS (" +
+		"SELECT 1 FROM order_events d WHERE d.stream_id = e.stream_id AND d.type = ? " +
+		"AND d.version = (SELECT MAX(m.version) FROM order_events m WHERE m.stream_id = d.stream_id)) ORDER BY e.stream_id LIMIT ?"
+)
+
+func (r *PublishedEventRepository) Unpublished(ctx context.Context, eventName, afterID string, limit int) (orders []entity.Order, err error) {
+	query, args := unpublishedOrdersQuery, []any{eventName, afterID, limit}
+	if r.EventSourced {
+		query, args = unpublishedEventsQuery, []any{eventName, afterID, entity.OrderDeletedEvent, limit}
+	}
+	ctx, end := startQuery(ctx, r.Dialect, "PublishedEventRepository.Unpublished", query)
+	defer func() { end(err) }()
+
+	rows, err := r.Db.QueryContext(ctx, r.Dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var order entity.Order
+		if !r.EventSourced {
+			if err := rows.Scan(&order.ID, &order.Price, &order.Tax, &order.FinalPrice); err != nil {
+				return nil, err
+			}
+			orders = append(orders, order)
+			continue
+		}
+		// the first event of a stream carries the order as it was created
+		var payload string
+		if err := rows.Scan(&order.ID, &payload); err != nil {
+			return nil, err
+		}
+		var data entity.OrderEventData
+		if err := json.Unmarshal([]byte(payload), &data); err != nil {
+			return nil, err
+		}
+		order.Price, order.Tax, order.FinalPrice = data.Price, data.Tax, data.FinalPrice
+		orders = append(orders, order)
+	}
+	return orders, rows.Err()
+}
+
+func (r *PublishedEventRepository) MarkMissing(ctx context.Context, eventName string, orderIDs []string, at time.Time) (firstSeen map[string]time.Time, err error) {
+	firstSeen = make(map[string]time.Time, len(orderIDs))
+	if len(orderIDs) == 0 {
+		return firstSeen, nil
+	}
+	query := "SELECT order_id, first_seen_at FROM missing_events WHERE event_name = ? AND order_id IN (?" +
+		strings.Repeat(", ?", len(orderIDs)-1) + ")"
+	ctx, end := startQuery(ctx, r.Dialect, "PublishedEventRepository.MarkMissing", query)
+	defer func() { end(err) }()
+
+	args := make([]any, 0, len(orderIDs)+1)
+	args = append(args, eventName)
+	for _, id := range orderIDs {
+		args = append(args, id)
+	}
+	tx, err := r.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	rows, err := tx.QueryContext(ctx, r.Dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var id string
+		var seenAt time.Time
+		if err := rows.Scan(&id, &seenAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		firstSeen[id] = seenAt.UTC()
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var values []string
+	args = args[:0]
+	for _, id := range orderIDs {
+		if _, ok := firstSeen[id]; ok {
+			continue
+		}
+		firstSeen[id] = at.UTC()
+		values = append(values, "(?, ?, ?)")
+		args = append(args, eventName, id, at.UTC())
+	}
+	if len(values) > 0 {
+		insert := "INSERT INTO missing_events (event_name, order_id, first_seen_at) VALUES " + strings.Join(values, ", ")
+		if _, err := tx.ExecContext(ctx, r.Dialect.Rebind(insert), args...); err != nil {
+			return nil, err
+		}
+	}
+	return firstSeen, tx.Commit()
+}
+
+func (r *PublishedEventRepository) PruneMissing(ctx context.Context, eventName string) (err error) {
+	const query = "DELETE FROM missing_events WHERE event_name = ? AND order_id IN (" +
+		"SELECT order_id FROM published_events WHERE event_name = ?)"
+	ctx, end := startQuery(ctx, r.Dialect, "PublishedEventRepository.PruneMissing", query)
+	defer func() { end(err) }()
+
+	_, err = r.Db.ExecContext(ctx, r.Dialect.Rebind(query), eventName, eventName)
+	return err
+}
@@ -0,0 +1,187 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/sql/migrations"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PublishedEventRepositoryTestSuite struct {
+	suite.Suite
+	Db *sql.DB
+}
+
+func (suite *PublishedEventRepositoryTestSuite) SetupTest() {
+	db, err := sql.Open("sqlite3", ":memory:")
+	suite.Require().NoError(err)
+	db.SetMaxOpenConns(1)
+	source, err := migrations.Source(SQLite.Name())
+	suite.Require().NoError(err)
+	_, err = NewMigrator(db, SQLite, source).Up(context.Background())
+	suite.Require().NoError(err)
+	suite.Db = db
+}
+
+func (suite *PublishedEventRepositoryTestSuite) TearDownTest() {
+	suite.Db.Close()
+}
+
+func TestPublishedEventRepositorySuite(t *testing.T) {
+	suite.Run(t, new(PublishedEventRepositoryTestSuite))
+}
+
+func (suite *PublishedEventRepositoryTestSuite) TestGivenOrdersInTheTable_WhenUnpublished_ThenShouldSkipThePublishedOnes() {
+	ctx := context.Background()
+	orders := []*entity.Order{{ID: "a", Price: 10.0, Tax: 1.0}, {ID: "b", Price: 20.0, Tax: 2.0}, {ID: "c", Price: 30.0, Tax: 3.0}}
+	for _, order := range orders {
+		order.CalculateFinalPrice()
+	}
+	suite.Require().NoError(NewOrderRepository(suite.Db, SQLite).SaveBatch(ctx, orders))
+	for _, order := range orders {
+		suite.False(order.CreatedAt.IsZero())
+		order.CreatedAt = time.Time{}
+	}
+
+	published := NewPublishedEventRepository(suite.Db, SQLite, false)
+	suite.NoError(published.MarkPublished(ctx, entity.OrderCreatedEvent, "b"))
+	suite.NoError(published.MarkPublished(ctx, entity.OrderCreatedEvent, "b"))
+	// another event doesn't count as the OrderCreated one
+	suite.NoError(published.MarkPublished(ctx, "OrderShipped", "c"))
+
+	unpublished, err := published.Unpublished(ctx, entity.OrderCreatedEvent, "", 10)
+	suite.NoError(err)
+	suite.Equal([]entity.Order{*orders[0], *orders[2]}, unpublished)
+
+	unpublished, err = published.Unpublished(ctx, entity.OrderCreatedEvent, "a", 1)
+	suite.NoError(err)
+	suite.Equal([]string{"c"}, orderIDs(unpublished))
+
+	// a deleted order isn't republished
+	_, err = NewOrderRepository(suite.Db, SQLite).Delete(ctx, "a")
+	suite.Require().NoError(err)
+	unpublished, err = published.Unpublished(ctx, entity.OrderCreatedEvent, "", 10)
+	suite.NoError(err)
+	suite.Equal([]string{"c"}, orderIDs(unpublished))
+}
+
+func (suite *PublishedEventRepositoryTestSuite) TestGivenAnEventStore_WhenUnpublished_ThenShouldReadTheCreatedOrders() {
+	ctx := context.Background()
+	store := NewOrderEventStore(suite.Db, SQLite, nil)
+	order := &entity.Order{ID: "a", Price: 10.0, Tax: 1.0}
+	order.CalculateFinalPrice()
+	suite.Require().NoError(store.Save(ctx, order))
+	order.Price = 50.0
+	order.CalculateFinalPrice()
+	suite.Require().NoError(store.Save(ctx, order))
+
+	unpublished, err := NewPublishedEventRepository(suite.Db, SQLite, true).Unpublished(ctx, entity.OrderCreatedEvent, "", 10)
+	suite.NoError(err)
+	suite.Equal([]entity.Order{{ID: "a", Price: 10.0, Tax: 1.0, FinalPrice: 11.0}}, unpublished)
+
+	_, err = store.Delete(ctx, "a")
+	suite.Require().NoError(err)
+	unpublished, err = NewPublishedEventRepository(suite.Db, SQLite, true).Unpublished(ctx, entity.OrderCreatedEvent, "", 10)
+	suite.NoError(err)
+	suite.Empty(unpublished)
+}
+
+func (suite *PublishedEventRepositoryTestSuite) TestGivenOrdersSavedBeforeTheMigration_WhenUnpublished_ThenShouldReturnNone() {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	suite.Require().NoError(err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	source, err := migrations.Source(SQLite.Name())
+	suite.Require().NoError(err)
+	files, err := fs.ReadDir(source, ".")
+	suite.Require().NoError(err)
+	before := fstest.MapFS{}
+	for _, file := range files {
+		if version, _, _, ok := migrations.ParseFileName(file.Name()); ok && version < 5 {
+			content, err := fs.ReadFile(source, file.Name())
+			suite.Require().NoError(err)
+			before[file.Name()] = &fstest.MapFile{Data: content}
+		}
+	}
+	_, err = NewMigrator(db, SQLite, before).Up(ctx)
+	suite.Require().NoError(err)
+	_, err = db.Exec("INSERT INTO orders (id, price, tax, final_price) VALUES ('a', 10, 1, 11)")
+	suite.Require().NoError(err)
+	_, err = db.Exec("INSERT INTO order_events (stream_id, version, type, payload, created_at) VALUES ('b', 1, ?, '{}', ?)",
+		entity.OrderCreatedEvent, time.Now().UTC())
+	suite.Require().NoError(err)
+
+	_, err = NewMigrator(db, SQLite, source).Up(ctx)
+	suite.Require().NoError(err)
+
+	for _, eventSourced := range []bool{false, true} {
+		unpublished, err := NewPublishedEventRepository(db, SQLite, eventSourced).Unpublished(ctx, entity.OrderCreatedEvent, "", 10)
+		suite.NoError(err)
+		suite.Empty(unpublished)
+	}
+}
+
+func (suite *PublishedEventRepositoryTestSuite) TestGivenTwoHolders_WhenAcquire_ThenShouldHandTheLeaseOverOnlyAfterItExpires() {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	leases := NewLeaseRepository(suite.Db, SQLite)
+	leases.now = func() time.Time { return now }
+
+	for _, tc := range []struct {
+		holder   string
+		after    time.Duration
+		acquired bool
+	}{
+		{"replica-1", 0, true},
+		{"replica-2", time.Second, false},
+		{"replica-1", 30 * time.Second, true},
+		{"replica-2", 80 * time.Second, false},
+		{"replica-2", 100 * time.Second, true},
+		{"replica-1", 101 * time.Second, false},
+	} {
+		leases.now = func() time.Time { return now.Add(tc.after) }
+		acquired, err := leases.Acquire(ctx, "job", tc.holder, time.Minute)
+		suite.NoError(err)
+		suite.Equal(tc.acquired, acquired, "%s after %s", tc.holder, tc.after)
+	}
+}
+
+func orderIDs(orders []entity.Order) []string {
+	var ids []string
+	for _, order := range orders {
+		ids = append(ids, order.ID)
+	}
+	return ids
+}
+
+func (suite *PublishedEventRepositoryTestSuite) TestGivenOrdersMarkedMissing_WhenMarkMissingAgain_ThenShouldKeepWhenTheyWereFirstSeen() {
+	ctx := context.Background()
+	published := NewPublishedEventRepository(suite.Db, SQLite, false)
+	first := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	second := first.Add(5 * time.Minute)
+
+	firstSeen, err := published.MarkMissing(ctx, entity.OrderCreatedEvent, []string{"a", "b"}, first)
+	suite.NoError(err)
+	suite.Equal(map[string]time.Time{"a": first, "b": first}, firstSeen)
+
+	firstSeen, err = published.MarkMissing(ctx, entity.OrderCreatedEvent, []string{"b", "c"}, second)
+	suite.NoError(err)
+	suite.Equal(map[string]time.Time{"b": first, "c": second}, firstSeen)
+
+	// b was published meanwhile: it's forgotten and, if lost again, seen anew
+	suite.NoError(published.MarkPublished(ctx, entity.OrderCreatedEvent, "b"))
+	suite.NoError(published.PruneMissing(ctx, entity.OrderCreatedEvent))
+	_, err = suite.Db.Exec("DELETE FROM published_events")
+	suite.Require().NoError(err)
+	firstSeen, err = published.MarkMissing(ctx, entity.OrderCreatedEvent, []string{"a", "b"}, second)
+	suite.NoError(err)
+	suite.Equal(map[string]time.Time{"a": first, "b": second}, firstSeen)
+}
@@ -0,0 +1,63 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/dataloader"
+)
+
+const (
+	// loaderWait is how long a loader waits for sibling fields to ask for
+	// their keys before fetching them.
+	loaderWait = 2 * time.Millisecond
+	// loaderMaxBatch bounds the number of IDs in one query.
+	loaderMaxBatch = 500
+)
+
+type loadersKey struct{}
+
+// Loaders batches the lookups made by the field resolvers of one response.
+type Loaders struct {
+	History *dataloader.Loader[string, []usecase.OrderHistoryOutputDTO]
+}
+
+func newLoaders(r *Resolver) *Loaders {
+	return &Loaders{
+		History: dataloader.NewLoader(r.GetOrderHistoryUseCase.ExecuteBatch, loaderWait, loaderMaxBatch),
+	}
+}
+
+// DataLoaders is a gqlgen extension that gives each response, including each
+// event of a subscription, its own Loaders, so nothing is cached between them.
+type DataLoaders struct {
+	Resolver *Resolver
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = DataLoaders{}
+
+func (DataLoaders) ExtensionName() string {
+	return "DataLoaders"
+}
+
+func (DataLoaders) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (d DataLoaders) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	return next(context.WithValue(ctx, loadersKey{}, newLoaders(d.Resolver)))
+}
+
+// loadersFor returns the Loaders of the response, or new ones when the schema
+// is served without the DataLoaders extension.
+func (r *Resolver) loadersFor(ctx context.Context) *Loaders {
+	if loaders, ok := ctx.Value(loadersKey{}).(*Loaders); ok {
+		return loaders
+	}
+	return newLoaders(r)
+}
@@ -0,0 +1,72 @@
+package graph
+
+import (
+	"context"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const errDepthLimit = "DEPTH_LIMIT_EXCEEDED"
+
+// DepthLimit is a gqlgen extension that rejects operations nesting fields
+// deeper than Limit. Introspection fields (__schema, __type) are not counted,
+// since the playground's own introspection query is deeply nested.
+type DepthLimit struct {
+	Limit int
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+} = DepthLimit{}
+
+func (DepthLimit) ExtensionName() string {
+	return "DepthLimit"
+}
+
+func (DepthLimit) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (d DepthLimit) MutateOperationContext(ctx context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	op := rc.Doc.Operations.ForName(rc.OperationName)
+	if op == nil {
+		return nil
+	}
+	if depth := selectionDepth(op.SelectionSet); depth > d.Limit {
+		err := gqlerror.Errorf("operation has depth %d, which exceeds the limit of %d", depth, d.Limit)
+		errcode.Set(err, errDepthLimit)
+		return err
+	}
+	return nil
+}
+
+// selectionDepth returns how many fields deep the selection set goes;
+// fragments count as the fields they contain.
+func selectionDepth(selections ast.SelectionSet) int {
+	depth := 0
+	for _, selection := range selections {
+		var d int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name, "__") {
+				continue
+			}
+			d = 1 + selectionDepth(selection.SelectionSet)
+		case *ast.InlineFragment:
+			d = selectionDepth(selection.SelectionSet)
+		case *ast.FragmentSpread:
+			if selection.Definition != nil {
+				d = selectionDepth(selection.Definition.SelectionSet)
+			}
+		}
+		if d > depth {
+			depth = d
+		}
+	}
+	return depth
+}
@@ -2,11 +2,29 @@
 
 package model
 
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
 type Order struct {
-	ID         string  `json:"id"`
-	Price      float64 `json:"Price"`
-	Tax        float64 `json:"Tax"`
-	FinalPrice float64 `json:"FinalPrice"`
+	ID         string             `json:"id"`
+	Price      float64            `json:"Price"`
+	Tax        float64            `json:"Tax"`
+	FinalPrice float64            `json:"FinalPrice"`
+	History    []*OrderAuditEntry `json:"history"`
+}
+
+type OrderAuditEntry struct {
+	Action    string      `json:"action"`
+	Actor     string      `json:"actor"`
+	Transport string      `json:"transport"`
+	RequestID string      `json:"requestId"`
+	Before    *OrderState `json:"before"`
+	After     *OrderState `json:"after"`
+	Timestamp time.Time   `json:"timestamp"`
 }
 
 type OrderInput struct {
@@ -14,3 +32,81 @@ type OrderInput struct {
 	Price float64 `json:"Price"`
 	Tax   float64 `json:"Tax"`
 }
+
+type OrderReport struct {
+	GroupBy ReportGroupBy     `json:"groupBy"`
+	Rows    []*OrderReportRow `json:"rows"`
+	Total   *OrderReportRow   `json:"total"`
+}
+
+type OrderReportRow struct {
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	Count         int       `json:"count"`
+	Price         float64   `json:"Price"`
+	Tax           float64   `json:"Tax"`
+	FinalPrice    float64   `json:"FinalPrice"`
+	AverageTicket float64   `json:"averageTicket"`
+}
+
+type OrderSearchPage struct {
+	Orders        []*OrderSearchResult `json:"orders"`
+	NextPageToken *string              `json:"nextPageToken"`
+}
+
+type OrderSearchResult struct {
+	ID         string    `json:"id"`
+	Price      float64   `json:"Price"`
+	Tax        float64   `json:"Tax"`
+	FinalPrice float64   `json:"FinalPrice"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type OrderState struct {
+	Price      float64 `json:"Price"`
+	Tax        float64 `json:"Tax"`
+	FinalPrice float64 `json:"FinalPrice"`
+}
+
+type ReportGroupBy string
+
+const (
+	ReportGroupByDay   ReportGroupBy = "DAY"
+	ReportGroupByWeek  ReportGroupBy = "WEEK"
+	ReportGroupByMonth ReportGroupBy = "MONTH"
+)
+
+var AllReportGroupBy = []ReportGroupBy{
+	ReportGroupByDay,
+	ReportGroupByWeek,
+	ReportGroupByMonth,
+}
+
+func (e ReportGroupBy) IsValid() bool {
+	switch e {
+	case ReportGroupByDay, ReportGroupByWeek, ReportGroupByMonth:
+		return true
+	}
+	return false
+}
+
+func (e ReportGroupBy) String() string {
+	return string(e)
+}
+
+func (e *ReportGroupBy) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReportGroupBy(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReportGroupBy", str)
+	}
+	return nil
+}
+
+func (e ReportGroupBy) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
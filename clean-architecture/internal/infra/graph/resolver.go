@@ -1,12 +1,46 @@
 package graph
 
-import "github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+import (
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/graph/model"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/pubsub"
+)
 
 // This file will not be regenerated automatically.
 //
 // It serves as dependency injection for your app, add any dependencies you require here.
 
 type Resolver struct {
-	CreateOrderUseCase usecase.CreateOrderUseCase
-	ListOrdersUseCase  usecase.ListOrdersUseCase
+	CreateOrderUseCase     usecase.CreateOrderUseCase
+	ListOrdersUseCase      usecase.ListOrdersUseCase
+	GetOrderHistoryUseCase usecase.GetOrderHistoryUseCase
+	SearchOrdersUseCase    usecase.SearchOrdersUseCase
+	ReportOrdersUseCase    usecase.ReportOrdersUseCase
+	DeleteOrderUseCase     usecase.DeleteOrderUseCase
+	RestoreOrderUseCase    usecase.RestoreOrderUseCase
+	// OrderBroker carries the created orders to the orderCreated subscriptions.
+	OrderBroker *pubsub.Broker[usecase.OrderOutputDTO]
+}
+
+// orderCreatedBuffer is how many created orders an orderCreated subscription
+// may fall behind before it is ended.
+const orderCreatedBuffer = 64
+
+func orderReportRow(row usecase.OrderReportRowDTO) *model.OrderReportRow {
+	return &model.OrderReportRow{
+		Start:         row.Start,
+		End:           row.End,
+		Count:         row.Count,
+		Price:         row.Price,
+		Tax:           row.Tax,
+		FinalPrice:    row.FinalPrice,
+		AverageTicket: row.AverageTicket,
+	}
+}
+
+func orderState(state *usecase.OrderStateDTO) *model.OrderState {
+	if state == nil {
+		return nil
+	}
+	return &model.OrderState{Price: state.Price, Tax: state.Tax, FinalPrice: state.FinalPrice}
 }
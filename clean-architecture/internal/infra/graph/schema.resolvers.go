@@ -6,19 +6,88 @@ package graph
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"log/slog"
+	"strings"
 
 	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/graph/model"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
 )
 
 // CreateOrder is the resolver for the createOrder field.
 func (r *mutationResolver) CreateOrder(ctx context.Context, input *model.OrderInput) (*model.Order, error) {
-	panic(fmt.Errorf("not implemented: CreateOrder - createOrder"))
+	if input == nil {
+		return nil, errors.New("input is required")
+	}
+	output, err := r.CreateOrderUseCase.Execute(ctx, usecase.OrderInputDTO{
+		ID:    input.ID,
+		Price: input.Price,
+		Tax:   input.Tax,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &model.Order{
+		ID:         output.ID,
+		Price:      output.Price,
+		Tax:        output.Tax,
+		FinalPrice: output.FinalPrice,
+	}, nil
+}
+
+// DeleteOrder is the resolver for the deleteOrder field.
+func (r *mutationResolver) DeleteOrder(ctx context.Context, id string) (*model.Order, error) {
+	output, err := r.DeleteOrderUseCase.Execute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Order{
+		ID:         output.ID,
+		Price:      output.Price,
+		Tax:        output.Tax,
+		FinalPrice: output.FinalPrice,
+	}, nil
+}
+
+// RestoreOrder is the resolver for the restoreOrder field.
+func (r *mutationResolver) RestoreOrder(ctx context.Context, id string) (*model.Order, error) {
+	output, err := r.RestoreOrderUseCase.Execute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Order{
+		ID:         output.ID,
+		Price:      output.Price,
+		Tax:        output.Tax,
+		FinalPrice: output.FinalPrice,
+	}, nil
+}
+
+// History is the resolver for the history field.
+func (r *orderResolver) History(ctx context.Context, obj *model.Order) ([]*model.OrderAuditEntry, error) {
+	entries, err := r.loadersFor(ctx).History.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]*model.OrderAuditEntry, 0, len(entries))
+	for _, entry := range entries {
+		history = append(history, &model.OrderAuditEntry{
+			Action:    entry.Action,
+			Actor:     entry.Actor,
+			Transport: entry.Transport,
+			RequestID: entry.RequestID,
+			Before:    orderState(entry.Before),
+			After:     orderState(entry.After),
+			Timestamp: entry.Timestamp,
+		})
+	}
+	return history, nil
 }
 
 // ListOrders is the resolver for the listOrders field.
 func (r *queryResolver) ListOrders(ctx context.Context) ([]*model.Order, error) {
-	orders, err := r.ListOrdersUseCase.Execute()
+	orders, err := r.ListOrdersUseCase.Execute(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -35,11 +104,101 @@ func (r *queryResolver) ListOrders(ctx context.Context) ([]*model.Order, error)
 	return ordersOutput, nil
 }
 
+// SearchOrders is the resolver for the searchOrders field.
+func (r *queryResolver) SearchOrders(ctx context.Context, query string, pageSize *int, pageToken *string) (*model.OrderSearchPage, error) {
+	input := usecase.SearchOrdersInputDTO{Query: query}
+	if pageSize != nil {
+		input.PageSize = *pageSize
+	}
+	if pageToken != nil {
+		input.PageToken = *pageToken
+	}
+	output, err := r.SearchOrdersUseCase.Execute(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.OrderSearchPage{Orders: make([]*model.OrderSearchResult, 0, len(output.Orders))}
+	for _, order := range output.Orders {
+		page.Orders = append(page.Orders, &model.OrderSearchResult{
+			ID:         order.ID,
+			Price:      order.Price,
+			Tax:        order.Tax,
+			FinalPrice: order.FinalPrice,
+			CreatedAt:  order.CreatedAt,
+		})
+	}
+	if output.NextPageToken != "" {
+		page.NextPageToken = &output.NextPageToken
+	}
+	return page, nil
+}
+
+// OrderReport is the resolver for the orderReport field.
+func (r *queryResolver) OrderReport(ctx context.Context, from string, to string, groupBy *model.ReportGroupBy) (*model.OrderReport, error) {
+	input := usecase.ReportOrdersInputDTO{From: from, To: to}
+	if groupBy != nil {
+		input.GroupBy = groupBy.String()
+	}
+	output, err := r.ReportOrdersUseCase.Execute(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.OrderReport{
+		GroupBy: model.ReportGroupBy(strings.ToUpper(output.GroupBy)),
+		Rows:    make([]*model.OrderReportRow, 0, len(output.Rows)),
+		Total:   orderReportRow(output.Total),
+	}
+	for _, row := range output.Rows {
+		report.Rows = append(report.Rows, orderReportRow(row))
+	}
+	return report, nil
+}
+
+// OrderCreated is the resolver for the orderCreated field.
+func (r *subscriptionResolver) OrderCreated(ctx context.Context, minPrice *float64) (<-chan *model.Order, error) {
+	subscription := r.OrderBroker.Subscribe(orderCreatedBuffer)
+	orders := make(chan *model.Order)
+	go func() {
+		defer close(orders)
+		defer subscription.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case order, ok := <-subscription.C:
+				if !ok {
+					slog.WarnContext(ctx, "orderCreated subscription ended", slog.Any("error", subscription.Err()))
+					return
+				}
+				if minPrice != nil && order.Price < *minPrice {
+					continue
+				}
+				select {
+				case orders <- &model.Order{ID: order.ID, Price: order.Price, Tax: order.Tax, FinalPrice: order.FinalPrice}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return orders, nil
+}
+
 // Mutation returns graph.MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
+// Order returns graph.OrderResolver implementation.
+func (r *Resolver) Order() OrderResolver { return &orderResolver{r} }
+
 // Query returns graph.QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
+// Subscription returns graph.SubscriptionResolver implementation.
+func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
+
 type mutationResolver struct{ *Resolver }
+type orderResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
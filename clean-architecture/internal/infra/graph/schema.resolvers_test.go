@@ -0,0 +1,283 @@
+package graph
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	eventhandler "github.com/alexandreti/posGoExpert/clean-architecture/internal/event/handler"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/database"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/pubsub"
+	"github.com/alexandreti/posGoExpert/clean-architecture/sql/migrations"
+
+	"github.com/stretchr/testify/suite"
+
+	// sqlite3
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type orderCreatedResponse struct {
+	OrderCreated struct {
+		ID         string
+		Price      float64
+		FinalPrice float64
+	}
+}
+
+// countingAudit counts the queries made to the audit repository.
+type countingAudit struct {
+	entity.OrderAuditRepositoryInterface
+	queries atomic.Int32
+}
+
+func (c *countingAudit) History(ctx context.Context, orderID string) ([]entity.OrderAuditEntry, error) {
+	c.queries.Add(1)
+	return c.OrderAuditRepositoryInterface.History(ctx, orderID)
+}
+
+func (c *countingAudit) Histories(ctx context.Context, orderIDs []string) (map[string][]entity.OrderAuditEntry, error) {
+	c.queries.Add(1)
+	return c.OrderAuditRepositoryInterface.Histories(ctx, orderIDs)
+}
+
+// ResolverTestSuite runs the schema on the server built by NewServer, on top
+// of SQLite.
+type ResolverTestSuite struct {
+	suite.Suite
+	Db     *sql.DB
+	Broker *pubsub.Broker[usecase.OrderOutputDTO]
+	Audit  *countingAudit
+	Client *client.Client
+}
+
+func (suite *ResolverTestSuite) SetupTest() {
+	db, err := sql.Open("sqlite3", ":memory:")
+	suite.Require().NoError(err)
+	db.SetMaxOpenConns(1)
+	source, err := migrations.Source(database.SQLite.Name())
+	suite.Require().NoError(err)
+	_, err = database.NewMigrator(db, database.SQLite, source).Up(context.Background())
+	suite.Require().NoError(err)
+	suite.Db = db
+
+	suite.Broker = pubsub.NewBroker[usecase.OrderOutputDTO]()
+	dispatcher := events.NewEventDispatcher()
+	orders := database.NewOrderRepository(db, database.SQLite)
+	views := database.NewOrderViewRepository(db, database.SQLite)
+	dispatcher.Register("OrderCreated", eventhandler.NewOrderProjectionHandler(views))
+	dispatcher.Register("OrderCreated", eventhandler.NewOrderBroadcastHandler(suite.Broker))
+	dispatcher.Register("OrderDeleted", eventhandler.NewOrderProjectionHandler(views))
+	dispatcher.Register("OrderRestored", eventhandler.NewOrderProjectionHandler(views))
+	suite.Audit = &countingAudit{OrderAuditRepositoryInterface: database.NewOrderAuditRepository(db, database.SQLite)}
+
+	srv := NewServer(&Resolver{
+		CreateOrderUseCase:     *usecase.NewCreateOrderUseCase(orders, dispatcher),
+		ListOrdersUseCase:      *usecase.NewListOrdersUseCase(views),
+		GetOrderHistoryUseCase: *usecase.NewGetOrderHistoryUseCase(suite.Audit),
+		SearchOrdersUseCase:    *usecase.NewSearchOrdersUseCase(views),
+		ReportOrdersUseCase:    *usecase.NewReportOrdersUseCase(orders),
+		DeleteOrderUseCase:     *usecase.NewDeleteOrderUseCase(orders, dispatcher),
+		RestoreOrderUseCase:    *usecase.NewRestoreOrderUseCase(orders, dispatcher),
+		OrderBroker:            suite.Broker,
+	}, ServerConfig{ComplexityLimit: 300, DepthLimit: 3, APQCacheSize: 10, Introspection: true})
+	suite.Client = client.New(srv)
+}
+
+func (suite *ResolverTestSuite) TearDownTest() {
+	suite.Db.Close()
+}
+
+func TestResolverSuite(t *testing.T) {
+	suite.Run(t, new(ResolverTestSuite))
+}
+
+func (suite *ResolverTestSuite) createOrder(id string, price float64) {
+	var resp map[string]interface{}
+	suite.Require().NoError(suite.Client.Post(
+		`mutation($id: String!, $price: Float!) { createOrder(input: {id: $id, Price: $price, Tax: 1}) { id } }`,
+		&resp, client.Var("id", id), client.Var("price", price),
+	))
+}
+
+// subscribers waits for the broker to have n subscriptions, since the server
+// opens them after acknowledging the subscription.
+func (suite *ResolverTestSuite) subscribers(n int) {
+	suite.Require().Eventually(func() bool { return suite.Broker.Subscribers() == n }, time.Second, 5*time.Millisecond)
+}
+
+func (suite *ResolverTestSuite) TestGivenASubscription_WhenOrdersAreCreated_ThenShouldReceiveThoseAboveMinPrice() {
+	subscription := suite.Client.Websocket(`subscription { orderCreated(minPrice: 50) { id Price FinalPrice } }`)
+	defer subscription.Close()
+	suite.subscribers(1)
+
+	suite.createOrder("a", 10)
+	suite.createOrder("b", 100)
+
+	var resp orderCreatedResponse
+	suite.Require().NoError(subscription.Next(&resp))
+	suite.Equal("b", resp.OrderCreated.ID)
+	suite.Equal(100.0, resp.OrderCreated.Price)
+	suite.Equal(101.0, resp.OrderCreated.FinalPrice)
+}
+
+func (suite *ResolverTestSuite) TestGivenASubscription_WhenTheClientDisconnects_ThenShouldUnsubscribe() {
+	subscription := suite.Client.Websocket(`subscription { orderCreated { id } }`)
+	suite.subscribers(1)
+
+	suite.NoError(subscription.Close())
+	suite.subscribers(0)
+}
+
+func (suite *ResolverTestSuite) TestGivenSeveralOrders_WhenListOrdersWithHistory_ThenShouldLoadTheHistoriesInOneQuery() {
+	for i := 1; i <= 5; i++ {
+		suite.createOrder(fmt.Sprint(i), float64(i*10))
+	}
+	suite.Audit.queries.Store(0)
+
+	var resp struct {
+		ListOrders []struct {
+			ID      string
+			History []struct{ Action string }
+		}
+	}
+	suite.Require().NoError(suite.Client.Post(`{ listOrders { id history { action } } }`, &resp))
+	suite.Len(resp.ListOrders, 5)
+	for _, order := range resp.ListOrders {
+		suite.Equal([]struct{ Action string }{{Action: entity.OrderCreatedEvent}}, order.History)
+	}
+	suite.Equal(int32(1), suite.Audit.queries.Load())
+}
+
+func (suite *ResolverTestSuite) TestGivenATooDeepQuery_WhenPost_ThenShouldRejectIt() {
+	var resp map[string]interface{}
+	err := suite.Client.Post(`{ listOrders { history { after { Price } } } }`, &resp)
+	suite.ErrorContains(err, "operation has depth 4, which exceeds the limit of 3")
+
+	suite.NoError(suite.Client.Post(`{ listOrders { history { action } } __schema { types { fields { type { name } } } } }`, &resp))
+}
+
+func (suite *ResolverTestSuite) TestGivenATooComplexQuery_WhenPost_ThenShouldRejectIt() {
+	var resp map[string]interface{}
+	err := suite.Client.Post(`{ listOrders { id Price Tax FinalPrice history { action actor transport requestId } } }`, &resp)
+	suite.ErrorContains(err, "operation has complexity 451, which exceeds the limit of 300")
+
+	suite.NoError(suite.Client.Post(`{ listOrders { id Price Tax FinalPrice } }`, &resp))
+}
+
+func (suite *ResolverTestSuite) TestGivenAPersistedQuery_WhenPostOnlyItsHash_ThenShouldRunIt() {
+	const query = `{ listOrders { id } }`
+	hash := sha256.Sum256([]byte(query))
+	persisted := client.Extensions(map[string]interface{}{
+		"persistedQuery": map[string]interface{}{"version": 1, "sha256Hash": hex.EncodeToString(hash[:])},
+	})
+	suite.createOrder("a", 10)
+
+	var resp struct{ ListOrders []struct{ ID string } }
+	err := suite.Client.Post("", &resp, persisted)
+	suite.ErrorContains(err, "PersistedQueryNotFound")
+
+	suite.Require().NoError(suite.Client.Post(query, &resp, persisted))
+	resp.ListOrders = nil
+	suite.Require().NoError(suite.Client.Post("", &resp, persisted))
+	suite.Equal("a", resp.ListOrders[0].ID)
+}
+
+func (suite *ResolverTestSuite) TestGivenOrders_WhenSearchOrders_ThenShouldPageThroughTheMatches() {
+	for i := 1; i <= 4; i++ {
+		suite.createOrder(fmt.Sprint("abc-", i), float64(i*10))
+	}
+	suite.createOrder("xyz", 100)
+
+	var resp struct {
+		SearchOrders struct {
+			Orders []struct {
+				ID        string
+				Price     float64
+				CreatedAt string
+			}
+			NextPageToken *string
+		}
+	}
+	const query = `query($token: String) { searchOrders(query: "id:abc* price>=20", pageSize: 2, pageToken: $token) {
+		orders { id Price createdAt } nextPageToken } }`
+	suite.Require().NoError(suite.Client.Post(query, &resp))
+	suite.Require().Len(resp.SearchOrders.Orders, 2)
+	suite.Equal("abc-2", resp.SearchOrders.Orders[0].ID)
+	suite.NotEmpty(resp.SearchOrders.Orders[0].CreatedAt)
+	suite.Require().NotNil(resp.SearchOrders.NextPageToken)
+
+	suite.Require().NoError(suite.Client.Post(query, &resp, client.Var("token", *resp.SearchOrders.NextPageToken)))
+	suite.Require().Len(resp.SearchOrders.Orders, 1)
+	suite.Equal("abc-4", resp.SearchOrders.Orders[0].ID)
+	suite.Nil(resp.SearchOrders.NextPageToken)
+
+	err := suite.Client.Post(`{ searchOrders(query: "status:paid") { orders { id } } }`, &resp)
+	suite.ErrorContains(err, "invalid order query")
+}
+
+func (suite *ResolverTestSuite) TestGivenOrders_WhenOrderReport_ThenShouldSumThemByPeriod() {
+	suite.createOrder("a", 10)
+	suite.createOrder("b", 30)
+	today := time.Now().UTC().Format("2006-01-02")
+
+	var resp struct {
+		OrderReport struct {
+			GroupBy string
+			Rows    []struct {
+				Count int
+				Price float64
+			}
+			Total struct {
+				FinalPrice    float64
+				AverageTicket float64
+			}
+		}
+	}
+	suite.Require().NoError(suite.Client.Post(`query($day: String!) { orderReport(from: $day, to: $day, groupBy: WEEK) {
+		groupBy rows { count Price } total { FinalPrice averageTicket } } }`, &resp, client.Var("day", today)))
+	suite.Equal("WEEK", resp.OrderReport.GroupBy)
+	suite.Require().Len(resp.OrderReport.Rows, 1)
+	suite.Equal(2, resp.OrderReport.Rows[0].Count)
+	suite.Equal(40.0, resp.OrderReport.Rows[0].Price)
+	suite.Equal(42.0, resp.OrderReport.Total.FinalPrice)
+	suite.Equal(21.0, resp.OrderReport.Total.AverageTicket)
+
+	err := suite.Client.Post(`{ orderReport(from: "2026-02", to: "2026-01") { groupBy } }`, &resp)
+	suite.ErrorContains(err, "invalid order report")
+}
+
+func (suite *ResolverTestSuite) TestGivenAnOrder_WhenDeleteAndRestoreOrder_ThenShouldListItOnlyWhileNotDeleted() {
+	suite.createOrder("a", 10)
+	suite.createOrder("b", 20)
+
+	var deleted struct {
+		DeleteOrder struct {
+			ID         string
+			FinalPrice float64
+		}
+	}
+	suite.Require().NoError(suite.Client.Post(`mutation { deleteOrder(id: "a") { id FinalPrice } }`, &deleted))
+	suite.Equal("a", deleted.DeleteOrder.ID)
+	suite.Equal(11.0, deleted.DeleteOrder.FinalPrice)
+
+	var list struct {
+		ListOrders []struct{ ID string }
+	}
+	suite.Require().NoError(suite.Client.Post(`{ listOrders { id } }`, &list))
+	suite.Len(list.ListOrders, 1)
+
+	var resp map[string]interface{}
+	suite.ErrorContains(suite.Client.Post(`mutation { deleteOrder(id: "a") { id } }`, &resp), "order is deleted")
+	suite.Require().NoError(suite.Client.Post(`mutation { restoreOrder(id: "a") { id } }`, &resp))
+	suite.Require().NoError(suite.Client.Post(`{ listOrders { id } }`, &list))
+	suite.Len(list.ListOrders, 2)
+}
@@ -0,0 +1,65 @@
+package graph
+
+import (
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+)
+
+const (
+	// listComplexityFactor weights the list fields as if they returned that
+	// many elements, since their size isn't known when the limit is checked.
+	listComplexityFactor = 10
+	// queryCacheSize is how many parsed and validated queries are kept.
+	queryCacheSize = 1000
+)
+
+// ServerConfig sets the limits of the GraphQL server. A zero limit disables it.
+type ServerConfig struct {
+	ComplexityLimit int
+	DepthLimit      int
+	// APQCacheSize is how many automatic persisted queries are kept, by hash,
+	// in an LRU cache.
+	APQCacheSize  int
+	Introspection bool
+}
+
+// NewServer returns the gqlgen server of the schema with the transports of
+// handler.NewDefaultServer, plus the configured limits and the DataLoaders.
+func NewServer(resolver *Resolver, config ServerConfig) *handler.Server {
+	schemaConfig := Config{Resolvers: resolver}
+	schemaConfig.Complexity.Query.ListOrders = func(childComplexity int) int {
+		return 1 + childComplexity*listComplexityFactor
+	}
+	schemaConfig.Complexity.Order.History = func(childComplexity int) int {
+		return 1 + childComplexity*listComplexityFactor
+	}
+
+	srv := handler.New(NewExecutableSchema(schemaConfig))
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+	})
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{})
+	srv.SetQueryCache(lru.New(queryCacheSize))
+
+	if config.Introspection {
+		srv.Use(extension.Introspection{})
+	}
+	if config.APQCacheSize > 0 {
+		srv.Use(extension.AutomaticPersistedQuery{Cache: lru.New(config.APQCacheSize)})
+	}
+	if config.ComplexityLimit > 0 {
+		srv.Use(extension.FixedComplexityLimit(config.ComplexityLimit))
+	}
+	if config.DepthLimit > 0 {
+		srv.Use(DepthLimit{Limit: config.DepthLimit})
+	}
+	srv.Use(DataLoaders{Resolver: resolver})
+	return srv
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// inProcessBufferSize is the size, in bytes, of the in-memory connection
+// buffer of InProcess.
+const inProcessBufferSize = 1024 * 1024
+
+// InProcess serves s on an in-memory listener and returns a connection to it,
+// so that calls made in the same process, e.g. by the grpc-gateway, go through
+// the interceptor chain of s. The connection isn't encrypted; s must not be
+// given TLS credentials. Stopping s closes the listener.
+func InProcess(s *grpc.Server, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	listener := bufconn.Listen(inProcessBufferSize)
+	go s.Serve(listener)
+
+	options := []grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}
+	return grpc.NewClient("passthrough:///inprocess", append(options, opts...)...)
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGivenAPanickingHandler_WhenCallUnary_ThenShouldReturnInternalAndLogThePanic(t *testing.T) {
+	var buffer bytes.Buffer
+	interceptor := UnaryRecoveryInterceptor(slog.New(slog.NewJSONHandler(&buffer, nil)))
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/pb.OrderService/CreateOrder"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Contains(t, buffer.String(), `"panic":"boom"`)
+	assert.Contains(t, buffer.String(), "/pb.OrderService/CreateOrder")
+	assert.Contains(t, buffer.String(), "recovery_test.go")
+}
+
+func TestGivenAPanickingHandler_WhenCallStream_ThenShouldReturnInternal(t *testing.T) {
+	interceptor := StreamRecoveryInterceptor(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+
+	err := interceptor(nil, &fakeStream{ctx: context.Background()}, &grpc.StreamServerInfo{}, func(srv interface{}, stream grpc.ServerStream) error {
+		var orders []string
+		_ = orders[1]
+		return nil
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestGivenAHandlerError_WhenCallUnary_ThenShouldReturnItUntouched(t *testing.T) {
+	interceptor := UnaryRecoveryInterceptor(slog.Default())
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "order not found")
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,79 @@
+// Package server builds the gRPC server of the order system: its interceptor
+// chain, message size limits and keepalive settings.
+package server
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/logging"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/requestctx"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// Config configures the server built by New.
+type Config struct {
+	Logger *slog.Logger
+	// AccessLog writes one log entry per call.
+	AccessLog bool
+	// Validate checks the requests against the buf.validate rules of order.proto.
+	Validate bool
+	// DefaultTimeout is the deadline of the unary calls sent without one; zero
+	// leaves them without a deadline.
+	DefaultTimeout time.Duration
+	// MaxMessageSize limits, in bytes, the messages received and sent; zero
+	// keeps the gRPC default of 4 MiB.
+	MaxMessageSize int
+	Keepalive      keepalive.ServerParameters
+	// KeepaliveMinTime is how often a client may ping; clients pinging more
+	// often are disconnected.
+	KeepaliveMinTime time.Duration
+	// Unary and Stream are run after the access log and the recovery, and
+	// before the timeout and the validation, e.g. the rate limiter.
+	Unary  []grpc.UnaryServerInterceptor
+	Stream []grpc.StreamServerInterceptor
+}
+
+// New creates a gRPC server with the interceptor chain
+//
+//	request ID → access log → recovery → Unary/Stream → timeout → validation
+//
+// so that every call, even a rejected or panicking one, is logged with its
+// request ID and final status code. opts are added to the server options.
+func New(cfg Config, opts ...grpc.ServerOption) *grpc.Server {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	unary := []grpc.UnaryServerInterceptor{requestctx.UnaryServerInterceptor()}
+	stream := []grpc.StreamServerInterceptor{requestctx.StreamServerInterceptor()}
+	if cfg.AccessLog {
+		unary = append(unary, logging.UnaryServerInterceptor(logger))
+		stream = append(stream, logging.StreamServerInterceptor(logger))
+	}
+	unary = append(unary, UnaryRecoveryInterceptor(logger))
+	stream = append(stream, StreamRecoveryInterceptor(logger))
+	unary = append(unary, cfg.Unary...)
+	stream = append(stream, cfg.Stream...)
+	if cfg.DefaultTimeout > 0 {
+		unary = append(unary, UnaryTimeoutInterceptor(cfg.DefaultTimeout))
+	}
+	if cfg.Validate {
+		unary = append(unary, UnaryValidationInterceptor(logger))
+		stream = append(stream, StreamValidationInterceptor(logger))
+	}
+
+	options := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+		grpc.KeepaliveParams(cfg.Keepalive),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{MinTime: cfg.KeepaliveMinTime, PermitWithoutStream: true}),
+	}
+	if cfg.MaxMessageSize > 0 {
+		options = append(options, grpc.MaxRecvMsgSize(cfg.MaxMessageSize), grpc.MaxSendMsgSize(cfg.MaxMessageSize))
+	}
+	return grpc.NewServer(append(options, opts...)...)
+}
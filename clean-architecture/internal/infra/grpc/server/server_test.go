@@ -0,0 +1,93 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/grpc/pb"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// echoService answers CreateOrder with the order it receives, and panics on
+// the order "panic".
+type echoService struct {
+	pb.UnimplementedOrderServiceServer
+}
+
+func (echoService) CreateOrder(ctx context.Context, in *pb.CreateOrderRequest) (*pb.CreateOrderResponse, error) {
+	if in.Id == "panic" {
+		panic("boom")
+	}
+	return &pb.CreateOrderResponse{Id: in.Id, Price: in.Price, Tax: in.Tax, FinalPrice: in.Price + in.Tax}, nil
+}
+
+// ServerTestSuite runs a server built by New over bufconn.
+type ServerTestSuite struct {
+	suite.Suite
+	Logs   *bytes.Buffer
+	Server *grpc.Server
+	Conn   *grpc.ClientConn
+	Client pb.OrderServiceClient
+}
+
+func (suite *ServerTestSuite) SetupTest() {
+	suite.Logs = &bytes.Buffer{}
+	suite.Server = New(Config{
+		Logger:         slog.New(slog.NewJSONHandler(suite.Logs, nil)),
+		AccessLog:      true,
+		Validate:       true,
+		MaxMessageSize: 1024,
+	})
+	pb.RegisterOrderServiceServer(suite.Server, echoService{})
+	listener := bufconn.Listen(1 << 20)
+	go suite.Server.Serve(listener)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	suite.Require().NoError(err)
+	suite.Conn = conn
+	suite.Client = pb.NewOrderServiceClient(conn)
+}
+
+func (suite *ServerTestSuite) TearDownTest() {
+	suite.Conn.Close()
+	suite.Server.Stop()
+}
+
+func TestServerSuite(t *testing.T) {
+	suite.Run(t, new(ServerTestSuite))
+}
+
+func (suite *ServerTestSuite) TestGivenAPanic_WhenCall_ThenShouldReturnInternalAndKeepServing() {
+	_, err := suite.Client.CreateOrder(context.Background(), &pb.CreateOrderRequest{Id: "panic", Price: 10, Tax: 1})
+	suite.Equal(codes.Internal, status.Code(err))
+
+	order, err := suite.Client.CreateOrder(context.Background(), &pb.CreateOrderRequest{Id: "a", Price: 10, Tax: 1})
+	suite.Require().NoError(err)
+	suite.Equal(11.0, order.FinalPrice)
+	// the access log runs around the recovery and sees the Internal code
+	suite.Contains(suite.Logs.String(), `"code":"Internal"`)
+}
+
+func (suite *ServerTestSuite) TestGivenAnInvalidRequest_WhenCall_ThenShouldLogInvalidArgumentWithTheRequestID() {
+	_, err := suite.Client.CreateOrder(context.Background(), &pb.CreateOrderRequest{Id: "a", Price: 0, Tax: 1})
+	suite.Equal(codes.InvalidArgument, status.Code(err))
+	suite.Contains(suite.Logs.String(), `"code":"InvalidArgument"`)
+}
+
+func (suite *ServerTestSuite) TestGivenAMessageOverTheLimit_WhenCall_ThenShouldReturnResourceExhausted() {
+	_, err := suite.Client.CreateOrder(context.Background(), &pb.CreateOrderRequest{Id: strings.Repeat("a", 2048), Price: 10, Tax: 1})
+	suite.Equal(codes.ResourceExhausted, status.Code(err))
+}
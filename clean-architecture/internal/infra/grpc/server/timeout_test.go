@@ -0,0 +1,42 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+func deadlineOf(t *testing.T, interceptor grpc.UnaryServerInterceptor, ctx context.Context) (time.Time, bool) {
+	var deadline time.Time
+	var ok bool
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		deadline, ok = ctx.Deadline()
+		return nil, nil
+	})
+	assert.NoError(t, err)
+	return deadline, ok
+}
+
+func TestGivenACallWithoutDeadline_WhenCall_ThenShouldSetTheDefaultTimeout(t *testing.T) {
+	deadline, ok := deadlineOf(t, UnaryTimeoutInterceptor(time.Minute), context.Background())
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+}
+
+func TestGivenACallWithADeadline_WhenCall_ThenShouldKeepIt(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	want, _ := ctx.Deadline()
+
+	deadline, ok := deadlineOf(t, UnaryTimeoutInterceptor(time.Minute), ctx)
+	assert.True(t, ok)
+	assert.Equal(t, want, deadline)
+}
+
+func TestGivenAZeroTimeout_WhenCall_ThenShouldNotSetADeadline(t *testing.T) {
+	_, ok := deadlineOf(t, UnaryTimeoutInterceptor(0), context.Background())
+	assert.False(t, ok)
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+
+	"buf.build/go/protovalidate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// validator is built on the first request validated and shared by every server.
+var validator = sync.OnceValues(func() (protovalidate.Validator, error) {
+	return protovalidate.New()
+})
+
+// UnaryValidationInterceptor checks the request against the buf.validate rules
+// of order.proto with protovalidate before calling the handler. An invalid request
+// is answered with InvalidArgument, detailing each field in a BadRequest.
+func UnaryValidationInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := validateRequest(ctx, logger, info.FullMethod, req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamValidationInterceptor validates the request of the server streaming
+// RPCs, such as StreamOrders. The messages streamed by the client are left to
+// the RPC, which reports the invalid rows without failing the stream.
+func StreamValidationInterceptor(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if info.IsClientStream {
+			return handler(srv, ss)
+		}
+		return handler(srv, &validatingStream{ServerStream: ss, logger: logger, method: info.FullMethod})
+	}
+}
+
+type validatingStream struct {
+	grpc.ServerStream
+	logger *slog.Logger
+	method string
+}
+
+func (s *validatingStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return validateRequest(s.Context(), s.logger, s.method, m)
+}
+
+func validateRequest(ctx context.Context, logger *slog.Logger, method string, req interface{}) error {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return nil
+	}
+	v, err := validator()
+	if err == nil {
+		err = v.Validate(msg)
+	}
+	var validationErr *protovalidate.ValidationError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &validationErr):
+		badRequest := &errdetails.BadRequest{}
+		for _, violation := range validationErr.Violations {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       protovalidate.FieldPathString(violation.Proto.GetField()),
+				Description: violation.Proto.GetMessage(),
+			})
+		}
+		st, detailErr := status.New(codes.InvalidArgument, validationErr.Error()).WithDetails(badRequest)
+		if detailErr != nil {
+			return status.Error(codes.InvalidArgument, validationErr.Error())
+		}
+		return st.Err()
+	default:
+		// the rules of the proto can't be evaluated: a bug, not a bad request
+		logger.ErrorContext(ctx, "failed to validate the gRPC request", slog.String("method", method), slog.Any("error", err))
+		return status.Error(codes.Internal, "internal error")
+	}
+}
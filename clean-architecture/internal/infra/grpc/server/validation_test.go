@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/grpc/pb"
+
+	"buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// validateUnary runs req through UnaryValidationInterceptor, reporting whether
+// the handler was called.
+func validateUnary(req proto.Message) (bool, error) {
+	called := false
+	_, err := UnaryValidationInterceptor(slog.Default())(context.Background(), req, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return nil, nil
+	})
+	return called, err
+}
+
+// fieldViolations returns the fields of the BadRequest detail of err.
+func fieldViolations(t *testing.T, err error) []*errdetails.BadRequest_FieldViolation {
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	if !assert.Len(t, st.Details(), 1) {
+		return nil
+	}
+	return st.Details()[0].(*errdetails.BadRequest).FieldViolations
+}
+
+func TestGivenAValidRequest_WhenCallUnary_ThenShouldCallTheHandler(t *testing.T) {
+	for _, req := range []proto.Message{&pb.CreateOrderRequest{Id: "a", Price: 10, Tax: 1}, &pb.StreamOrdersRequest{}} {
+		called, err := validateUnary(req)
+		assert.NoError(t, err)
+		assert.True(t, called)
+	}
+}
+
+func TestGivenAnInvalidRequest_WhenCallUnary_ThenShouldListEveryViolation(t *testing.T) {
+	_, err := validateUnary(&pb.CreateOrderRequest{Price: 0, Tax: -1})
+	var fields []string
+	for _, violation := range fieldViolations(t, err) {
+		fields = append(fields, violation.Field)
+	}
+	assert.ElementsMatch(t, []string{"id", "price", "tax"}, fields)
+
+	_, err = validateUnary(&pb.StreamOrdersRequest{PageSize: -1})
+	assert.Equal(t, []*errdetails.BadRequest_FieldViolation{
+		{Field: "page_size", Description: "value must be greater than or equal to 0"},
+	}, fieldViolations(t, err))
+}
+
+// ruleMessage builds a message type with a single string field "name" holding
+// rules, to test the rules order.proto doesn't use.
+func ruleMessage(t *testing.T, rules *validate.FieldRules) proto.Message {
+	options := &descriptorpb.FieldOptions{}
+	proto.SetExtension(options, validate.E_Field, rules)
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("rules_test.proto"),
+		Package:    proto.String("test"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"buf/validate/validate.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Named"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     proto.String("name"),
+				Number:   proto.Int32(1),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				JsonName: proto.String("name"),
+				Options:  options,
+			}},
+		}},
+	}, protoregistry.GlobalFiles)
+	assert.NoError(t, err)
+	msg := dynamicpb.NewMessage(file.Messages().Get(0))
+	msg.Set(file.Messages().Get(0).Fields().Get(0), protoreflect.ValueOfString("order-1"))
+	return msg
+}
+
+func TestGivenAnyBufValidateRule_WhenCallUnary_ThenShouldEvaluateIt(t *testing.T) {
+	_, err := validateUnary(ruleMessage(t, validate.FieldRules_builder{String: validate.StringRules_builder{Pattern: proto.String("^[a-z]+$")}.Build()}.Build()))
+	assert.Equal(t, "name", fieldViolations(t, err)[0].Field)
+
+	_, err = validateUnary(ruleMessage(t, validate.FieldRules_builder{String: validate.StringRules_builder{Email: proto.Bool(true)}.Build()}.Build()))
+	assert.Equal(t, "name", fieldViolations(t, err)[0].Field)
+
+	_, err = validateUnary(ruleMessage(t, validate.FieldRules_builder{Cel: []*validate.Rule{validate.Rule_builder{
+		Id:         proto.String("name.prefix"),
+		Message:    proto.String("name must start with order-"),
+		Expression: proto.String("this.startsWith('order-')"),
+	}.Build()}}.Build()))
+	assert.NoError(t, err)
+}
+
+func TestGivenAnInvalidRequest_WhenCallUnary_ThenShouldNotCallTheHandler(t *testing.T) {
+	called, err := validateUnary(&pb.CreateOrderRequest{Id: "a", Price: -5, Tax: 1})
+	assert.False(t, called)
+	assert.Equal(t, "price", fieldViolations(t, err)[0].Field)
+}
+
+func TestGivenAServerStreamingRequest_WhenRecv_ThenShouldValidateIt(t *testing.T) {
+	interceptor := StreamValidationInterceptor(slog.Default())
+	stream := &fakeStream{ctx: context.Background(), msg: &pb.StreamOrdersRequest{PageSize: -1}}
+
+	err := interceptor(nil, stream, &grpc.StreamServerInfo{IsServerStream: true}, func(srv interface{}, stream grpc.ServerStream) error {
+		return stream.RecvMsg(&pb.StreamOrdersRequest{})
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGivenAClientStream_WhenRecv_ThenShouldLeaveTheRowsToTheRPC(t *testing.T) {
+	interceptor := StreamValidationInterceptor(slog.Default())
+	stream := &fakeStream{ctx: context.Background(), msg: &pb.CreateOrderRequest{Price: -1}}
+
+	err := interceptor(nil, stream, &grpc.StreamServerInfo{IsClientStream: true}, func(srv interface{}, stream grpc.ServerStream) error {
+		return stream.RecvMsg(&pb.CreateOrderRequest{})
+	})
+	assert.NoError(t, err)
+}
+
+// fakeStream is a grpc.ServerStream receiving msg.
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	msg proto.Message
+}
+
+func (s *fakeStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeStream) RecvMsg(m interface{}) error {
+	if s.msg != nil {
+		proto.Merge(m.(proto.Message), s.msg)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/grpc/pb"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/requestctx"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// NewGatewayHandler serves the RPCs annotated with google.api.http in
+// order.proto as JSON over HTTP, under /v1. The requests are sent over conn, so
+// they go through the interceptor chain of the server on the other end
+// (validation, recovery, default deadline, access log); the request ID and the
+// actor stored by the HTTP middlewares are forwarded as metadata. The JSON uses
+// the proto field names, as the chi routes do, and unknown fields are rejected.
+// middlewares run once the request is routed, when GatewayPattern is known.
+func NewGatewayHandler(ctx context.Context, conn grpc.ClientConnInterface, middlewares ...func(http.Handler) http.Handler) (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithMiddlewares(gatewayMiddlewares(middlewares)...),
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{
+				UseProtoNames:   true,
+				EmitUnpopulated: true,
+			},
+		}),
+		runtime.WithMetadata(forwardRequestContext),
+		// the request ID is already echoed by the HTTP middleware
+		runtime.WithOutgoingHeaderMatcher(func(string) (string, bool) { return "", false }),
+	)
+	if err := pb.RegisterOrderServiceHandlerClient(ctx, mux, pb.NewOrderServiceClient(conn)); err != nil {
+		return nil, err
+	}
+	return mux, nil
+}
+
+// GatewayPattern returns the google.api.http path template the gateway
+// routed r to, as written in order.proto, e.g. "/v1/orders/{id}/history". The
+// gateway middlewares see it.
+func GatewayPattern(r *http.Request) string {
+	pattern, ok := runtime.HTTPPattern(r.Context())
+	if !ok {
+		return ""
+	}
+	// {id=*} is how the gateway writes a variable matching one segment
+	return strings.ReplaceAll(pattern.String(), "=*}", "}")
+}
+
+// gatewayMiddlewares runs each middleware after the gateway routed the request.
+func gatewayMiddlewares(middlewares []func(http.Handler) http.Handler) []runtime.Middleware {
+	wrapped := make([]runtime.Middleware, len(middlewares))
+	for i, middleware := range middlewares {
+		wrapped[i] = func(next runtime.HandlerFunc) runtime.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+				middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					next(w, r, pathParams)
+				})).ServeHTTP(w, r)
+			}
+		}
+	}
+	return wrapped
+}
+
+func forwardRequestContext(ctx context.Context, r *http.Request) metadata.MD {
+	md := metadata.MD{}
+	if requestID := requestctx.RequestID(ctx); requestID != "" {
+		md.Set(requestctx.RequestIDMetadata, requestID)
+	}
+	if actor := requestctx.Actor(ctx); actor != requestctx.AnonymousActor {
+		md.Set(requestctx.ActorMetadata, actor)
+	}
+	return md
+}
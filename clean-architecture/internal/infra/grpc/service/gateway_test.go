@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/requestctx"
+)
+
+func (suite *OrderServiceTestSuite) serveGateway(method, target, body string) *httptest.ResponseRecorder {
+	gateway, err := NewGatewayHandler(context.Background(), suite.Conn)
+	suite.Require().NoError(err)
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	gateway.ServeHTTP(rec, req)
+	return rec
+}
+
+func (suite *OrderServiceTestSuite) TestGivenAnOrder_WhenPostToTheGateway_ThenShouldReturnItWithDoublePrices() {
+	rec := suite.serveGateway(http.MethodPost, "/v1/orders", `{"id":"a","price":10.1,"tax":0.2}`)
+	suite.Require().Equal(http.StatusOK, rec.Code, rec.Body.String())
+	var order map[string]any
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &order))
+	// a float32 would turn 10.1 into 10.100000381469727
+	price, tax := 10.1, 0.2
+	suite.Equal(map[string]any{"id": "a", "price": price, "tax": tax, "final_price": price + tax}, order)
+}
+
+func (suite *OrderServiceTestSuite) TestGivenOrders_WhenGetFromTheGateway_ThenShouldReturnAnArray() {
+	suite.Require().Equal(http.StatusOK, suite.serveGateway(http.MethodPost, "/v1/orders", `{"id":"a","price":10,"tax":1}`).Code)
+	suite.Require().Equal(http.StatusOK, suite.serveGateway(http.MethodPost, "/v1/orders", `{"id":"b","price":20,"tax":2}`).Code)
+
+	rec := suite.serveGateway(http.MethodGet, "/v1/orders", "")
+	suite.Require().Equal(http.StatusOK, rec.Code, rec.Body.String())
+	var orders []map[string]any
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &orders))
+	suite.Len(orders, 2)
+}
+
+func (suite *OrderServiceTestSuite) TestGivenAnUnknownOrder_WhenGetItsHistoryFromTheGateway_ThenShouldReturnNotFound() {
+	rec := suite.serveGateway(http.MethodGet, "/v1/orders/missing/history", "")
+	suite.Equal(http.StatusNotFound, rec.Code)
+}
+
+func (suite *OrderServiceTestSuite) TestGivenAnUnknownField_WhenPostToTheGateway_ThenShouldReturnBadRequest() {
+	rec := suite.serveGateway(http.MethodPost, "/v1/orders", `{"id":"a","price":10,"tax":1,"discount":2}`)
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
+
+func (suite *OrderServiceTestSuite) TestGivenAnOrder_WhenGetItFromTheGateway_ThenShouldReturnIt() {
+	suite.Require().Equal(http.StatusOK, suite.serveGateway(http.MethodPost, "/v1/orders", `{"id":"a","price":10,"tax":1}`).Code)
+
+	rec := suite.serveGateway(http.MethodGet, "/v1/orders/a", "")
+	suite.Require().Equal(http.StatusOK, rec.Code, rec.Body.String())
+	suite.JSONEq(`{"id":"a","price":10,"tax":1,"final_price":11}`, rec.Body.String())
+	suite.Equal(http.StatusNotFound, suite.serveGateway(http.MethodGet, "/v1/orders/missing", "").Code)
+}
+
+func (suite *OrderServiceTestSuite) TestGivenOrders_WhenSearchTheGateway_ThenShouldReturnTheMatches() {
+	suite.Require().Equal(http.StatusOK, suite.serveGateway(http.MethodPost, "/v1/orders", `{"id":"abc-1","price":150,"tax":1}`).Code)
+	suite.Require().Equal(http.StatusOK, suite.serveGateway(http.MethodPost, "/v1/orders", `{"id":"abc-2","price":50,"tax":1}`).Code)
+
+	rec := suite.serveGateway(http.MethodGet, "/v1/orders:search?query="+url.QueryEscape("price>100 id:ABC*"), "")
+	suite.Require().Equal(http.StatusOK, rec.Code, rec.Body.String())
+	var response struct {
+		Orders []map[string]any `json:"orders"`
+	}
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &response))
+	suite.Require().Len(response.Orders, 1)
+	suite.Equal("abc-1", response.Orders[0]["id"])
+	suite.Contains(response.Orders[0], "created_at")
+
+	suite.Equal(http.StatusBadRequest, suite.serveGateway(http.MethodGet, "/v1/orders:search?query=price>abc", "").Code)
+}
+
+func (suite *OrderServiceTestSuite) TestGivenAnOrder_WhenDeleteAndRestoreThroughTheGateway_ThenShouldReturnIt() {
+	suite.Require().Equal(http.StatusOK, suite.serveGateway(http.MethodPost, "/v1/orders", `{"id":"a","price":10,"tax":1}`).Code)
+
+	rec := suite.serveGateway(http.MethodDelete, "/v1/orders/a", "")
+	suite.Require().Equal(http.StatusOK, rec.Code, rec.Body.String())
+	suite.JSONEq(`{"id":"a","price":10,"tax":1,"final_price":11}`, rec.Body.String())
+	suite.Equal(http.StatusNotFound, suite.serveGateway(http.MethodGet, "/v1/orders/a", "").Code)
+	suite.Equal(http.StatusBadRequest, suite.serveGateway(http.MethodDelete, "/v1/orders/a", "").Code)
+
+	rec = suite.serveGateway(http.MethodPost, "/v1/orders/a:restore", "")
+	suite.Require().Equal(http.StatusOK, rec.Code, rec.Body.String())
+	suite.Equal(http.StatusOK, suite.serveGateway(http.MethodGet, "/v1/orders/a", "").Code)
+}
+
+func (suite *OrderServiceTestSuite) TestGivenAnInvalidOrder_WhenPostToTheGateway_ThenShouldReturnBadRequest() {
+	rec := suite.serveGateway(http.MethodPost, "/v1/orders", `{"id":"","price":0,"tax":0}`)
+	suite.Equal(http.StatusBadRequest, rec.Code, rec.Body.String())
+
+	orders, err := suite.Views.ListOrders(context.Background())
+	suite.NoError(err)
+	suite.Empty(orders)
+}
+
+func (suite *OrderServiceTestSuite) TestGivenARequestIDAndAnActor_WhenPostToTheGateway_ThenShouldRecordThem() {
+	gateway, err := NewGatewayHandler(context.Background(), suite.Conn)
+	suite.Require().NoError(err)
+	req := httptest.NewRequest(http.MethodPost, "/v1/orders", strings.NewReader(`{"id":"a","price":10,"tax":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(requestctx.RequestIDHeader, "req-1")
+	req.Header.Set(requestctx.ActorHeader, "billing")
+	rec := httptest.NewRecorder()
+	requestctx.HTTPMiddleware(gateway).ServeHTTP(rec, req)
+	suite.Require().Equal(http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = suite.serveGateway(http.MethodGet, "/v1/orders/a/history", "")
+	suite.Require().Equal(http.StatusOK, rec.Code, rec.Body.String())
+	var history []map[string]any
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &history))
+	suite.Require().Len(history, 1)
+	suite.Equal("req-1", history[0]["request_id"])
+	suite.Equal("billing", history[0]["actor"])
+}
+
+func (suite *OrderServiceTestSuite) TestGivenAMiddleware_WhenGetFromTheGateway_ThenShouldSeeTheRoutePattern() {
+	var patterns []string
+	record := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			patterns = append(patterns, r.Method+" "+GatewayPattern(r))
+			next.ServeHTTP(w, r)
+		})
+	}
+	gateway, err := NewGatewayHandler(context.Background(), suite.Conn, record)
+	suite.Require().NoError(err)
+
+	for _, target := range []string{"/v1/orders/a/history", "/v1/orders/b/history"} {
+		rec := httptest.NewRecorder()
+		gateway.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		suite.Equal(http.StatusNotFound, rec.Code, rec.Body.String())
+	}
+	suite.Equal([]string{"GET /v1/orders/{id}/history", "GET /v1/orders/{id}/history"}, patterns)
+}
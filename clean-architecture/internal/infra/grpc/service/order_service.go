@@ -2,47 +2,100 @@ package service
 
 import (
 	"context"
+	"errors"
+	"io"
 
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
 	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/grpc/pb"
 	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/pubsub"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type OrderService struct {
 	pb.UnimplementedOrderServiceServer
-	CreateOrderUseCase usecase.CreateOrderUseCase
-	ListOrdersUseCase  usecase.ListOrdersUseCase
+	CreateOrderUseCase     usecase.CreateOrderUseCase
+	ListOrdersUseCase      usecase.ListOrdersUseCase
+	GetOrderHistoryUseCase usecase.GetOrderHistoryUseCase
+	ImportOrdersUseCase    usecase.ImportOrdersUseCase
+	StreamOrdersUseCase    usecase.StreamOrdersUseCase
+	CreateOrdersUseCase    usecase.CreateOrdersUseCase
+	GetOrderUseCase        usecase.GetOrderUseCase
+	ReplayEventsUseCase    usecase.ReplayOrderEventsUseCase
+	SearchOrdersUseCase    usecase.SearchOrdersUseCase
+	ReportOrdersUseCase    usecase.ReportOrdersUseCase
+	DeleteOrderUseCase     usecase.DeleteOrderUseCase
+	RestoreOrderUseCase    usecase.RestoreOrderUseCase
+	// OrderBroker carries the created orders to WatchOrders.
+	OrderBroker *pubsub.Broker[usecase.OrderOutputDTO]
 }
 
+// watchBuffer is how many created orders a WatchOrders stream may fall behind
+// before it is ended with ResourceExhausted.
+const watchBuffer = 64
+
 func NewOrderService(
 	createOrderUseCase usecase.CreateOrderUseCase,
 	listOrdersUseCase usecase.ListOrdersUseCase,
+	getOrderHistoryUseCase usecase.GetOrderHistoryUseCase,
+	importOrdersUseCase usecase.ImportOrdersUseCase,
+	streamOrdersUseCase usecase.StreamOrdersUseCase,
+	createOrdersUseCase usecase.CreateOrdersUseCase,
+	getOrderUseCase usecase.GetOrderUseCase,
+	replayEventsUseCase usecase.ReplayOrderEventsUseCase,
+	searchOrdersUseCase usecase.SearchOrdersUseCase,
+	reportOrdersUseCase usecase.ReportOrdersUseCase,
+	deleteOrderUseCase usecase.DeleteOrderUseCase,
+	restoreOrderUseCase usecase.RestoreOrderUseCase,
+	orderBroker *pubsub.Broker[usecase.OrderOutputDTO],
 ) *OrderService {
 	return &OrderService{
-		CreateOrderUseCase: createOrderUseCase,
-		ListOrdersUseCase:  listOrdersUseCase,
+		CreateOrderUseCase:     createOrderUseCase,
+		ListOrdersUseCase:      listOrdersUseCase,
+		GetOrderHistoryUseCase: getOrderHistoryUseCase,
+		ImportOrdersUseCase:    importOrdersUseCase,
+		StreamOrdersUseCase:    streamOrdersUseCase,
+		CreateOrdersUseCase:    createOrdersUseCase,
+		GetOrderUseCase:        getOrderUseCase,
+		ReplayEventsUseCase:    replayEventsUseCase,
+		SearchOrdersUseCase:    searchOrdersUseCase,
+		ReportOrdersUseCase:    reportOrdersUseCase,
+		DeleteOrderUseCase:     deleteOrderUseCase,
+		RestoreOrderUseCase:    restoreOrderUseCase,
+		OrderBroker:            orderBroker,
 	}
 }
 
 func (s *OrderService) CreateOrder(ctx context.Context, in *pb.CreateOrderRequest) (*pb.CreateOrderResponse, error) {
 	dto := usecase.OrderInputDTO{
 		ID:    in.Id,
-		Price: float64(in.Price),
-		Tax:   float64(in.Tax),
+		Price: in.Price,
+		Tax:   in.Tax,
+	}
+	output, err := s.CreateOrderUseCase.Execute(ctx, dto)
+	if errors.Is(err, usecase.ErrInvalidOrder) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if errors.Is(err, entity.ErrOrderAlreadyExists) {
+		return nil, status.Error(codes.AlreadyExists, err.Error())
 	}
-	output, err := s.CreateOrderUseCase.Execute(dto)
 	if err != nil {
 		return nil, err
 	}
 	return &pb.CreateOrderResponse{
 		Id:         output.ID,
-		Price:      float32(output.Price),
-		Tax:        float32(output.Tax),
-		FinalPrice: float32(output.FinalPrice),
+		Price:      output.Price,
+		Tax:        output.Tax,
+		FinalPrice: output.FinalPrice,
 	}, nil
 }
 
 func (s *OrderService) ListOrders(ctx context.Context, in *pb.Blank) (*pb.ListOrdersResponse, error) {
-	output, err := s.ListOrdersUseCase.Execute()
+	output, err := s.ListOrdersUseCase.Execute(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -51,9 +104,9 @@ func (s *OrderService) ListOrders(ctx context.Context, in *pb.Blank) (*pb.ListOr
 	for _, o := range output {
 		orders = append(orders, &pb.Order{
 			Id:         o.ID,
-			Price:      float32(o.Price),
-			Tax:        float32(o.Tax),
-			FinalPrice: float32(o.FinalPrice),
+			Price:      o.Price,
+			Tax:        o.Tax,
+			FinalPrice: o.FinalPrice,
 		})
 	}
 
@@ -61,3 +114,320 @@ func (s *OrderService) ListOrders(ctx context.Context, in *pb.Blank) (*pb.ListOr
 		Orders: orders,
 	}, nil
 }
+
+func (s *OrderService) GetOrder(ctx context.Context, in *pb.GetOrderRequest) (*pb.Order, error) {
+	output, err := s.GetOrderUseCase.Execute(ctx, in.Id)
+	if errors.Is(err, entity.ErrOrderNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Order{
+		Id:         output.ID,
+		Price:      output.Price,
+		Tax:        output.Tax,
+		FinalPrice: output.FinalPrice,
+	}, nil
+}
+
+func (s *OrderService) DeleteOrder(ctx context.Context, in *pb.DeleteOrderRequest) (*pb.Order, error) {
+	output, err := s.DeleteOrderUseCase.Execute(ctx, in.Id)
+	if err != nil {
+		return nil, deletionError(err)
+	}
+	return &pb.Order{
+		Id:         output.ID,
+		Price:      output.Price,
+		Tax:        output.Tax,
+		FinalPrice: output.FinalPrice,
+	}, nil
+}
+
+func (s *OrderService) RestoreOrder(ctx context.Context, in *pb.RestoreOrderRequest) (*pb.Order, error) {
+	output, err := s.RestoreOrderUseCase.Execute(ctx, in.Id)
+	if err != nil {
+		return nil, deletionError(err)
+	}
+	return &pb.Order{
+		Id:         output.ID,
+		Price:      output.Price,
+		Tax:        output.Tax,
+		FinalPrice: output.FinalPrice,
+	}, nil
+}
+
+// deletionError maps the errors of DeleteOrder and RestoreOrder to a status.
+func deletionError(err error) error {
+	switch {
+	case errors.Is(err, entity.ErrOrderNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, entity.ErrOrderDeleted), errors.Is(err, entity.ErrOrderNotDeleted):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return err
+}
+
+func (s *OrderService) SearchOrders(ctx context.Context, in *pb.SearchOrdersRequest) (*pb.SearchOrdersResponse, error) {
+	output, err := s.SearchOrdersUseCase.Execute(ctx, usecase.SearchOrdersInputDTO{
+		Query:     in.Query,
+		PageSize:  int(in.PageSize),
+		PageToken: in.PageToken,
+	})
+	if errors.Is(err, entity.ErrInvalidOrderQuery) || errors.Is(err, usecase.ErrInvalidPageToken) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response := &pb.SearchOrdersResponse{NextPageToken: output.NextPageToken}
+	for _, o := range output.Orders {
+		response.Orders = append(response.Orders, &pb.OrderSearchResult{
+			Id:         o.ID,
+			Price:      o.Price,
+			Tax:        o.Tax,
+			FinalPrice: o.FinalPrice,
+			CreatedAt:  timestamppb.New(o.CreatedAt),
+		})
+	}
+	return response, nil
+}
+
+func (s *OrderService) GetOrderReport(ctx context.Context, in *pb.GetOrderReportRequest) (*pb.GetOrderReportResponse, error) {
+	output, err := s.ReportOrdersUseCase.Execute(ctx, usecase.ReportOrdersInputDTO{
+		From:    in.From,
+		To:      in.To,
+		GroupBy: in.GroupBy,
+	})
+	if errors.Is(err, entity.ErrInvalidOrderReport) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response := &pb.GetOrderReportResponse{GroupBy: output.GroupBy, Total: reportRow(output.Total)}
+	for _, row := range output.Rows {
+		response.Rows = append(response.Rows, reportRow(row))
+	}
+	return response, nil
+}
+
+func reportRow(row usecase.OrderReportRowDTO) *pb.OrderReportRow {
+	return &pb.OrderReportRow{
+		Start:         timestamppb.New(row.Start),
+		End:           timestamppb.New(row.End),
+		Count:         int64(row.Count),
+		Price:         row.Price,
+		Tax:           row.Tax,
+		FinalPrice:    row.FinalPrice,
+		AverageTicket: row.AverageTicket,
+	}
+}
+
+func (s *OrderService) GetOrderHistory(ctx context.Context, in *pb.GetOrderHistoryRequest) (*pb.GetOrderHistoryResponse, error) {
+	output, err := s.GetOrderHistoryUseCase.Execute(ctx, in.Id)
+	if errors.Is(err, entity.ErrOrderNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*pb.OrderAuditEntry
+	for _, e := range output {
+		entries = append(entries, &pb.OrderAuditEntry{
+			Action:    e.Action,
+			Actor:     e.Actor,
+			Transport: e.Transport,
+			RequestId: e.RequestID,
+			Before:    orderState(e.Before),
+			After:     orderState(e.After),
+			Timestamp: timestamppb.New(e.Timestamp),
+		})
+	}
+
+	return &pb.GetOrderHistoryResponse{
+		Entries: entries,
+	}, nil
+}
+
+func orderState(state *usecase.OrderStateDTO) *pb.OrderState {
+	if state == nil {
+		return nil
+	}
+	return &pb.OrderState{
+		Price:      state.Price,
+		Tax:        state.Tax,
+		FinalPrice: state.FinalPrice,
+	}
+}
+
+// ImportOrders receives one order per message and answers with the import
+// report once the client closes the stream.
+func (s *OrderService) ImportOrders(stream pb.OrderService_ImportOrdersServer) error {
+	rows, first, err := newStreamRows(stream.Recv, func(in *pb.ImportOrdersRequest) usecase.OrderInputDTO {
+		return usecase.OrderInputDTO{ID: in.Id, Price: in.Price, Tax: in.Tax}
+	})
+	if err != nil {
+		return err
+	}
+	output, err := s.ImportOrdersUseCase.Execute(stream.Context(), rows, first.GetDryRun())
+	if err != nil {
+		return statusOf(err)
+	}
+
+	return stream.SendAndClose(&pb.ImportOrdersResponse{
+		Rows:     int32(output.Rows),
+		Imported: int32(output.Imported),
+		Failed:   int32(output.Failed),
+		DryRun:   output.DryRun,
+		Errors:   rowErrors(output.Errors),
+	})
+}
+
+// StreamOrders sends the orders one page per message.
+func (s *OrderService) StreamOrders(in *pb.StreamOrdersRequest, stream pb.OrderService_StreamOrdersServer) error {
+	page := int32(0)
+	err := s.StreamOrdersUseCase.Execute(stream.Context(), int(in.PageSize), func(output []usecase.ListOrdersOutputDTO) error {
+		page++
+		orders := make([]*pb.Order, 0, len(output))
+		for _, o := range output {
+			orders = append(orders, &pb.Order{
+				Id:         o.ID,
+				Price:      o.Price,
+				Tax:        o.Tax,
+				FinalPrice: o.FinalPrice,
+			})
+		}
+		return stream.Send(&pb.OrdersPage{Page: page, Orders: orders})
+	})
+	return statusOf(err)
+}
+
+// CreateOrdersStream creates the orders sent by the client in batches and
+// answers with the ones created once the client closes the stream.
+func (s *OrderService) CreateOrdersStream(stream pb.OrderService_CreateOrdersStreamServer) error {
+	rows, _, err := newStreamRows(stream.Recv, func(in *pb.CreateOrderRequest) usecase.OrderInputDTO {
+		return usecase.OrderInputDTO{ID: in.Id, Price: in.Price, Tax: in.Tax}
+	})
+	if err != nil {
+		return err
+	}
+	output, err := s.CreateOrdersUseCase.Execute(stream.Context(), rows)
+	if err != nil {
+		return statusOf(err)
+	}
+
+	response := &pb.CreateOrdersStreamResponse{
+		Failed: int32(output.Failed),
+		Errors: rowErrors(output.Errors),
+	}
+	for _, o := range output.Orders {
+		response.Orders = append(response.Orders, &pb.CreateOrderResponse{
+			Id:         o.ID,
+			Price:      o.Price,
+			Tax:        o.Tax,
+			FinalPrice: o.FinalPrice,
+		})
+	}
+	return stream.SendAndClose(response)
+}
+
+// WatchOrders pushes every order created after the call until the client
+// goes away. The response header is sent once the subscription is open, so a
+// client that waits for it doesn't miss any order created afterwards.
+func (s *OrderService) WatchOrders(in *pb.WatchOrdersRequest, stream pb.OrderService_WatchOrdersServer) error {
+	subscription := s.OrderBroker.Subscribe(watchBuffer)
+	defer subscription.Close()
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return statusOf(stream.Context().Err())
+		case o, ok := <-subscription.C:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, subscription.Err().Error())
+			}
+			err := stream.Send(&pb.Order{
+				Id:         o.ID,
+				Price:      o.Price,
+				Tax:        o.Tax,
+				FinalPrice: o.FinalPrice,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// ReplayEvents streams the recorded events, of one order or of all of them.
+func (s *OrderService) ReplayEvents(in *pb.ReplayEventsRequest, stream pb.OrderService_ReplayEventsServer) error {
+	err := s.ReplayEventsUseCase.Execute(stream.Context(), in.OrderId, func(event usecase.OrderEventOutputDTO) error {
+		return stream.Send(&pb.OrderEvent{
+			OrderId:   event.OrderID,
+			Version:   int32(event.Version),
+			Type:      event.Type,
+			Data:      orderState(&event.Data),
+			Timestamp: timestamppb.New(event.Timestamp),
+		})
+	})
+	return statusOf(err)
+}
+
+// statusOf turns context errors into the Canceled / DeadlineExceeded status.
+func statusOf(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	return err
+}
+
+func rowErrors(errs []usecase.ImportRowErrorDTO) []*pb.ImportRowError {
+	var rows []*pb.ImportRowError
+	for _, e := range errs {
+		rows = append(rows, &pb.ImportRowError{
+			Row:   int32(e.Row),
+			Id:    e.ID,
+			Error: e.Error,
+		})
+	}
+	return rows
+}
+
+// streamRows reads a client stream as usecase.OrderInputReader. The first
+// message is received up front, so the RPC can read its options.
+type streamRows[T any] struct {
+	recv    func() (*T, error)
+	convert func(in *T) usecase.OrderInputDTO
+	first   *T
+	eof     bool
+}
+
+func newStreamRows[T any](recv func() (*T, error), convert func(in *T) usecase.OrderInputDTO) (*streamRows[T], *T, error) {
+	first, err := recv()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, err
+	}
+	return &streamRows[T]{recv: recv, convert: convert, first: first, eof: first == nil}, first, nil
+}
+
+func (r *streamRows[T]) Read() (usecase.OrderInputDTO, error) {
+	if r.eof {
+		return usecase.OrderInputDTO{}, io.EOF
+	}
+	in := r.first
+	r.first = nil
+	if in == nil {
+		var err error
+		if in, err = r.recv(); err != nil {
+			return usecase.OrderInputDTO{}, err
+		}
+	}
+	return r.convert(in), nil
+}
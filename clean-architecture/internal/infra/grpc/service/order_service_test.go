@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/event/handler"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/database"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/grpc/pb"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/pubsub"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/requestctx"
+	"github.com/alexandreti/posGoExpert/clean-architecture/sql/migrations"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	// sqlite3
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// OrderServiceTestSuite runs the service over bufconn, on top of SQLite.
+type OrderServiceTestSuite struct {
+	suite.Suite
+	Db      *sql.DB
+	Broker  *pubsub.Broker[usecase.OrderOutputDTO]
+	Server  *grpc.Server
+	Conn    *grpc.ClientConn
+	Client  pb.OrderServiceClient
+	Views   *database.OrderViewRepository
+	Service *OrderService
+	// StreamCodes receives the code each streaming RPC returned on the server.
+	StreamCodes chan codes.Code
+}
+
+func (suite *OrderServiceTestSuite) SetupTest() {
+	db, err := sql.Open("sqlite3", ":memory:")
+	suite.Require().NoError(err)
+	db.SetMaxOpenConns(1)
+	source, err := migrations.Source(database.SQLite.Name())
+	suite.Require().NoError(err)
+	_, err = database.NewMigrator(db, database.SQLite, source).Up(context.Background())
+	suite.Require().NoError(err)
+	suite.Db = db
+
+	orders := database.NewOrderRepository(db, database.SQLite)
+	views := database.NewOrderViewRepository(db, database.SQLite)
+	suite.Broker = pubsub.NewBroker[usecase.OrderOutputDTO]()
+	dispatcher := events.NewEventDispatcher()
+	dispatcher.Register("OrderCreated", handler.NewOrderProjectionHandler(views))
+	dispatcher.Register("OrderCreated", handler.NewOrderBroadcastHandler(suite.Broker))
+	dispatcher.Register("OrderDeleted", handler.NewOrderProjectionHandler(views))
+	dispatcher.Register("OrderRestored", handler.NewOrderProjectionHandler(views))
+	importOrders := usecase.NewImportOrdersUseCase(orders, dispatcher)
+	importOrders.BatchSize = 2
+
+	suite.Views = views
+	suite.Service = NewOrderService(
+		*usecase.NewCreateOrderUseCase(orders, dispatcher),
+		*usecase.NewListOrdersUseCase(views),
+		*usecase.NewGetOrderHistoryUseCase(database.NewOrderAuditRepository(db, database.SQLite)),
+		*importOrders,
+		*usecase.NewStreamOrdersUseCase(views),
+		*usecase.NewCreateOrdersUseCase(importOrders),
+		*usecase.NewGetOrderUseCase(views),
+		*usecase.NewReplayOrderEventsUseCase(orders),
+		*usecase.NewSearchOrdersUseCase(views),
+		*usecase.NewReportOrdersUseCase(orders),
+		*usecase.NewDeleteOrderUseCase(orders, dispatcher),
+		*usecase.NewRestoreOrderUseCase(orders, dispatcher),
+		suite.Broker,
+	)
+
+	streamCodes := make(chan codes.Code, 10)
+	suite.StreamCodes = streamCodes
+	suite.Server = grpc.NewServer(grpc.ChainUnaryInterceptor(requestctx.UnaryServerInterceptor()), grpc.ChainStreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		streamCodes <- status.Code(err)
+		return err
+	}))
+	pb.RegisterOrderServiceServer(suite.Server, suite.Service)
+	listener := bufconn.Listen(1024 * 1024)
+	go suite.Server.Serve(listener)
+
+	suite.Conn, err = grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	suite.Require().NoError(err)
+	suite.Client = pb.NewOrderServiceClient(suite.Conn)
+}
+
+func (suite *OrderServiceTestSuite) TearDownTest() {
+	suite.Conn.Close()
+	suite.Server.Stop()
+	suite.Db.Close()
+}
+
+func TestOrderServiceSuite(t *testing.T) {
+	suite.Run(t, new(OrderServiceTestSuite))
+}
+
+func (suite *OrderServiceTestSuite) createOrders(n int) {
+	for i := 1; i <= n; i++ {
+		_, err := suite.Client.CreateOrder(context.Background(), &pb.CreateOrderRequest{Id: fmt.Sprintf("%02d", i), Price: float64(i * 10), Tax: 1})
+		suite.Require().NoError(err)
+	}
+}
+
+func (suite *OrderServiceTestSuite) serverCode() codes.Code {
+	select {
+	case code := <-suite.StreamCodes:
+		return code
+	case <-time.After(5 * time.Second):
+		suite.FailNow("the streaming RPC didn't return")
+		return codes.Unknown
+	}
+}
+
+func (suite *OrderServiceTestSuite) TestGivenFiveOrders_WhenStreamOrdersByTwo_ThenShouldReceiveThreePages() {
+	suite.createOrders(5)
+
+	stream, err := suite.Client.StreamOrders(context.Background(), &pb.StreamOrdersRequest{PageSize: 2})
+	suite.Require().NoError(err)
+	var pages [][]string
+	for {
+		page, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		suite.Require().NoError(err)
+		suite.Equal(int32(len(pages)+1), page.Page)
+		var ids []string
+		for _, order := range page.Orders {
+			ids = append(ids, order.Id)
+		}
+		pages = append(pages, ids)
+	}
+	suite.Equal([][]string{{"01", "02"}, {"03", "04"}, {"05"}}, pages)
+	suite.Equal(codes.OK, suite.serverCode())
+}
+
+// blockingViews serves the first page and then waits for the call to end.
+type blockingViews struct {
+	*database.OrderViewRepository
+}
+
+func (v *blockingViews) ListOrdersPage(ctx context.Context, afterID string, limit int) ([]entity.OrderView, error) {
+	if afterID != "" {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return v.OrderViewRepository.ListOrdersPage(ctx, afterID, limit)
+}
+
+func (suite *OrderServiceTestSuite) TestGivenACanceledCall_WhenStreamOrders_ThenTheServerShouldStop() {
+	suite.createOrders(3)
+	suite.Service.StreamOrdersUseCase = *usecase.NewStreamOrdersUseCase(&blockingViews{suite.Views})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stream, err := suite.Client.StreamOrders(ctx, &pb.StreamOrdersRequest{PageSize: 1})
+	suite.Require().NoError(err)
+	_, err = stream.Recv()
+	suite.Require().NoError(err)
+	cancel()
+
+	_, err = stream.Recv()
+	suite.Equal(codes.Canceled, status.Code(err))
+	suite.Equal(codes.Canceled, suite.serverCode())
+}
+
+func (suite *OrderServiceTestSuite) TestGivenADeadline_WhenStreamOrders_ThenShouldReturnDeadlineExceeded() {
+	suite.createOrders(3)
+	suite.Service.StreamOrdersUseCase = *usecase.NewStreamOrdersUseCase(&blockingViews{suite.Views})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	stream, err := suite.Client.StreamOrders(ctx, &pb.StreamOrdersRequest{PageSize: 1})
+	suite.Require().NoError(err)
+	_, err = stream.Recv()
+	suite.Require().NoError(err)
+
+	_, err = stream.Recv()
+	suite.Equal(codes.DeadlineExceeded, status.Code(err))
+	suite.Contains([]codes.Code{codes.DeadlineExceeded, codes.Canceled}, suite.serverCode())
+}
+
+func (suite *OrderServiceTestSuite) TestGivenAStreamOfOrders_WhenCreateOrdersStream_ThenShouldCreateTheValidOnes() {
+	stream, err := suite.Client.CreateOrdersStream(context.Background())
+	suite.Require().NoError(err)
+	for _, request := range []*pb.CreateOrderRequest{
+		{Id: "a", Price: 10, Tax: 1},
+		{Id: "b", Price: 0, Tax: 1},
+		{Id: "c", Price: 20, Tax: 2},
+		{Id: "d", Price: 30, Tax: 3},
+	} {
+		suite.Require().NoError(stream.Send(request))
+	}
+	response, err := stream.CloseAndRecv()
+	suite.Require().NoError(err)
+
+	suite.Len(response.Orders, 3)
+	suite.Equal(22.0, response.Orders[1].FinalPrice)
+	suite.Equal(int32(1), response.Failed)
+	suite.Equal(int32(2), response.Errors[0].Row)
+
+	list, err := suite.Client.ListOrders(context.Background(), &pb.Blank{})
+	suite.NoError(err)
+	suite.Len(list.Orders, 3)
+}
+
+func (suite *OrderServiceTestSuite) TestGivenACanceledCall_WhenCreateOrdersStream_ThenShouldCreateNothing() {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := suite.Client.CreateOrdersStream(ctx)
+	suite.Require().NoError(err)
+	suite.Require().NoError(stream.Send(&pb.CreateOrderRequest{Id: "a", Price: 10, Tax: 1}))
+	cancel()
+
+	_, err = stream.CloseAndRecv()
+	suite.Equal(codes.Canceled, status.Code(err))
+	suite.Equal(codes.Canceled, suite.serverCode())
+	list, err := suite.Client.ListOrders(context.Background(), &pb.Blank{})
+	suite.NoError(err)
+	suite.Empty(list.Orders)
+}
+
+func (suite *OrderServiceTestSuite) TestGivenAWatcher_WhenAnOrderIsCreated_ThenShouldReceiveIt() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := suite.Client.WatchOrders(ctx, &pb.WatchOrdersRequest{})
+	suite.Require().NoError(err)
+	_, err = stream.Header()
+	suite.Require().NoError(err)
+
+	suite.createOrders(2)
+
+	for _, id := range []string{"01", "02"} {
+		order, err := stream.Recv()
+		suite.Require().NoError(err)
+		suite.Equal(id, order.Id)
+	}
+}
+
+func (suite *OrderServiceTestSuite) TestGivenADeadline_WhenWatchOrders_ThenShouldEndAndUnsubscribe() {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	stream, err := suite.Client.WatchOrders(ctx, &pb.WatchOrdersRequest{})
+	suite.Require().NoError(err)
+	_, err = stream.Header()
+	suite.Require().NoError(err)
+	suite.Equal(1, suite.Broker.Subscribers())
+
+	_, err = stream.Recv()
+	suite.Equal(codes.DeadlineExceeded, status.Code(err))
+	suite.Contains([]codes.Code{codes.DeadlineExceeded, codes.Canceled}, suite.serverCode())
+	suite.Equal(0, suite.Broker.Subscribers())
+}
+
+func (suite *OrderServiceTestSuite) TestGivenAnOrder_WhenGetOrder_ThenShouldReturnItOrNotFound() {
+	suite.createOrders(2)
+
+	order, err := suite.Client.GetOrder(context.Background(), &pb.GetOrderRequest{Id: "02"})
+	suite.Require().NoError(err)
+	suite.Equal(21.0, order.FinalPrice)
+
+	_, err = suite.Client.GetOrder(context.Background(), &pb.GetOrderRequest{Id: "missing"})
+	suite.Equal(codes.NotFound, status.Code(err))
+}
+
+func (suite *OrderServiceTestSuite) TestGivenAnOrder_WhenDeleteAndRestore_ThenShouldHideItOnlyWhileDeleted() {
+	suite.createOrders(2)
+	ctx := context.Background()
+
+	order, err := suite.Client.DeleteOrder(ctx, &pb.DeleteOrderRequest{Id: "02"})
+	suite.Require().NoError(err)
+	suite.Equal(21.0, order.FinalPrice)
+	_, err = suite.Client.GetOrder(ctx, &pb.GetOrderRequest{Id: "02"})
+	suite.Equal(codes.NotFound, status.Code(err))
+	_, err = suite.Client.DeleteOrder(ctx, &pb.DeleteOrderRequest{Id: "02"})
+	suite.Equal(codes.FailedPrecondition, status.Code(err))
+
+	_, err = suite.Client.RestoreOrder(ctx, &pb.RestoreOrderRequest{Id: "02"})
+	suite.Require().NoError(err)
+	_, err = suite.Client.GetOrder(ctx, &pb.GetOrderRequest{Id: "02"})
+	suite.NoError(err)
+	_, err = suite.Client.RestoreOrder(ctx, &pb.RestoreOrderRequest{Id: "02"})
+	suite.Equal(codes.FailedPrecondition, status.Code(err))
+	_, err = suite.Client.DeleteOrder(ctx, &pb.DeleteOrderRequest{Id: "missing"})
+	suite.Equal(codes.NotFound, status.Code(err))
+}
+
+func (suite *OrderServiceTestSuite) TestGivenOrders_WhenReplayEventsOfOne_ThenShouldReceiveOnlyItsEvents() {
+	suite.createOrders(3)
+
+	stream, err := suite.Client.ReplayEvents(context.Background(), &pb.ReplayEventsRequest{OrderId: "02"})
+	suite.Require().NoError(err)
+	var events []*pb.OrderEvent
+	for {
+		event, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		suite.Require().NoError(err)
+		events = append(events, event)
+	}
+	suite.Require().Len(events, 1)
+	suite.Equal("OrderCreated", events[0].Type)
+	suite.Equal(21.0, events[0].Data.FinalPrice)
+	suite.Equal(codes.OK, suite.serverCode())
+}
+
+func (suite *OrderServiceTestSuite) TestGivenOrders_WhenSearchOrders_ThenShouldPageThroughTheMatches() {
+	suite.createOrders(5)
+
+	response, err := suite.Client.SearchOrders(context.Background(), &pb.SearchOrdersRequest{Query: "price>=20 id:0*", PageSize: 3})
+	suite.Require().NoError(err)
+	suite.Len(response.Orders, 3)
+	suite.Equal("02", response.Orders[0].Id)
+	suite.False(response.Orders[0].CreatedAt.AsTime().IsZero())
+
+	response, err = suite.Client.SearchOrders(context.Background(), &pb.SearchOrdersRequest{Query: "price>=20 id:0*", PageSize: 3, PageToken: response.NextPageToken})
+	suite.Require().NoError(err)
+	suite.Len(response.Orders, 1)
+	suite.Equal("05", response.Orders[0].Id)
+	suite.Empty(response.NextPageToken)
+
+	_, err = suite.Client.SearchOrders(context.Background(), &pb.SearchOrdersRequest{Query: "status:paid"})
+	suite.Equal(codes.InvalidArgument, status.Code(err))
+}
+
+func (suite *OrderServiceTestSuite) TestGivenOrders_WhenGetOrderReport_ThenShouldSumThemByPeriod() {
+	suite.createOrders(3)
+	today := time.Now().UTC().Format("2006-01-02")
+
+	response, err := suite.Client.GetOrderReport(context.Background(), &pb.GetOrderReportRequest{From: today, To: today})
+	suite.Require().NoError(err)
+	suite.Equal("day", response.GroupBy)
+	suite.Require().Len(response.Rows, 1)
+	suite.Equal(int64(3), response.Rows[0].Count)
+	suite.Equal(60.0, response.Rows[0].Price)
+	suite.Equal(63.0, response.Total.FinalPrice)
+	suite.Equal(21.0, response.Total.AverageTicket)
+
+	_, err = suite.Client.GetOrderReport(context.Background(), &pb.GetOrderReportRequest{From: today, To: today, GroupBy: "year"})
+	suite.Equal(codes.InvalidArgument, status.Code(err))
+}
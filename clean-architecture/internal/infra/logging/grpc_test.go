@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/requestctx"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestGivenAFailingCall_WhenCallGRPC_ThenShouldLogTheMethodCodeAndPeer(t *testing.T) {
+	var buffer bytes.Buffer
+	logger, err := New(&buffer, "info", "json")
+	assert.Nil(t, err)
+	interceptor := UnaryServerInterceptor(logger)
+	ctx := requestctx.WithRequestID(context.Background(), "abc")
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}})
+
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/pb.OrderService/GetOrderHistory"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "order not found")
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	var entry map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &entry))
+	assert.Equal(t, "grpc request", entry["msg"])
+	assert.Equal(t, "/pb.OrderService/GetOrderHistory", entry["method"])
+	assert.Equal(t, "NotFound", entry["code"])
+	assert.Equal(t, "order not found", entry["error"])
+	assert.Equal(t, "10.0.0.1:5000", entry["remote_addr"])
+	assert.Equal(t, "abc", entry["request_id"])
+	assert.Contains(t, entry, "duration")
+}
+
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestGivenAStream_WhenItEnds_ThenShouldLogItOnce(t *testing.T) {
+	var buffer bytes.Buffer
+	logger, err := New(&buffer, "info", "json")
+	assert.Nil(t, err)
+	interceptor := StreamServerInterceptor(logger)
+
+	err = interceptor(nil, contextStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: "/pb.OrderService/StreamOrders"}, func(srv interface{}, stream grpc.ServerStream) error {
+		return nil
+	})
+	assert.Nil(t, err)
+
+	var entry map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &entry))
+	assert.Equal(t, "grpc stream", entry["msg"])
+	assert.Equal(t, "OK", entry["code"])
+	assert.NotContains(t, entry, "error")
+}
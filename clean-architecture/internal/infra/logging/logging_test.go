@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/requestctx"
+
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestGivenARequestID_WhenLog_ThenShouldWriteItAsJSON(t *testing.T) {
+	var buffer bytes.Buffer
+	logger, err := New(&buffer, "info", "json")
+	assert.Nil(t, err)
+
+	logger.InfoContext(requestctx.WithRequestID(context.Background(), "abc"), "order created")
+
+	var entry map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &entry))
+	assert.Equal(t, "order created", entry["msg"])
+	assert.Equal(t, "abc", entry["request_id"])
+	assert.NotContains(t, entry, "trace_id")
+}
+
+func TestGivenASpan_WhenLog_ThenShouldWriteTheTraceID(t *testing.T) {
+	var buffer bytes.Buffer
+	logger, err := New(&buffer, "info", "json")
+	assert.Nil(t, err)
+
+	ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "test")
+	logger.InfoContext(ctx, "order created")
+	span.End()
+
+	var entry map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &entry))
+	assert.Equal(t, span.SpanContext().TraceID().String(), entry["trace_id"])
+	assert.Equal(t, span.SpanContext().SpanID().String(), entry["span_id"])
+}
+
+func TestGivenALevel_WhenLogBelowIt_ThenShouldSkipTheEntry(t *testing.T) {
+	var buffer bytes.Buffer
+	logger, err := New(&buffer, "warn", "text")
+	assert.Nil(t, err)
+
+	logger.Info("ignored")
+	assert.Empty(t, buffer.String())
+	logger.Warn("written")
+	assert.Contains(t, buffer.String(), "msg=written")
+}
+
+func TestGivenAnInvalidLevelOrFormat_WhenNew_ThenShouldReturnAnError(t *testing.T) {
+	_, err := New(&bytes.Buffer{}, "verbose", "json")
+	assert.Error(t, err)
+	_, err = New(&bytes.Buffer{}, "info", "xml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,212 @@
+// Package orderio reads and writes orders as CSV or NDJSON, for the bulk
+// import and export endpoints.
+package orderio
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+)
+
+const (
+	CSV    = "csv"
+	NDJSON = "ndjson"
+)
+
+// ErrUnsupportedFormat is returned for a format or media type other than CSV
+// and NDJSON.
+var ErrUnsupportedFormat = errors.New("unsupported format, use csv or ndjson")
+
+// ContentTypes maps each format to the media type it is served with.
+var ContentTypes = map[string]string{
+	CSV:    "text/csv",
+	NDJSON: "application/x-ndjson",
+}
+
+// FormatOf returns the format of a media type such as a Content-Type header.
+func FormatOf(mediaType string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return "", ErrUnsupportedFormat
+	}
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return CSV, nil
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/json-lines":
+		return NDJSON, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+// NewReader returns a reader of orders in format. A CSV input must start with
+// a header naming the id, price and tax columns, in any order.
+func NewReader(format string, r io.Reader) (usecase.OrderInputReader, error) {
+	switch format {
+	case CSV:
+		return newCSVReader(r)
+	case NDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		return &ndjsonReader{scanner: scanner}, nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+type csvReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("empty csv, the header is missing")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading csv header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"id", "price", "tax"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("csv header has no %q column", name)
+		}
+	}
+	return &csvReader{reader: reader, columns: columns}, nil
+}
+
+func (r *csvReader) Read() (usecase.OrderInputDTO, error) {
+	record, err := r.reader.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return usecase.OrderInputDTO{}, fmt.Errorf("%w: %v", usecase.ErrMalformedRow, parseErr.Err)
+	}
+	if err != nil {
+		return usecase.OrderInputDTO{}, err
+	}
+
+	field := func(name string) string {
+		if i := r.columns[name]; i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	input := usecase.OrderInputDTO{ID: field("id")}
+	if input.Price, err = parseFloat("price", field("price")); err != nil {
+		return input, err
+	}
+	if input.Tax, err = parseFloat("tax", field("tax")); err != nil {
+		return input, err
+	}
+	return input, nil
+}
+
+func parseFloat(name, value string) (float64, error) {
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid %s %q", usecase.ErrMalformedRow, name, value)
+	}
+	return number, nil
+}
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+}
+
+// Read skips blank lines, so a trailing line break isn't a row.
+func (r *ndjsonReader) Read() (usecase.OrderInputDTO, error) {
+	for r.scanner.Scan() {
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" {
+			continue
+		}
+		var input usecase.OrderInputDTO
+		if err := json.Unmarshal([]byte(line), &input); err != nil {
+			return usecase.OrderInputDTO{}, fmt.Errorf("%w: %v", usecase.ErrMalformedRow, err)
+		}
+		return input, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return usecase.OrderInputDTO{}, err
+	}
+	return usecase.OrderInputDTO{}, io.EOF
+}
+
+// Writer writes orders in one of the formats. Flush must be called after the
+// last one.
+type Writer interface {
+	Write(order usecase.ListOrdersOutputDTO) error
+	Flush() error
+}
+
+// NewWriter returns a writer of orders in format; CSV starts with the header
+// id,price,tax,final_price.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case CSV:
+		return &csvWriter{writer: csv.NewWriter(w)}, nil
+	case NDJSON:
+		buffered := bufio.NewWriter(w)
+		return &ndjsonWriter{buffered: buffered, encoder: json.NewEncoder(buffered)}, nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+type csvWriter struct {
+	writer      *csv.Writer
+	wroteHeader bool
+}
+
+func (w *csvWriter) Write(order usecase.ListOrdersOutputDTO) error {
+	if err := w.header(); err != nil {
+		return err
+	}
+	return w.writer.Write([]string{order.ID, formatFloat(order.Price), formatFloat(order.Tax), formatFloat(order.FinalPrice)})
+}
+
+// Flush writes the header even when there were no orders.
+func (w *csvWriter) Flush() error {
+	if err := w.header(); err != nil {
+		return err
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+func (w *csvWriter) header() error {
+	if w.wroteHeader {
+		return nil
+	}
+	w.wroteHeader = true
+	return w.writer.Write([]string{"id", "price", "tax", "final_price"})
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+type ndjsonWriter struct {
+	buffered *bufio.Writer
+	encoder  *json.Encoder
+}
+
+func (w *ndjsonWriter) Write(order usecase.ListOrdersOutputDTO) error {
+	return w.encoder.Encode(order)
+}
+
+func (w *ndjsonWriter) Flush() error {
+	return w.buffered.Flush()
+}
@@ -0,0 +1,83 @@
+package orderio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAll(t *testing.T, reader usecase.OrderInputReader) ([]usecase.OrderInputDTO, []error) {
+	var inputs []usecase.OrderInputDTO
+	var errs []error
+	for {
+		input, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return inputs, errs
+		}
+		require.True(t, err == nil || errors.Is(err, usecase.ErrMalformedRow), "unexpected error %v", err)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		inputs = append(inputs, input)
+	}
+}
+
+func TestGivenACSV_WhenRead_ThenShouldMapColumnsByHeader(t *testing.T) {
+	reader, err := NewReader(CSV, strings.NewReader("Tax,ID,Price\n0.5,a,100.5\n2,b,abc\n1,c,10\n"))
+	require.NoError(t, err)
+
+	inputs, errs := readAll(t, reader)
+	assert.Equal(t, []usecase.OrderInputDTO{{ID: "a", Price: 100.5, Tax: 0.5}, {ID: "c", Price: 10, Tax: 1}}, inputs)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), `invalid price "abc"`)
+}
+
+func TestGivenACSVWithoutTheTaxColumn_WhenNewReader_ThenShouldReturnAnError(t *testing.T) {
+	_, err := NewReader(CSV, strings.NewReader("id,price\na,10\n"))
+	assert.ErrorContains(t, err, `"tax"`)
+}
+
+func TestGivenNDJSON_WhenRead_ThenShouldSkipBlankLinesAndReportBadOnes(t *testing.T) {
+	reader, err := NewReader(NDJSON, strings.NewReader("{\"id\":\"a\",\"price\":10,\"tax\":1}\n\n{bad\n{\"id\":\"b\",\"price\":20,\"tax\":2}\n"))
+	require.NoError(t, err)
+
+	inputs, errs := readAll(t, reader)
+	assert.Equal(t, []usecase.OrderInputDTO{{ID: "a", Price: 10, Tax: 1}, {ID: "b", Price: 20, Tax: 2}}, inputs)
+	assert.Len(t, errs, 1)
+}
+
+func TestGivenOrders_WhenWrite_ThenShouldEncodeEachFormat(t *testing.T) {
+	orders := []usecase.ListOrdersOutputDTO{{ID: "a", Price: 100.5, Tax: 0.5, FinalPrice: 101}}
+	for format, expected := range map[string]string{
+		CSV:    "id,price,tax,final_price\na,100.5,0.5,101\n",
+		NDJSON: "{\"id\":\"a\",\"price\":100.5,\"tax\":0.5,\"final_price\":101}\n",
+	} {
+		var buf bytes.Buffer
+		writer, err := NewWriter(format, &buf)
+		require.NoError(t, err)
+		for _, order := range orders {
+			require.NoError(t, writer.Write(order))
+		}
+		require.NoError(t, writer.Flush())
+		assert.Equal(t, expected, buf.String(), format)
+	}
+}
+
+func TestGivenMediaTypes_WhenFormatOf_ThenShouldMapThem(t *testing.T) {
+	format, err := FormatOf("text/csv; charset=utf-8")
+	assert.NoError(t, err)
+	assert.Equal(t, CSV, format)
+	format, err = FormatOf("application/x-ndjson")
+	assert.NoError(t, err)
+	assert.Equal(t, NDJSON, format)
+	_, err = FormatOf("application/xml")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
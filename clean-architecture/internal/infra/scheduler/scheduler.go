@@ -0,0 +1,109 @@
+// Package scheduler runs background jobs on a fixed interval. Every replica of
+// the service starts the same jobs; a lease taken in the database before each
+// run makes only one of them actually run it.
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+)
+
+// Job runs Run every Interval while holding the lease named Name. Holder
+// identifies the replica. The lease lasts LeaseTTL (twice the interval when
+// zero), so when its holder goes away another replica takes over once it
+// expires. While Run runs, the lease is renewed every third of LeaseTTL; the
+// context of Run is canceled when the lease can't be kept, because another
+// replica took it or it couldn't be renewed before it expired, so Run must
+// return once its context is done.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	LeaseTTL time.Duration
+	Leases   entity.LeaseRepositoryInterface
+	Holder   string
+	Run      func(ctx context.Context) error
+}
+
+// Start runs the job until ctx is canceled. The first run happens after one
+// interval. Errors are logged and don't stop the job.
+func (j *Job) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.tick(ctx)
+		}
+	}
+}
+
+// tick runs the job once if the lease could be taken, and tells whether it ran.
+func (j *Job) tick(ctx context.Context) bool {
+	ttl := j.LeaseTTL
+	if ttl <= 0 {
+		ttl = 2 * j.Interval
+	}
+	expires := time.Now().Add(ttl)
+	acquired, err := j.Leases.Acquire(ctx, j.Name, j.Holder, ttl)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to acquire the job lease", slog.String("job", j.Name), slog.Any("error", err))
+		return false
+	}
+	if !acquired {
+		slog.DebugContext(ctx, "job lease held by another replica", slog.String("job", j.Name))
+		return false
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	held := make(chan struct{})
+	go func() {
+		defer close(held)
+		j.holdLease(runCtx, ttl, expires, cancel)
+	}()
+	err = j.Run(runCtx)
+	cancel()
+	<-held
+	if err != nil {
+		slog.ErrorContext(ctx, "job failed", slog.String("job", j.Name), slog.Any("error", err))
+	}
+	return true
+}
+
+// holdLease renews the lease, which expires at expires, every third of ttl
+// until ctx is done, and calls lost once it can't be sure to hold it anymore.
+// A failed renewal is retried while the lease still outlasts the next one.
+func (j *Job) holdLease(ctx context.Context, ttl time.Duration, expires time.Time, lost context.CancelFunc) {
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		renewed := time.Now()
+		acquired, err := j.Leases.Acquire(ctx, j.Name, j.Holder, ttl)
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err == nil && acquired:
+			expires = renewed.Add(ttl)
+			continue
+		case err == nil:
+			slog.WarnContext(ctx, "job lease taken by another replica, stopping the run", slog.String("job", j.Name))
+		case time.Until(expires) > ttl/3:
+			slog.ErrorContext(ctx, "failed to renew the job lease", slog.String("job", j.Name), slog.Any("error", err))
+			continue
+		default:
+			slog.ErrorContext(ctx, "failed to renew the job lease before it expires, stopping the run",
+				slog.String("job", j.Name), slog.Any("error", err))
+		}
+		lost()
+		return
+	}
+}
@@ -0,0 +1,156 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// LeasesStub hands the lease to Holder only.
+type LeasesStub struct {
+	mu     sync.Mutex
+	Holder string
+	Err    error
+	TTLs   []time.Duration
+}
+
+func (l *LeasesStub) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.TTLs = append(l.TTLs, ttl)
+	return holder == l.Holder, l.Err
+}
+
+// set changes the answer of the next calls, while the lease may be renewed.
+func (l *LeasesStub) set(holder string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.Holder, l.Err = holder, err
+}
+
+func (l *LeasesStub) calls() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.TTLs)
+}
+
+type SchedulerTestSuite struct {
+	suite.Suite
+	leases *LeasesStub
+	runs   int
+	job    *Job
+}
+
+func (suite *SchedulerTestSuite) SetupTest() {
+	suite.leases = &LeasesStub{Holder: "replica-1"}
+	suite.runs = 0
+	suite.job = &Job{
+		Name:     "job",
+		Interval: 10 * time.Millisecond,
+		Leases:   suite.leases,
+		Holder:   "replica-1",
+		Run: func(ctx context.Context) error {
+			suite.runs++
+			return nil
+		},
+	}
+}
+
+func TestSchedulerSuite(t *testing.T) {
+	suite.Run(t, new(SchedulerTestSuite))
+}
+
+func (suite *SchedulerTestSuite) TestGivenTheLease_WhenTick_ThenShouldRunTheJob() {
+	suite.True(suite.job.tick(context.Background()))
+	suite.Equal(1, suite.runs)
+	suite.Equal([]time.Duration{20 * time.Millisecond}, suite.leases.TTLs)
+}
+
+func (suite *SchedulerTestSuite) TestGivenTheLeaseHeldElsewhere_WhenTick_ThenShouldSkipTheRun() {
+	suite.job.Holder = "replica-2"
+	suite.False(suite.job.tick(context.Background()))
+
+	suite.job.Holder = "replica-1"
+	suite.leases.Err = errors.New("db down")
+	suite.False(suite.job.tick(context.Background()))
+	suite.Equal(0, suite.runs)
+}
+
+func (suite *SchedulerTestSuite) TestGivenACanceledContext_WhenStart_ThenShouldReturn() {
+	ctx, cancel := context.WithCancel(context.Background())
+	suite.job.Run = func(ctx context.Context) error {
+		cancel()
+		return errors.New("failed")
+	}
+	done := make(chan struct{})
+	go func() {
+		suite.job.Start(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		suite.Fail("Start didn't return")
+	}
+}
+
+func (suite *SchedulerTestSuite) TestGivenARunLongerThanTheLease_WhenTick_ThenShouldRenewTheLeaseUntilItEnds() {
+	suite.job.LeaseTTL = 30 * time.Millisecond
+	var runErr error
+	suite.job.Run = func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			runErr = ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+		return nil
+	}
+
+	suite.True(suite.job.tick(context.Background()))
+	suite.NoError(runErr)
+	suite.GreaterOrEqual(suite.leases.calls(), 4)
+}
+
+func (suite *SchedulerTestSuite) TestGivenTheLeaseTakenDuringTheRun_WhenTick_ThenShouldCancelTheRun() {
+	suite.job.LeaseTTL = 30 * time.Millisecond
+	var runErr error
+	suite.job.Run = func(ctx context.Context) error {
+		suite.leases.set("replica-2", nil)
+		select {
+		case <-ctx.Done():
+			runErr = ctx.Err()
+		case <-time.After(time.Second):
+		}
+		return runErr
+	}
+
+	suite.True(suite.job.tick(context.Background()))
+	suite.ErrorIs(runErr, context.Canceled)
+}
+
+func (suite *SchedulerTestSuite) TestGivenTheRenewalsFail_WhenTick_ThenShouldCancelTheRunBeforeTheLeaseExpires() {
+	suite.job.LeaseTTL = 150 * time.Millisecond
+	var runErr error
+	var ran time.Duration
+	suite.job.Run = func(ctx context.Context) error {
+		start := time.Now()
+		suite.leases.set("replica-1", errors.New("db down"))
+		select {
+		case <-ctx.Done():
+			runErr = ctx.Err()
+		case <-time.After(time.Second):
+		}
+		ran = time.Since(start)
+		return runErr
+	}
+
+	suite.True(suite.job.tick(context.Background()))
+	suite.ErrorIs(runErr, context.Canceled)
+	suite.Less(ran, suite.job.LeaseTTL)
+	// the first failed renewal is retried
+	suite.GreaterOrEqual(suite.leases.calls(), 3)
+}
@@ -0,0 +1,33 @@
+package telemetry
+
+import (
+	"fmt"
+
+	"github.com/streadway/amqp"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// AMQPHeadersCarrier lets the otel propagator read and write amqp.Publishing headers.
+type AMQPHeadersCarrier amqp.Table
+
+var _ propagation.TextMapCarrier = AMQPHeadersCarrier{}
+
+func (c AMQPHeadersCarrier) Get(key string) string {
+	value, ok := c[key]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func (c AMQPHeadersCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c AMQPHeadersCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,30 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestGivenASpan_WhenInjectIntoAMQPHeaders_ThenShouldExtractTheSameSpanContext(t *testing.T) {
+	ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "publish")
+	defer span.End()
+	propagator := propagation.TraceContext{}
+	headers := amqp.Table{"x-retries": 2}
+
+	propagator.Inject(ctx, AMQPHeadersCarrier(headers))
+	assert.Contains(t, headers["traceparent"], span.SpanContext().TraceID().String())
+	assert.ElementsMatch(t, []string{"x-retries", "traceparent"}, AMQPHeadersCarrier(headers).Keys())
+	assert.Equal(t, "2", AMQPHeadersCarrier(headers).Get("x-retries"))
+	assert.Empty(t, AMQPHeadersCarrier(headers).Get("missing"))
+
+	extracted := trace.SpanContextFromContext(propagator.Extract(context.Background(), AMQPHeadersCarrier(headers)))
+	assert.Equal(t, span.SpanContext().TraceID(), extracted.TraceID())
+	assert.Equal(t, span.SpanContext().SpanID(), extracted.SpanID())
+	assert.True(t, extracted.IsRemote())
+}
@@ -0,0 +1,59 @@
+package telemetry
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const graphQLTracerName = "github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/telemetry/graphql"
+
+// GraphQLTracer is a gqlgen extension that wraps every operation in a span.
+type GraphQLTracer struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = GraphQLTracer{}
+
+func (GraphQLTracer) ExtensionName() string {
+	return "OpenTelemetry"
+}
+
+func (GraphQLTracer) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (GraphQLTracer) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+	oc := graphql.GetOperationContext(ctx)
+	name := oc.OperationName
+	operationType := ""
+	if oc.Operation != nil {
+		operationType = string(oc.Operation.Operation)
+		if name == "" {
+			name = oc.Operation.Name
+		}
+	}
+
+	ctx, span := otel.Tracer(graphQLTracerName).Start(ctx, "graphql "+operationType+" "+name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("graphql.operation.type", operationType),
+			attribute.String("graphql.operation.name", name),
+		),
+	)
+	defer span.End()
+
+	response := next(ctx)
+	if response != nil && len(response.Errors) > 0 {
+		span.SetStatus(codes.Error, response.Errors.Error())
+	}
+	return response
+}
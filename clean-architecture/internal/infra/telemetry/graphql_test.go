@@ -0,0 +1,77 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/stretchr/testify/suite"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type GraphQLTracerTestSuite struct {
+	suite.Suite
+	spanRecorder *tracetest.SpanRecorder
+}
+
+func (suite *GraphQLTracerTestSuite) SetupTest() {
+	suite.spanRecorder = tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(suite.spanRecorder)))
+}
+
+func TestGraphQLTracerSuite(t *testing.T) {
+	suite.Run(t, new(GraphQLTracerTestSuite))
+}
+
+func operation(name string, operationType ast.Operation) context.Context {
+	return graphql.WithOperationContext(context.Background(), &graphql.OperationContext{
+		Operation: &ast.OperationDefinition{Name: name, Operation: operationType},
+	})
+}
+
+func (suite *GraphQLTracerTestSuite) TestGivenAnOperation_WhenIntercept_ThenShouldWrapItInASpan() {
+	var inner trace.SpanContext
+	response := GraphQLTracer{}.InterceptResponse(operation("ListOrders", ast.Query), func(ctx context.Context) *graphql.Response {
+		inner = trace.SpanContextFromContext(ctx)
+		return &graphql.Response{}
+	})
+	suite.NotNil(response)
+
+	spans := suite.spanRecorder.Ended()
+	suite.Require().Len(spans, 1)
+	suite.Equal("graphql query ListOrders", spans[0].Name())
+	suite.Equal(trace.SpanKindServer, spans[0].SpanKind())
+	suite.Equal(spans[0].SpanContext().SpanID(), inner.SpanID())
+	suite.Contains(spans[0].Attributes(), attribute.String("graphql.operation.type", "query"))
+	suite.Contains(spans[0].Attributes(), attribute.String("graphql.operation.name", "ListOrders"))
+	suite.Equal(codes.Unset, spans[0].Status().Code)
+}
+
+func (suite *GraphQLTracerTestSuite) TestGivenAFailingOperation_WhenIntercept_ThenShouldMarkTheSpanAsError() {
+	GraphQLTracer{}.InterceptResponse(operation("CreateOrder", ast.Mutation), func(ctx context.Context) *graphql.Response {
+		return &graphql.Response{Errors: gqlerror.List{gqlerror.Errorf("invalid order")}}
+	})
+
+	spans := suite.spanRecorder.Ended()
+	suite.Require().Len(spans, 1)
+	suite.Equal("graphql mutation CreateOrder", spans[0].Name())
+	suite.Equal(codes.Error, spans[0].Status().Code)
+	suite.Contains(spans[0].Status().Description, "invalid order")
+}
+
+func (suite *GraphQLTracerTestSuite) TestGivenNoOperation_WhenIntercept_ThenShouldNotStartASpan() {
+	called := false
+	GraphQLTracer{}.InterceptResponse(context.Background(), func(ctx context.Context) *graphql.Response {
+		called = true
+		return nil
+	})
+	suite.True(called)
+	suite.Empty(suite.spanRecorder.Ended())
+}
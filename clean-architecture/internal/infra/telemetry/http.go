@@ -0,0 +1,61 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HTTPMiddleware creates a server span and the http.server.* RED metrics for
+// every request. It must be used by a chi router: the span starts named after
+// the method and, once the request is routed, is renamed after the chi route
+// pattern, e.g. "GET /order/{id}/history", which also labels the metrics as
+// http.route, so every ID of a route shares one name. A request routed to a
+// mounted handler (a pattern ending in "/*") is left for the handler to name
+// with HTTPRouteMiddleware.
+func HTTPMiddleware(operation string) func(http.Handler) http.Handler {
+	middleware := otelhttp.NewMiddleware(operation,
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method
+		}),
+	)
+	return func(next http.Handler) http.Handler {
+		return middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+			rctx := chi.RouteContext(r.Context())
+			if rctx == nil {
+				return
+			}
+			if pattern := rctx.RoutePattern(); pattern != "" && !strings.HasSuffix(pattern, "/*") {
+				setRoute(r.Context(), r.Method, pattern)
+			}
+		}))
+	}
+}
+
+// HTTPRouteMiddleware names the span of HTTPMiddleware for a handler that
+// routes the requests itself: pattern returns the route pattern of a request.
+func HTTPRouteMiddleware(pattern func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if route := pattern(r); route != "" {
+				setRoute(r.Context(), r.Method, route)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func setRoute(ctx context.Context, method, pattern string) {
+	span := trace.SpanFromContext(ctx)
+	span.SetName(method + " " + pattern)
+	span.SetAttributes(semconv.HTTPRoute(pattern))
+	if labeler, ok := otelhttp.LabelerFromContext(ctx); ok {
+		labeler.Add(semconv.HTTPRoute(pattern))
+	}
+}
@@ -0,0 +1,73 @@
+package telemetry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+type HTTPMiddlewareTestSuite struct {
+	suite.Suite
+	spanRecorder *tracetest.SpanRecorder
+	router       *chi.Mux
+}
+
+func (suite *HTTPMiddlewareTestSuite) SetupTest() {
+	suite.spanRecorder = tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(suite.spanRecorder)))
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	suite.router = chi.NewRouter()
+	suite.router.Use(HTTPMiddleware("webserver"))
+	suite.router.Get("/order/{id}/history", ok)
+	// a handler that routes the requests itself, as the gateway does
+	mounted := http.NewServeMux()
+	mounted.HandleFunc("GET /v1/orders/{id}", ok)
+	suite.router.Mount("/v1", HTTPRouteMiddleware(func(r *http.Request) string {
+		if _, pattern := mounted.Handler(r); pattern != "" {
+			return "/v1/orders/{id}"
+		}
+		return ""
+	})(mounted))
+}
+
+func TestHTTPMiddlewareSuite(t *testing.T) {
+	suite.Run(t, new(HTTPMiddlewareTestSuite))
+}
+
+func (suite *HTTPMiddlewareTestSuite) serve(method, target string) sdktrace.ReadOnlySpan {
+	suite.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, target, nil))
+	spans := suite.spanRecorder.Ended()
+	suite.Require().Len(spans, 1)
+	return spans[0]
+}
+
+func (suite *HTTPMiddlewareTestSuite) TestGivenARoutedRequest_WhenServe_ThenShouldNameTheSpanAfterTheRoute() {
+	span := suite.serve(http.MethodGet, "/order/42/history")
+	suite.Equal("GET /order/{id}/history", span.Name())
+	suite.Contains(span.Attributes(), semconv.HTTPRoute("/order/{id}/history"))
+}
+
+func (suite *HTTPMiddlewareTestSuite) TestGivenARequestToAMountedHandler_WhenServe_ThenShouldNameTheSpanAfterItsRoute() {
+	span := suite.serve(http.MethodGet, "/v1/orders/42")
+	suite.Equal("GET /v1/orders/{id}", span.Name())
+	suite.Contains(span.Attributes(), semconv.HTTPRoute("/v1/orders/{id}"))
+}
+
+func (suite *HTTPMiddlewareTestSuite) TestGivenAnUnknownRoute_WhenServe_ThenShouldNameTheSpanAfterTheMethodOnly() {
+	for _, target := range []string{"/order/42", "/v1/customers/42"} {
+		suite.spanRecorder.Reset()
+		span := suite.serve(http.MethodGet, target)
+		suite.Equal("GET", span.Name())
+		for _, attr := range span.Attributes() {
+			suite.NotEqual(attribute.Key("http.route"), attr.Key)
+		}
+	}
+}
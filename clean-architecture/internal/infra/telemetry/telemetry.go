@@ -0,0 +1,91 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+type Config struct {
+	ServiceName  string
+	OTLPEndpoint string
+}
+
+// Telemetry holds the providers registered as the otel globals.
+// Spans and OTLP metrics are only exported when an OTLP endpoint is configured;
+// the Prometheus handler is always available.
+type Telemetry struct {
+	TracerProvider *sdktrace.TracerProvider
+	MeterProvider  *sdkmetric.MeterProvider
+	MetricsHandler http.Handler
+}
+
+func Setup(ctx context.Context, cfg Config) (*Telemetry, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	registry := prometheus.NewRegistry()
+	promExporter, err := otelprometheus.New(otelprometheus.WithRegisterer(registry))
+	if err != nil {
+		return nil, err
+	}
+	meterOptions := []sdkmetric.Option{sdkmetric.WithResource(res), sdkmetric.WithReader(promExporter)}
+	traceOptions := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+
+	if cfg.OTLPEndpoint != "" {
+		traceExporter, err := otlptracegrpc.New(ctx,
+			otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint),
+			otlptracegrpc.WithInsecure(),
+		)
+		if err != nil {
+			return nil, err
+		}
+		metricExporter, err := otlpmetricgrpc.New(ctx,
+			otlpmetricgrpc.WithEndpoint(cfg.OTLPEndpoint),
+			otlpmetricgrpc.WithInsecure(),
+		)
+		if err != nil {
+			return nil, err
+		}
+		traceOptions = append(traceOptions, sdktrace.WithBatcher(traceExporter))
+		meterOptions = append(meterOptions, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)))
+	}
+
+	t := &Telemetry{
+		TracerProvider: sdktrace.NewTracerProvider(traceOptions...),
+		MeterProvider:  sdkmetric.NewMeterProvider(meterOptions...),
+		MetricsHandler: promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+	}
+	otel.SetTracerProvider(t.TracerProvider)
+	otel.SetMeterProvider(t.MeterProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	return t, nil
+}
+
+// Shutdown flushes pending spans and metrics.
+func (t *Telemetry) Shutdown(ctx context.Context) error {
+	return errors.Join(
+		t.TracerProvider.Shutdown(ctx),
+		t.MeterProvider.Shutdown(ctx),
+	)
+}
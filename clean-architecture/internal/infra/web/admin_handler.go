@@ -0,0 +1,55 @@
+package web
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+)
+
+// AdminHandler serves the operational routes under /admin.
+type AdminHandler struct {
+	ReconcileOrderEventsUseCase *usecase.ReconcileOrderEventsUseCase
+}
+
+func NewAdminHandler(reconcileOrderEventsUseCase *usecase.ReconcileOrderEventsUseCase) *AdminHandler {
+	return &AdminHandler{
+		ReconcileOrderEventsUseCase: reconcileOrderEventsUseCase,
+	}
+}
+
+// Reconciliation serves GET /admin/reconciliation: a dry run of the
+// reconciliation, listing the orders whose event was not published without
+// republishing them.
+func (h *AdminHandler) Reconciliation(w http.ResponseWriter, r *http.Request) {
+	output, err := h.ReconcileOrderEventsUseCase.Execute(r.Context(), true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(output)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// AdminAuth only lets through the requests carrying token as a bearer token,
+// answering the others with a 401.
+func AdminAuth(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			credentials, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(credentials), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+				writeError(w, http.StatusUnauthorized, errors.New("missing or invalid admin token"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
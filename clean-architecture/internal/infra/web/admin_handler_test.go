@@ -0,0 +1,52 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AdminAuthTestSuite struct {
+	suite.Suite
+	Handler http.Handler
+	Called  bool
+}
+
+func (suite *AdminAuthTestSuite) SetupTest() {
+	suite.Called = false
+	suite.Handler = AdminAuth("s3cret")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.Called = true
+	}))
+}
+
+func TestAdminAuthSuite(t *testing.T) {
+	suite.Run(t, new(AdminAuthTestSuite))
+}
+
+func (suite *AdminAuthTestSuite) serve(authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/admin/reconciliation", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	rec := httptest.NewRecorder()
+	suite.Handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func (suite *AdminAuthTestSuite) TestGivenTheAdminToken_WhenGet_ThenShouldPassItOn() {
+	rec := suite.serve("Bearer s3cret")
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.True(suite.Called)
+}
+
+func (suite *AdminAuthTestSuite) TestGivenNoOrAWrongToken_WhenGet_ThenShouldReturnUnauthorized() {
+	for _, authorization := range []string{"", "Bearer wrong", "Basic s3cret", "s3cret"} {
+		rec := suite.serve(authorization)
+		suite.Equal(http.StatusUnauthorized, rec.Code, authorization)
+		suite.Equal(`Bearer realm="admin"`, rec.Header().Get("WWW-Authenticate"))
+		suite.JSONEq(`{"error":"missing or invalid admin token"}`, rec.Body.String())
+	}
+	suite.False(suite.Called)
+}
@@ -0,0 +1,110 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// FieldError tells what is wrong with one field of the request. Body fields
+// are named by their path, e.g. "price" or "items.0.id".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ErrorResponse is the body of the 400 responses.
+type ErrorResponse struct {
+	Error  string       `json:"error"`
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// RequestError is an invalid request, reported as a 400 with an ErrorResponse.
+type RequestError struct {
+	Message string
+	Fields  []FieldError
+}
+
+func (e *RequestError) Error() string {
+	if len(e.Fields) == 0 {
+		return e.Message
+	}
+	fields := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		fields[i] = field.Field + ": " + field.Message
+	}
+	return e.Message + " (" + strings.Join(fields, "; ") + ")"
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	response := ErrorResponse{Error: err.Error()}
+	var requestErr *RequestError
+	if errors.As(err, &requestErr) {
+		response = ErrorResponse{Error: requestErr.Message, Fields: requestErr.Fields}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// decodeJSON decodes the single JSON value of body into v, rejecting unknown
+// fields and values of the wrong type with a RequestError naming the field.
+func decodeJSON(body io.Reader, v any) error {
+	decoder := json.NewDecoder(body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return decodeError(err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return &RequestError{Message: "the body must hold a single JSON value"}
+	}
+	return nil
+}
+
+func decodeError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &typeErr):
+		return &RequestError{Message: "invalid field", Fields: []FieldError{{
+			Field:   typeErr.Field,
+			Message: fmt.Sprintf("expected %s, got %s", jsonType(typeErr.Type), typeErr.Value),
+		}}}
+	case errors.As(err, &syntaxErr):
+		return &RequestError{Message: fmt.Sprintf("malformed JSON at offset %d: %s", syntaxErr.Offset, syntaxErr.Error())}
+	case errors.Is(err, io.EOF):
+		return &RequestError{Message: "the body is empty"}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &RequestError{Message: "malformed JSON: unexpected end of the body"}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no type for this error
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return &RequestError{Message: "invalid field", Fields: []FieldError{{Field: field, Message: "unknown field"}}}
+	default:
+		return &RequestError{Message: err.Error()}
+	}
+}
+
+// jsonType names the JSON type a Go type is decoded from.
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
@@ -0,0 +1,44 @@
+package web
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeOrder(body string) (*RequestError, error) {
+	var dto usecase.OrderInputDTO
+	err := decodeJSON(strings.NewReader(body), &dto)
+	var requestErr *RequestError
+	errors.As(err, &requestErr)
+	return requestErr, err
+}
+
+func TestGivenAValidBody_WhenDecodeJSON_ThenShouldFillTheDTO(t *testing.T) {
+	var dto usecase.OrderInputDTO
+	assert.NoError(t, decodeJSON(strings.NewReader(`{"id":"a","price":10.5,"tax":1}`), &dto))
+	assert.Equal(t, usecase.OrderInputDTO{ID: "a", Price: 10.5, Tax: 1}, dto)
+}
+
+func TestGivenAnUnknownField_WhenDecodeJSON_ThenShouldNameIt(t *testing.T) {
+	requestErr, _ := decodeOrder(`{"id":"a","price":10.5,"tax":1,"discount":2}`)
+	assert.Equal(t, []FieldError{{Field: "discount", Message: "unknown field"}}, requestErr.Fields)
+}
+
+func TestGivenAWrongType_WhenDecodeJSON_ThenShouldNameTheFieldAndType(t *testing.T) {
+	requestErr, _ := decodeOrder(`{"id":"a","price":"10.5","tax":1}`)
+	assert.Equal(t, []FieldError{{Field: "price", Message: "expected number, got string"}}, requestErr.Fields)
+}
+
+func TestGivenAMalformedBody_WhenDecodeJSON_ThenShouldReturnARequestError(t *testing.T) {
+	for _, body := range []string{"", `{"id":`, `{"id":"a"} {"id":"b"}`, `{"id" "a"}`} {
+		requestErr, err := decodeOrder(body)
+		assert.Error(t, err, body)
+		assert.NotNil(t, requestErr, body)
+		assert.Empty(t, requestErr.Fields, body)
+	}
+}
@@ -0,0 +1,161 @@
+package web
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+// OpenAPISpec is the OpenAPI 3 document of the order routes.
+//
+//go:embed openapi.json
+var OpenAPISpec []byte
+
+// LoadOpenAPI parses and validates OpenAPISpec.
+func LoadOpenAPI() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(OpenAPISpec)
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// OpenAPIHandler serves OpenAPISpec, at /openapi.json.
+func OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(OpenAPISpec)
+}
+
+// swaggerUIPage loads Swagger UI from a CDN and points it to /openapi.json.
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Order System REST API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => { window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" }); };
+  </script>
+</body>
+</html>
+`
+
+// SwaggerUIHandler serves the Swagger UI of OpenAPISpec, at /docs.
+func SwaggerUIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(swaggerUIPage))
+}
+
+// gatewayExtension marks the operations served by grpc-gateway.
+const gatewayExtension = "x-grpc-gateway"
+
+// ValidationMiddleware rejects, with a 400 ErrorResponse, the requests that
+// don't match the operation of doc they are sent to. Requests to paths not
+// described by doc, such as /metrics, pass through, and so do the requests to
+// the operations marked with x-grpc-gateway: the gateway validates them with
+// the buf.validate rules of order.proto and answers a google.rpc.Status. Only
+// JSON bodies are validated: CSV and NDJSON bodies are streamed, and checked
+// row by row.
+func ValidationMiddleware(doc *openapi3.T) (func(http.Handler) http.Handler, error) {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, pathParams, err := router.FindRoute(r)
+			if errors.Is(err, routers.ErrPathNotFound) || errors.Is(err, routers.ErrMethodNotAllowed) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+			if served, _ := route.Operation.Extensions[gatewayExtension].(bool); served {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: pathParams,
+				Route:      route,
+				Options: &openapi3filter.Options{
+					MultiError:         true,
+					ExcludeRequestBody: !takesJSON(route.Operation),
+					// the secured routes check their credentials themselves (AdminAuth)
+					AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+				},
+			}
+			if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+				writeError(w, http.StatusBadRequest, &RequestError{Message: "invalid request", Fields: validationFields(err)})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// takesJSON tells whether the operation has a JSON body.
+func takesJSON(operation *openapi3.Operation) bool {
+	body := operation.RequestBody
+	return body != nil && body.Value != nil && body.Value.Content.Get("application/json") != nil
+}
+
+// validationFields flattens the errors of openapi3filter.ValidateRequest.
+func validationFields(err error) []FieldError {
+	switch err := err.(type) {
+	case openapi3.MultiError:
+		var fields []FieldError
+		for _, e := range err {
+			fields = append(fields, validationFields(e)...)
+		}
+		return fields
+	case *openapi3filter.RequestError:
+		field := "body"
+		if err.Parameter != nil {
+			field = err.Parameter.Name
+		}
+		if err.Err == nil {
+			return []FieldError{{Field: field, Message: err.Reason}}
+		}
+		fields := validationFields(err.Err)
+		for i := range fields {
+			// a parameter is named by itself, a body field by its path
+			if err.Parameter != nil || fields[i].Field == "" {
+				fields[i].Field = field
+			}
+		}
+		return fields
+	case *openapi3.SchemaError:
+		path := err.JSONPointer()
+		// an unknown property is reported on its parent object
+		if name, ok := strings.CutSuffix(strings.TrimPrefix(err.Reason, "property "), " is unsupported"); ok && err.SchemaField == "properties" {
+			if name, unquoteErr := strconv.Unquote(name); unquoteErr == nil {
+				return []FieldError{{Field: strings.Join(append(path, name), "."), Message: "unknown field"}}
+			}
+		}
+		return []FieldError{{Field: strings.Join(path, "."), Message: err.Reason}}
+	default:
+		if unwrapped := errors.Unwrap(err); unwrapped != nil {
+			return validationFields(unwrapped)
+		}
+		return []FieldError{{Message: err.Error()}}
+	}
+}
@@ -0,0 +1,158 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/grpc/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+)
+
+type ValidationMiddlewareTestSuite struct {
+	suite.Suite
+	Handler http.Handler
+	Called  bool
+}
+
+func (suite *ValidationMiddlewareTestSuite) SetupTest() {
+	doc, err := LoadOpenAPI()
+	suite.Require().NoError(err)
+	validate, err := ValidationMiddleware(doc)
+	suite.Require().NoError(err)
+	suite.Called = false
+	suite.Handler = validate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.Called = true
+	}))
+}
+
+func TestValidationMiddlewareSuite(t *testing.T) {
+	suite.Run(t, new(ValidationMiddlewareTestSuite))
+}
+
+func (suite *ValidationMiddlewareTestSuite) serve(method, target, contentType, body string) (*httptest.ResponseRecorder, ErrorResponse) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	rec := httptest.NewRecorder()
+	suite.Handler.ServeHTTP(rec, req)
+	var response ErrorResponse
+	if rec.Code == http.StatusBadRequest {
+		suite.Require().NoError(json.NewDecoder(rec.Body).Decode(&response))
+	}
+	return rec, response
+}
+
+func (suite *ValidationMiddlewareTestSuite) TestGivenAValidOrder_WhenPost_ThenShouldPassItOn() {
+	rec, _ := suite.serve(http.MethodPost, "/order", "application/json", `{"id":"a","price":10.5,"tax":1}`)
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.True(suite.Called)
+}
+
+func (suite *ValidationMiddlewareTestSuite) TestGivenAnInvalidOrder_WhenPost_ThenShouldListEveryInvalidField() {
+	rec, response := suite.serve(http.MethodPost, "/order", "application/json", `{"id":"a","price":"10","tax":0,"extra":true}`)
+	suite.Equal(http.StatusBadRequest, rec.Code)
+	suite.False(suite.Called)
+	suite.Equal("invalid request", response.Error)
+	fields := map[string]bool{}
+	for _, field := range response.Fields {
+		fields[field.Field] = true
+	}
+	suite.Equal(map[string]bool{"price": true, "tax": true, "extra": true}, fields)
+}
+
+func (suite *ValidationMiddlewareTestSuite) TestGivenAnInvalidQueryParameter_WhenGet_ThenShouldNameIt() {
+	rec, response := suite.serve(http.MethodGet, "/orders/export?format=xml", "", "")
+	suite.Equal(http.StatusBadRequest, rec.Code)
+	suite.Require().Len(response.Fields, 1)
+	suite.Equal("format", response.Fields[0].Field)
+}
+
+func (suite *ValidationMiddlewareTestSuite) TestGivenACSVImport_WhenPost_ThenShouldNotReadTheBody() {
+	rec, _ := suite.serve(http.MethodPost, "/orders/import?dry_run=true", "text/csv", "id,price,tax\na,10,1\n")
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.True(suite.Called)
+}
+
+func (suite *ValidationMiddlewareTestSuite) TestGivenAPathOutOfTheSpec_WhenGet_ThenShouldPassItOn() {
+	rec, _ := suite.serve(http.MethodGet, "/metrics", "", "")
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.True(suite.Called)
+}
+
+func (suite *ValidationMiddlewareTestSuite) TestGivenASecuredRoute_WhenGet_ThenShouldLeaveTheCredentialsToTheHandler() {
+	rec, _ := suite.serve(http.MethodGet, "/admin/reconciliation", "", "")
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.True(suite.Called)
+}
+
+func (suite *ValidationMiddlewareTestSuite) TestGivenAReportWithoutItsRange_WhenGet_ThenShouldNameTheMissingParameters() {
+	rec, response := suite.serve(http.MethodGet, "/reports/orders?group_by=year", "", "")
+	suite.Equal(http.StatusBadRequest, rec.Code)
+	fields := map[string]bool{}
+	for _, field := range response.Fields {
+		fields[field.Field] = true
+	}
+	suite.Equal(map[string]bool{"from": true, "to": true, "group_by": true}, fields)
+
+	rec, _ = suite.serve(http.MethodGet, "/reports/orders?from=2026-01&to=2026-02&group_by=week", "", "")
+	suite.Equal(http.StatusOK, rec.Code)
+}
+
+func (suite *ValidationMiddlewareTestSuite) TestGivenAGatewayRoute_WhenRequest_ThenShouldLeaveTheValidationToTheGateway() {
+	rec, _ := suite.serve(http.MethodPost, "/v1/orders", "application/json", `{"id":"a","price":"10","tax":0}`)
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.True(suite.Called)
+
+	suite.Called = false
+	rec, _ = suite.serve(http.MethodGet, "/v1/reports/orders", "", "")
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.True(suite.Called)
+}
+
+func TestGivenOrderProto_WhenLoadOpenAPI_ThenShouldDescribeEveryGatewayRoute(t *testing.T) {
+	doc, err := LoadOpenAPI()
+	require.NoError(t, err)
+
+	bindings := map[string]bool{}
+	methods := pb.File_internal_infra_grpc_protofiles_order_proto.Services().ByName("OrderService").Methods()
+	for i := 0; i < methods.Len(); i++ {
+		rule, ok := proto.GetExtension(methods.Get(i).Options(), annotations.E_Http).(*annotations.HttpRule)
+		if !ok || rule == nil {
+			continue
+		}
+		var method, path string
+		switch pattern := rule.GetPattern().(type) {
+		case *annotations.HttpRule_Get:
+			method, path = http.MethodGet, pattern.Get
+		case *annotations.HttpRule_Post:
+			method, path = http.MethodPost, pattern.Post
+		case *annotations.HttpRule_Delete:
+			method, path = http.MethodDelete, pattern.Delete
+		default:
+			t.Fatalf("%s: unexpected binding %v", methods.Get(i).Name(), rule)
+		}
+		bindings[method+" "+path] = true
+		item := doc.Paths.Value(path)
+		require.NotNil(t, item, "%s %s (%s) is missing from openapi.json", method, path, methods.Get(i).Name())
+		operation := item.GetOperation(method)
+		require.NotNil(t, operation, "%s %s (%s) is missing from openapi.json", method, path, methods.Get(i).Name())
+		assert.Equal(t, true, operation.Extensions[gatewayExtension], "%s %s", method, path)
+	}
+	assert.Len(t, bindings, 8)
+
+	for path, item := range doc.Paths.Map() {
+		for method, operation := range item.Operations() {
+			if operation.Extensions[gatewayExtension] == true {
+				assert.True(t, bindings[method+" "+path], "%s %s isn't served by the gateway", method, path)
+			}
+		}
+	}
+}
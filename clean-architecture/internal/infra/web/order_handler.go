@@ -2,41 +2,61 @@ package web
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/orderio"
 	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
 	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
+
+	"github.com/go-chi/chi/v5"
 )
 
 type WebOrderHandler struct {
-	EventDispatcher   events.EventDispatcherInterface
-	OrderRepository   entity.OrderRepositoryInterface
-	OrderCreatedEvent events.EventInterface
+	EventDispatcher      events.EventDispatcherInterface
+	OrderRepository      entity.OrderRepositoryInterface
+	OrderViewRepository  entity.OrderViewRepositoryInterface
+	OrderAuditRepository entity.OrderAuditRepositoryInterface
+	// MaxImportBytes limits the body of Import; 0 leaves it unlimited.
+	MaxImportBytes int64
 }
 
 func NewWebOrderHandler(
 	EventDispatcher events.EventDispatcherInterface,
 	OrderRepository entity.OrderRepositoryInterface,
-	OrderCreatedEvent events.EventInterface,
+	OrderViewRepository entity.OrderViewRepositoryInterface,
+	OrderAuditRepository entity.OrderAuditRepositoryInterface,
 ) *WebOrderHandler {
 	return &WebOrderHandler{
-		EventDispatcher:   EventDispatcher,
-		OrderRepository:   OrderRepository,
-		OrderCreatedEvent: OrderCreatedEvent,
+		EventDispatcher:      EventDispatcher,
+		OrderRepository:      OrderRepository,
+		OrderViewRepository:  OrderViewRepository,
+		OrderAuditRepository: OrderAuditRepository,
 	}
 }
 
 func (h *WebOrderHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var dto usecase.OrderInputDTO
-	err := json.NewDecoder(r.Body).Decode(&dto)
+	err := decodeJSON(r.Body, &dto)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	createOrder := usecase.NewCreateOrderUseCase(h.OrderRepository, h.OrderCreatedEvent, h.EventDispatcher)
-	output, err := createOrder.Execute(dto)
+	createOrder := usecase.NewCreateOrderUseCase(h.OrderRepository, h.EventDispatcher)
+	output, err := createOrder.Execute(r.Context(), dto)
+	if errors.Is(err, usecase.ErrInvalidOrder) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if errors.Is(err, entity.ErrOrderAlreadyExists) {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -49,15 +69,157 @@ func (h *WebOrderHandler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *WebOrderHandler) FindAll(w http.ResponseWriter, r *http.Request) {
-	listOrdersUseCase := usecase.NewListOrdersUseCase(h.OrderRepository)
-	output, err := listOrdersUseCase.Execute()
+	listOrdersUseCase := usecase.NewListOrdersUseCase(h.OrderViewRepository)
+	output, err := listOrdersUseCase.Execute(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(output)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// History serves GET /order/{id}/history.
+func (h *WebOrderHandler) History(w http.ResponseWriter, r *http.Request) {
+	getOrderHistory := usecase.NewGetOrderHistoryUseCase(h.OrderAuditRepository)
+	output, err := getOrderHistory.Execute(r.Context(), chi.URLParam(r, "id"))
+	if errors.Is(err, entity.ErrOrderNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(output)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+}
+
+// Import serves POST /orders/import. The body is CSV or NDJSON, as told by
+// the format query parameter or the Content-Type; dry_run=true only validates.
+// Rows with errors don't fail the request: they are listed in the report.
+// A body over MaxImportBytes stops the import with a 413; the batches saved
+// before it was reached stay saved.
+func (h *WebOrderHandler) Import(w http.ResponseWriter, r *http.Request) {
+	if h.MaxImportBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, h.MaxImportBytes)
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		var err error
+		if format, err = orderio.FormatOf(r.Header.Get("Content-Type")); err != nil {
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+			return
+		}
+	}
+	dryRun := false
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			writeError(w, http.StatusBadRequest, &RequestError{Message: "invalid request", Fields: []FieldError{{Field: "dry_run", Message: "expected a boolean"}}})
+			return
+		}
+	}
+	rows, err := orderio.NewReader(format, r.Body)
+	if errors.Is(err, orderio.ErrUnsupportedFormat) {
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	if bodyTooLarge(w, err) {
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	importOrders := usecase.NewImportOrdersUseCase(h.OrderRepository, h.EventDispatcher)
+	output, err := importOrders.Execute(r.Context(), rows, dryRun)
+	if bodyTooLarge(w, err) {
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
 	err = json.NewEncoder(w).Encode(output)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 }
+
+// bodyTooLarge answers a 413 when err is the body going over the limit of
+// http.MaxBytesReader.
+func bodyTooLarge(w http.ResponseWriter, err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(err, &maxBytesErr) {
+		return false
+	}
+	writeError(w, http.StatusRequestEntityTooLarge, &RequestError{Message: fmt.Sprintf("the body is larger than %d bytes", maxBytesErr.Limit)})
+	return true
+}
+
+// Export serves GET /orders/export, streaming the orders as CSV (the default)
+// or NDJSON, chosen by the format query parameter or the Accept header.
+func (h *WebOrderHandler) Export(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = orderio.CSV
+		if accepted, err := orderio.FormatOf(r.Header.Get("Accept")); err == nil {
+			format = accepted
+		}
+	}
+	body := &startedWriter{ResponseWriter: w}
+	writer, err := orderio.NewWriter(format, body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", orderio.ContentTypes[format])
+	w.Header().Set("Content-Disposition", "attachment; filename=orders."+format)
+	exportOrders := usecase.NewExportOrdersUseCase(h.OrderViewRepository)
+	err = exportOrders.Execute(r.Context(), writer.Write)
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err != nil && !body.started {
+		w.Header().Del("Content-Disposition")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err != nil {
+		// the status was already sent, so the client only sees a cut response
+		slog.ErrorContext(r.Context(), "failed to export orders", slog.Any("error", err))
+	}
+}
+
+// startedWriter tells whether the body started to be sent, after which the
+// status can no longer change.
+type startedWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *startedWriter) Write(p []byte) (int, error) {
+	w.started = true
+	return w.ResponseWriter.Write(p)
+}
+
+// Deprecated wraps a route kept for compatibility, pointing the clients to the
+// route generated from order.proto that replaces it.
+func Deprecated(successor string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "<"+successor+`>; rel="successor-version"`)
+		next(w, r)
+	}
+}
@@ -0,0 +1,167 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// memoryOrders keeps the imported orders, which memoryViews exports.
+type memoryOrders struct {
+	entity.OrderRepositoryInterface
+	mu     sync.Mutex
+	orders map[string]entity.Order
+}
+
+func (m *memoryOrders) Save(ctx context.Context, order *entity.Order) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.orders[order.ID]; ok {
+		return fmt.Errorf("%w: %s", entity.ErrOrderAlreadyExists, order.ID)
+	}
+	m.orders[order.ID] = *order
+	return nil
+}
+
+func (m *memoryOrders) SaveBatch(ctx context.Context, orders []*entity.Order) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, order := range orders {
+		m.orders[order.ID] = *order
+	}
+	return nil
+}
+
+func (m *memoryOrders) ExistingIDs(ctx context.Context, ids []string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var existing []string
+	for _, id := range ids {
+		if _, ok := m.orders[id]; ok {
+			existing = append(existing, id)
+		}
+	}
+	return existing, nil
+}
+
+type memoryViews struct {
+	entity.OrderViewRepositoryInterface
+	orders *memoryOrders
+}
+
+func (m memoryViews) ForEach(ctx context.Context, fn func(view entity.OrderView) error) error {
+	m.orders.mu.Lock()
+	ids := slices.Sorted(maps.Keys(m.orders.orders))
+	views := make([]entity.OrderView, len(ids))
+	for i, id := range ids {
+		order := m.orders.orders[id]
+		views[i] = entity.OrderView{ID: order.ID, Price: order.Price, Tax: order.Tax, FinalPrice: order.FinalPrice}
+	}
+	m.orders.mu.Unlock()
+	for _, view := range views {
+		if err := fn(view); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type WebOrderHandlerTestSuite struct {
+	suite.Suite
+	Orders  *memoryOrders
+	Handler *WebOrderHandler
+}
+
+func (suite *WebOrderHandlerTestSuite) SetupTest() {
+	suite.Orders = &memoryOrders{orders: map[string]entity.Order{}}
+	suite.Handler = NewWebOrderHandler(events.NewEventDispatcher(), suite.Orders, memoryViews{orders: suite.Orders}, nil)
+}
+
+func TestWebOrderHandlerSuite(t *testing.T) {
+	suite.Run(t, new(WebOrderHandlerTestSuite))
+}
+
+func (suite *WebOrderHandlerTestSuite) importOrders(target, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	rec := httptest.NewRecorder()
+	suite.Handler.Import(rec, req)
+	return rec
+}
+
+func (suite *WebOrderHandlerTestSuite) exportOrders(target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	suite.Handler.Export(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func (suite *WebOrderHandlerTestSuite) TestGivenAnExistingID_WhenCreate_ThenShouldReturnConflict() {
+	create := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		suite.Handler.Create(rec, httptest.NewRequest(http.MethodPost, "/order", strings.NewReader(`{"id":"a","price":10,"tax":1}`)))
+		return rec
+	}
+	suite.Equal(http.StatusOK, create().Code)
+
+	rec := create()
+	suite.Equal(http.StatusConflict, rec.Code)
+	var response ErrorResponse
+	suite.Require().NoError(json.NewDecoder(rec.Body).Decode(&response))
+	suite.Equal("order id already exists: a", response.Error)
+}
+
+func (suite *WebOrderHandlerTestSuite) TestGivenACSVImport_WhenExportIt_ThenShouldReturnTheSameOrders() {
+	rec := suite.importOrders("/orders/import", "text/csv", "id,price,tax\nb,20,1\na,10,0.5\n")
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	var report usecase.ImportOrdersOutputDTO
+	suite.Require().NoError(json.NewDecoder(rec.Body).Decode(&report))
+	suite.Equal(2, report.Rows)
+	suite.Equal(2, report.Imported)
+
+	rec = suite.exportOrders("/orders/export")
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.Equal("text/csv", rec.Header().Get("Content-Type"))
+	suite.Equal("id,price,tax,final_price\na,10,0.5,10.5\nb,20,1,21\n", rec.Body.String())
+}
+
+func (suite *WebOrderHandlerTestSuite) TestGivenAnNDJSONImport_WhenExportIt_ThenShouldReturnTheSameOrders() {
+	body := `{"id":"a","price":10,"tax":0.5}` + "\n" + `{"id":"b","price":20,"tax":1}` + "\n"
+	rec := suite.importOrders("/orders/import?format=ndjson", "", body)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+
+	rec = suite.exportOrders("/orders/export?format=ndjson")
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.Equal("application/x-ndjson", rec.Header().Get("Content-Type"))
+	suite.Equal(`{"id":"a","price":10,"tax":0.5,"final_price":10.5}`+"\n"+`{"id":"b","price":20,"tax":1,"final_price":21}`+"\n", rec.Body.String())
+}
+
+func (suite *WebOrderHandlerTestSuite) TestGivenABodyOverTheLimit_WhenImport_ThenShouldReturnRequestEntityTooLarge() {
+	suite.Handler.MaxImportBytes = 16
+
+	rec := suite.importOrders("/orders/import?dry_run=true", "text/csv", "id,price,tax\nb,20,1\na,10,0.5\n")
+	suite.Equal(http.StatusRequestEntityTooLarge, rec.Code)
+	var response ErrorResponse
+	suite.Require().NoError(json.NewDecoder(rec.Body).Decode(&response))
+	suite.Equal("the body is larger than 16 bytes", response.Error)
+}
+
+func (suite *WebOrderHandlerTestSuite) TestGivenAHeaderOverTheLimit_WhenImport_ThenShouldReturnRequestEntityTooLarge() {
+	suite.Handler.MaxImportBytes = 4
+
+	rec := suite.importOrders("/orders/import", "text/csv", "id,price,tax\nb,20,1\n")
+	suite.Equal(http.StatusRequestEntityTooLarge, rec.Code)
+	suite.Empty(suite.Orders.orders)
+}
@@ -0,0 +1,114 @@
+package web
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+)
+
+// reportHeader is the header of the CSV reports.
+var reportHeader = []string{"start", "end", "count", "price", "tax", "final_price", "average_ticket"}
+
+// ReportHandler serves the reports under /reports.
+type ReportHandler struct {
+	ReportOrdersUseCase *usecase.ReportOrdersUseCase
+}
+
+func NewReportHandler(reportOrdersUseCase *usecase.ReportOrdersUseCase) *ReportHandler {
+	return &ReportHandler{
+		ReportOrdersUseCase: reportOrdersUseCase,
+	}
+}
+
+// Orders serves GET /reports/orders?from=...&to=...&group_by=day|week|month,
+// as CSV (the default, one line per period) or JSON (with the total too),
+// chosen by the format query parameter or the Accept header.
+func (h *ReportHandler) Orders(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = "csv"
+		if strings.Contains(r.Header.Get("Accept"), "application/json") {
+			format = "json"
+		}
+	}
+	if format != "csv" && format != "json" {
+		writeError(w, http.StatusBadRequest, &RequestError{Message: "invalid request", Fields: []FieldError{{Field: "format", Message: "expected csv or json"}}})
+		return
+	}
+
+	output, err := h.ReportOrdersUseCase.Execute(r.Context(), usecase.ReportOrdersInputDTO{
+		From:    query.Get("from"),
+		To:      query.Get("to"),
+		GroupBy: query.Get("group_by"),
+	})
+	if errors.Is(err, entity.ErrInvalidOrderReport) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// the report is small, so it's encoded before the status is sent, and an
+	// error can still turn it into a 500
+	var body bytes.Buffer
+	contentType := "application/json"
+	if format == "json" {
+		err = json.NewEncoder(&body).Encode(output)
+	} else {
+		contentType = "text/csv"
+		err = writeReportCSV(&body, output.Rows)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	if format == "csv" {
+		w.Header().Set("Content-Disposition", "attachment; filename=orders-report.csv")
+	}
+	if _, err := body.WriteTo(w); err != nil {
+		// the status was already sent, so the client only sees a cut response
+		slog.ErrorContext(r.Context(), "failed to send the orders report", slog.Any("error", err))
+	}
+}
+
+func writeReportCSV(w io.Writer, rows []usecase.OrderReportRowDTO) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(reportHeader); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		err := writer.Write([]string{
+			row.Start.Format(time.RFC3339),
+			row.End.Format(time.RFC3339),
+			strconv.Itoa(row.Count),
+			formatAmount(row.Price),
+			formatAmount(row.Tax),
+			formatAmount(row.FinalPrice),
+			formatAmount(row.AverageTicket),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// formatAmount rounds to cents, hiding the float error of the sums.
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
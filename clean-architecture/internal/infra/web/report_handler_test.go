@@ -0,0 +1,101 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// januaryTotals has one order, of 10 plus a tax of 0.1, on each day of
+// January 2026.
+type januaryTotals struct{}
+
+func (januaryTotals) GetTotals(ctx context.Context, periods []entity.OrderPeriod) ([]entity.OrderTotals, error) {
+	totals := make([]entity.OrderTotals, len(periods))
+	for i, period := range periods {
+		if period.From.Month() != 1 {
+			continue
+		}
+		days := int(period.To.Sub(period.From).Hours() / 24)
+		totals[i] = entity.OrderTotals{Count: days, Price: 10 * float64(days), Tax: 0.1 * float64(days), FinalPrice: 10.1 * float64(days)}
+	}
+	return totals, nil
+}
+
+// infiniteTotals sums to a price JSON can't encode.
+type infiniteTotals struct{}
+
+func (infiniteTotals) GetTotals(ctx context.Context, periods []entity.OrderPeriod) ([]entity.OrderTotals, error) {
+	totals := make([]entity.OrderTotals, len(periods))
+	for i := range totals {
+		totals[i] = entity.OrderTotals{Count: 1, Price: math.Inf(1), FinalPrice: math.Inf(1)}
+	}
+	return totals, nil
+}
+
+type ReportHandlerTestSuite struct {
+	suite.Suite
+	Handler *ReportHandler
+}
+
+func (suite *ReportHandlerTestSuite) SetupTest() {
+	suite.Handler = NewReportHandler(usecase.NewReportOrdersUseCase(januaryTotals{}))
+}
+
+func TestReportHandlerSuite(t *testing.T) {
+	suite.Run(t, new(ReportHandlerTestSuite))
+}
+
+func (suite *ReportHandlerTestSuite) serve(target, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set("Accept", accept)
+	rec := httptest.NewRecorder()
+	suite.Handler.Orders(rec, req)
+	return rec
+}
+
+func (suite *ReportHandlerTestSuite) TestGivenARange_WhenGetTheReport_ThenShouldReturnACSVLinePerPeriod() {
+	rec := suite.serve("/reports/orders?from=2026-01&to=2026-02&group_by=month", "")
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.Equal("text/csv", rec.Header().Get("Content-Type"))
+	suite.Equal("start,end,count,price,tax,final_price,average_ticket\n"+
+		"2026-01-01T00:00:00Z,2026-02-01T00:00:00Z,31,310.00,3.10,313.10,10.10\n"+
+		"2026-02-01T00:00:00Z,2026-03-01T00:00:00Z,0,0.00,0.00,0.00,0.00\n", rec.Body.String())
+}
+
+func (suite *ReportHandlerTestSuite) TestGivenJSONIsAccepted_WhenGetTheReport_ThenShouldReturnTheRowsAndTheTotal() {
+	rec := suite.serve("/reports/orders?from=2026-01-30&to=2026-02-01", "application/json")
+	suite.Equal(http.StatusOK, rec.Code)
+	var output usecase.ReportOrdersOutputDTO
+	suite.Require().NoError(json.NewDecoder(rec.Body).Decode(&output))
+	suite.Equal("day", output.GroupBy)
+	suite.Len(output.Rows, 3)
+	suite.Equal(2, output.Total.Count)
+}
+
+func (suite *ReportHandlerTestSuite) TestGivenAnInvalidReport_WhenGetTheReport_ThenShouldReturnBadRequest() {
+	for _, target := range []string{
+		"/reports/orders?from=2026-02&to=2026-01",
+		"/reports/orders?from=2026-01&to=2026-02&group_by=year",
+		"/reports/orders?to=2026-02",
+		"/reports/orders?from=2026-01&to=2026-02&format=xml",
+	} {
+		suite.Equal(http.StatusBadRequest, suite.serve(target, "").Code, target)
+	}
+}
+
+func (suite *ReportHandlerTestSuite) TestGivenAReportThatCantBeEncoded_WhenGetTheReport_ThenShouldReturnAnInternalError() {
+	suite.Handler = NewReportHandler(usecase.NewReportOrdersUseCase(infiniteTotals{}))
+
+	rec := suite.serve("/reports/orders?from=2026-01-01&to=2026-01-01", "application/json")
+	suite.Equal(http.StatusInternalServerError, rec.Code)
+	suite.NotContains(rec.Header().Get("Content-Type"), "application/json")
+}
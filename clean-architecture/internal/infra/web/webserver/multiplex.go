@@ -0,0 +1,65 @@
+package webserver
+
+import (
+	"crypto/tls"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// GraphQLPrefix is where SinglePort serves GraphQL.
+const GraphQLPrefix = "/graphql"
+
+// SinglePort serves gRPC, GraphQL and the REST router on one listener. HTTP/2
+// requests with the application/grpc content type go to GRPC, the paths under
+// GraphQLPrefix to GraphQL, with the prefix removed (GraphQLPrefix itself
+// becomes "/"), and the rest to REST.
+// Without TLSConfig, HTTP/2 is served in cleartext (h2c), as gRPC requires.
+type SinglePort struct {
+	Addr      string
+	GRPC      http.Handler
+	GraphQL   http.Handler
+	REST      http.Handler
+	TLSConfig *tls.Config
+}
+
+// Handler dispatches each request to GRPC, GraphQL or REST.
+func (s *SinglePort) Handler() http.Handler {
+	graphql := http.StripPrefix(GraphQLPrefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// StripPrefix copies the URL, so it can be rewritten; an empty path
+		// would be redirected to "/" by a ServeMux
+		if r.URL.Path == "" {
+			r.URL.Path, r.URL.RawPath = "/", ""
+		}
+		s.GraphQL.ServeHTTP(w, r)
+	}))
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case isGRPC(r):
+			s.GRPC.ServeHTTP(w, r)
+		case r.URL.Path == GraphQLPrefix || strings.HasPrefix(r.URL.Path, GraphQLPrefix+"/"):
+			graphql.ServeHTTP(w, r)
+		default:
+			s.REST.ServeHTTP(w, r)
+		}
+	})
+	if s.TLSConfig != nil {
+		return handler
+	}
+	return h2c.NewHandler(handler, &http2.Server{})
+}
+
+// ListenAndServe serves Handler on Addr.
+func (s *SinglePort) ListenAndServe() error {
+	server := &http.Server{Addr: s.Addr, Handler: s.Handler(), TLSConfig: s.TLSConfig}
+	if s.TLSConfig != nil {
+		return server.ListenAndServeTLS("", "")
+	}
+	return server.ListenAndServe()
+}
+
+func isGRPC(r *http.Request) bool {
+	return r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
+}
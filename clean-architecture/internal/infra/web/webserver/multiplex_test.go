@@ -0,0 +1,103 @@
+package webserver
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// SinglePortTestSuite serves a gRPC health server, a GraphQL stub and a REST
+// stub on one h2c listener.
+type SinglePortTestSuite struct {
+	suite.Suite
+	Server *httptest.Server
+	GRPC   *grpc.Server
+}
+
+func (suite *SinglePortTestSuite) SetupTest() {
+	suite.GRPC = grpc.NewServer()
+	healthpb.RegisterHealthServer(suite.GRPC, health.NewServer())
+	echoPath := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, name+" "+r.URL.Path)
+		})
+	}
+	singlePort := &SinglePort{GRPC: suite.GRPC, GraphQL: echoPath("graphql"), REST: echoPath("rest")}
+	suite.Server = httptest.NewServer(singlePort.Handler())
+}
+
+func (suite *SinglePortTestSuite) TearDownTest() {
+	suite.Server.Close()
+	suite.GRPC.Stop()
+}
+
+func TestSinglePortSuite(t *testing.T) {
+	suite.Run(t, new(SinglePortTestSuite))
+}
+
+func (suite *SinglePortTestSuite) get(path string) string {
+	response, err := http.Get(suite.Server.URL + path)
+	suite.Require().NoError(err)
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	suite.Require().NoError(err)
+	return string(body)
+}
+
+func (suite *SinglePortTestSuite) TestGivenAGRPCCall_WhenServedOverH2C_ThenShouldReachTheGRPCServer() {
+	conn, err := grpc.NewClient(suite.Server.Listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	suite.Require().NoError(err)
+	defer conn.Close()
+
+	response, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	suite.Require().NoError(err)
+	suite.Equal(healthpb.HealthCheckResponse_SERVING, response.Status)
+}
+
+func (suite *SinglePortTestSuite) TestGivenAGraphQLPath_WhenGet_ThenShouldReachGraphQLWithoutThePrefix() {
+	suite.Equal("graphql /query", suite.get("/graphql/query"))
+}
+
+func (suite *SinglePortTestSuite) TestGivenTheGraphQLPrefixItself_WhenGet_ThenShouldReachTheGraphQLRoot() {
+	suite.Equal("graphql /", suite.get("/graphql"))
+	suite.Equal("graphql /", suite.get("/graphql?query=%7Bping%7D"))
+	suite.Equal("graphql /", suite.get("/graphql/"))
+}
+
+func (suite *SinglePortTestSuite) TestGivenAGraphQLServeMux_WhenGetThePrefix_ThenShouldNotRedirect() {
+	mux := http.NewServeMux()
+	mux.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "playground")
+	}))
+	server := httptest.NewServer((&SinglePort{GRPC: suite.GRPC, GraphQL: mux, REST: http.NotFoundHandler()}).Handler())
+	defer server.Close()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+
+	response, err := client.Get(server.URL + "/graphql")
+	suite.Require().NoError(err)
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Equal("playground", string(body))
+}
+
+func (suite *SinglePortTestSuite) TestGivenAnyOtherPath_WhenGet_ThenShouldReachTheRESTRouter() {
+	suite.Equal("rest /list", suite.get("/list"))
+	suite.Equal("rest /graphqlx", suite.get("/graphqlx"))
+}
+
+func (suite *SinglePortTestSuite) TestGivenAGRPCContentTypeOverHTTP1_WhenPost_ThenShouldReachTheRESTRouter() {
+	response, err := http.Post(suite.Server.URL+"/pb.OrderService/ListOrders", "application/grpc", nil)
+	suite.Require().NoError(err)
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+	suite.Equal("rest /pb.OrderService/ListOrders", string(body))
+}
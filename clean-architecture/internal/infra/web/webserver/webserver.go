@@ -1,37 +1,68 @@
 package webserver
 
 import (
+	"crypto/tls"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
 )
 
 type WebServer struct {
 	Router        chi.Router
-	Handlers      map[string]http.HandlerFunc
+	Handlers      map[string]map[string]http.HandlerFunc
+	Middlewares   []func(http.Handler) http.Handler
+	Mounts        map[string]http.Handler
 	WebServerPort string
+	// TLSConfig, when set, makes the server serve HTTPS.
+	TLSConfig *tls.Config
 }
 
 func NewWebServer(serverPort string) *WebServer {
 	return &WebServer{
 		Router:        chi.NewRouter(),
-		Handlers:      make(map[string]http.HandlerFunc),
+		Handlers:      make(map[string]map[string]http.HandlerFunc),
+		Mounts:        make(map[string]http.Handler),
 		WebServerPort: serverPort,
 	}
 }
 
 func (s *WebServer) AddHandler(method, path string, handler http.HandlerFunc) {
-	s.Handlers[path] = handler
+	if _, ok := s.Handlers[path]; !ok {
+		s.Handlers[path] = make(map[string]http.HandlerFunc)
+	}
+	s.Handlers[path][method] = handler
+}
+
+// Mount serves every method and path under pattern with handler.
+func (s *WebServer) Mount(pattern string, handler http.Handler) {
+	s.Mounts[pattern] = handler
+}
+
+func (s *WebServer) AddMiddleware(middleware func(http.Handler) http.Handler) {
+	s.Middlewares = append(s.Middlewares, middleware)
+}
+
+// Handler registers the middlewares (request id, logger, etc.), the handlers
+// and the mounts on the router, and returns it. It must be called once.
+func (s *WebServer) Handler() http.Handler {
+	s.Router.Use(s.Middlewares...)
+	for path, methods := range s.Handlers {
+		for method, handler := range methods {
+			s.Router.Method(method, path, handler)
+		}
+	}
+	for pattern, handler := range s.Mounts {
+		s.Router.Mount(pattern, handler)
+	}
+	return s.Router
 }
 
-// loop through the handlers and add them to the router
-// register middeleware logger
-// start the server
+// Start serves Handler on WebServerPort.
 func (s *WebServer) Start() {
-	s.Router.Use(middleware.Logger)
-	for path, handler := range s.Handlers {
-		s.Router.Handle(path, handler)
+	server := &http.Server{Addr: s.WebServerPort, Handler: s.Handler(), TLSConfig: s.TLSConfig}
+	if s.TLSConfig != nil {
+		server.ListenAndServeTLS("", "")
+		return
 	}
-	http.ListenAndServe(s.WebServerPort, s.Router)
+	server.ListenAndServe()
 }
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	ordersArchivedCounter, _ = meter.Int64Counter("orders_archived",
+		metric.WithDescription("Number of orders archived by the retention policy"))
+	ordersPurgedCounter, _ = meter.Int64Counter("orders_purged",
+		metric.WithDescription("Number of orders purged by the retention policy"))
+	retentionFailuresCounter, _ = meter.Int64Counter("order_retention_failures",
+		metric.WithDescription("Number of retention runs that failed"))
+)
+
+// DefaultRetentionBatchSize is how many expired orders are archived and purged
+// at a time.
+const DefaultRetentionBatchSize = 500
+
+type ApplyRetentionOutputDTO struct {
+	Archived int `json:"archived"`
+	Purged   int `json:"purged"`
+}
+
+// ApplyRetentionUseCase archives and then purges the orders created more than
+// MaxAge ago or soft deleted more than DeletedMaxAge ago. A zero age turns
+// that rule off. An order is only purged after it's archived, so a failed run
+// leaves it for the next one. Purge checks the rules again, so an order
+// restored after it was archived is kept, and isn't counted as purged.
+//
+// The purge deletes the rows of the read model directly, without going through
+// the projection, so OnPurged, when set, runs after every purged batch to let
+// whatever sits in front of the read model (the order cache) drop them too.
+type ApplyRetentionUseCase struct {
+	OrderRetentionRepository entity.OrderRetentionRepositoryInterface
+	OrderArchive             entity.OrderArchiveInterface
+	MaxAge                   time.Duration
+	DeletedMaxAge            time.Duration
+	BatchSize                int
+	OnPurged                 func(ctx context.Context)
+	now                      func() time.Time
+}
+
+func NewApplyRetentionUseCase(
+	OrderRetentionRepository entity.OrderRetentionRepositoryInterface,
+	OrderArchive entity.OrderArchiveInterface,
+) *ApplyRetentionUseCase {
+	return &ApplyRetentionUseCase{
+		OrderRetentionRepository: OrderRetentionRepository,
+		OrderArchive:             OrderArchive,
+		BatchSize:                DefaultRetentionBatchSize,
+		now:                      time.Now,
+	}
+}
+
+func (c *ApplyRetentionUseCase) Execute(ctx context.Context) (output ApplyRetentionOutputDTO, err error) {
+	ctx, end := startUseCase(ctx, "ApplyRetentionUseCase.Execute")
+	defer func() {
+		if err != nil {
+			retentionFailuresCounter.Add(ctx, 1)
+		}
+		end(err)
+	}()
+
+	now := c.now().UTC()
+	var createdBefore, deletedBefore time.Time
+	if c.MaxAge > 0 {
+		createdBefore = now.Add(-c.MaxAge)
+	}
+	if c.DeletedMaxAge > 0 {
+		deletedBefore = now.Add(-c.DeletedMaxAge)
+	}
+	if createdBefore.IsZero() && deletedBefore.IsZero() {
+		return output, nil
+	}
+
+	for afterID := ""; ; {
+		orders, err := c.OrderRetentionRepository.Expired(ctx, createdBefore, deletedBefore, afterID, c.BatchSize)
+		if err != nil {
+			return output, err
+		}
+		if len(orders) == 0 {
+			return output, nil
+		}
+		ids := make([]string, len(orders))
+		for i := range orders {
+			orders[i].ArchivedAt = now
+			ids[i] = orders[i].ID
+		}
+		if err := c.OrderArchive.Archive(ctx, orders); err != nil {
+			return output, err
+		}
+		output.Archived += len(orders)
+		ordersArchivedCounter.Add(ctx, int64(len(orders)))
+		purged, err := c.OrderRetentionRepository.Purge(ctx, createdBefore, deletedBefore, ids)
+		if err != nil {
+			return output, err
+		}
+		output.Purged += len(purged)
+		ordersPurgedCounter.Add(ctx, int64(len(purged)))
+		if c.OnPurged != nil {
+			c.OnPurged(ctx)
+		}
+		if len(orders) < c.BatchSize {
+			return output, nil
+		}
+		afterID = ids[len(ids)-1]
+	}
+}
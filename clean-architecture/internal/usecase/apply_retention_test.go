@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// OrderRetentionFake keeps the stored orders in memory, ordered by ID.
+type OrderRetentionFake struct {
+	Orders   []entity.ArchivedOrder
+	PurgeErr error
+	// BeforePurge, when set, changes Orders between Expired and Purge.
+	BeforePurge func()
+	Purges      [][]string
+	Archived    []entity.ArchivedOrder
+	Expiries    []time.Time
+	Deletions   []time.Time
+}
+
+func (f *OrderRetentionFake) Expired(ctx context.Context, createdBefore, deletedBefore time.Time, afterID string, limit int) ([]entity.ArchivedOrder, error) {
+	f.Expiries = append(f.Expiries, createdBefore)
+	f.Deletions = append(f.Deletions, deletedBefore)
+	var orders []entity.ArchivedOrder
+	for _, order := range f.Orders {
+		if order.ID > afterID && expired(order, createdBefore, deletedBefore) && len(orders) < limit {
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+func expired(order entity.ArchivedOrder, createdBefore, deletedBefore time.Time) bool {
+	return !createdBefore.IsZero() && order.CreatedAt.Before(createdBefore) ||
+		!deletedBefore.IsZero() && order.DeletedAt != nil && order.DeletedAt.Before(deletedBefore)
+}
+
+func (f *OrderRetentionFake) Purge(ctx context.Context, createdBefore, deletedBefore time.Time, ids []string) ([]string, error) {
+	if f.PurgeErr != nil {
+		return nil, f.PurgeErr
+	}
+	if f.BeforePurge != nil {
+		f.BeforePurge()
+	}
+	f.Purges = append(f.Purges, ids)
+	var orders []entity.ArchivedOrder
+	var purged []string
+	for _, order := range f.Orders {
+		if slices.Contains(ids, order.ID) && expired(order, createdBefore, deletedBefore) {
+			purged = append(purged, order.ID)
+			continue
+		}
+		orders = append(orders, order)
+	}
+	f.Orders = orders
+	return purged, nil
+}
+
+func (f *OrderRetentionFake) Archive(ctx context.Context, orders []entity.ArchivedOrder) error {
+	f.Archived = append(f.Archived, orders...)
+	return nil
+}
+
+type ApplyRetentionUseCaseTestSuite struct {
+	suite.Suite
+	now       time.Time
+	retention *OrderRetentionFake
+	useCase   *ApplyRetentionUseCase
+}
+
+func (suite *ApplyRetentionUseCaseTestSuite) SetupTest() {
+	suite.now = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	deletedAt := suite.now.AddDate(0, 0, -10)
+	suite.retention = &OrderRetentionFake{Orders: []entity.ArchivedOrder{
+		{ID: "a", CreatedAt: suite.now.AddDate(0, 0, -100)},
+		{ID: "b", CreatedAt: suite.now.AddDate(0, 0, -1)},
+		{ID: "c", CreatedAt: suite.now.AddDate(0, 0, -20), DeletedAt: &deletedAt},
+		{ID: "d", CreatedAt: suite.now.AddDate(0, 0, -95)},
+	}}
+	suite.useCase = NewApplyRetentionUseCase(suite.retention, suite.retention)
+	suite.useCase.now = func() time.Time { return suite.now }
+	suite.useCase.MaxAge = 90 * 24 * time.Hour
+	suite.useCase.DeletedMaxAge = 7 * 24 * time.Hour
+}
+
+func TestApplyRetentionUseCaseSuite(t *testing.T) {
+	suite.Run(t, new(ApplyRetentionUseCaseTestSuite))
+}
+
+func (suite *ApplyRetentionUseCaseTestSuite) TestGivenExpiredOrders_WhenExecute_ThenShouldArchiveThemBeforePurgingThemInBatches() {
+	suite.useCase.BatchSize = 2
+
+	output, err := suite.useCase.Execute(context.Background())
+	suite.Require().NoError(err)
+	suite.Equal(ApplyRetentionOutputDTO{Archived: 3, Purged: 3}, output)
+	suite.Equal([][]string{{"a", "c"}, {"d"}}, suite.retention.Purges)
+	suite.Len(suite.retention.Archived, 3)
+	for _, order := range suite.retention.Archived {
+		suite.Equal(suite.now, order.ArchivedAt)
+	}
+	suite.Equal([]entity.ArchivedOrder{{ID: "b", CreatedAt: suite.now.AddDate(0, 0, -1)}}, suite.retention.Orders)
+	suite.Equal(suite.now.AddDate(0, 0, -90), suite.retention.Expiries[0])
+	suite.Equal(suite.now.AddDate(0, 0, -7), suite.retention.Deletions[0])
+}
+
+func (suite *ApplyRetentionUseCaseTestSuite) TestGivenOnlyTheDeletedRule_WhenExecute_ThenShouldKeepTheOldOrders() {
+	suite.useCase.MaxAge = 0
+
+	output, err := suite.useCase.Execute(context.Background())
+	suite.Require().NoError(err)
+	suite.Equal(ApplyRetentionOutputDTO{Archived: 1, Purged: 1}, output)
+	suite.True(suite.retention.Expiries[0].IsZero())
+}
+
+func (suite *ApplyRetentionUseCaseTestSuite) TestGivenNoRule_WhenExecute_ThenShouldNotQueryTheRepository() {
+	suite.useCase.MaxAge, suite.useCase.DeletedMaxAge = 0, 0
+
+	output, err := suite.useCase.Execute(context.Background())
+	suite.Require().NoError(err)
+	suite.Zero(output)
+	suite.Empty(suite.retention.Expiries)
+}
+
+func (suite *ApplyRetentionUseCaseTestSuite) TestGivenAnOrderRestoredBeforeThePurge_WhenExecute_ThenShouldNotCountIt() {
+	suite.retention.BeforePurge = func() { suite.retention.Orders[2].DeletedAt = nil }
+	suite.useCase.MaxAge = 0
+
+	output, err := suite.useCase.Execute(context.Background())
+	suite.Require().NoError(err)
+	suite.Equal(ApplyRetentionOutputDTO{Archived: 1, Purged: 0}, output)
+	suite.Len(suite.retention.Orders, 4)
+}
+
+func (suite *ApplyRetentionUseCaseTestSuite) TestGivenThePurgeFails_WhenExecute_ThenShouldKeepTheArchivedOrders() {
+	suite.retention.PurgeErr = errors.New("db down")
+
+	output, err := suite.useCase.Execute(context.Background())
+	suite.EqualError(err, "db down")
+	suite.Equal(ApplyRetentionOutputDTO{Archived: 3}, output)
+	suite.Len(suite.retention.Orders, 4)
+}
+
+func (suite *ApplyRetentionUseCaseTestSuite) TestGivenAPurgeHook_WhenExecute_ThenShouldCallItAfterEachPurgedBatch() {
+	suite.useCase.BatchSize = 2
+	var purges []int
+	suite.useCase.OnPurged = func(ctx context.Context) {
+		purges = append(purges, len(suite.retention.Purges))
+	}
+
+	_, err := suite.useCase.Execute(context.Background())
+	suite.Require().NoError(err)
+	suite.Equal([]int{1, 2}, purges)
+}
+
+func (suite *ApplyRetentionUseCaseTestSuite) TestGivenAPurgeHookAndThePurgeFails_WhenExecute_ThenShouldNotCallIt() {
+	suite.retention.PurgeErr = errors.New("db down")
+	called := false
+	suite.useCase.OnPurged = func(ctx context.Context) { called = true }
+
+	_, err := suite.useCase.Execute(context.Background())
+	suite.Error(err)
+	suite.False(called)
+}
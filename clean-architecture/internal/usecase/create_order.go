@@ -1,10 +1,19 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/event"
 	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
 )
 
+// ErrInvalidOrder is returned for an order that entity.Order.IsValid rejects.
+var ErrInvalidOrder = errors.New("invalid order")
+
 type OrderInputDTO struct {
 	ID    string  `json:"id"`
 	Price float64 `json:"price"`
@@ -16,34 +25,42 @@ type OrderOutputDTO struct {
 	Price      float64 `json:"price"`
 	Tax        float64 `json:"tax"`
 	FinalPrice float64 `json:"final_price"`
+	// CreatedAt is when the order was saved, for the projection of an
+	// OrderCreated; it isn't part of the responses.
+	CreatedAt time.Time `json:"-"`
 }
 
+// CreateOrderUseCase saves an order and dispatches an OrderCreated for it. The
+// use case is shared by concurrent requests, so every dispatch gets its own
+// event.
 type CreateOrderUseCase struct {
 	OrderRepository entity.OrderRepositoryInterface
-	OrderCreated    events.EventInterface
 	EventDispatcher events.EventDispatcherInterface
 }
 
 func NewCreateOrderUseCase(
 	OrderRepository entity.OrderRepositoryInterface,
-	OrderCreated events.EventInterface,
 	EventDispatcher events.EventDispatcherInterface,
 ) *CreateOrderUseCase {
 	return &CreateOrderUseCase{
 		OrderRepository: OrderRepository,
-		OrderCreated:    OrderCreated,
 		EventDispatcher: EventDispatcher,
 	}
 }
 
-func (c *CreateOrderUseCase) Execute(input OrderInputDTO) (OrderOutputDTO, error) {
+func (c *CreateOrderUseCase) Execute(ctx context.Context, input OrderInputDTO) (output OrderOutputDTO, err error) {
+	ctx, end := startUseCase(ctx, "CreateOrderUseCase.Execute")
+	defer func() { end(err) }()
+
 	order := entity.Order{
 		ID:    input.ID,
 		Price: input.Price,
 		Tax:   input.Tax,
 	}
-	order.CalculateFinalPrice()
-	if err := c.OrderRepository.Save(&order); err != nil {
+	if err := order.CalculateFinalPrice(); err != nil {
+		return OrderOutputDTO{}, fmt.Errorf("%w: %w", ErrInvalidOrder, err)
+	}
+	if err := c.OrderRepository.Save(ctx, &order); err != nil {
 		return OrderOutputDTO{}, err
 	}
 
@@ -52,10 +69,13 @@ func (c *CreateOrderUseCase) Execute(input OrderInputDTO) (OrderOutputDTO, error
 		Price:      order.Price,
 		Tax:        order.Tax,
 		FinalPrice: order.Price + order.Tax,
+		CreatedAt:  order.CreatedAt,
 	}
 
-	c.OrderCreated.SetPayload(dto)
-	c.EventDispatcher.Dispatch(c.OrderCreated)
+	orderCreated := event.NewOrderCreated()
+	orderCreated.SetPayload(dto)
+	c.EventDispatcher.Dispatch(ctx, orderCreated)
+	ordersCreatedCounter.Add(ctx, 1)
 
 	return dto, nil
 }
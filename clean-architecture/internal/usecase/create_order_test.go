@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type OrderRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *OrderRepositoryMock) Save(ctx context.Context, order *entity.Order) error {
+	args := m.Called(ctx, order)
+	return args.Error(0)
+}
+
+func (m *OrderRepositoryMock) SaveBatch(ctx context.Context, orders []*entity.Order) error {
+	args := m.Called(ctx, orders)
+	return args.Error(0)
+}
+
+func (m *OrderRepositoryMock) ExistingIDs(ctx context.Context, ids []string) ([]string, error) {
+	args := m.Called(ctx, ids)
+	existing, _ := args.Get(0).([]string)
+	return existing, args.Error(1)
+}
+
+func (m *OrderRepositoryMock) ListOrders(ctx context.Context) ([]entity.Order, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]entity.Order), args.Error(1)
+}
+
+type CreateOrderUseCaseTestSuite struct {
+	suite.Suite
+	spanRecorder    *tracetest.SpanRecorder
+	orderRepository *OrderRepositoryMock
+	useCase         *CreateOrderUseCase
+}
+
+func (suite *CreateOrderUseCaseTestSuite) SetupTest() {
+	suite.spanRecorder = tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(suite.spanRecorder)))
+	suite.orderRepository = &OrderRepositoryMock{}
+	suite.useCase = NewCreateOrderUseCase(suite.orderRepository, events.NewEventDispatcher())
+}
+
+func TestCreateOrderUseCaseSuite(t *testing.T) {
+	suite.Run(t, new(CreateOrderUseCaseTestSuite))
+}
+
+func (suite *CreateOrderUseCaseTestSuite) TestGivenAValidInput_WhenExecute_ThenShouldRecordASpanParentOfTheRepository() {
+	var repositorySpan trace.SpanContext
+	suite.orderRepository.On("Save", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			repositorySpan = trace.SpanContextFromContext(args.Get(0).(context.Context))
+		}).
+		Return(nil)
+
+	output, err := suite.useCase.Execute(context.Background(), OrderInputDTO{ID: "123", Price: 10.0, Tax: 2.0})
+	suite.NoError(err)
+	suite.Equal(12.0, output.FinalPrice)
+
+	spans := suite.spanRecorder.Ended()
+	suite.Len(spans, 1)
+	suite.Equal("CreateOrderUseCase.Execute", spans[0].Name())
+	suite.Equal(spans[0].SpanContext().SpanID(), repositorySpan.SpanID())
+}
+
+func (suite *CreateOrderUseCaseTestSuite) TestGivenASavedOrder_WhenExecute_ThenShouldCarryItsCreationTime() {
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	suite.orderRepository.On("Save", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { args.Get(1).(*entity.Order).CreatedAt = createdAt }).
+		Return(nil)
+
+	output, err := suite.useCase.Execute(context.Background(), OrderInputDTO{ID: "123", Price: 10.0, Tax: 2.0})
+	suite.NoError(err)
+	suite.Equal(createdAt, output.CreatedAt)
+}
+
+func (suite *CreateOrderUseCaseTestSuite) TestGivenTwoOrders_WhenExecute_ThenShouldDispatchAnEventForEach() {
+	recorder := &EventRecorder{}
+	dispatcher := events.NewEventDispatcher()
+	dispatcher.Register("OrderCreated", recorder)
+	useCase := NewCreateOrderUseCase(suite.orderRepository, dispatcher)
+	suite.orderRepository.On("Save", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := useCase.Execute(context.Background(), OrderInputDTO{ID: "a", Price: 10.0, Tax: 2.0})
+	suite.Require().NoError(err)
+	_, err = useCase.Execute(context.Background(), OrderInputDTO{ID: "b", Price: 20.0, Tax: 2.0})
+	suite.Require().NoError(err)
+
+	suite.Require().Len(recorder.Events, 2)
+	suite.NotSame(recorder.Events[0], recorder.Events[1])
+	suite.Equal("a", recorder.Events[0].GetPayload().(OrderOutputDTO).ID)
+	suite.Equal("b", recorder.Events[1].GetPayload().(OrderOutputDTO).ID)
+}
+
+func (suite *CreateOrderUseCaseTestSuite) TestGivenARepositoryError_WhenExecute_ThenShouldMarkTheSpanAsError() {
+	suite.orderRepository.On("Save", mock.Anything, mock.Anything).Return(errors.New("db down"))
+
+	_, err := suite.useCase.Execute(context.Background(), OrderInputDTO{ID: "123", Price: 10.0, Tax: 2.0})
+	suite.Error(err)
+
+	spans := suite.spanRecorder.Ended()
+	suite.Len(spans, 1)
+	suite.Equal(codes.Error, spans[0].Status().Code)
+	suite.Equal("db down", spans[0].Status().Description)
+}
+
+func (suite *CreateOrderUseCaseTestSuite) TestGivenAnInvalidInput_WhenExecute_ThenShouldReturnErrInvalidOrderWithoutSaving() {
+	_, err := suite.useCase.Execute(context.Background(), OrderInputDTO{ID: "", Price: 0, Tax: 0})
+	suite.ErrorIs(err, ErrInvalidOrder)
+	suite.ErrorContains(err, "invalid id")
+	suite.orderRepository.AssertNotCalled(suite.T(), "Save", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"context"
+)
+
+type CreateOrdersOutputDTO struct {
+	Orders []OrderOutputDTO    `json:"orders"`
+	Failed int                 `json:"failed"`
+	Errors []ImportRowErrorDTO `json:"errors"`
+}
+
+// CreateOrdersUseCase creates a stream of orders in batches, the way
+// ImportOrdersUseCase imports them, and returns the orders created.
+type CreateOrdersUseCase struct {
+	ImportOrdersUseCase *ImportOrdersUseCase
+}
+
+func NewCreateOrdersUseCase(
+	ImportOrdersUseCase *ImportOrdersUseCase,
+) *CreateOrdersUseCase {
+	return &CreateOrdersUseCase{
+		ImportOrdersUseCase: ImportOrdersUseCase,
+	}
+}
+
+func (c *CreateOrdersUseCase) Execute(ctx context.Context, rows OrderInputReader) (output CreateOrdersOutputDTO, err error) {
+	ctx, end := startUseCase(ctx, "CreateOrdersUseCase.Execute")
+	defer func() { end(err) }()
+
+	output.Orders = []OrderOutputDTO{}
+	report, err := c.ImportOrdersUseCase.run(ctx, rows, false, func(order OrderOutputDTO) {
+		output.Orders = append(output.Orders, order)
+	})
+	output.Failed, output.Errors = report.Failed, report.Errors
+	return output, err
+}
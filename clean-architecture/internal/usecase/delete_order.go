@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/event"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
+)
+
+// DeleteOrderUseCase soft deletes an order: it's left out of the queries, but
+// kept until the retention policy purges it or RestoreOrderUseCase brings it
+// back. An unknown order is reported as entity.ErrOrderNotFound and an order
+// already deleted as entity.ErrOrderDeleted.
+type DeleteOrderUseCase struct {
+	OrderDeletionRepository entity.OrderDeletionRepositoryInterface
+	EventDispatcher         events.EventDispatcherInterface
+}
+
+func NewDeleteOrderUseCase(
+	OrderDeletionRepository entity.OrderDeletionRepositoryInterface,
+	EventDispatcher events.EventDispatcherInterface,
+) *DeleteOrderUseCase {
+	return &DeleteOrderUseCase{
+		OrderDeletionRepository: OrderDeletionRepository,
+		EventDispatcher:         EventDispatcher,
+	}
+}
+
+func (c *DeleteOrderUseCase) Execute(ctx context.Context, orderID string) (output OrderOutputDTO, err error) {
+	ctx, end := startUseCase(ctx, "DeleteOrderUseCase.Execute")
+	defer func() { end(err) }()
+
+	change, err := c.OrderDeletionRepository.Delete(ctx, orderID)
+	if err != nil {
+		return OrderOutputDTO{}, err
+	}
+
+	orderDeleted := event.NewOrderDeleted()
+	orderDeleted.SetPayload(change)
+	c.EventDispatcher.Dispatch(ctx, orderDeleted)
+	ordersDeletedCounter.Add(ctx, 1)
+
+	return orderOutput(change), nil
+}
+
+// orderOutput is the state of the order after the change.
+func orderOutput(change entity.OrderEvent) OrderOutputDTO {
+	return OrderOutputDTO{
+		ID:         change.OrderID,
+		Price:      change.Data.Price,
+		Tax:        change.Data.Tax,
+		FinalPrice: change.Data.FinalPrice,
+	}
+}
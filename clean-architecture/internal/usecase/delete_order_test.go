@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type OrderDeletionRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *OrderDeletionRepositoryMock) Delete(ctx context.Context, id string) (entity.OrderEvent, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entity.OrderEvent), args.Error(1)
+}
+
+func (m *OrderDeletionRepositoryMock) Restore(ctx context.Context, id string) (entity.OrderEvent, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entity.OrderEvent), args.Error(1)
+}
+
+// EventRecorder keeps the events it handles and their payloads.
+type EventRecorder struct {
+	Events   []events.EventInterface
+	Payloads []interface{}
+}
+
+func (h *EventRecorder) Handle(ctx context.Context, event events.EventInterface, wg *sync.WaitGroup) {
+	defer wg.Done()
+	h.Events = append(h.Events, event)
+	h.Payloads = append(h.Payloads, event.GetPayload())
+}
+
+type DeleteOrderUseCaseTestSuite struct {
+	suite.Suite
+	repository *OrderDeletionRepositoryMock
+	recorder   *EventRecorder
+	useCase    *DeleteOrderUseCase
+}
+
+func (suite *DeleteOrderUseCaseTestSuite) SetupTest() {
+	suite.repository = &OrderDeletionRepositoryMock{}
+	suite.recorder = &EventRecorder{}
+	dispatcher := events.NewEventDispatcher()
+	dispatcher.Register("OrderDeleted", suite.recorder)
+	suite.useCase = NewDeleteOrderUseCase(suite.repository, dispatcher)
+}
+
+func TestDeleteOrderUseCaseSuite(t *testing.T) {
+	suite.Run(t, new(DeleteOrderUseCaseTestSuite))
+}
+
+func (suite *DeleteOrderUseCaseTestSuite) TestGivenAnOrder_WhenExecute_ThenShouldDispatchTheOrderDeletedEvent() {
+	deletedAt := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	change := entity.OrderEvent{OrderID: "123", Version: 2, Type: entity.OrderDeletedEvent, Timestamp: deletedAt,
+		Data: entity.OrderEventData{Price: 10, Tax: 2, FinalPrice: 12, DeletedAt: &deletedAt}}
+	suite.repository.On("Delete", mock.Anything, "123").Return(change, nil)
+
+	output, err := suite.useCase.Execute(context.Background(), "123")
+	suite.Require().NoError(err)
+	suite.Equal(OrderOutputDTO{ID: "123", Price: 10, Tax: 2, FinalPrice: 12}, output)
+	suite.Equal([]interface{}{change}, suite.recorder.Payloads)
+}
+
+func (suite *DeleteOrderUseCaseTestSuite) TestGivenTwoOrders_WhenExecute_ThenShouldDispatchAnEventForEach() {
+	suite.repository.On("Delete", mock.Anything, "a").Return(entity.OrderEvent{OrderID: "a", Type: entity.OrderDeletedEvent}, nil)
+	suite.repository.On("Delete", mock.Anything, "b").Return(entity.OrderEvent{OrderID: "b", Type: entity.OrderDeletedEvent}, nil)
+
+	_, err := suite.useCase.Execute(context.Background(), "a")
+	suite.Require().NoError(err)
+	_, err = suite.useCase.Execute(context.Background(), "b")
+	suite.Require().NoError(err)
+
+	suite.Require().Len(suite.recorder.Events, 2)
+	suite.NotSame(suite.recorder.Events[0], suite.recorder.Events[1])
+	suite.Equal("a", suite.recorder.Events[0].GetPayload().(entity.OrderEvent).OrderID)
+}
+
+func (suite *DeleteOrderUseCaseTestSuite) TestGivenADeletedOrder_WhenExecute_ThenShouldNotDispatchAnEvent() {
+	suite.repository.On("Delete", mock.Anything, "123").Return(entity.OrderEvent{}, entity.ErrOrderDeleted)
+
+	_, err := suite.useCase.Execute(context.Background(), "123")
+	suite.ErrorIs(err, entity.ErrOrderDeleted)
+	suite.Empty(suite.recorder.Payloads)
+}
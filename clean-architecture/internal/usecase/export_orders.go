@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+)
+
+// ExportOrdersUseCase streams every order of the read model to fn, without
+// holding them all in memory.
+type ExportOrdersUseCase struct {
+	OrderViewRepository entity.OrderViewRepositoryInterface
+}
+
+func NewExportOrdersUseCase(
+	OrderViewRepository entity.OrderViewRepositoryInterface,
+) *ExportOrdersUseCase {
+	return &ExportOrdersUseCase{
+		OrderViewRepository: OrderViewRepository,
+	}
+}
+
+func (c *ExportOrdersUseCase) Execute(ctx context.Context, fn func(order ListOrdersOutputDTO) error) (err error) {
+	ctx, end := startUseCase(ctx, "ExportOrdersUseCase.Execute")
+	defer func() { end(err) }()
+
+	return c.OrderViewRepository.ForEach(ctx, func(view entity.OrderView) error {
+		return fn(ListOrdersOutputDTO{
+			ID:         view.ID,
+			Price:      view.Price,
+			Tax:        view.Tax,
+			FinalPrice: view.FinalPrice,
+		})
+	})
+}
@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+)
+
+// GetOrderUseCase reads one order from the read model. An unknown order is
+// reported as entity.ErrOrderNotFound.
+type GetOrderUseCase struct {
+	OrderViewRepository entity.OrderViewRepositoryInterface
+}
+
+func NewGetOrderUseCase(
+	OrderViewRepository entity.OrderViewRepositoryInterface,
+) *GetOrderUseCase {
+	return &GetOrderUseCase{
+		OrderViewRepository: OrderViewRepository,
+	}
+}
+
+func (c *GetOrderUseCase) Execute(ctx context.Context, orderID string) (dto ListOrdersOutputDTO, err error) {
+	ctx, end := startUseCase(ctx, "GetOrderUseCase.Execute")
+	defer func() { end(err) }()
+
+	view, err := c.OrderViewRepository.FindOrder(ctx, orderID)
+	if err != nil {
+		return ListOrdersOutputDTO{}, err
+	}
+	return ListOrdersOutputDTO{
+		ID:         view.ID,
+		Price:      view.Price,
+		Tax:        view.Tax,
+		FinalPrice: view.FinalPrice,
+	}, nil
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+)
+
+type OrderStateDTO struct {
+	Price      float64 `json:"price"`
+	Tax        float64 `json:"tax"`
+	FinalPrice float64 `json:"final_price"`
+}
+
+type OrderHistoryOutputDTO struct {
+	Action    string         `json:"action"`
+	Actor     string         `json:"actor"`
+	Transport string         `json:"transport"`
+	RequestID string         `json:"request_id"`
+	Before    *OrderStateDTO `json:"before"`
+	After     *OrderStateDTO `json:"after"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
+// GetOrderHistoryUseCase returns the audit trail of an order. An order without
+// entries is reported as entity.ErrOrderNotFound.
+type GetOrderHistoryUseCase struct {
+	OrderAuditRepository entity.OrderAuditRepositoryInterface
+}
+
+func NewGetOrderHistoryUseCase(
+	OrderAuditRepository entity.OrderAuditRepositoryInterface,
+) *GetOrderHistoryUseCase {
+	return &GetOrderHistoryUseCase{
+		OrderAuditRepository: OrderAuditRepository,
+	}
+}
+
+func (c *GetOrderHistoryUseCase) Execute(ctx context.Context, orderID string) (dtos []OrderHistoryOutputDTO, err error) {
+	ctx, end := startUseCase(ctx, "GetOrderHistoryUseCase.Execute")
+	defer func() { end(err) }()
+
+	entries, err := c.OrderAuditRepository.History(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: %s", entity.ErrOrderNotFound, orderID)
+	}
+
+	return orderHistory(entries), nil
+}
+
+// ExecuteBatch returns the audit trail of several orders at once, keyed by
+// order ID. Unlike Execute, orders without entries are just left out.
+func (c *GetOrderHistoryUseCase) ExecuteBatch(ctx context.Context, orderIDs []string) (histories map[string][]OrderHistoryOutputDTO, err error) {
+	ctx, end := startUseCase(ctx, "GetOrderHistoryUseCase.ExecuteBatch")
+	defer func() { end(err) }()
+
+	entries, err := c.OrderAuditRepository.Histories(ctx, orderIDs)
+	if err != nil {
+		return nil, err
+	}
+	histories = make(map[string][]OrderHistoryOutputDTO, len(entries))
+	for orderID, orderEntries := range entries {
+		histories[orderID] = orderHistory(orderEntries)
+	}
+	return histories, nil
+}
+
+func orderHistory(entries []entity.OrderAuditEntry) []OrderHistoryOutputDTO {
+	dtos := make([]OrderHistoryOutputDTO, 0, len(entries))
+	for _, entry := range entries {
+		dtos = append(dtos, OrderHistoryOutputDTO{
+			Action:    entry.Action,
+			Actor:     entry.Actor,
+			Transport: entry.Transport,
+			RequestID: entry.RequestID,
+			Before:    orderState(entry.Before),
+			After:     orderState(entry.After),
+			Timestamp: entry.Timestamp,
+		})
+	}
+	return dtos
+}
+
+func orderState(data *entity.OrderEventData) *OrderStateDTO {
+	if data == nil {
+		return nil
+	}
+	return &OrderStateDTO{Price: data.Price, Tax: data.Tax, FinalPrice: data.FinalPrice}
+}
@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type OrderAuditRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *OrderAuditRepositoryMock) History(ctx context.Context, orderID string) ([]entity.OrderAuditEntry, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]entity.OrderAuditEntry), args.Error(1)
+}
+
+func (m *OrderAuditRepositoryMock) Histories(ctx context.Context, orderIDs []string) (map[string][]entity.OrderAuditEntry, error) {
+	args := m.Called(ctx, orderIDs)
+	return args.Get(0).(map[string][]entity.OrderAuditEntry), args.Error(1)
+}
+
+type GetOrderHistoryUseCaseTestSuite struct {
+	suite.Suite
+	audit *OrderAuditRepositoryMock
+}
+
+func (suite *GetOrderHistoryUseCaseTestSuite) SetupTest() {
+	suite.audit = &OrderAuditRepositoryMock{}
+}
+
+func TestGetOrderHistoryUseCaseSuite(t *testing.T) {
+	suite.Run(t, new(GetOrderHistoryUseCaseTestSuite))
+}
+
+func (suite *GetOrderHistoryUseCaseTestSuite) TestGivenAuditEntries_WhenExecute_ThenShouldMapThem() {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	suite.audit.On("History", mock.Anything, "a").Return([]entity.OrderAuditEntry{
+		{OrderID: "a", Action: entity.OrderCreatedEvent, Actor: "alice", Transport: "REST", RequestID: "req-1",
+			After: &entity.OrderEventData{Price: 10.0, Tax: 2.0, FinalPrice: 12.0}, Timestamp: at},
+		{OrderID: "a", Action: entity.OrderUpdatedEvent, Actor: "bob", Transport: "gRPC", RequestID: "req-2",
+			Before: &entity.OrderEventData{Price: 10.0, Tax: 2.0, FinalPrice: 12.0},
+			After:  &entity.OrderEventData{Price: 20.0, Tax: 2.0, FinalPrice: 22.0}, Timestamp: at},
+	}, nil)
+
+	output, err := NewGetOrderHistoryUseCase(suite.audit).Execute(context.Background(), "a")
+	suite.NoError(err)
+	suite.Equal([]OrderHistoryOutputDTO{
+		{Action: entity.OrderCreatedEvent, Actor: "alice", Transport: "REST", RequestID: "req-1",
+			After: &OrderStateDTO{Price: 10.0, Tax: 2.0, FinalPrice: 12.0}, Timestamp: at},
+		{Action: entity.OrderUpdatedEvent, Actor: "bob", Transport: "gRPC", RequestID: "req-2",
+			Before: &OrderStateDTO{Price: 10.0, Tax: 2.0, FinalPrice: 12.0},
+			After:  &OrderStateDTO{Price: 20.0, Tax: 2.0, FinalPrice: 22.0}, Timestamp: at},
+	}, output)
+}
+
+func (suite *GetOrderHistoryUseCaseTestSuite) TestGivenNoAuditEntries_WhenExecute_ThenShouldReturnNotFound() {
+	suite.audit.On("History", mock.Anything, "a").Return([]entity.OrderAuditEntry(nil), nil)
+
+	_, err := NewGetOrderHistoryUseCase(suite.audit).Execute(context.Background(), "a")
+	suite.ErrorIs(err, entity.ErrOrderNotFound)
+}
+
+func (suite *GetOrderHistoryUseCaseTestSuite) TestGivenSeveralOrders_WhenExecuteBatch_ThenShouldMapEachHistory() {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	suite.audit.On("Histories", mock.Anything, []string{"a", "b"}).Return(map[string][]entity.OrderAuditEntry{
+		"a": {{OrderID: "a", Action: entity.OrderCreatedEvent, Actor: "alice", Transport: "REST", RequestID: "req-1",
+			After: &entity.OrderEventData{Price: 10.0, Tax: 2.0, FinalPrice: 12.0}, Timestamp: at}},
+	}, nil)
+
+	output, err := NewGetOrderHistoryUseCase(suite.audit).ExecuteBatch(context.Background(), []string{"a", "b"})
+	suite.NoError(err)
+	suite.Equal(map[string][]OrderHistoryOutputDTO{
+		"a": {{Action: entity.OrderCreatedEvent, Actor: "alice", Transport: "REST", RequestID: "req-1",
+			After: &OrderStateDTO{Price: 10.0, Tax: 2.0, FinalPrice: 12.0}, Timestamp: at}},
+	}, output)
+}
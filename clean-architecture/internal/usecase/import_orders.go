@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/event"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
+)
+
+// DefaultImportBatchSize is how many orders are saved per transaction.
+const DefaultImportBatchSize = 500
+
+// ErrMalformedRow is wrapped by an OrderInputReader when a row can't be
+// decoded; the row is reported and the import goes on.
+var ErrMalformedRow = errors.New("malformed row")
+
+// OrderInputReader yields the rows of an import, one order at a time. Read
+// returns io.EOF after the last row.
+type OrderInputReader interface {
+	Read() (OrderInputDTO, error)
+}
+
+type ImportRowErrorDTO struct {
+	Row   int    `json:"row"`
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error"`
+}
+
+type ImportOrdersOutputDTO struct {
+	Rows     int                 `json:"rows"`
+	Imported int                 `json:"imported"`
+	Failed   int                 `json:"failed"`
+	DryRun   bool                `json:"dry_run"`
+	Errors   []ImportRowErrorDTO `json:"errors"`
+}
+
+// ImportOrdersUseCase validates every row with entity.Order.IsValid and saves
+// the valid ones in batches of BatchSize, each batch in one transaction. The
+// rows whose ID is already stored are rejected before the batch is saved; when
+// the batch still fails, its rows are saved one by one, so only the failing
+// ones are reported. Rows are numbered from 1. A dry run only validates,
+// against the stored IDs too.
+type ImportOrdersUseCase struct {
+	OrderRepository entity.OrderRepositoryInterface
+	EventDispatcher events.EventDispatcherInterface
+	BatchSize       int
+}
+
+func NewImportOrdersUseCase(
+	OrderRepository entity.OrderRepositoryInterface,
+	EventDispatcher events.EventDispatcherInterface,
+) *ImportOrdersUseCase {
+	return &ImportOrdersUseCase{
+		OrderRepository: OrderRepository,
+		EventDispatcher: EventDispatcher,
+		BatchSize:       DefaultImportBatchSize,
+	}
+}
+
+type importRow struct {
+	row   int
+	order *entity.Order
+}
+
+// Execute reads every row of rows. It only fails when rows can't be read any
+// further, returning the report up to that point.
+func (c *ImportOrdersUseCase) Execute(ctx context.Context, rows OrderInputReader, dryRun bool) (output ImportOrdersOutputDTO, err error) {
+	ctx, end := startUseCase(ctx, "ImportOrdersUseCase.Execute")
+	defer func() { end(err) }()
+
+	return c.run(ctx, rows, dryRun, nil)
+}
+
+// run imports rows, calling saved, when not nil, with each saved order.
+func (c *ImportOrdersUseCase) run(ctx context.Context, rows OrderInputReader, dryRun bool, saved func(order OrderOutputDTO)) (ImportOrdersOutputDTO, error) {
+	output := ImportOrdersOutputDTO{DryRun: dryRun, Errors: []ImportRowErrorDTO{}}
+	var batch []importRow
+	seen := map[string]bool{}
+	flush := func() {
+		if len(batch) > 0 {
+			c.importBatch(ctx, batch, dryRun, &output, saved)
+		}
+		batch = batch[:0]
+		clear(seen)
+	}
+
+	for {
+		input, err := rows.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		output.Rows++
+		if errors.Is(err, ErrMalformedRow) {
+			output.reject(output.Rows, "", err)
+			continue
+		}
+		if err != nil {
+			flush()
+			return output, fmt.Errorf("reading row %d: %w", output.Rows, err)
+		}
+
+		order := &entity.Order{ID: input.ID, Price: input.Price, Tax: input.Tax}
+		if err := order.CalculateFinalPrice(); err != nil {
+			output.reject(output.Rows, input.ID, err)
+			continue
+		}
+		if seen[order.ID] {
+			output.reject(output.Rows, input.ID, errors.New("duplicate id in the batch"))
+			continue
+		}
+		seen[order.ID] = true
+		batch = append(batch, importRow{row: output.Rows, order: order})
+		if c.BatchSize > 0 && len(batch) >= c.BatchSize {
+			flush()
+		}
+	}
+	flush()
+	return output, nil
+}
+
+// importBatch rejects the rows whose ID is already stored and saves the
+// others, unless it's a dry run.
+func (c *ImportOrdersUseCase) importBatch(ctx context.Context, batch []importRow, dryRun bool, output *ImportOrdersOutputDTO, saved func(order OrderOutputDTO)) {
+	ids := make([]string, len(batch))
+	for i, row := range batch {
+		ids[i] = row.order.ID
+	}
+	existing, err := c.OrderRepository.ExistingIDs(ctx, ids)
+	if err != nil {
+		for _, row := range batch {
+			output.reject(row.row, row.order.ID, err)
+		}
+		return
+	}
+	taken := make(map[string]bool, len(existing))
+	for _, id := range existing {
+		taken[id] = true
+	}
+	rows := make([]importRow, 0, len(batch))
+	for _, row := range batch {
+		if taken[row.order.ID] {
+			output.reject(row.row, row.order.ID, entity.ErrOrderAlreadyExists)
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	if dryRun {
+		output.Imported += len(rows)
+		return
+	}
+	if err := c.saveBatch(ctx, rows, output, saved); err == nil {
+		return
+	}
+	// an order saved since ExistingIDs, or a single bad row, fails the whole
+	// transaction: save them one by one to report only the failing ones
+	for _, row := range rows {
+		if err := c.saveBatch(ctx, []importRow{row}, output, saved); err != nil {
+			if errors.Is(err, entity.ErrOrderAlreadyExists) {
+				err = entity.ErrOrderAlreadyExists
+			}
+			output.reject(row.row, row.order.ID, err)
+		}
+	}
+}
+
+// saveBatch saves the rows in one transaction and dispatches an OrderCreated
+// for each saved order, as CreateOrderUseCase does.
+func (c *ImportOrdersUseCase) saveBatch(ctx context.Context, batch []importRow, output *ImportOrdersOutputDTO, saved func(order OrderOutputDTO)) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	orders := make([]*entity.Order, len(batch))
+	for i, row := range batch {
+		orders[i] = row.order
+	}
+	if err := c.OrderRepository.SaveBatch(ctx, orders); err != nil {
+		return err
+	}
+
+	output.Imported += len(orders)
+	for _, order := range orders {
+		dto := OrderOutputDTO{
+			ID:         order.ID,
+			Price:      order.Price,
+			Tax:        order.Tax,
+			FinalPrice: order.FinalPrice,
+			CreatedAt:  order.CreatedAt,
+		}
+		orderCreated := event.NewOrderCreated()
+		orderCreated.SetPayload(dto)
+		c.EventDispatcher.Dispatch(ctx, orderCreated)
+		if saved != nil {
+			saved(dto)
+		}
+	}
+	ordersCreatedCounter.Add(ctx, int64(len(orders)))
+	return nil
+}
+
+func (o *ImportOrdersOutputDTO) reject(row int, id string, err error) {
+	o.Failed++
+	o.Errors = append(o.Errors, ImportRowErrorDTO{Row: row, ID: id, Error: err.Error()})
+}
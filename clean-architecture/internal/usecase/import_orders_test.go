@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// rowsStub yields fixed rows; a non nil error in errs is returned for that row.
+type rowsStub struct {
+	inputs []OrderInputDTO
+	errs   []error
+	next   int
+}
+
+func (r *rowsStub) Read() (OrderInputDTO, error) {
+	if r.next == len(r.inputs) {
+		return OrderInputDTO{}, io.EOF
+	}
+	i := r.next
+	r.next++
+	if i < len(r.errs) && r.errs[i] != nil {
+		return OrderInputDTO{}, r.errs[i]
+	}
+	return r.inputs[i], nil
+}
+
+type ImportOrdersUseCaseTestSuite struct {
+	suite.Suite
+	orderRepository *OrderRepositoryMock
+	useCase         *ImportOrdersUseCase
+}
+
+func (suite *ImportOrdersUseCaseTestSuite) SetupTest() {
+	suite.orderRepository = &OrderRepositoryMock{}
+	suite.orderRepository.On("ExistingIDs", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	suite.useCase = NewImportOrdersUseCase(suite.orderRepository, events.NewEventDispatcher())
+	suite.useCase.BatchSize = 2
+}
+
+func TestImportOrdersUseCaseSuite(t *testing.T) {
+	suite.Run(t, new(ImportOrdersUseCaseTestSuite))
+}
+
+func batchOf(ids ...string) interface{} {
+	return mock.MatchedBy(func(orders []*entity.Order) bool {
+		if len(orders) != len(ids) {
+			return false
+		}
+		for i, order := range orders {
+			if order.ID != ids[i] {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func (suite *ImportOrdersUseCaseTestSuite) TestGivenABatch_WhenExecute_ThenShouldDispatchAnEventForEachOrder() {
+	recorder := &EventRecorder{}
+	dispatcher := events.NewEventDispatcher()
+	dispatcher.Register("OrderCreated", recorder)
+	useCase := NewImportOrdersUseCase(suite.orderRepository, dispatcher)
+	suite.orderRepository.On("SaveBatch", mock.Anything, batchOf("a", "b")).Return(nil).Once()
+	rows := &rowsStub{inputs: []OrderInputDTO{{ID: "a", Price: 10, Tax: 1}, {ID: "b", Price: 20, Tax: 2}}}
+
+	_, err := useCase.Execute(context.Background(), rows, false)
+	suite.Require().NoError(err)
+	suite.Require().Len(recorder.Events, 2)
+	suite.NotSame(recorder.Events[0], recorder.Events[1])
+	suite.Equal("a", recorder.Events[0].GetPayload().(OrderOutputDTO).ID)
+	suite.Equal("b", recorder.Events[1].GetPayload().(OrderOutputDTO).ID)
+}
+
+func (suite *ImportOrdersUseCaseTestSuite) TestGivenValidAndInvalidRows_WhenExecute_ThenShouldSaveValidOnesInBatches() {
+	suite.orderRepository.On("SaveBatch", mock.Anything, batchOf("a", "b")).Return(nil).Once()
+	suite.orderRepository.On("SaveBatch", mock.Anything, batchOf("d")).Return(nil).Once()
+	rows := &rowsStub{
+		inputs: []OrderInputDTO{{ID: "a", Price: 10, Tax: 1}, {ID: "b", Price: 20, Tax: 2}, {ID: "c", Price: 0, Tax: 1}, {}, {ID: "d", Price: 30, Tax: 3}},
+		errs:   []error{nil, nil, nil, fmt.Errorf("%w: invalid price", ErrMalformedRow)},
+	}
+
+	output, err := suite.useCase.Execute(context.Background(), rows, false)
+	suite.NoError(err)
+	suite.Equal(ImportOrdersOutputDTO{
+		Rows:     5,
+		Imported: 3,
+		Failed:   2,
+		Errors: []ImportRowErrorDTO{
+			{Row: 3, ID: "c", Error: "invalid price"},
+			{Row: 4, Error: "malformed row: invalid price"},
+		},
+	}, output)
+	suite.orderRepository.AssertExpectations(suite.T())
+}
+
+func (suite *ImportOrdersUseCaseTestSuite) TestGivenAFailingBatch_WhenExecute_ThenShouldSaveItsRowsOneByOne() {
+	alreadyExists := fmt.Errorf("%w: a", entity.ErrOrderAlreadyExists)
+	suite.orderRepository.On("SaveBatch", mock.Anything, batchOf("a", "b")).Return(alreadyExists).Once()
+	suite.orderRepository.On("SaveBatch", mock.Anything, batchOf("a")).Return(alreadyExists).Once()
+	suite.orderRepository.On("SaveBatch", mock.Anything, batchOf("b")).Return(nil).Once()
+	suite.orderRepository.On("SaveBatch", mock.Anything, batchOf("c")).Return(errors.New("connection reset")).Twice()
+	rows := &rowsStub{inputs: []OrderInputDTO{{ID: "a", Price: 10, Tax: 1}, {ID: "b", Price: 20, Tax: 2}, {ID: "c", Price: 30, Tax: 3}}}
+
+	output, err := suite.useCase.Execute(context.Background(), rows, false)
+	suite.NoError(err)
+	suite.Equal(1, output.Imported)
+	suite.Equal([]ImportRowErrorDTO{{Row: 1, ID: "a", Error: "order id already exists"}, {Row: 3, ID: "c", Error: "connection reset"}}, output.Errors)
+	suite.orderRepository.AssertExpectations(suite.T())
+}
+
+func (suite *ImportOrdersUseCaseTestSuite) TestGivenStoredIDs_WhenExecute_ThenShouldRejectOnlyThoseRows() {
+	suite.orderRepository.ExpectedCalls = nil
+	suite.orderRepository.On("ExistingIDs", mock.Anything, []string{"a", "b"}).Return([]string{"b"}, nil).Twice()
+	suite.orderRepository.On("SaveBatch", mock.Anything, batchOf("a")).Return(nil).Once()
+	rows := func() *rowsStub {
+		return &rowsStub{inputs: []OrderInputDTO{{ID: "a", Price: 10, Tax: 1}, {ID: "b", Price: 20, Tax: 2}}}
+	}
+
+	for _, dryRun := range []bool{true, false} {
+		output, err := suite.useCase.Execute(context.Background(), rows(), dryRun)
+		suite.NoError(err)
+		suite.Equal(1, output.Imported)
+		suite.Equal([]ImportRowErrorDTO{{Row: 2, ID: "b", Error: "order id already exists"}}, output.Errors)
+	}
+	suite.orderRepository.AssertExpectations(suite.T())
+}
+
+func (suite *ImportOrdersUseCaseTestSuite) TestGivenADuplicateIDInTheBatch_WhenExecute_ThenShouldRejectTheSecond() {
+	suite.orderRepository.On("SaveBatch", mock.Anything, batchOf("a")).Return(nil).Once()
+	suite.useCase.BatchSize = 10
+	rows := &rowsStub{inputs: []OrderInputDTO{{ID: "a", Price: 10, Tax: 1}, {ID: "a", Price: 20, Tax: 2}}}
+
+	output, err := suite.useCase.Execute(context.Background(), rows, false)
+	suite.NoError(err)
+	suite.Equal([]ImportRowErrorDTO{{Row: 2, ID: "a", Error: "duplicate id in the batch"}}, output.Errors)
+}
+
+func (suite *ImportOrdersUseCaseTestSuite) TestGivenADryRun_WhenExecute_ThenShouldOnlyValidate() {
+	rows := &rowsStub{inputs: []OrderInputDTO{{ID: "a", Price: 10, Tax: 1}, {ID: "b", Price: 20, Tax: 2}, {ID: "c", Price: 30}}}
+
+	output, err := suite.useCase.Execute(context.Background(), rows, true)
+	suite.NoError(err)
+	suite.True(output.DryRun)
+	suite.Equal(2, output.Imported)
+	suite.Equal(1, output.Failed)
+	suite.orderRepository.AssertNotCalled(suite.T(), "SaveBatch", mock.Anything, mock.Anything)
+}
+
+func (suite *ImportOrdersUseCaseTestSuite) TestGivenAReadError_WhenExecute_ThenShouldSaveWhatWasReadAndFail() {
+	suite.orderRepository.On("SaveBatch", mock.Anything, batchOf("a")).Return(nil).Once()
+	rows := &rowsStub{
+		inputs: []OrderInputDTO{{ID: "a", Price: 10, Tax: 1}, {}},
+		errs:   []error{nil, io.ErrUnexpectedEOF},
+	}
+
+	output, err := suite.useCase.Execute(context.Background(), rows, false)
+	suite.ErrorIs(err, io.ErrUnexpectedEOF)
+	suite.Equal(1, output.Imported)
+}
+
+func (suite *ImportOrdersUseCaseTestSuite) TestGivenTheReadModel_WhenExport_ThenShouldStreamEachOrder() {
+	views := &OrderViewRepositoryMock{}
+	views.On("ForEach", mock.Anything, mock.Anything).Return([]entity.OrderView{
+		{ID: "a", Price: 10, Tax: 1, FinalPrice: 11},
+		{ID: "b", Price: 20, Tax: 2, FinalPrice: 22},
+	}, nil)
+
+	var exported []ListOrdersOutputDTO
+	err := NewExportOrdersUseCase(views).Execute(context.Background(), func(order ListOrdersOutputDTO) error {
+		exported = append(exported, order)
+		return nil
+	})
+	suite.NoError(err)
+	suite.Equal([]ListOrdersOutputDTO{{ID: "a", Price: 10, Tax: 1, FinalPrice: 11}, {ID: "b", Price: 20, Tax: 2, FinalPrice: 22}}, exported)
+}
+
+func (suite *ImportOrdersUseCaseTestSuite) TestGivenAStreamOfOrders_WhenCreateOrders_ThenShouldReturnTheCreatedOnes() {
+	suite.orderRepository.On("SaveBatch", mock.Anything, batchOf("a", "b")).Return(nil).Once()
+	rows := &rowsStub{inputs: []OrderInputDTO{{ID: "a", Price: 10, Tax: 1}, {ID: "x"}, {ID: "b", Price: 20, Tax: 2}}}
+
+	output, err := NewCreateOrdersUseCase(suite.useCase).Execute(context.Background(), rows)
+	suite.NoError(err)
+	suite.Equal(CreateOrdersOutputDTO{
+		Orders: []OrderOutputDTO{{ID: "a", Price: 10, Tax: 1, FinalPrice: 11}, {ID: "b", Price: 20, Tax: 2, FinalPrice: 22}},
+		Failed: 1,
+		Errors: []ImportRowErrorDTO{{Row: 2, ID: "x", Error: "invalid price"}},
+	}, output)
+}
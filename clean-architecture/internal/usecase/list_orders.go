@@ -1,6 +1,8 @@
 package usecase
 
 import (
+	"context"
+
 	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
 )
 
@@ -11,31 +13,35 @@ type ListOrdersOutputDTO struct {
 	FinalPrice float64 `json:"final_price"`
 }
 
+// ListOrdersUseCase reads only from the read model (order_views), never from
+// the tables the writes hit.
 type ListOrdersUseCase struct {
-	OrderRepository entity.OrderRepositoryInterface
+	OrderViewRepository entity.OrderViewRepositoryInterface
 }
 
 func NewListOrdersUseCase(
-	OrderRepository entity.OrderRepositoryInterface,
+	OrderViewRepository entity.OrderViewRepositoryInterface,
 ) *ListOrdersUseCase {
 	return &ListOrdersUseCase{
-		OrderRepository: OrderRepository,
+		OrderViewRepository: OrderViewRepository,
 	}
 }
 
-func (c *ListOrdersUseCase) Execute() ([]ListOrdersOutputDTO, error) {
-	orders, err := c.OrderRepository.ListOrders()
+func (c *ListOrdersUseCase) Execute(ctx context.Context) (dtos []ListOrdersOutputDTO, err error) {
+	ctx, end := startUseCase(ctx, "ListOrdersUseCase.Execute")
+	defer func() { end(err) }()
+
+	orders, err := c.OrderViewRepository.ListOrders(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	var dtos []ListOrdersOutputDTO
 	for _, order := range orders {
 		dto := ListOrdersOutputDTO{
 			ID:         order.ID,
 			Price:      order.Price,
 			Tax:        order.Tax,
-			FinalPrice: order.Price + order.Tax,
+			FinalPrice: order.FinalPrice,
 		}
 		dtos = append(dtos, dto)
 	}
@@ -0,0 +1,238 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type OrderViewRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *OrderViewRepositoryMock) Apply(ctx context.Context, event entity.OrderEvent) (bool, error) {
+	args := m.Called(ctx, event)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *OrderViewRepositoryMock) Reset(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *OrderViewRepositoryMock) FindOrder(ctx context.Context, id string) (entity.OrderView, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entity.OrderView), args.Error(1)
+}
+
+func (m *OrderViewRepositoryMock) ListOrders(ctx context.Context) ([]entity.OrderView, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]entity.OrderView), args.Error(1)
+}
+
+func (m *OrderViewRepositoryMock) ListOrdersPage(ctx context.Context, afterID string, limit int) ([]entity.OrderView, error) {
+	args := m.Called(ctx, afterID, limit)
+	return args.Get(0).([]entity.OrderView), args.Error(1)
+}
+
+func (m *OrderViewRepositoryMock) SearchOrders(ctx context.Context, query entity.OrderQuery, afterID string, limit int) ([]entity.OrderView, error) {
+	args := m.Called(ctx, query, afterID, limit)
+	return args.Get(0).([]entity.OrderView), args.Error(1)
+}
+
+func (m *OrderViewRepositoryMock) ForEach(ctx context.Context, fn func(view entity.OrderView) error) error {
+	args := m.Called(ctx, fn)
+	for _, view := range args.Get(0).([]entity.OrderView) {
+		if err := fn(view); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+// OrderHistoryStub replays a fixed list of events, counting the full replays.
+type OrderHistoryStub struct {
+	Events []entity.OrderEvent
+	Scans  int
+}
+
+func (h *OrderHistoryStub) ReplayEvents(ctx context.Context, fn func(event entity.OrderEvent) error) error {
+	h.Scans++
+	for _, event := range h.Events {
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *OrderHistoryStub) ReplayOrderEvents(ctx context.Context, id string, fn func(event entity.OrderEvent) error) error {
+	for _, event := range h.Events {
+		if event.OrderID != id {
+			continue
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type OrderViewsUseCaseTestSuite struct {
+	suite.Suite
+	views *OrderViewRepositoryMock
+}
+
+func (suite *OrderViewsUseCaseTestSuite) SetupTest() {
+	suite.views = &OrderViewRepositoryMock{}
+}
+
+func TestOrderViewsUseCaseSuite(t *testing.T) {
+	suite.Run(t, new(OrderViewsUseCaseTestSuite))
+}
+
+func (suite *OrderViewsUseCaseTestSuite) TestGivenProjectedOrders_WhenListOrders_ThenShouldReturnTheProjectedFinalPrice() {
+	suite.views.On("ListOrders", mock.Anything).Return([]entity.OrderView{
+		{ID: "a", Price: 10.0, Tax: 2.0, FinalPrice: 12.5, Version: 3, UpdatedAt: time.Now()},
+	}, nil)
+
+	output, err := NewListOrdersUseCase(suite.views).Execute(context.Background())
+	suite.NoError(err)
+	suite.Equal([]ListOrdersOutputDTO{{ID: "a", Price: 10.0, Tax: 2.0, FinalPrice: 12.5}}, output)
+}
+
+func (suite *OrderViewsUseCaseTestSuite) TestGivenAHistory_WhenRebuild_ThenShouldResetAndProjectEveryEvent() {
+	history := &OrderHistoryStub{Events: []entity.OrderEvent{
+		{OrderID: "a", Version: 1, Type: entity.OrderCreatedEvent},
+		{OrderID: "a", Version: 2, Type: entity.OrderUpdatedEvent},
+		{OrderID: "b", Version: 1, Type: entity.OrderCreatedEvent},
+	}}
+	suite.views.On("Reset", mock.Anything).Return(nil).Once()
+	suite.views.On("Apply", mock.Anything, history.Events[0]).Return(true, nil).Once()
+	// already projected live while rebuilding
+	suite.views.On("Apply", mock.Anything, history.Events[1]).Return(false, nil).Once()
+	suite.views.On("Apply", mock.Anything, history.Events[2]).Return(true, nil).Once()
+
+	output, err := NewRebuildOrderViewsUseCase(history, suite.views).Execute(context.Background())
+	suite.NoError(err)
+	suite.Equal(RebuildOrderViewsOutputDTO{Events: 3, Applied: 2}, output)
+	suite.views.AssertExpectations(suite.T())
+}
+
+func (suite *OrderViewsUseCaseTestSuite) TestGivenAFailingProjection_WhenRebuild_ThenShouldStopWithTheError() {
+	history := &OrderHistoryStub{Events: []entity.OrderEvent{{OrderID: "a", Version: 1}, {OrderID: "b", Version: 1}}}
+	suite.views.On("Reset", mock.Anything).Return(nil)
+	suite.views.On("Apply", mock.Anything, history.Events[0]).Return(false, errors.New("db down"))
+
+	output, err := NewRebuildOrderViewsUseCase(history, suite.views).Execute(context.Background())
+	suite.EqualError(err, "db down")
+	suite.Equal(0, output.Events)
+	suite.views.AssertNotCalled(suite.T(), "Apply", mock.Anything, history.Events[1])
+}
+
+func (suite *OrderViewsUseCaseTestSuite) TestGivenFiveOrders_WhenStreamOrdersByTwo_ThenShouldSendThreePages() {
+	views := []entity.OrderView{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}, {ID: "e"}}
+	suite.views.On("ListOrdersPage", mock.Anything, "", 2).Return(views[0:2], nil)
+	suite.views.On("ListOrdersPage", mock.Anything, "b", 2).Return(views[2:4], nil)
+	suite.views.On("ListOrdersPage", mock.Anything, "d", 2).Return(views[4:], nil)
+
+	var pages [][]string
+	err := NewStreamOrdersUseCase(suite.views).Execute(context.Background(), 2, func(page []ListOrdersOutputDTO) error {
+		var ids []string
+		for _, order := range page {
+			ids = append(ids, order.ID)
+		}
+		pages = append(pages, ids)
+		return nil
+	})
+	suite.NoError(err)
+	suite.Equal([][]string{{"a", "b"}, {"c", "d"}, {"e"}}, pages)
+}
+
+func (suite *OrderViewsUseCaseTestSuite) TestGivenACanceledContext_WhenStreamOrders_ThenShouldStopBeforeTheNextPage() {
+	ctx, cancel := context.WithCancel(context.Background())
+	suite.views.On("ListOrdersPage", mock.Anything, "", 1).Return([]entity.OrderView{{ID: "a"}}, nil)
+
+	err := NewStreamOrdersUseCase(suite.views).Execute(ctx, 1, func(page []ListOrdersOutputDTO) error {
+		cancel()
+		return nil
+	})
+	suite.ErrorIs(err, context.Canceled)
+	suite.views.AssertNumberOfCalls(suite.T(), "ListOrdersPage", 1)
+}
+
+func (suite *OrderViewsUseCaseTestSuite) TestGivenAProjectedOrder_WhenGetOrder_ThenShouldReturnIt() {
+	suite.views.On("FindOrder", mock.Anything, "a").Return(entity.OrderView{ID: "a", Price: 10.0, Tax: 2.0, FinalPrice: 12.0, Version: 2}, nil)
+	suite.views.On("FindOrder", mock.Anything, "b").Return(entity.OrderView{}, entity.ErrOrderNotFound)
+
+	output, err := NewGetOrderUseCase(suite.views).Execute(context.Background(), "a")
+	suite.NoError(err)
+	suite.Equal(ListOrdersOutputDTO{ID: "a", Price: 10.0, Tax: 2.0, FinalPrice: 12.0}, output)
+
+	_, err = NewGetOrderUseCase(suite.views).Execute(context.Background(), "b")
+	suite.ErrorIs(err, entity.ErrOrderNotFound)
+}
+
+func (suite *OrderViewsUseCaseTestSuite) TestGivenAnOrderID_WhenReplayEvents_ThenShouldReplayOnlyItsEvents() {
+	history := &OrderHistoryStub{Events: []entity.OrderEvent{
+		{OrderID: "a", Version: 1, Type: entity.OrderCreatedEvent},
+		{OrderID: "b", Version: 1, Type: entity.OrderCreatedEvent, Data: entity.OrderEventData{Price: 1.0, Tax: 1.0, FinalPrice: 2.0}},
+		{OrderID: "b", Version: 2, Type: entity.OrderUpdatedEvent},
+		{OrderID: "c", Version: 1, Type: entity.OrderCreatedEvent},
+	}}
+
+	var replayed []OrderEventOutputDTO
+	err := NewReplayOrderEventsUseCase(history).Execute(context.Background(), "b", func(event OrderEventOutputDTO) error {
+		replayed = append(replayed, event)
+		return nil
+	})
+	suite.NoError(err)
+	suite.Require().Len(replayed, 2)
+	suite.Equal(OrderStateDTO{Price: 1.0, Tax: 1.0, FinalPrice: 2.0}, replayed[0].Data)
+	suite.Equal(entity.OrderUpdatedEvent, replayed[1].Type)
+	suite.Zero(history.Scans)
+
+	count := 0
+	err = NewReplayOrderEventsUseCase(history).Execute(context.Background(), "", func(OrderEventOutputDTO) error {
+		count++
+		return nil
+	})
+	suite.NoError(err)
+	suite.Equal(4, count)
+}
+
+func (suite *OrderViewsUseCaseTestSuite) TestGivenMoreOrdersThanAPage_WhenSearchOrders_ThenShouldHandOutTheNextPageToken() {
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	query, err := entity.ParseOrderQuery("price>100")
+	suite.Require().NoError(err)
+	suite.views.On("SearchOrders", mock.Anything, query, "", 3).
+		Return([]entity.OrderView{{ID: "a", Price: 101.0, CreatedAt: createdAt}, {ID: "b"}, {ID: "c"}}, nil)
+	suite.views.On("SearchOrders", mock.Anything, query, "b", 3).Return([]entity.OrderView{{ID: "c"}}, nil)
+
+	search := NewSearchOrdersUseCase(suite.views)
+	output, err := search.Execute(context.Background(), SearchOrdersInputDTO{Query: "price>100", PageSize: 2})
+	suite.NoError(err)
+	suite.Equal([]OrderSearchResultDTO{{ID: "a", Price: 101.0, CreatedAt: createdAt}, {ID: "b"}}, output.Orders)
+	suite.NotEmpty(output.NextPageToken)
+
+	output, err = search.Execute(context.Background(), SearchOrdersInputDTO{Query: "price>100", PageSize: 2, PageToken: output.NextPageToken})
+	suite.NoError(err)
+	suite.Equal([]OrderSearchResultDTO{{ID: "c"}}, output.Orders)
+	suite.Empty(output.NextPageToken)
+}
+
+func (suite *OrderViewsUseCaseTestSuite) TestGivenAnInvalidQueryOrToken_WhenSearchOrders_ThenShouldNotQueryTheReadModel() {
+	search := NewSearchOrdersUseCase(suite.views)
+	_, err := search.Execute(context.Background(), SearchOrdersInputDTO{Query: "status:paid"})
+	suite.ErrorIs(err, entity.ErrInvalidOrderQuery)
+
+	_, err = search.Execute(context.Background(), SearchOrdersInputDTO{PageToken: "not base64!"})
+	suite.ErrorIs(err, ErrInvalidPageToken)
+	suite.views.AssertNotCalled(suite.T(), "SearchOrders", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+)
+
+type RebuildOrderViewsOutputDTO struct {
+	Events  int `json:"events"`
+	Applied int `json:"applied"`
+}
+
+// RebuildOrderViewsUseCase empties the read model and projects the whole order
+// history again. Events projected live during the rebuild are not lost: the
+// projection skips versions it already has.
+type RebuildOrderViewsUseCase struct {
+	OrderHistory        entity.OrderHistoryInterface
+	OrderViewRepository entity.OrderViewRepositoryInterface
+}
+
+func NewRebuildOrderViewsUseCase(
+	OrderHistory entity.OrderHistoryInterface,
+	OrderViewRepository entity.OrderViewRepositoryInterface,
+) *RebuildOrderViewsUseCase {
+	return &RebuildOrderViewsUseCase{
+		OrderHistory:        OrderHistory,
+		OrderViewRepository: OrderViewRepository,
+	}
+}
+
+func (c *RebuildOrderViewsUseCase) Execute(ctx context.Context) (output RebuildOrderViewsOutputDTO, err error) {
+	ctx, end := startUseCase(ctx, "RebuildOrderViewsUseCase.Execute")
+	defer func() { end(err) }()
+
+	if err := c.OrderViewRepository.Reset(ctx); err != nil {
+		return RebuildOrderViewsOutputDTO{}, err
+	}
+	err = c.OrderHistory.ReplayEvents(ctx, func(event entity.OrderEvent) error {
+		applied, err := c.OrderViewRepository.Apply(ctx, event)
+		if err != nil {
+			return err
+		}
+		output.Events++
+		if applied {
+			output.Applied++
+		}
+		return nil
+	})
+	return output, err
+}
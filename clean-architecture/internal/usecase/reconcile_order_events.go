@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
+
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	unpublishedEventsGauge, _ = meter.Int64Gauge("order_events_unpublished",
+		metric.WithDescription("Number of stored orders whose OrderCreated event was not published, as of the last reconciliation"))
+	republishedEventsCounter, _ = meter.Int64Counter("order_events_republished",
+		metric.WithDescription("Number of OrderCreated events republished by the reconciliation"))
+	republishFailuresCounter, _ = meter.Int64Counter("order_events_republish_failures",
+		metric.WithDescription("Number of OrderCreated events the reconciliation failed to republish"))
+)
+
+const (
+	// DefaultReconcileBatchSize is how many unpublished orders are read per query.
+	DefaultReconcileBatchSize = 500
+	// DefaultReconcileGracePeriod is how long an order may go without its
+	// event published before it's republished.
+	DefaultReconcileGracePeriod = 5 * time.Minute
+)
+
+// OrderEventPublisher publishes an event to the message broker, recording it
+// as published on success.
+type OrderEventPublisher interface {
+	Publish(ctx context.Context, event events.EventInterface) error
+}
+
+type ReconcileOrderEventsOutputDTO struct {
+	DryRun bool `json:"dry_run"`
+	// Missing lists the orders whose OrderCreated event was not published.
+	Missing []string `json:"missing"`
+	// Pending are the missing orders still in their grace period, left for
+	// a later run.
+	Pending     int `json:"pending"`
+	Republished int `json:"republished"`
+	Failed      int `json:"failed"`
+}
+
+// ReconcileOrderEventsUseCase compares the stored orders with the log of
+// published events and republishes the OrderCreated events that were lost.
+//
+// An order that was just created may not have its event published yet, so an
+// order is only republished once it has been missing for GracePeriod; the
+// orders still in their grace period are reported as pending. When an order
+// was first found missing is kept by PublishedEvents, so that it survives a
+// restart and the replica holding the reconciliation lease changing.
+type ReconcileOrderEventsUseCase struct {
+	PublishedEvents entity.PublishedEventRepositoryInterface
+	OrderCreated    events.EventInterface
+	Publisher       OrderEventPublisher
+	BatchSize       int
+	GracePeriod     time.Duration
+	now             func() time.Time
+}
+
+func NewReconcileOrderEventsUseCase(
+	PublishedEvents entity.PublishedEventRepositoryInterface,
+	OrderCreated events.EventInterface,
+	Publisher OrderEventPublisher,
+) *ReconcileOrderEventsUseCase {
+	return &ReconcileOrderEventsUseCase{
+		PublishedEvents: PublishedEvents,
+		OrderCreated:    OrderCreated,
+		Publisher:       Publisher,
+		BatchSize:       DefaultReconcileBatchSize,
+		GracePeriod:     DefaultReconcileGracePeriod,
+		now:             time.Now,
+	}
+}
+
+// Execute finds the unpublished orders and, unless dryRun is set, republishes
+// the ones missing for longer than GracePeriod. A failed republish is counted
+// and retried on the next run. A dry run records nothing.
+func (c *ReconcileOrderEventsUseCase) Execute(ctx context.Context, dryRun bool) (output ReconcileOrderEventsOutputDTO, err error) {
+	ctx, end := startUseCase(ctx, "ReconcileOrderEventsUseCase.Execute")
+	defer func() { end(err) }()
+
+	output = ReconcileOrderEventsOutputDTO{DryRun: dryRun, Missing: []string{}}
+	now := c.now().UTC()
+	eventName := c.OrderCreated.GetName()
+	for afterID := ""; ; {
+		orders, err := c.PublishedEvents.Unpublished(ctx, eventName, afterID, c.BatchSize)
+		if err != nil {
+			return ReconcileOrderEventsOutputDTO{}, err
+		}
+		ids := make([]string, len(orders))
+		for i, order := range orders {
+			ids[i] = order.ID
+		}
+		output.Missing = append(output.Missing, ids...)
+		if len(orders) > 0 {
+			afterID = ids[len(ids)-1]
+		}
+		if !dryRun {
+			firstSeen, err := c.PublishedEvents.MarkMissing(ctx, eventName, ids, now)
+			if err != nil {
+				return ReconcileOrderEventsOutputDTO{}, err
+			}
+			for _, order := range orders {
+				if now.Sub(firstSeen[order.ID]) < c.GracePeriod {
+					output.Pending++
+					continue
+				}
+				if err := c.republish(ctx, order); err != nil {
+					slog.WarnContext(ctx, "failed to republish event", slog.String("event", eventName),
+						slog.String("order_id", order.ID), slog.Any("error", err))
+					output.Failed++
+					continue
+				}
+				output.Republished++
+			}
+		}
+		if len(orders) < c.BatchSize {
+			break
+		}
+	}
+
+	unpublishedEventsGauge.Record(ctx, int64(len(output.Missing)))
+	if !dryRun {
+		republishedEventsCounter.Add(ctx, int64(output.Republished))
+		republishFailuresCounter.Add(ctx, int64(output.Failed))
+		if err := c.PublishedEvents.PruneMissing(ctx, eventName); err != nil {
+			return output, err
+		}
+	}
+	return output, nil
+}
+
+func (c *ReconcileOrderEventsUseCase) republish(ctx context.Context, order entity.Order) error {
+	c.OrderCreated.SetPayload(OrderOutputDTO{
+		ID:         order.ID,
+		Price:      order.Price,
+		Tax:        order.Tax,
+		FinalPrice: order.FinalPrice,
+	})
+	return c.Publisher.Publish(ctx, c.OrderCreated)
+}
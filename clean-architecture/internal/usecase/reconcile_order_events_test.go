@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/event"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// PublishedEventsFake keeps the published and missing events in memory;
+// Orders are the stored orders, ordered by ID.
+type PublishedEventsFake struct {
+	Orders    []entity.Order
+	Published map[string]bool
+	Missing   map[string]time.Time
+}
+
+func (f *PublishedEventsFake) MarkPublished(ctx context.Context, eventName, orderID string) error {
+	f.Published[orderID] = true
+	return nil
+}
+
+func (f *PublishedEventsFake) Unpublished(ctx context.Context, eventName, afterID string, limit int) ([]entity.Order, error) {
+	var orders []entity.Order
+	for _, order := range f.Orders {
+		if order.ID > afterID && !f.Published[order.ID] && len(orders) < limit {
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+func (f *PublishedEventsFake) MarkMissing(ctx context.Context, eventName string, orderIDs []string, at time.Time) (map[string]time.Time, error) {
+	firstSeen := map[string]time.Time{}
+	for _, id := range orderIDs {
+		if _, ok := f.Missing[id]; !ok {
+			f.Missing[id] = at
+		}
+		firstSeen[id] = f.Missing[id]
+	}
+	return firstSeen, nil
+}
+
+func (f *PublishedEventsFake) PruneMissing(ctx context.Context, eventName string) error {
+	for id := range f.Missing {
+		if f.Published[id] {
+			delete(f.Missing, id)
+		}
+	}
+	return nil
+}
+
+// EventPublisherStub marks the event published unless the order is in Fail.
+type EventPublisherStub struct {
+	PublishedEvents *PublishedEventsFake
+	Fail            map[string]bool
+	Published       []OrderOutputDTO
+}
+
+func (p *EventPublisherStub) Publish(ctx context.Context, event events.EventInterface) error {
+	order := event.GetPayload().(OrderOutputDTO)
+	if p.Fail[order.ID] {
+		return errors.New("channel closed")
+	}
+	p.Published = append(p.Published, order)
+	return p.PublishedEvents.MarkPublished(ctx, event.GetName(), order.ID)
+}
+
+type ReconcileOrderEventsUseCaseTestSuite struct {
+	suite.Suite
+	now       time.Time
+	published *PublishedEventsFake
+	publisher *EventPublisherStub
+	reconcile *ReconcileOrderEventsUseCase
+}
+
+func (suite *ReconcileOrderEventsUseCaseTestSuite) SetupTest() {
+	suite.published = &PublishedEventsFake{
+		Orders: []entity.Order{
+			{ID: "a", Price: 10.0, Tax: 1.0, FinalPrice: 11.0},
+			{ID: "b", Price: 20.0, Tax: 2.0, FinalPrice: 22.0},
+			{ID: "c", Price: 30.0, Tax: 3.0, FinalPrice: 33.0},
+		},
+		Published: map[string]bool{"b": true},
+		Missing:   map[string]time.Time{},
+	}
+	suite.publisher = &EventPublisherStub{PublishedEvents: suite.published, Fail: map[string]bool{}}
+	suite.now = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	suite.reconcile = suite.newReconcile()
+}
+
+// newReconcile builds the use case on the suite's clock and fakes, as a
+// replica starting would.
+func (suite *ReconcileOrderEventsUseCaseTestSuite) newReconcile() *ReconcileOrderEventsUseCase {
+	reconcile := NewReconcileOrderEventsUseCase(suite.published, event.NewOrderCreated(), suite.publisher)
+	reconcile.BatchSize = 1
+	reconcile.GracePeriod = time.Minute
+	reconcile.now = func() time.Time { return suite.now }
+	return reconcile
+}
+
+func TestReconcileOrderEventsUseCaseSuite(t *testing.T) {
+	suite.Run(t, new(ReconcileOrderEventsUseCaseTestSuite))
+}
+
+func (suite *ReconcileOrderEventsUseCaseTestSuite) TestGivenUnpublishedOrders_WhenDryRun_ThenShouldOnlyReportThem() {
+	output, err := suite.reconcile.Execute(context.Background(), true)
+	suite.NoError(err)
+	suite.Equal(ReconcileOrderEventsOutputDTO{DryRun: true, Missing: []string{"a", "c"}}, output)
+	suite.Empty(suite.publisher.Published)
+	suite.Empty(suite.published.Missing)
+}
+
+func (suite *ReconcileOrderEventsUseCaseTestSuite) TestGivenUnpublishedOrders_WhenExecuteAfterTheGracePeriod_ThenShouldRepublishThem() {
+	ctx := context.Background()
+	output, err := suite.reconcile.Execute(ctx, false)
+	suite.NoError(err)
+	suite.Equal(ReconcileOrderEventsOutputDTO{Missing: []string{"a", "c"}, Pending: 2}, output)
+	suite.Empty(suite.publisher.Published)
+
+	// published meanwhile, so it is not republished
+	suite.published.Published["c"] = true
+	suite.published.Orders = append(suite.published.Orders, entity.Order{ID: "d"})
+	suite.now = suite.now.Add(time.Minute)
+
+	output, err = suite.reconcile.Execute(ctx, false)
+	suite.NoError(err)
+	suite.Equal(ReconcileOrderEventsOutputDTO{Missing: []string{"a", "d"}, Pending: 1, Republished: 1}, output)
+	suite.Equal([]OrderOutputDTO{{ID: "a", Price: 10.0, Tax: 1.0, FinalPrice: 11.0}}, suite.publisher.Published)
+	suite.Equal(map[string]time.Time{"d": suite.now}, suite.published.Missing)
+}
+
+func (suite *ReconcileOrderEventsUseCaseTestSuite) TestGivenAnOrderInItsGracePeriod_WhenExecuteAgain_ThenShouldLeaveItPending() {
+	ctx := context.Background()
+	_, err := suite.reconcile.Execute(ctx, false)
+	suite.NoError(err)
+
+	suite.now = suite.now.Add(59 * time.Second)
+	output, err := suite.reconcile.Execute(ctx, false)
+	suite.NoError(err)
+	suite.Equal(ReconcileOrderEventsOutputDTO{Missing: []string{"a", "c"}, Pending: 2}, output)
+	suite.Empty(suite.publisher.Published)
+}
+
+func (suite *ReconcileOrderEventsUseCaseTestSuite) TestGivenARestart_WhenExecuteAfterTheGracePeriod_ThenShouldRepublishWithoutWaitingAgain() {
+	ctx := context.Background()
+	_, err := suite.reconcile.Execute(ctx, false)
+	suite.NoError(err)
+
+	suite.now = suite.now.Add(time.Minute)
+	output, err := suite.newReconcile().Execute(ctx, false)
+	suite.NoError(err)
+	suite.Equal(ReconcileOrderEventsOutputDTO{Missing: []string{"a", "c"}, Republished: 2}, output)
+}
+
+func (suite *ReconcileOrderEventsUseCaseTestSuite) TestGivenAFailingPublisher_WhenExecute_ThenShouldRetryOnTheNextRun() {
+	ctx := context.Background()
+	suite.publisher.Fail["a"] = true
+	_, err := suite.reconcile.Execute(ctx, false)
+	suite.NoError(err)
+
+	suite.now = suite.now.Add(time.Minute)
+	output, err := suite.reconcile.Execute(ctx, false)
+	suite.NoError(err)
+	suite.Equal(1, output.Failed)
+	suite.Equal(1, output.Republished)
+
+	delete(suite.publisher.Fail, "a")
+	output, err = suite.reconcile.Execute(ctx, false)
+	suite.NoError(err)
+	suite.Equal(ReconcileOrderEventsOutputDTO{Missing: []string{"a"}, Republished: 1}, output)
+}
@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+)
+
+type OrderEventOutputDTO struct {
+	OrderID   string        `json:"order_id"`
+	Version   int           `json:"version"`
+	Type      string        `json:"type"`
+	Data      OrderStateDTO `json:"data"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// ReplayOrderEventsUseCase hands the recorded order events to fn, in version
+// order within each order, without projecting them anywhere: it lets an
+// operator read the history that a projections rebuild would replay.
+type ReplayOrderEventsUseCase struct {
+	OrderHistory entity.OrderHistoryInterface
+}
+
+func NewReplayOrderEventsUseCase(
+	OrderHistory entity.OrderHistoryInterface,
+) *ReplayOrderEventsUseCase {
+	return &ReplayOrderEventsUseCase{
+		OrderHistory: OrderHistory,
+	}
+}
+
+// Execute replays the events of every order, or only those of orderID when
+// it isn't empty.
+func (c *ReplayOrderEventsUseCase) Execute(ctx context.Context, orderID string, fn func(event OrderEventOutputDTO) error) (err error) {
+	ctx, end := startUseCase(ctx, "ReplayOrderEventsUseCase.Execute")
+	defer func() { end(err) }()
+
+	output := func(event entity.OrderEvent) error {
+		return fn(OrderEventOutputDTO{
+			OrderID: event.OrderID,
+			Version: event.Version,
+			Type:    event.Type,
+			Data: OrderStateDTO{
+				Price:      event.Data.Price,
+				Tax:        event.Data.Tax,
+				FinalPrice: event.Data.FinalPrice,
+			},
+			Timestamp: event.Timestamp,
+		})
+	}
+	if orderID != "" {
+		return c.OrderHistory.ReplayOrderEvents(ctx, orderID, output)
+	}
+	return c.OrderHistory.ReplayEvents(ctx, output)
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+)
+
+// MaxReportPeriods is how many periods a report may have: two years by day.
+const MaxReportPeriods = 731
+
+type ReportOrdersInputDTO struct {
+	// From and To are parsed by entity.ParseOrderReportRange.
+	From string
+	To   string
+	// GroupBy is day (the default), week or month.
+	GroupBy string
+}
+
+type OrderReportRowDTO struct {
+	// Start and End bound the period: from Start, inclusive, to End, exclusive.
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	Count         int       `json:"count"`
+	Price         float64   `json:"price"`
+	Tax           float64   `json:"tax"`
+	FinalPrice    float64   `json:"final_price"`
+	AverageTicket float64   `json:"average_ticket"`
+}
+
+type ReportOrdersOutputDTO struct {
+	GroupBy string              `json:"group_by"`
+	Rows    []OrderReportRowDTO `json:"rows"`
+	// Total sums the whole range.
+	Total OrderReportRowDTO `json:"total"`
+}
+
+// ReportOrdersUseCase sums the orders created in a range, one row per UTC
+// day, week or month, with a row for every period even when it has no orders.
+// An invalid range or grouping is reported as entity.ErrInvalidOrderReport.
+type ReportOrdersUseCase struct {
+	OrderTotalsRepository entity.OrderTotalsRepositoryInterface
+}
+
+func NewReportOrdersUseCase(
+	OrderTotalsRepository entity.OrderTotalsRepositoryInterface,
+) *ReportOrdersUseCase {
+	return &ReportOrdersUseCase{
+		OrderTotalsRepository: OrderTotalsRepository,
+	}
+}
+
+func (c *ReportOrdersUseCase) Execute(ctx context.Context, input ReportOrdersInputDTO) (output ReportOrdersOutputDTO, err error) {
+	ctx, end := startUseCase(ctx, "ReportOrdersUseCase.Execute")
+	defer func() { end(err) }()
+
+	period, err := entity.ParseOrderReportRange(input.From, input.To)
+	if err != nil {
+		return ReportOrdersOutputDTO{}, err
+	}
+	grouping, err := entity.ParseOrderReportGrouping(input.GroupBy)
+	if err != nil {
+		return ReportOrdersOutputDTO{}, err
+	}
+	periods, err := grouping.Split(period, MaxReportPeriods)
+	if err != nil {
+		return ReportOrdersOutputDTO{}, err
+	}
+
+	output.GroupBy = string(grouping)
+	output.Rows = make([]OrderReportRowDTO, 0, len(periods))
+	totals, err := c.OrderTotalsRepository.GetTotals(ctx, periods)
+	if err != nil {
+		return ReportOrdersOutputDTO{}, err
+	}
+	var total entity.OrderTotals
+	for i, p := range periods {
+		output.Rows = append(output.Rows, reportRow(p, totals[i]))
+		total = total.Add(totals[i])
+	}
+	output.Total = reportRow(period, total)
+	return output, nil
+}
+
+func reportRow(period entity.OrderPeriod, totals entity.OrderTotals) OrderReportRowDTO {
+	return OrderReportRowDTO{
+		Start:         period.From,
+		End:           period.To,
+		Count:         totals.Count,
+		Price:         totals.Price,
+		Tax:           totals.Tax,
+		FinalPrice:    totals.FinalPrice,
+		AverageTicket: totals.AverageTicket(),
+	}
+}
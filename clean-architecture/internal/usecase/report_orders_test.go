@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type OrderTotalsRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *OrderTotalsRepositoryMock) GetTotals(ctx context.Context, periods []entity.OrderPeriod) ([]entity.OrderTotals, error) {
+	args := m.Called(ctx, periods)
+	totals, _ := args.Get(0).([]entity.OrderTotals)
+	return totals, args.Error(1)
+}
+
+type ReportOrdersUseCaseTestSuite struct {
+	suite.Suite
+	totals *OrderTotalsRepositoryMock
+}
+
+func (suite *ReportOrdersUseCaseTestSuite) SetupTest() {
+	suite.totals = &OrderTotalsRepositoryMock{}
+}
+
+func TestReportOrdersUseCaseSuite(t *testing.T) {
+	suite.Run(t, new(ReportOrdersUseCaseTestSuite))
+}
+
+func day(month time.Month, d int) time.Time {
+	return time.Date(2026, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func (suite *ReportOrdersUseCaseTestSuite) TestGivenARange_WhenExecute_ThenShouldReturnARowPerPeriodAndTheTotal() {
+	ctx := context.Background()
+	suite.totals.On("GetTotals", mock.Anything, []entity.OrderPeriod{
+		{From: day(1, 1), To: day(2, 1)},
+		{From: day(2, 1), To: day(3, 1)},
+		{From: day(3, 1), To: day(4, 1)},
+	}).Return([]entity.OrderTotals{
+		{Count: 2, Price: 30, Tax: 2, FinalPrice: 32},
+		{},
+		{Count: 2, Price: 10, Tax: 2, FinalPrice: 12},
+	}, nil).Once()
+
+	output, err := NewReportOrdersUseCase(suite.totals).Execute(ctx, ReportOrdersInputDTO{From: "2026-01", To: "2026-03", GroupBy: "month"})
+	suite.Require().NoError(err)
+	suite.Equal("month", output.GroupBy)
+	suite.Equal([]OrderReportRowDTO{
+		{Start: day(1, 1), End: day(2, 1), Count: 2, Price: 30, Tax: 2, FinalPrice: 32, AverageTicket: 16},
+		{Start: day(2, 1), End: day(3, 1)},
+		{Start: day(3, 1), End: day(4, 1), Count: 2, Price: 10, Tax: 2, FinalPrice: 12, AverageTicket: 6},
+	}, output.Rows)
+	suite.Equal(OrderReportRowDTO{Start: day(1, 1), End: day(4, 1), Count: 4, Price: 40, Tax: 4, FinalPrice: 44, AverageTicket: 11}, output.Total)
+	suite.totals.AssertExpectations(suite.T())
+}
+
+func (suite *ReportOrdersUseCaseTestSuite) TestGivenAnInvalidReport_WhenExecute_ThenShouldNotQueryTheRepository() {
+	for _, input := range []ReportOrdersInputDTO{
+		{From: "2026-02", To: "2026-01"},
+		{From: "2026-01", To: "2026-02", GroupBy: "year"},
+		{From: "2020", To: "2026"},
+	} {
+		_, err := NewReportOrdersUseCase(suite.totals).Execute(context.Background(), input)
+		suite.ErrorIs(err, entity.ErrInvalidOrderReport, input)
+	}
+	suite.totals.AssertNotCalled(suite.T(), "GetTotals", mock.Anything, mock.Anything)
+}
+
+func (suite *ReportOrdersUseCaseTestSuite) TestGivenTheRepositoryFails_WhenExecute_ThenShouldReturnTheError() {
+	suite.totals.On("GetTotals", mock.Anything, mock.Anything).Return(nil, errors.New("db down"))
+
+	_, err := NewReportOrdersUseCase(suite.totals).Execute(context.Background(), ReportOrdersInputDTO{From: "2026-01-01", To: "2026-01-02"})
+	suite.EqualError(err, "db down")
+}
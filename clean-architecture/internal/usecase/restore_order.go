@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/event"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
+)
+
+// RestoreOrderUseCase brings back an order soft deleted by DeleteOrderUseCase.
+// An unknown order, or one already purged, is reported as
+// entity.ErrOrderNotFound and an order that isn't deleted as
+// entity.ErrOrderNotDeleted.
+type RestoreOrderUseCase struct {
+	OrderDeletionRepository entity.OrderDeletionRepositoryInterface
+	EventDispatcher         events.EventDispatcherInterface
+}
+
+func NewRestoreOrderUseCase(
+	OrderDeletionRepository entity.OrderDeletionRepositoryInterface,
+	EventDispatcher events.EventDispatcherInterface,
+) *RestoreOrderUseCase {
+	return &RestoreOrderUseCase{
+		OrderDeletionRepository: OrderDeletionRepository,
+		EventDispatcher:         EventDispatcher,
+	}
+}
+
+func (c *RestoreOrderUseCase) Execute(ctx context.Context, orderID string) (output OrderOutputDTO, err error) {
+	ctx, end := startUseCase(ctx, "RestoreOrderUseCase.Execute")
+	defer func() { end(err) }()
+
+	change, err := c.OrderDeletionRepository.Restore(ctx, orderID)
+	if err != nil {
+		return OrderOutputDTO{}, err
+	}
+
+	orderRestored := event.NewOrderRestored()
+	orderRestored.SetPayload(change)
+	c.EventDispatcher.Dispatch(ctx, orderRestored)
+	ordersRestoredCounter.Add(ctx, 1)
+
+	return orderOutput(change), nil
+}
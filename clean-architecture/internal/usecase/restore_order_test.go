@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type RestoreOrderUseCaseTestSuite struct {
+	suite.Suite
+	repository *OrderDeletionRepositoryMock
+	recorder   *EventRecorder
+	useCase    *RestoreOrderUseCase
+}
+
+func (suite *RestoreOrderUseCaseTestSuite) SetupTest() {
+	suite.repository = &OrderDeletionRepositoryMock{}
+	suite.recorder = &EventRecorder{}
+	dispatcher := events.NewEventDispatcher()
+	dispatcher.Register("OrderRestored", suite.recorder)
+	suite.useCase = NewRestoreOrderUseCase(suite.repository, dispatcher)
+}
+
+func TestRestoreOrderUseCaseSuite(t *testing.T) {
+	suite.Run(t, new(RestoreOrderUseCaseTestSuite))
+}
+
+func (suite *RestoreOrderUseCaseTestSuite) TestGivenADeletedOrder_WhenExecute_ThenShouldDispatchTheOrderRestoredEvent() {
+	change := entity.OrderEvent{OrderID: "123", Version: 3, Type: entity.OrderRestoredEvent,
+		Data: entity.OrderEventData{Price: 10, Tax: 2, FinalPrice: 12}}
+	suite.repository.On("Restore", mock.Anything, "123").Return(change, nil)
+
+	output, err := suite.useCase.Execute(context.Background(), "123")
+	suite.Require().NoError(err)
+	suite.Equal(OrderOutputDTO{ID: "123", Price: 10, Tax: 2, FinalPrice: 12}, output)
+	suite.Equal([]interface{}{change}, suite.recorder.Payloads)
+}
+
+func (suite *RestoreOrderUseCaseTestSuite) TestGivenAnOrderNotDeleted_WhenExecute_ThenShouldReturnTheError() {
+	suite.repository.On("Restore", mock.Anything, "123").Return(entity.OrderEvent{}, entity.ErrOrderNotDeleted)
+
+	_, err := suite.useCase.Execute(context.Background(), "123")
+	suite.ErrorIs(err, entity.ErrOrderNotDeleted)
+	suite.Empty(suite.recorder.Payloads)
+}
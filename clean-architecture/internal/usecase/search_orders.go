@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+)
+
+// ErrInvalidPageToken is returned for a page token that wasn't handed out by
+// SearchOrdersUseCase.
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+type SearchOrdersInputDTO struct {
+	// Query is parsed by entity.ParseOrderQuery; empty matches every order.
+	Query     string
+	PageSize  int
+	PageToken string
+}
+
+type OrderSearchResultDTO struct {
+	ID         string    `json:"id"`
+	Price      float64   `json:"price"`
+	Tax        float64   `json:"tax"`
+	FinalPrice float64   `json:"final_price"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type SearchOrdersOutputDTO struct {
+	Orders []OrderSearchResultDTO `json:"orders"`
+	// NextPageToken reads the next page; it's empty on the last one.
+	NextPageToken string `json:"next_page_token,omitempty"`
+}
+
+// SearchOrdersUseCase finds orders in the read model with the query language
+// of entity.ParseOrderQuery, one page at a time, ordered by ID. An invalid
+// query is reported as entity.ErrInvalidOrderQuery.
+type SearchOrdersUseCase struct {
+	OrderViewRepository entity.OrderViewRepositoryInterface
+}
+
+func NewSearchOrdersUseCase(
+	OrderViewRepository entity.OrderViewRepositoryInterface,
+) *SearchOrdersUseCase {
+	return &SearchOrdersUseCase{
+		OrderViewRepository: OrderViewRepository,
+	}
+}
+
+// Execute uses DefaultPageSize when input.PageSize <= 0 and caps it at
+// MaxPageSize.
+func (c *SearchOrdersUseCase) Execute(ctx context.Context, input SearchOrdersInputDTO) (output SearchOrdersOutputDTO, err error) {
+	ctx, end := startUseCase(ctx, "SearchOrdersUseCase.Execute")
+	defer func() { end(err) }()
+
+	query, err := entity.ParseOrderQuery(input.Query)
+	if err != nil {
+		return SearchOrdersOutputDTO{}, err
+	}
+	afterID, err := base64.RawURLEncoding.DecodeString(input.PageToken)
+	if err != nil {
+		return SearchOrdersOutputDTO{}, ErrInvalidPageToken
+	}
+	pageSize := input.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	pageSize = min(pageSize, MaxPageSize)
+
+	// one more than the page tells whether there is a next one
+	views, err := c.OrderViewRepository.SearchOrders(ctx, query, string(afterID), pageSize+1)
+	if err != nil {
+		return SearchOrdersOutputDTO{}, err
+	}
+	if len(views) > pageSize {
+		views = views[:pageSize]
+		output.NextPageToken = base64.RawURLEncoding.EncodeToString([]byte(views[pageSize-1].ID))
+	}
+	output.Orders = make([]OrderSearchResultDTO, 0, len(views))
+	for _, view := range views {
+		output.Orders = append(output.Orders, OrderSearchResultDTO{
+			ID:         view.ID,
+			Price:      view.Price,
+			Tax:        view.Tax,
+			FinalPrice: view.FinalPrice,
+			CreatedAt:  view.CreatedAt,
+		})
+	}
+	return output, nil
+}
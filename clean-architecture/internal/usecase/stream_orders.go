@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/entity"
+)
+
+const (
+	DefaultPageSize = 100
+	MaxPageSize     = 1000
+)
+
+// StreamOrdersUseCase reads the read model page by page, by ID, handing each
+// page to fn before reading the next one. It stops as soon as ctx is done.
+type StreamOrdersUseCase struct {
+	OrderViewRepository entity.OrderViewRepositoryInterface
+}
+
+func NewStreamOrdersUseCase(
+	OrderViewRepository entity.OrderViewRepositoryInterface,
+) *StreamOrdersUseCase {
+	return &StreamOrdersUseCase{
+		OrderViewRepository: OrderViewRepository,
+	}
+}
+
+// Execute uses DefaultPageSize when pageSize <= 0 and caps it at MaxPageSize.
+func (c *StreamOrdersUseCase) Execute(ctx context.Context, pageSize int, fn func(page []ListOrdersOutputDTO) error) (err error) {
+	ctx, end := startUseCase(ctx, "StreamOrdersUseCase.Execute")
+	defer func() { end(err) }()
+
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	pageSize = min(pageSize, MaxPageSize)
+
+	last := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		views, err := c.OrderViewRepository.ListOrdersPage(ctx, last, pageSize)
+		if err != nil {
+			return err
+		}
+		if len(views) == 0 {
+			return nil
+		}
+		page := make([]ListOrdersOutputDTO, 0, len(views))
+		for _, view := range views {
+			page = append(page, ListOrdersOutputDTO{
+				ID:         view.ID,
+				Price:      view.Price,
+				Tax:        view.Tax,
+				FinalPrice: view.FinalPrice,
+			})
+		}
+		if err := fn(page); err != nil {
+			return err
+		}
+		if len(views) < pageSize {
+			return nil
+		}
+		last = views[len(views)-1].ID
+	}
+}
@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const instrumentationName = "github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+
+var (
+	meter                   = otel.Meter(instrumentationName)
+	ordersCreatedCounter, _ = meter.Int64Counter("orders_created",
+		metric.WithDescription("Number of orders created"))
+	ordersDeletedCounter, _ = meter.Int64Counter("orders_deleted",
+		metric.WithDescription("Number of orders soft deleted"))
+	ordersRestoredCounter, _ = meter.Int64Counter("orders_restored",
+		metric.WithDescription("Number of soft deleted orders restored"))
+	useCaseDuration, _ = meter.Float64Histogram("usecase_duration",
+		metric.WithDescription("Duration of use case executions"),
+		metric.WithUnit("s"))
+)
+
+// startUseCase opens the span of a use case. The returned func ends it,
+// recording the error (if any) and the execution time.
+func startUseCase(ctx context.Context, name string) (context.Context, func(err error)) {
+	start := time.Now()
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, name)
+	return ctx, func(err error) {
+		outcome := "success"
+		if err != nil {
+			outcome = "error"
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		useCaseDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+			attribute.String("usecase", name),
+			attribute.String("outcome", outcome),
+		))
+		span.End()
+	}
+}
@@ -0,0 +1,106 @@
+// Package cache holds values read from a slower source, such as the database.
+// LRU is the in-process implementation; a shared cache (redis, memcached, etc.)
+// only needs to implement Cache.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Cache maps keys to values until they expire or are purged. An error means
+// the cache could not be reached; callers should fall back to the source.
+type Cache[V any] interface {
+	// Get reports whether key holds a value that didn't expire yet.
+	Get(ctx context.Context, key string) (V, bool, error)
+	Set(ctx context.Context, key string, value V) error
+	// Purge removes every value.
+	Purge(ctx context.Context) error
+}
+
+// LRU is an in-process Cache of up to Size values, each kept for TTL at most.
+// When full, the least recently used value makes room for the new one.
+type LRU[V any] struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	items map[string]*list.Element
+	order *list.List
+	now   func() time.Time
+}
+
+type entry[V any] struct {
+	key       string
+	value     V
+	expiresAt time.Time
+}
+
+// NewLRU returns an LRU of size values (at least 1) that expire after ttl; a
+// ttl of zero keeps them until evicted or purged.
+func NewLRU[V any](size int, ttl time.Duration) *LRU[V] {
+	return &LRU[V]{
+		size:  max(size, 1),
+		ttl:   ttl,
+		items: make(map[string]*list.Element),
+		order: list.New(),
+		now:   time.Now,
+	}
+}
+
+func (c *LRU[V]) Get(ctx context.Context, key string) (V, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	element, ok := c.items[key]
+	if !ok {
+		return zero, false, nil
+	}
+	e := element.Value.(*entry[V])
+	if c.ttl > 0 && !c.now().Before(e.expiresAt) {
+		c.remove(element)
+		return zero, false, nil
+	}
+	c.order.MoveToFront(element)
+	return e.value, true, nil
+}
+
+func (c *LRU[V]) Set(ctx context.Context, key string, value V) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(c.ttl)
+	if element, ok := c.items[key]; ok {
+		e := element.Value.(*entry[V])
+		e.value, e.expiresAt = value, expiresAt
+		c.order.MoveToFront(element)
+		return nil
+	}
+	c.items[key] = c.order.PushFront(&entry[V]{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+func (c *LRU[V]) Purge(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+	return nil
+}
+
+// Len returns how many values are held, expired ones included.
+func (c *LRU[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRU[V]) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.items, element.Value.(*entry[V]).key)
+}
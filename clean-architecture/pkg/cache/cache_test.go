@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestLRU(size int, ttl time.Duration) (*LRU[string], *fakeClock) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	lru := NewLRU[string](size, ttl)
+	lru.now = clock.Now
+	return lru, clock
+}
+
+func TestGivenAValue_WhenGet_ThenShouldReturnIt(t *testing.T) {
+	lru, _ := newTestLRU(2, time.Minute)
+	ctx := context.Background()
+
+	assert.Nil(t, lru.Set(ctx, "a", "1"))
+	value, ok, err := lru.Get(ctx, "a")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "1", value)
+
+	_, ok, err = lru.Get(ctx, "b")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
+
+func TestGivenAnExpiredValue_WhenGet_ThenShouldMissAndDropIt(t *testing.T) {
+	lru, clock := newTestLRU(2, time.Minute)
+	ctx := context.Background()
+
+	assert.Nil(t, lru.Set(ctx, "a", "1"))
+	clock.now = clock.now.Add(59 * time.Second)
+	_, ok, _ := lru.Get(ctx, "a")
+	assert.True(t, ok)
+
+	clock.now = clock.now.Add(time.Second)
+	_, ok, _ = lru.Get(ctx, "a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, lru.Len())
+}
+
+func TestGivenAFullLRU_WhenSet_ThenShouldEvictTheLeastRecentlyUsed(t *testing.T) {
+	lru, _ := newTestLRU(2, 0)
+	ctx := context.Background()
+
+	assert.Nil(t, lru.Set(ctx, "a", "1"))
+	assert.Nil(t, lru.Set(ctx, "b", "2"))
+	_, _, _ = lru.Get(ctx, "a")
+	assert.Nil(t, lru.Set(ctx, "c", "3"))
+
+	assert.Equal(t, 2, lru.Len())
+	_, ok, _ := lru.Get(ctx, "b")
+	assert.False(t, ok)
+	value, ok, _ := lru.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, "1", value)
+}
+
+func TestGivenAKeySetTwice_WhenGet_ThenShouldReturnTheLastValue(t *testing.T) {
+	lru, _ := newTestLRU(2, time.Minute)
+	ctx := context.Background()
+
+	assert.Nil(t, lru.Set(ctx, "a", "1"))
+	assert.Nil(t, lru.Set(ctx, "a", "2"))
+
+	value, _, _ := lru.Get(ctx, "a")
+	assert.Equal(t, "2", value)
+	assert.Equal(t, 1, lru.Len())
+}
+
+func TestGivenValues_WhenPurge_ThenShouldDropThemAll(t *testing.T) {
+	lru, _ := newTestLRU(2, time.Minute)
+	ctx := context.Background()
+
+	assert.Nil(t, lru.Set(ctx, "a", "1"))
+	assert.Nil(t, lru.Set(ctx, "b", "2"))
+	assert.Nil(t, lru.Purge(ctx))
+
+	assert.Equal(t, 0, lru.Len())
+	_, ok, _ := lru.Get(ctx, "a")
+	assert.False(t, ok)
+}
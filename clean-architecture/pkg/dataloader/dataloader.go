@@ -0,0 +1,119 @@
+// Package dataloader batches and caches the lookups made while resolving one
+// request, so that loading a field of N parents costs one query instead of N.
+package dataloader
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BatchFunc fetches the values of keys in one go. Keys missing from the
+// returned map load as the zero value of V; an error fails every key.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader collects the keys asked within Wait of the first one, or until
+// MaxBatch keys are pending, and fetches them with a single BatchFunc call.
+// Results are cached for the life of the Loader, which should therefore be
+// created per request.
+type Loader[K comparable, V any] struct {
+	fetch    BatchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	results map[K]*result[V]
+	batch   *batch[K, V]
+}
+
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+type batch[K comparable, V any] struct {
+	ctx     context.Context
+	keys    []K
+	results []*result[V]
+	timer   *time.Timer
+}
+
+// NewLoader returns a Loader; maxBatch <= 0 means no limit on the batch size.
+func NewLoader[K comparable, V any](fetch BatchFunc[K, V], wait time.Duration, maxBatch int) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:    fetch,
+		wait:     wait,
+		maxBatch: maxBatch,
+		results:  map[K]*result[V]{},
+	}
+}
+
+// Load returns the value of key, waiting for the batch it joins to be fetched.
+// The batch runs with the context of the Load that opened it.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	r, ok := l.results[key]
+	if !ok {
+		r = &result[V]{done: make(chan struct{})}
+		l.results[key] = r
+		l.enqueue(ctx, key, r)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// enqueue adds key to the pending batch, opening one if needed; l.mu must be
+// held.
+func (l *Loader[K, V]) enqueue(ctx context.Context, key K, r *result[V]) {
+	if l.batch == nil {
+		b := &batch[K, V]{ctx: ctx}
+		b.timer = time.AfterFunc(l.wait, func() { l.dispatch(b) })
+		l.batch = b
+	}
+	b := l.batch
+	b.keys = append(b.keys, key)
+	b.results = append(b.results, r)
+	if l.maxBatch > 0 && len(b.keys) >= l.maxBatch {
+		b.timer.Stop()
+		l.batch = nil
+		go l.run(b)
+	}
+}
+
+// dispatch runs b when its wait is over, unless it was already sent for
+// being full.
+func (l *Loader[K, V]) dispatch(b *batch[K, V]) {
+	l.mu.Lock()
+	if l.batch != b {
+		l.mu.Unlock()
+		return
+	}
+	l.batch = nil
+	l.mu.Unlock()
+	l.run(b)
+}
+
+func (l *Loader[K, V]) run(b *batch[K, V]) {
+	values, err := l.fetch(b.ctx, b.keys)
+	if err != nil {
+		// a failed fetch isn't cached, so a later Load can try again
+		l.mu.Lock()
+		for _, key := range b.keys {
+			delete(l.results, key)
+		}
+		l.mu.Unlock()
+	}
+	for i, key := range b.keys {
+		r := b.results[i]
+		r.value, r.err = values[key], err
+		close(r.done)
+	}
+}
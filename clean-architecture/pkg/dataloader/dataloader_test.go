@@ -0,0 +1,110 @@
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder is a BatchFunc that keeps the batches it was called with.
+type recorder struct {
+	mu      sync.Mutex
+	batches [][]int
+	err     error
+}
+
+func (r *recorder) fetch(_ context.Context, keys []int) (map[int]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sorted := append([]int(nil), keys...)
+	sort.Ints(sorted)
+	r.batches = append(r.batches, sorted)
+	if r.err != nil {
+		return nil, r.err
+	}
+	values := map[int]string{}
+	for _, key := range keys {
+		if key != 0 {
+			values[key] = fmt.Sprint("v", key)
+		}
+	}
+	return values, nil
+}
+
+func loadAll(loader *Loader[int, string], keys ...int) ([]string, []error) {
+	values, errs := make([]string, len(keys)), make([]error, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values[i], errs[i] = loader.Load(context.Background(), key)
+		}()
+	}
+	wg.Wait()
+	return values, errs
+}
+
+func TestGivenConcurrentLoads_WhenLoad_ThenShouldFetchThemInOneBatch(t *testing.T) {
+	r := &recorder{}
+	loader := NewLoader(r.fetch, 10*time.Millisecond, 0)
+
+	values, errs := loadAll(loader, 1, 2, 3, 2, 0)
+
+	assert.Equal(t, []string{"v1", "v2", "v3", "v2", ""}, values)
+	assert.Equal(t, []error{nil, nil, nil, nil, nil}, errs)
+	assert.Equal(t, [][]int{{0, 1, 2, 3}}, r.batches)
+}
+
+func TestGivenALoadedKey_WhenLoadAgain_ThenShouldUseTheCache(t *testing.T) {
+	r := &recorder{}
+	loader := NewLoader(r.fetch, time.Millisecond, 0)
+
+	loadAll(loader, 1)
+	value, err := loader.Load(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", value)
+	assert.Len(t, r.batches, 1)
+}
+
+func TestGivenMaxBatch_WhenLoad_ThenShouldSplitTheBatches(t *testing.T) {
+	r := &recorder{}
+	loader := NewLoader(r.fetch, 10*time.Millisecond, 2)
+
+	loadAll(loader, 1, 2, 3, 4, 5)
+
+	assert.Len(t, r.batches, 3)
+}
+
+func TestGivenAFailedFetch_WhenLoad_ThenShouldFailTheBatchAndNotCacheIt(t *testing.T) {
+	r := &recorder{err: errors.New("boom")}
+	loader := NewLoader(r.fetch, time.Millisecond, 0)
+
+	_, errs := loadAll(loader, 1, 2)
+	assert.EqualError(t, errs[0], "boom")
+	assert.EqualError(t, errs[1], "boom")
+
+	r.err = nil
+	value, err := loader.Load(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", value)
+}
+
+func TestGivenACanceledContext_WhenLoad_ThenShouldReturnItsError(t *testing.T) {
+	loader := NewLoader(func(ctx context.Context, keys []int) (map[int]string, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, time.Millisecond, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := loader.Load(ctx, 1)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
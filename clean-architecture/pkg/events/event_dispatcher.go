@@ -1,6 +1,7 @@
 package events
 
 import (
+	"context"
 	"errors"
 	"sync"
 )
@@ -17,12 +18,12 @@ func NewEventDispatcher() *EventDispatcher {
 	}
 }
 
-func (ev *EventDispatcher) Dispatch(event EventInterface) error {
+func (ev *EventDispatcher) Dispatch(ctx context.Context, event EventInterface) error {
 	if handlers, ok := ev.handlers[event.GetName()]; ok {
 		wg := &sync.WaitGroup{}
 		for _, handler := range handlers {
 			wg.Add(1)
-			go handler.Handle(event, wg)
+			go handler.Handle(ctx, event, wg)
 		}
 		wg.Wait()
 	}
@@ -0,0 +1,359 @@
+// Package orderclient is the Go client of the ordersystem. The same Client
+// talks to the gRPC API (NewGRPC) or to the REST API generated from
+// order.proto (NewREST), so that the other services don't hand-roll HTTP
+// calls:
+//
+//	client, err := orderclient.NewGRPC("localhost:50051", orderclient.WithCredentials(orderclient.Actor("billing")))
+//	if err != nil {
+//		return err
+//	}
+//	defer client.Close()
+//	order, err := client.CreateOrder(ctx, orderclient.OrderInput{ID: "a", Price: 10, Tax: 1})
+//	if errors.Is(err, orderclient.ErrInvalidArgument) {
+//		...
+//	}
+//
+// The deadline of ctx is propagated to the server on both transports, the
+// idempotent calls are retried (see RetryPolicy) and the errors answered by
+// the server are *Error values, matching the sentinels with errors.Is.
+package orderclient
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// OrderInput is an order to create or import.
+type OrderInput struct {
+	ID    string  `json:"id"`
+	Price float64 `json:"price"`
+	Tax   float64 `json:"tax"`
+}
+
+// Order is an order as stored by the ordersystem.
+type Order struct {
+	ID         string  `json:"id"`
+	Price      float64 `json:"price"`
+	Tax        float64 `json:"tax"`
+	FinalPrice float64 `json:"final_price"`
+}
+
+// OrderState is the state of an order before or after a change.
+type OrderState struct {
+	Price      float64 `json:"price"`
+	Tax        float64 `json:"tax"`
+	FinalPrice float64 `json:"final_price"`
+}
+
+// HistoryEntry is one change in the audit trail of an order. Before is nil
+// when the order was created.
+type HistoryEntry struct {
+	Action    string      `json:"action"`
+	Actor     string      `json:"actor"`
+	Transport string      `json:"transport"`
+	RequestID string      `json:"request_id"`
+	Before    *OrderState `json:"before"`
+	After     *OrderState `json:"after"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// OrderEvent is a recorded change of an order; Data is the state after it.
+type OrderEvent struct {
+	OrderID   string     `json:"order_id"`
+	Version   int        `json:"version"`
+	Type      string     `json:"type"`
+	Data      OrderState `json:"data"`
+	Timestamp time.Time  `json:"timestamp"`
+}
+
+// RowError is an order of an import that was rejected. Row counts from 1.
+type RowError struct {
+	Row   int    `json:"row"`
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error"`
+}
+
+// ImportReport is the outcome of ImportOrders.
+type ImportReport struct {
+	Rows     int        `json:"rows"`
+	Imported int        `json:"imported"`
+	Failed   int        `json:"failed"`
+	DryRun   bool       `json:"dry_run"`
+	Errors   []RowError `json:"errors"`
+}
+
+// transport makes the calls over gRPC or REST. The streams it opens end with
+// io.EOF and are released when their ctx is canceled.
+type transport interface {
+	createOrder(ctx context.Context, input OrderInput) (Order, error)
+	getOrder(ctx context.Context, id string) (Order, error)
+	listOrders(ctx context.Context) ([]Order, error)
+	orderHistory(ctx context.Context, id string) ([]HistoryEntry, error)
+	importOrders(ctx context.Context, orders []OrderInput, dryRun bool) (ImportReport, error)
+	orderPages(ctx context.Context, pageSize int) (func() ([]Order, error), error)
+	watchOrders(ctx context.Context) (func() (Order, error), error)
+	replayEvents(ctx context.Context, orderID string) (func() (OrderEvent, error), error)
+	close() error
+}
+
+// Client calls the ordersystem. It is safe for concurrent use.
+type Client struct {
+	transport transport
+	retry     RetryPolicy
+	timeout   time.Duration
+}
+
+type options struct {
+	credentials Credentials
+	retry       RetryPolicy
+	timeout     time.Duration
+	tlsConfig   *tls.Config
+	httpClient  *http.Client
+	dialOptions []grpc.DialOption
+}
+
+// Option configures a Client.
+type Option func(*options)
+
+// WithCredentials authenticates every call with credentials.
+func WithCredentials(credentials Credentials) Option {
+	return func(o *options) { o.credentials = credentials }
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *options) { o.retry = policy }
+}
+
+// WithTimeout sets a deadline on the calls made with a ctx that has none.
+// It doesn't apply to the iterators, which last as long as they are read.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.timeout = timeout }
+}
+
+// WithTLS connects over TLS with config, which may hold a client certificate
+// for mutual TLS. Without it the connection is in plain text.
+func WithTLS(config *tls.Config) Option {
+	return func(o *options) { o.tlsConfig = config }
+}
+
+// WithHTTPClient makes the REST transport use client; WithTLS is then ignored.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) { o.httpClient = client }
+}
+
+// WithDialOptions adds options to the gRPC connection.
+func WithDialOptions(dialOptions ...grpc.DialOption) Option {
+	return func(o *options) { o.dialOptions = append(o.dialOptions, dialOptions...) }
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{retry: DefaultRetryPolicy}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.retry.MaxAttempts < 1 {
+		o.retry.MaxAttempts = 1
+	}
+	return o
+}
+
+func newClient(t transport, o *options) *Client {
+	return &Client{transport: t, retry: o.retry, timeout: o.timeout}
+}
+
+// Close releases the connection.
+func (c *Client) Close() error {
+	return c.transport.close()
+}
+
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || c.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+// CreateOrder creates an order. It is never retried, as a retry of a call
+// that reached the server would fail with an order already created.
+func (c *Client) CreateOrder(ctx context.Context, input OrderInput) (Order, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	return c.transport.createOrder(ctx, input)
+}
+
+// GetOrder returns an order, or fails with ErrNotFound.
+func (c *Client) GetOrder(ctx context.Context, id string) (Order, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	var order Order
+	err := c.retry.do(ctx, func() (err error) {
+		order, err = c.transport.getOrder(ctx, id)
+		return err
+	})
+	return order, err
+}
+
+// ListOrders returns every order. Use Orders to read them page by page.
+func (c *Client) ListOrders(ctx context.Context) ([]Order, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	var orders []Order
+	err := c.retry.do(ctx, func() (err error) {
+		orders, err = c.transport.listOrders(ctx)
+		return err
+	})
+	return orders, err
+}
+
+// OrderHistory returns the audit trail of an order, oldest first. It fails
+// with ErrNotFound for an unknown order.
+func (c *Client) OrderHistory(ctx context.Context, id string) ([]HistoryEntry, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	var entries []HistoryEntry
+	err := c.retry.do(ctx, func() (err error) {
+		entries, err = c.transport.orderHistory(ctx, id)
+		return err
+	})
+	return entries, err
+}
+
+// ImportOrders creates orders in batches. The rejected ones don't fail the
+// call: they are listed in the report. With dryRun the orders are only
+// validated, so the call is retried like the other idempotent ones.
+func (c *Client) ImportOrders(ctx context.Context, orders []OrderInput, dryRun bool) (ImportReport, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	if !dryRun {
+		return c.transport.importOrders(ctx, orders, false)
+	}
+	var report ImportReport
+	err := c.retry.do(ctx, func() (err error) {
+		report, err = c.transport.importOrders(ctx, orders, true)
+		return err
+	})
+	return report, err
+}
+
+// OrderPages iterates over the orders in pages of pageSize (100 when 0, at
+// most 1000), without loading them all at once. Opening the stream is
+// retried; an error afterwards is yielded and ends the iteration.
+func (c *Client) OrderPages(ctx context.Context, pageSize int) iter.Seq2[[]Order, error] {
+	return func(yield func([]Order, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		var next func() ([]Order, error)
+		var first []Order
+		err := c.retry.do(ctx, func() (err error) {
+			if next, err = c.transport.orderPages(ctx, pageSize); err != nil {
+				return err
+			}
+			first, err = next()
+			return err
+		})
+		for page := first; ; page, err = next() {
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(page, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Orders iterates over the orders one by one, reading them in pages of
+// pageSize as OrderPages does.
+func (c *Client) Orders(ctx context.Context, pageSize int) iter.Seq2[Order, error] {
+	return func(yield func(Order, error) bool) {
+		for page, err := range c.OrderPages(ctx, pageSize) {
+			if err != nil {
+				yield(Order{}, err)
+				return
+			}
+			for _, order := range page {
+				if !yield(order, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// WatchOrders subscribes to the orders created from now on. It returns once
+// the subscription is open, so no order created afterwards is missed; the
+// iteration lasts until ctx is done, which ends it with no error, or the
+// server drops the subscriber. Only the gRPC transport supports it: REST
+// answers ErrUnimplemented. The subscription is released when the iteration
+// stops or ctx is canceled.
+func (c *Client) WatchOrders(ctx context.Context) (iter.Seq2[Order, error], error) {
+	ctx, cancel := context.WithCancel(ctx)
+	var next func() (Order, error)
+	err := c.retry.do(ctx, func() (err error) {
+		next, err = c.transport.watchOrders(ctx)
+		return err
+	})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return func(yield func(Order, error) bool) {
+		defer cancel()
+		for {
+			order, err := next()
+			if err != nil {
+				if ctx.Err() == nil && !errors.Is(err, io.EOF) {
+					yield(Order{}, err)
+				}
+				return
+			}
+			if !yield(order, nil) {
+				return
+			}
+		}
+	}, nil
+}
+
+// ReplayEvents iterates over the recorded events of the order orderID, or of
+// every order when it is empty, in version order within each order. Opening
+// the stream is retried. Only the gRPC transport supports it: REST yields
+// ErrUnimplemented.
+func (c *Client) ReplayEvents(ctx context.Context, orderID string) iter.Seq2[OrderEvent, error] {
+	return func(yield func(OrderEvent, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		var next func() (OrderEvent, error)
+		var event OrderEvent
+		err := c.retry.do(ctx, func() (err error) {
+			if next, err = c.transport.replayEvents(ctx, orderID); err != nil {
+				return err
+			}
+			event, err = next()
+			return err
+		})
+		for ; ; event, err = next() {
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				yield(OrderEvent{}, err)
+				return
+			}
+			if !yield(event, nil) {
+				return
+			}
+		}
+	}
+}
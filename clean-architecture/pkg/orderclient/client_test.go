@@ -0,0 +1,319 @@
+package orderclient
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/event/handler"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/database"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/grpc/pb"
+	grpcserver "github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/grpc/server"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/grpc/service"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/web"
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/usecase"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/events"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/pubsub"
+	"github.com/alexandreti/posGoExpert/clean-architecture/pkg/requestctx"
+	"github.com/alexandreti/posGoExpert/clean-architecture/sql/migrations"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/test/bufconn"
+
+	// sqlite3
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// ClientTestSuite runs the same cases on the gRPC and the REST transports,
+// against the order service served in process on top of SQLite.
+type ClientTestSuite struct {
+	suite.Suite
+	Transport string
+	Db        *sql.DB
+	Server    *grpc.Server
+	// GatewayServer is the in-process server the REST gateway calls.
+	GatewayServer *grpc.Server
+	REST          *httptest.Server
+	Client        *Client
+}
+
+func (suite *ClientTestSuite) SetupTest() {
+	db, err := sql.Open("sqlite3", ":memory:")
+	suite.Require().NoError(err)
+	db.SetMaxOpenConns(1)
+	source, err := migrations.Source(database.SQLite.Name())
+	suite.Require().NoError(err)
+	_, err = database.NewMigrator(db, database.SQLite, source).Up(context.Background())
+	suite.Require().NoError(err)
+	suite.Db = db
+
+	orders := database.NewOrderRepository(db, database.SQLite)
+	views := database.NewOrderViewRepository(db, database.SQLite)
+	audit := database.NewOrderAuditRepository(db, database.SQLite)
+	broker := pubsub.NewBroker[usecase.OrderOutputDTO]()
+	dispatcher := events.NewEventDispatcher()
+	dispatcher.Register("OrderCreated", handler.NewOrderProjectionHandler(views))
+	dispatcher.Register("OrderCreated", handler.NewOrderBroadcastHandler(broker))
+	importOrders := usecase.NewImportOrdersUseCase(orders, dispatcher)
+	orderService := service.NewOrderService(
+		*usecase.NewCreateOrderUseCase(orders, dispatcher),
+		*usecase.NewListOrdersUseCase(views),
+		*usecase.NewGetOrderHistoryUseCase(audit),
+		*importOrders,
+		*usecase.NewStreamOrdersUseCase(views),
+		*usecase.NewCreateOrdersUseCase(importOrders),
+		*usecase.NewGetOrderUseCase(views),
+		*usecase.NewReplayOrderEventsUseCase(orders),
+		*usecase.NewSearchOrdersUseCase(views),
+		*usecase.NewReportOrdersUseCase(orders),
+		*usecase.NewDeleteOrderUseCase(orders, dispatcher),
+		*usecase.NewRestoreOrderUseCase(orders, dispatcher),
+		broker,
+	)
+
+	suite.Server = grpcserver.New(grpcserver.Config{Validate: true})
+	pb.RegisterOrderServiceServer(suite.Server, orderService)
+	listener := bufconn.Listen(1024 * 1024)
+	go suite.Server.Serve(listener)
+
+	gatewayServer := grpcserver.New(grpcserver.Config{
+		Validate: true,
+		Unary:    []grpc.UnaryServerInterceptor{requestctx.UnaryTransportInterceptor(requestctx.TransportREST)},
+	})
+	pb.RegisterOrderServiceServer(gatewayServer, orderService)
+	suite.GatewayServer = gatewayServer
+	gatewayConn, err := grpcserver.InProcess(gatewayServer)
+	suite.Require().NoError(err)
+	gateway, err := service.NewGatewayHandler(context.Background(), gatewayConn)
+	suite.Require().NoError(err)
+	webOrderHandler := web.NewWebOrderHandler(dispatcher, orders, views, audit)
+	mux := http.NewServeMux()
+	mux.Handle("/v1/", gateway)
+	mux.HandleFunc("POST /orders/import", webOrderHandler.Import)
+	mux.HandleFunc("GET /orders/export", webOrderHandler.Export)
+	suite.REST = httptest.NewServer(requestctx.HTTPMiddleware(requestctx.TransportMiddleware(requestctx.TransportREST)(mux)))
+
+	credentials := WithCredentials(Actor("billing"))
+	if suite.Transport == requestctx.TransportGRPC {
+		suite.Client, err = NewGRPC("passthrough:///bufnet", credentials, WithDialOptions(
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		))
+	} else {
+		suite.Client, err = NewREST(suite.REST.URL, credentials)
+	}
+	suite.Require().NoError(err)
+}
+
+func (suite *ClientTestSuite) TearDownTest() {
+	suite.Client.Close()
+	suite.REST.Close()
+	suite.Server.Stop()
+	suite.GatewayServer.Stop()
+	suite.Db.Close()
+}
+
+func TestClientSuite(t *testing.T) {
+	for _, transport := range []string{requestctx.TransportGRPC, requestctx.TransportREST} {
+		t.Run(transport, func(t *testing.T) {
+			suite.Run(t, &ClientTestSuite{Transport: transport})
+		})
+	}
+}
+
+func (suite *ClientTestSuite) createOrders(n int) {
+	for i := 1; i <= n; i++ {
+		_, err := suite.Client.CreateOrder(context.Background(), OrderInput{ID: fmt.Sprintf("%02d", i), Price: float64(i * 10), Tax: 1})
+		suite.Require().NoError(err)
+	}
+}
+
+func (suite *ClientTestSuite) TestGivenAnOrder_WhenCreateOrder_ThenShouldRecordItWithTheActor() {
+	order, err := suite.Client.CreateOrder(context.Background(), OrderInput{ID: "a", Price: 10.1, Tax: 0.2})
+	suite.Require().NoError(err)
+	price, tax := 10.1, 0.2
+	suite.Equal(Order{ID: "a", Price: price, Tax: tax, FinalPrice: price + tax}, order)
+
+	orders, err := suite.Client.ListOrders(context.Background())
+	suite.Require().NoError(err)
+	suite.Equal([]Order{order}, orders)
+
+	history, err := suite.Client.OrderHistory(context.Background(), "a")
+	suite.Require().NoError(err)
+	suite.Require().Len(history, 1)
+	suite.Equal("billing", history[0].Actor)
+	suite.Equal(suite.Transport, history[0].Transport)
+	suite.Nil(history[0].Before)
+	suite.Equal(&OrderState{Price: price, Tax: tax, FinalPrice: price + tax}, history[0].After)
+	suite.False(history[0].Timestamp.IsZero())
+}
+
+func (suite *ClientTestSuite) TestGivenAnUnknownOrder_WhenOrderHistory_ThenShouldReturnErrNotFound() {
+	_, err := suite.Client.OrderHistory(context.Background(), "missing")
+	suite.ErrorIs(err, ErrNotFound)
+	var clientErr *Error
+	suite.Require().ErrorAs(err, &clientErr)
+	suite.Equal(codes.NotFound, clientErr.Code)
+}
+
+func (suite *ClientTestSuite) TestGivenAnInvalidOrder_WhenCreateOrder_ThenShouldReturnTheInvalidFields() {
+	_, err := suite.Client.CreateOrder(context.Background(), OrderInput{ID: "a", Price: 0, Tax: 1})
+	suite.ErrorIs(err, ErrInvalidArgument)
+	var clientErr *Error
+	suite.Require().ErrorAs(err, &clientErr)
+	suite.Require().Len(clientErr.Fields, 1)
+	suite.Equal("price", clientErr.Fields[0].Field)
+}
+
+func (suite *ClientTestSuite) TestGivenAnExistingID_WhenCreateOrder_ThenShouldReturnErrAlreadyExists() {
+	suite.createOrders(1)
+
+	_, err := suite.Client.CreateOrder(context.Background(), OrderInput{ID: "01", Price: 20, Tax: 1})
+	suite.ErrorIs(err, ErrAlreadyExists)
+	var clientErr *Error
+	suite.Require().ErrorAs(err, &clientErr)
+	suite.Equal(codes.AlreadyExists, clientErr.Code)
+	if suite.Transport == requestctx.TransportREST {
+		suite.Equal(http.StatusConflict, clientErr.HTTPStatus)
+	}
+
+	orders, err := suite.Client.ListOrders(context.Background())
+	suite.Require().NoError(err)
+	suite.Equal([]Order{{ID: "01", Price: 10, Tax: 1, FinalPrice: 11}}, orders)
+}
+
+func (suite *ClientTestSuite) TestGivenFiveOrders_WhenOrderPagesByTwo_ThenShouldYieldThreePages() {
+	suite.createOrders(5)
+
+	var sizes []int
+	for page, err := range suite.Client.OrderPages(context.Background(), 2) {
+		suite.Require().NoError(err)
+		sizes = append(sizes, len(page))
+	}
+	suite.Equal([]int{2, 2, 1}, sizes)
+
+	var ids []string
+	for order, err := range suite.Client.Orders(context.Background(), 2) {
+		suite.Require().NoError(err)
+		ids = append(ids, order.ID)
+	}
+	suite.Equal([]string{"01", "02", "03", "04", "05"}, ids)
+}
+
+func (suite *ClientTestSuite) TestGivenABreak_WhenRangeOverOrders_ThenShouldStop() {
+	suite.createOrders(5)
+
+	var ids []string
+	for order, err := range suite.Client.Orders(context.Background(), 2) {
+		suite.Require().NoError(err)
+		ids = append(ids, order.ID)
+		if len(ids) == 3 {
+			break
+		}
+	}
+	suite.Equal([]string{"01", "02", "03"}, ids)
+}
+
+func (suite *ClientTestSuite) TestGivenNoOrders_WhenRangeOverOrders_ThenShouldYieldNothing() {
+	for _, err := range suite.Client.Orders(context.Background(), 0) {
+		suite.Fail("unexpected order", err)
+	}
+}
+
+func (suite *ClientTestSuite) TestGivenADryRun_WhenImportOrders_ThenShouldOnlyValidateThem() {
+	orders := []OrderInput{{ID: "a", Price: 10, Tax: 1}, {ID: "b", Price: -1, Tax: 1}}
+
+	report, err := suite.Client.ImportOrders(context.Background(), orders, true)
+	suite.Require().NoError(err)
+	suite.True(report.DryRun)
+	suite.Equal(2, report.Rows)
+	suite.Equal(1, report.Failed)
+	suite.Require().Len(report.Errors, 1)
+	suite.Equal(RowError{Row: 2, ID: "b", Error: report.Errors[0].Error}, report.Errors[0])
+	listed, err := suite.Client.ListOrders(context.Background())
+	suite.Require().NoError(err)
+	suite.Empty(listed)
+
+	report, err = suite.Client.ImportOrders(context.Background(), orders, false)
+	suite.Require().NoError(err)
+	suite.False(report.DryRun)
+	suite.Equal(1, report.Imported)
+	listed, err = suite.Client.ListOrders(context.Background())
+	suite.Require().NoError(err)
+	suite.Len(listed, 1)
+}
+
+func (suite *ClientTestSuite) TestGivenAnExpiredDeadline_WhenListOrders_ThenShouldReturnDeadlineExceeded() {
+	ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+
+	_, err := suite.Client.ListOrders(ctx)
+	suite.ErrorIs(err, ErrDeadlineExceeded)
+	suite.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func (suite *ClientTestSuite) TestGivenAWatcher_WhenAnOrderIsCreated_ThenShouldReceiveIt() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	orders, err := suite.Client.WatchOrders(ctx)
+	if suite.Transport == requestctx.TransportREST {
+		suite.ErrorIs(err, ErrUnimplemented)
+		return
+	}
+	suite.Require().NoError(err)
+	suite.createOrders(1)
+
+	for order, err := range orders {
+		suite.Require().NoError(err)
+		suite.Equal("01", order.ID)
+		break
+	}
+	cancel()
+	for _, err := range orders {
+		suite.Fail("the iteration should be over", err)
+	}
+}
+
+func (suite *ClientTestSuite) TestGivenACanceledContext_WhenCreateOrder_ThenShouldReturnCanceled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := suite.Client.CreateOrder(ctx, OrderInput{ID: "a", Price: 10, Tax: 1})
+	suite.True(errors.Is(err, context.Canceled), err)
+}
+
+func (suite *ClientTestSuite) TestGivenAnOrder_WhenGetOrder_ThenShouldReturnItOrErrNotFound() {
+	suite.createOrders(2)
+
+	order, err := suite.Client.GetOrder(context.Background(), "02")
+	suite.Require().NoError(err)
+	suite.Equal(Order{ID: "02", Price: 20, Tax: 1, FinalPrice: 21}, order)
+
+	_, err = suite.Client.GetOrder(context.Background(), "missing")
+	suite.ErrorIs(err, ErrNotFound)
+}
+
+func (suite *ClientTestSuite) TestGivenOrders_WhenReplayEvents_ThenShouldYieldTheirEvents() {
+	suite.createOrders(2)
+
+	var events []OrderEvent
+	for event, err := range suite.Client.ReplayEvents(context.Background(), "") {
+		if suite.Transport == requestctx.TransportREST {
+			suite.ErrorIs(err, ErrUnimplemented)
+			return
+		}
+		suite.Require().NoError(err)
+		events = append(events, event)
+	}
+	suite.Require().Len(events, 2)
+	suite.Equal("01", events[0].OrderID)
+	suite.Equal(OrderState{Price: 20, Tax: 1, FinalPrice: 21}, events[1].Data)
+}
@@ -0,0 +1,124 @@
+package orderclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FieldViolation tells what is wrong with one field of a rejected request.
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// Error is an error answered by the ordersystem. Both transports report it
+// with the gRPC status code; the REST transport also keeps the HTTP status.
+type Error struct {
+	Code       codes.Code
+	Message    string
+	Fields     []FieldViolation
+	HTTPStatus int
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return "orderclient: " + e.Code.String()
+	}
+	return fmt.Sprintf("orderclient: %s: %s", e.Code, e.Message)
+}
+
+// Is matches the sentinel errors below by code, e.g.
+// errors.Is(err, orderclient.ErrNotFound).
+func (e *Error) Is(target error) bool {
+	sentinel, ok := target.(*Error)
+	return ok && sentinel.Message == "" && sentinel.Code == e.Code
+}
+
+// Unwrap returns the context error matching a Canceled or DeadlineExceeded
+// code, so that errors.Is(err, context.DeadlineExceeded) holds.
+func (e *Error) Unwrap() error {
+	switch e.Code {
+	case codes.Canceled:
+		return context.Canceled
+	case codes.DeadlineExceeded:
+		return context.DeadlineExceeded
+	default:
+		return nil
+	}
+}
+
+var (
+	ErrInvalidArgument  = &Error{Code: codes.InvalidArgument}
+	ErrNotFound         = &Error{Code: codes.NotFound}
+	ErrAlreadyExists    = &Error{Code: codes.AlreadyExists}
+	ErrUnauthenticated  = &Error{Code: codes.Unauthenticated}
+	ErrPermissionDenied = &Error{Code: codes.PermissionDenied}
+	// ErrRateLimited is answered when the rate limiter rejects the call.
+	ErrRateLimited      = &Error{Code: codes.ResourceExhausted}
+	ErrUnavailable      = &Error{Code: codes.Unavailable}
+	ErrDeadlineExceeded = &Error{Code: codes.DeadlineExceeded}
+	// ErrUnimplemented is answered, among others, by the calls the REST
+	// transport can't make, such as WatchOrders.
+	ErrUnimplemented = &Error{Code: codes.Unimplemented}
+	ErrInternal      = &Error{Code: codes.Internal}
+)
+
+// fromGRPC converts a gRPC status error into an *Error.
+func fromGRPC(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		st = status.FromContextError(err)
+	}
+	e := &Error{Code: st.Code(), Message: st.Message()}
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.GetFieldViolations() {
+				e.Fields = append(e.Fields, FieldViolation{Field: violation.GetField(), Description: violation.GetDescription()})
+			}
+		}
+	}
+	return e
+}
+
+// codeOfHTTPStatus is the inverse of the grpc-gateway mapping, used when the
+// body doesn't carry the gRPC code.
+func codeOfHTTPStatus(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusRequestEntityTooLarge:
+		// The body is over the server's limit: sending it again won't help.
+		return codes.InvalidArgument
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case 499:
+		return codes.Canceled
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		if httpStatus >= 400 && httpStatus < 500 {
+			return codes.FailedPrecondition
+		}
+		return codes.Internal
+	}
+}
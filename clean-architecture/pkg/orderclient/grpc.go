@@ -0,0 +1,230 @@
+package orderclient
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/alexandreti/posGoExpert/clean-architecture/internal/infra/grpc/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// NewGRPC returns a client of the gRPC API at target, e.g. "localhost:50051".
+// The connection is made lazily, on the first call.
+func NewGRPC(target string, opts ...Option) (*Client, error) {
+	o := newOptions(opts)
+	creds := insecure.NewCredentials()
+	if o.tlsConfig != nil {
+		creds = credentials.NewTLS(o.tlsConfig)
+	}
+	dialOptions := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if o.credentials != nil {
+		dialOptions = append(dialOptions,
+			grpc.WithChainUnaryInterceptor(unaryCredentials(o.credentials)),
+			grpc.WithChainStreamInterceptor(streamCredentials(o.credentials)),
+		)
+	}
+	conn, err := grpc.NewClient(target, append(dialOptions, o.dialOptions...)...)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(&grpcTransport{conn: conn, client: pb.NewOrderServiceClient(conn)}, o), nil
+}
+
+func withCredentials(ctx context.Context, creds Credentials) (context.Context, error) {
+	md, err := creds.Metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range md {
+		ctx = metadata.AppendToOutgoingContext(ctx, key, value)
+	}
+	return ctx, nil
+}
+
+func unaryCredentials(creds Credentials) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, err := withCredentials(ctx, creds)
+		if err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func streamCredentials(creds Credentials) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, err := withCredentials(ctx, creds)
+		if err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+type grpcTransport struct {
+	conn   *grpc.ClientConn
+	client pb.OrderServiceClient
+}
+
+func (t *grpcTransport) close() error {
+	return t.conn.Close()
+}
+
+func orderOf(o *pb.Order) Order {
+	return Order{ID: o.GetId(), Price: o.GetPrice(), Tax: o.GetTax(), FinalPrice: o.GetFinalPrice()}
+}
+
+func stateOf(state *pb.OrderState) *OrderState {
+	if state == nil {
+		return nil
+	}
+	return &OrderState{Price: state.GetPrice(), Tax: state.GetTax(), FinalPrice: state.GetFinalPrice()}
+}
+
+func (t *grpcTransport) createOrder(ctx context.Context, input OrderInput) (Order, error) {
+	out, err := t.client.CreateOrder(ctx, &pb.CreateOrderRequest{Id: input.ID, Price: input.Price, Tax: input.Tax})
+	if err != nil {
+		return Order{}, fromGRPC(err)
+	}
+	return Order{ID: out.GetId(), Price: out.GetPrice(), Tax: out.GetTax(), FinalPrice: out.GetFinalPrice()}, nil
+}
+
+func (t *grpcTransport) getOrder(ctx context.Context, id string) (Order, error) {
+	out, err := t.client.GetOrder(ctx, &pb.GetOrderRequest{Id: id})
+	if err != nil {
+		return Order{}, fromGRPC(err)
+	}
+	return orderOf(out), nil
+}
+
+func (t *grpcTransport) listOrders(ctx context.Context) ([]Order, error) {
+	out, err := t.client.ListOrders(ctx, &pb.Blank{})
+	if err != nil {
+		return nil, fromGRPC(err)
+	}
+	orders := make([]Order, 0, len(out.GetOrders()))
+	for _, o := range out.GetOrders() {
+		orders = append(orders, orderOf(o))
+	}
+	return orders, nil
+}
+
+func (t *grpcTransport) orderHistory(ctx context.Context, id string) ([]HistoryEntry, error) {
+	out, err := t.client.GetOrderHistory(ctx, &pb.GetOrderHistoryRequest{Id: id})
+	if err != nil {
+		return nil, fromGRPC(err)
+	}
+	entries := make([]HistoryEntry, 0, len(out.GetEntries()))
+	for _, e := range out.GetEntries() {
+		entries = append(entries, HistoryEntry{
+			Action:    e.GetAction(),
+			Actor:     e.GetActor(),
+			Transport: e.GetTransport(),
+			RequestID: e.GetRequestId(),
+			Before:    stateOf(e.GetBefore()),
+			After:     stateOf(e.GetAfter()),
+			Timestamp: e.GetTimestamp().AsTime(),
+		})
+	}
+	return entries, nil
+}
+
+func (t *grpcTransport) importOrders(ctx context.Context, orders []OrderInput, dryRun bool) (ImportReport, error) {
+	stream, err := t.client.ImportOrders(ctx)
+	if err != nil {
+		return ImportReport{}, fromGRPC(err)
+	}
+	for i, order := range orders {
+		// dry_run is read from the first message only
+		err := stream.Send(&pb.ImportOrdersRequest{Id: order.ID, Price: order.Price, Tax: order.Tax, DryRun: dryRun && i == 0})
+		if errors.Is(err, io.EOF) {
+			// the server ended the call: CloseAndRecv returns its status
+			break
+		}
+		if err != nil {
+			return ImportReport{}, fromGRPC(err)
+		}
+	}
+	out, err := stream.CloseAndRecv()
+	if err != nil {
+		return ImportReport{}, fromGRPC(err)
+	}
+	report := ImportReport{
+		Rows:     int(out.GetRows()),
+		Imported: int(out.GetImported()),
+		Failed:   int(out.GetFailed()),
+		DryRun:   out.GetDryRun(),
+	}
+	for _, rowErr := range out.GetErrors() {
+		report.Errors = append(report.Errors, RowError{Row: int(rowErr.GetRow()), ID: rowErr.GetId(), Error: rowErr.GetError()})
+	}
+	return report, nil
+}
+
+func (t *grpcTransport) orderPages(ctx context.Context, pageSize int) (func() ([]Order, error), error) {
+	stream, err := t.client.StreamOrders(ctx, &pb.StreamOrdersRequest{PageSize: int32(pageSize)})
+	if err != nil {
+		return nil, fromGRPC(err)
+	}
+	return func() ([]Order, error) {
+		page, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, fromGRPC(err)
+		}
+		orders := make([]Order, 0, len(page.GetOrders()))
+		for _, o := range page.GetOrders() {
+			orders = append(orders, orderOf(o))
+		}
+		return orders, nil
+	}, nil
+}
+
+func (t *grpcTransport) watchOrders(ctx context.Context) (func() (Order, error), error) {
+	stream, err := t.client.WatchOrders(ctx, &pb.WatchOrdersRequest{})
+	if err != nil {
+		return nil, fromGRPC(err)
+	}
+	// the server sends the header once subscribed
+	if _, err := stream.Header(); err != nil {
+		return nil, fromGRPC(err)
+	}
+	return func() (Order, error) {
+		o, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return Order{}, io.EOF
+		}
+		if err != nil {
+			return Order{}, fromGRPC(err)
+		}
+		return orderOf(o), nil
+	}, nil
+}
+
+func (t *grpcTransport) replayEvents(ctx context.Context, orderID string) (func() (OrderEvent, error), error) {
+	stream, err := t.client.ReplayEvents(ctx, &pb.ReplayEventsRequest{OrderId: orderID})
+	if err != nil {
+		return nil, fromGRPC(err)
+	}
+	return func() (OrderEvent, error) {
+		e, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return OrderEvent{}, io.EOF
+		}
+		if err != nil {
+			return OrderEvent{}, fromGRPC(err)
+		}
+		event := OrderEvent{OrderID: e.GetOrderId(), Version: int(e.GetVersion()), Type: e.GetType(), Timestamp: e.GetTimestamp().AsTime()}
+		if data := stateOf(e.GetData()); data != nil {
+			event.Data = *data
+		}
+		return event, nil
+	}, nil
+}
@@ -0,0 +1,259 @@
+package orderclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// NewREST returns a client of the REST API served at baseURL, e.g.
+// "http://localhost:8000": the /v1 routes generated from order.proto and the
+// /orders/import and /orders/export routes.
+func NewREST(baseURL string, opts ...Option) (*Client, error) {
+	o := newOptions(opts)
+	base, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, fmt.Errorf("orderclient: the base URL must be http or https, got %q", baseURL)
+	}
+	httpClient := o.httpClient
+	if httpClient == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = o.tlsConfig
+		httpClient = &http.Client{Transport: transport}
+	}
+	return newClient(&restTransport{base: base, client: httpClient, credentials: o.credentials}, o), nil
+}
+
+type restTransport struct {
+	base        *url.URL
+	client      *http.Client
+	credentials Credentials
+}
+
+func (t *restTransport) close() error {
+	t.client.CloseIdleConnections()
+	return nil
+}
+
+// do sends a request and returns the response when its status is 2xx, or
+// the error of the body otherwise.
+func (t *restTransport) do(ctx context.Context, method, path string, query url.Values, contentType string, body io.Reader) (*http.Response, error) {
+	target := t.base.JoinPath(path)
+	target.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		// the gateway gives the RPC the same deadline
+		req.Header.Set("Grpc-Timeout", grpcTimeout(time.Until(deadline)))
+	}
+	if t.credentials != nil {
+		md, err := t.credentials.Metadata(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range md {
+			req.Header.Set(key, value)
+		}
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, &Error{Code: codeOfContext(ctx), Message: err.Error()}
+		}
+		return nil, &Error{Code: codes.Unavailable, Message: err.Error()}
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, errorOfResponse(resp)
+	}
+	return resp, nil
+}
+
+func codeOfContext(ctx context.Context) codes.Code {
+	if ctx.Err() == context.DeadlineExceeded {
+		return codes.DeadlineExceeded
+	}
+	return codes.Canceled
+}
+
+// grpcTimeout formats a timeout as the Grpc-Timeout header, in milliseconds.
+func grpcTimeout(timeout time.Duration) string {
+	return strconv.FormatInt(max(timeout.Milliseconds(), 1), 10) + "m"
+}
+
+// errorOfResponse reads the error of a response: the google.rpc.Status JSON
+// of the gateway, the ErrorResponse of the other routes, or a plain text.
+func errorOfResponse(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	e := &Error{Code: codeOfHTTPStatus(resp.StatusCode), HTTPStatus: resp.StatusCode}
+
+	var payload struct {
+		Code    *codes.Code `json:"code"`
+		Message string      `json:"message"`
+		Details []struct {
+			FieldViolations []struct {
+				Field       string `json:"field"`
+				Description string `json:"description"`
+			} `json:"field_violations"`
+		} `json:"details"`
+		Error  string `json:"error"`
+		Fields []struct {
+			Field   string `json:"field"`
+			Message string `json:"message"`
+		} `json:"fields"`
+	}
+	if json.Unmarshal(body, &payload) != nil {
+		e.Message = strings.TrimSpace(string(body))
+		return e
+	}
+	if payload.Code != nil {
+		e.Code, e.Message = *payload.Code, payload.Message
+		for _, detail := range payload.Details {
+			for _, violation := range detail.FieldViolations {
+				e.Fields = append(e.Fields, FieldViolation{Field: violation.Field, Description: violation.Description})
+			}
+		}
+		return e
+	}
+	e.Message = payload.Error
+	for _, field := range payload.Fields {
+		e.Fields = append(e.Fields, FieldViolation{Field: field.Field, Description: field.Message})
+	}
+	return e
+}
+
+func (t *restTransport) getJSON(ctx context.Context, path string, v any) error {
+	resp, err := t.do(ctx, http.MethodGet, path, nil, "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return decodeBody(resp, v)
+}
+
+func decodeBody(resp *http.Response, v any) error {
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return &Error{Code: codes.Internal, Message: "decoding the response: " + err.Error(), HTTPStatus: resp.StatusCode}
+	}
+	return nil
+}
+
+func (t *restTransport) createOrder(ctx context.Context, input OrderInput) (Order, error) {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return Order{}, err
+	}
+	resp, err := t.do(ctx, http.MethodPost, "/v1/orders", nil, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return Order{}, err
+	}
+	defer resp.Body.Close()
+	var order Order
+	err = decodeBody(resp, &order)
+	return order, err
+}
+
+func (t *restTransport) getOrder(ctx context.Context, id string) (Order, error) {
+	var order Order
+	err := t.getJSON(ctx, "/v1/orders/"+url.PathEscape(id), &order)
+	return order, err
+}
+
+func (t *restTransport) listOrders(ctx context.Context) ([]Order, error) {
+	orders := []Order{}
+	err := t.getJSON(ctx, "/v1/orders", &orders)
+	return orders, err
+}
+
+func (t *restTransport) orderHistory(ctx context.Context, id string) ([]HistoryEntry, error) {
+	entries := []HistoryEntry{}
+	err := t.getJSON(ctx, "/v1/orders/"+url.PathEscape(id)+"/history", &entries)
+	return entries, err
+}
+
+func (t *restTransport) importOrders(ctx context.Context, orders []OrderInput, dryRun bool) (ImportReport, error) {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, order := range orders {
+		if err := encoder.Encode(order); err != nil {
+			return ImportReport{}, err
+		}
+	}
+	query := url.Values{"format": {"ndjson"}, "dry_run": {strconv.FormatBool(dryRun)}}
+	resp, err := t.do(ctx, http.MethodPost, "/orders/import", query, "application/x-ndjson", &body)
+	if err != nil {
+		return ImportReport{}, err
+	}
+	defer resp.Body.Close()
+	var report ImportReport
+	err = decodeBody(resp, &report)
+	return report, err
+}
+
+// orderPages reads the NDJSON export, cutting it in pages of pageSize as the
+// gRPC StreamOrders does.
+func (t *restTransport) orderPages(ctx context.Context, pageSize int) (func() ([]Order, error), error) {
+	if pageSize < 0 {
+		return nil, &Error{Code: codes.InvalidArgument, Message: "the page size can't be negative"}
+	}
+	if pageSize == 0 {
+		pageSize = 100
+	}
+	pageSize = min(pageSize, 1000)
+	resp, err := t.do(ctx, http.MethodGet, "/orders/export", url.Values{"format": {"ndjson"}}, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	context.AfterFunc(ctx, func() { resp.Body.Close() })
+	scanner := bufio.NewScanner(resp.Body)
+	return func() ([]Order, error) {
+		var page []Order
+		for len(page) < pageSize && scanner.Scan() {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			var order Order
+			if err := json.Unmarshal(scanner.Bytes(), &order); err != nil {
+				return nil, &Error{Code: codes.Internal, Message: "decoding the response: " + err.Error(), HTTPStatus: resp.StatusCode}
+			}
+			page = append(page, order)
+		}
+		if err := scanner.Err(); err != nil {
+			if ctx.Err() != nil {
+				return nil, &Error{Code: codeOfContext(ctx), Message: err.Error()}
+			}
+			return nil, &Error{Code: codes.Unavailable, Message: err.Error()}
+		}
+		if len(page) == 0 {
+			return nil, io.EOF
+		}
+		return page, nil
+	}, nil
+}
+
+func (t *restTransport) watchOrders(context.Context) (func() (Order, error), error) {
+	return nil, &Error{Code: codes.Unimplemented, Message: "watching orders needs the gRPC transport"}
+}
+
+func (t *restTransport) replayEvents(context.Context, string) (func() (OrderEvent, error), error) {
+	return nil, &Error{Code: codes.Unimplemented, Message: "replaying events needs the gRPC transport"}
+}
@@ -0,0 +1,64 @@
+package orderclient
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// RetryPolicy retries the idempotent calls (listing, history, dry-run imports)
+// that fail with Unavailable, ResourceExhausted or Aborted, waiting an
+// exponential backoff with jitter between the attempts. Creating orders is
+// never retried, nor is a request answered 413, whatever code its body carries.
+type RetryPolicy struct {
+	// MaxAttempts counts the first call; 1 disables the retries.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+}
+
+// DefaultRetryPolicy is used unless WithRetryPolicy is given.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+	Multiplier:     2,
+}
+
+func retryable(err error) bool {
+	var e *Error
+	if !errors.As(err, &e) || e.HTTPStatus == http.StatusRequestEntityTooLarge {
+		return false
+	}
+	return e.Code == codes.Unavailable || e.Code == codes.ResourceExhausted || e.Code == codes.Aborted
+}
+
+// do calls fn until it succeeds, fails with an error that can't be retried,
+// the attempts run out or ctx is done.
+func (p RetryPolicy) do(ctx context.Context, fn func() error) error {
+	backoff := p.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.MaxAttempts || !retryable(err) {
+			return err
+		}
+		// sleep between half and the whole backoff
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff = time.Duration(float64(backoff) * p.Multiplier)
+		if backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package orderclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/codes"
+)
+
+// RetryTestSuite runs the REST transport against a fake server answering
+// Status until it was called FailFor times.
+type RetryTestSuite struct {
+	suite.Suite
+	Server  *httptest.Server
+	Client  *Client
+	Calls   atomic.Int32
+	FailFor int32
+	Status  int
+	Body    string
+	Request *http.Request
+}
+
+func (suite *RetryTestSuite) SetupTest() {
+	suite.Calls.Store(0)
+	suite.FailFor, suite.Status, suite.Body = 0, http.StatusServiceUnavailable, ""
+	suite.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.Request = r
+		if suite.Calls.Add(1) <= suite.FailFor {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(suite.Status)
+			w.Write([]byte(suite.Body))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/orders":
+			w.Write([]byte(`[{"id":"a","price":10,"tax":1,"final_price":11}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	var err error
+	suite.Client, err = NewREST(suite.Server.URL,
+		WithCredentials(BearerToken("secret")),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Multiplier: 2}),
+	)
+	suite.Require().NoError(err)
+}
+
+func (suite *RetryTestSuite) TearDownTest() {
+	suite.Client.Close()
+	suite.Server.Close()
+}
+
+func TestRetrySuite(t *testing.T) {
+	suite.Run(t, new(RetryTestSuite))
+}
+
+func (suite *RetryTestSuite) TestGivenAnUnavailableServer_WhenListOrders_ThenShouldRetryUntilItAnswers() {
+	suite.FailFor = 2
+
+	orders, err := suite.Client.ListOrders(context.Background())
+	suite.Require().NoError(err)
+	suite.Equal([]Order{{ID: "a", Price: 10, Tax: 1, FinalPrice: 11}}, orders)
+	suite.Equal(int32(3), suite.Calls.Load())
+}
+
+func (suite *RetryTestSuite) TestGivenTooManyFailures_WhenListOrders_ThenShouldReturnTheLastError() {
+	suite.FailFor = 10
+	suite.Status = http.StatusTooManyRequests
+
+	_, err := suite.Client.ListOrders(context.Background())
+	suite.ErrorIs(err, ErrRateLimited)
+	suite.Equal(int32(3), suite.Calls.Load())
+}
+
+func (suite *RetryTestSuite) TestGivenABodyTooLarge_WhenImportOrdersDryRun_ThenShouldNotRetry() {
+	suite.FailFor = 10
+	suite.Status = http.StatusRequestEntityTooLarge
+	suite.Body = `{"error":"request body too large"}`
+
+	_, err := suite.Client.ImportOrders(context.Background(), nil, true)
+	suite.ErrorIs(err, ErrInvalidArgument)
+	suite.Equal(int32(1), suite.Calls.Load())
+}
+
+func (suite *RetryTestSuite) TestGivenABodyTooLargeWithAGRPCCode_WhenListOrders_ThenShouldNotRetry() {
+	suite.FailFor = 10
+	suite.Status = http.StatusRequestEntityTooLarge
+	suite.Body = `{"code":8,"message":"message too large","details":[]}`
+
+	_, err := suite.Client.ListOrders(context.Background())
+	suite.ErrorIs(err, ErrRateLimited)
+	suite.Equal(int32(1), suite.Calls.Load())
+}
+
+func (suite *RetryTestSuite) TestGivenAnUnavailableServer_WhenCreateOrder_ThenShouldNotRetry() {
+	suite.FailFor = 1
+
+	_, err := suite.Client.CreateOrder(context.Background(), OrderInput{ID: "a", Price: 10, Tax: 1})
+	suite.ErrorIs(err, ErrUnavailable)
+	suite.Equal(int32(1), suite.Calls.Load())
+}
+
+func (suite *RetryTestSuite) TestGivenANotFound_WhenOrderHistory_ThenShouldNotRetry() {
+	suite.FailFor = 1
+	suite.Status = http.StatusNotFound
+	suite.Body = `{"code":5,"message":"order not found","details":[]}`
+
+	_, err := suite.Client.OrderHistory(context.Background(), "a")
+	suite.ErrorIs(err, ErrNotFound)
+	suite.EqualError(err, "orderclient: NotFound: order not found")
+	suite.Equal(int32(1), suite.Calls.Load())
+}
+
+func (suite *RetryTestSuite) TestGivenADeadline_WhenListOrders_ThenShouldSendItWithTheCredentials() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	_, err := suite.Client.ListOrders(ctx)
+	suite.Require().NoError(err)
+	timeout, err := time.ParseDuration(suite.Request.Header.Get("Grpc-Timeout") + "s")
+	suite.Require().NoError(err)
+	suite.InDelta(time.Minute.Milliseconds(), timeout.Milliseconds(), 1000)
+	suite.Equal("Bearer secret", suite.Request.Header.Get("Authorization"))
+}
+
+func (suite *RetryTestSuite) TestGivenAGatewayError_WhenReadIt_ThenShouldKeepTheCodeAndTheFields() {
+	suite.FailFor = 1
+	suite.Status = http.StatusBadRequest
+	suite.Body = `{"code":3,"message":"invalid request","details":[{"@type":"type.googleapis.com/google.rpc.BadRequest","field_violations":[{"field":"price","description":"value must be greater than 0"}]}]}`
+
+	_, err := suite.Client.CreateOrder(context.Background(), OrderInput{ID: "a"})
+	var clientErr *Error
+	suite.Require().ErrorAs(err, &clientErr)
+	suite.Equal(codes.InvalidArgument, clientErr.Code)
+	suite.Equal(http.StatusBadRequest, clientErr.HTTPStatus)
+	suite.Equal([]FieldViolation{{Field: "price", Description: "value must be greater than 0"}}, clientErr.Fields)
+}
+
+func (suite *RetryTestSuite) TestGivenARouteError_WhenReadIt_ThenShouldMapTheHTTPStatus() {
+	suite.FailFor = 1
+	suite.Status = http.StatusBadRequest
+	suite.Body = `{"error":"invalid request","fields":[{"field":"dry_run","message":"expected a boolean"}]}`
+
+	_, err := suite.Client.ImportOrders(context.Background(), nil, false)
+	suite.ErrorIs(err, ErrInvalidArgument)
+	var clientErr *Error
+	suite.Require().ErrorAs(err, &clientErr)
+	suite.Equal("invalid request", clientErr.Message)
+	suite.Equal([]FieldViolation{{Field: "dry_run", Description: "expected a boolean"}}, clientErr.Fields)
+}
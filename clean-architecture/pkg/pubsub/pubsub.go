@@ -0,0 +1,84 @@
+// Package pubsub fans out in-process messages to every current subscriber.
+package pubsub
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrSlowSubscriber is reported by a subscription closed because its buffer
+// was full when a message was published.
+var ErrSlowSubscriber = errors.New("subscriber too slow, messages were dropped")
+
+// Broker delivers each published message to the subscriptions open at that
+// moment. Publish never blocks: a subscriber that doesn't keep up with its
+// buffer is dropped, instead of slowing down the publisher and the others.
+type Broker[T any] struct {
+	mu            sync.Mutex
+	subscriptions map[*Subscription[T]]struct{}
+}
+
+func NewBroker[T any]() *Broker[T] {
+	return &Broker[T]{subscriptions: map[*Subscription[T]]struct{}{}}
+}
+
+// Subscribe opens a subscription holding up to buffer pending messages. It
+// must be closed when no longer read.
+func (b *Broker[T]) Subscribe(buffer int) *Subscription[T] {
+	c := make(chan T, buffer)
+	subscription := &Subscription[T]{C: c, c: c, broker: b}
+	b.mu.Lock()
+	b.subscriptions[subscription] = struct{}{}
+	b.mu.Unlock()
+	return subscription
+}
+
+func (b *Broker[T]) Publish(message T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for subscription := range b.subscriptions {
+		select {
+		case subscription.c <- message:
+		default:
+			subscription.err = ErrSlowSubscriber
+			b.remove(subscription)
+		}
+	}
+}
+
+// Subscribers returns how many subscriptions are open.
+func (b *Broker[T]) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscriptions)
+}
+
+// remove closes the channel of the subscription; b.mu must be held.
+func (b *Broker[T]) remove(subscription *Subscription[T]) {
+	if _, ok := b.subscriptions[subscription]; ok {
+		delete(b.subscriptions, subscription)
+		close(subscription.c)
+	}
+}
+
+// Subscription receives the messages published after it was opened on C,
+// which is closed by Close or when the subscriber falls behind.
+type Subscription[T any] struct {
+	C      <-chan T
+	c      chan T
+	broker *Broker[T]
+	err    error
+}
+
+func (s *Subscription[T]) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.remove(s)
+}
+
+// Err tells why C was closed: ErrSlowSubscriber, or nil after Close.
+func (s *Subscription[T]) Err() error {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	return s.err
+}
@@ -0,0 +1,50 @@
+package pubsub
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGivenSubscribers_WhenPublish_ThenEachShouldReceiveTheMessage(t *testing.T) {
+	broker := NewBroker[string]()
+	first, second := broker.Subscribe(1), broker.Subscribe(1)
+	defer first.Close()
+	defer second.Close()
+
+	broker.Publish("a")
+
+	assert.Equal(t, "a", <-first.C)
+	assert.Equal(t, "a", <-second.C)
+}
+
+func TestGivenAClosedSubscription_WhenPublish_ThenShouldSkipIt(t *testing.T) {
+	broker := NewBroker[string]()
+	subscription := broker.Subscribe(1)
+	subscription.Close()
+	subscription.Close()
+
+	broker.Publish("a")
+
+	_, open := <-subscription.C
+	assert.False(t, open)
+	assert.NoError(t, subscription.Err())
+	assert.Equal(t, 0, broker.Subscribers())
+}
+
+func TestGivenASlowSubscriber_WhenPublish_ThenShouldDropOnlyIt(t *testing.T) {
+	broker := NewBroker[int]()
+	slow, fast := broker.Subscribe(1), broker.Subscribe(2)
+	defer fast.Close()
+
+	broker.Publish(1)
+	broker.Publish(2)
+
+	assert.Equal(t, 1, <-slow.C)
+	_, open := <-slow.C
+	assert.False(t, open)
+	assert.ErrorIs(t, slow.Err(), ErrSlowSubscriber)
+	assert.Equal(t, 1, <-fast.C)
+	assert.Equal(t, 2, <-fast.C)
+	assert.Equal(t, 1, broker.Subscribers())
+}
@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const apiKeyMetadata = "x-api-key"
+
+// UnaryServerInterceptor limits calls per full method name and client, answering
+// ResourceExhausted with a retry-after header when the bucket is empty.
+func (l *Limiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := l.allowRPC(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor applies the same limit when a stream is opened.
+func (l *Limiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := l.allowRPC(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func (l *Limiter) allowRPC(ctx context.Context, method string) error {
+	result, _ := l.Allow(ctx, method, l.grpcClientKey(ctx))
+	if result.Allowed {
+		return nil
+	}
+	grpc.SetHeader(ctx, metadata.Pairs("retry-after", retryAfterSeconds(result.RetryAfter)))
+	return status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry after %s", result.RetryAfter)
+}
+
+func (l *Limiter) grpcClientKey(ctx context.Context) string {
+	apiKey := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(apiKeyMetadata); len(values) > 0 {
+			apiKey = values[0]
+		}
+	}
+	host := "unknown"
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		var err error
+		if host, _, err = net.SplitHostPort(p.Addr.String()); err != nil {
+			host = p.Addr.String()
+		}
+	}
+	return l.clientKey(apiKey, host)
+}
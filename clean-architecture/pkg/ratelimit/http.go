@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// HTTPMiddleware limits requests per "METHOD /pattern" route and client,
+// answering 429 with a Retry-After header when the bucket is empty. pattern is
+// the chi route pattern, e.g. "GET /order/{id}/history", so every ID of a route
+// shares one bucket; a request matching no route is limited as "METHOD *". It
+// must be used by a chi router. A request routed to a mounted handler (a
+// pattern ending in "/*") is passed on, for the handler to limit it by its own
+// routes with HTTPRouteMiddleware.
+func (l *Limiter) HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pattern := chiPattern(r)
+		if strings.HasSuffix(pattern, "/*") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		l.serveHTTP(w, r, pattern, next)
+	})
+}
+
+// HTTPRouteMiddleware is HTTPMiddleware for a handler that routes the requests
+// itself: pattern returns the route pattern of a request.
+func (l *Limiter) HTTPRouteMiddleware(pattern func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			l.serveHTTP(w, r, pattern(r), next)
+		})
+	}
+}
+
+func (l *Limiter) serveHTTP(w http.ResponseWriter, r *http.Request, pattern string, next http.Handler) {
+	result, _ := l.Allow(r.Context(), r.Method+" "+pattern, l.httpClientKey(r))
+	if !result.Allowed {
+		w.Header().Set("Retry-After", retryAfterSeconds(result.RetryAfter))
+		http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+	next.ServeHTTP(w, r)
+}
+
+// chiPattern finds the route of r in the chi router serving it. The router
+// middlewares run before the routing, so the pattern isn't set yet.
+func chiPattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return "*"
+	}
+	match := chi.NewRouteContext()
+	if !rctx.Routes.Match(match, r.Method, r.URL.Path) {
+		return "*"
+	}
+	return match.RoutePattern()
+}
+
+func (l *Limiter) httpClientKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return l.clientKey(r.Header.Get(APIKeyHeader), host)
+}
+
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Max(1, math.Ceil(d.Seconds()))))
+}
@@ -0,0 +1,143 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRule = errors.New("invalid rate limit rule")
+
+// Limit describes a token bucket: Rate tokens are added per second up to Burst.
+// A zero Rate disables limiting.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0
+}
+
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets. MemoryStore is the default; a shared store (redis, etc.)
+// only needs to implement this interface.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Limiter applies a limit per route (HTTP "METHOD /pattern" or gRPC full method)
+// and per client. A client is its API key when the key is one of APIKeys, and
+// its IP otherwise: an unknown key is never trusted, or sending a new one on
+// each request would get a full bucket every time.
+type Limiter struct {
+	Store   Store
+	Default Limit
+	Rules   map[string]Limit
+	// APIKeys are the keys that get a bucket of their own.
+	APIKeys map[string]bool
+}
+
+func NewLimiter(store Store, defaultLimit Limit, rules map[string]Limit) *Limiter {
+	if rules == nil {
+		rules = make(map[string]Limit)
+	}
+	return &Limiter{
+		Store:   store,
+		Default: defaultLimit,
+		Rules:   rules,
+	}
+}
+
+func (l *Limiter) LimitFor(route string) Limit {
+	limit, ok := l.Rules[route]
+	if !ok {
+		limit = l.Default
+	}
+	if limit.Burst < 1 {
+		limit.Burst = int(math.Max(1, math.Ceil(limit.Rate)))
+	}
+	return limit
+}
+
+// clientKey returns the bucket of the client with apiKey, which may be empty,
+// calling from host.
+func (l *Limiter) clientKey(apiKey, host string) string {
+	if apiKey != "" && l.APIKeys[apiKey] {
+		return "key:" + apiKey
+	}
+	return "ip:" + host
+}
+
+// ParseAPIKeys reads a comma-separated list of API keys.
+func ParseAPIKeys(s string) map[string]bool {
+	keys := make(map[string]bool)
+	for _, key := range strings.Split(s, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys[key] = true
+		}
+	}
+	return keys
+}
+
+// Allow consumes one token of the client's bucket for the route.
+// Store errors fail open, so an unavailable backend does not take the API down.
+func (l *Limiter) Allow(ctx context.Context, route, client string) (Result, error) {
+	limit := l.LimitFor(route)
+	if limit.Unlimited() {
+		return Result{Allowed: true}, nil
+	}
+	result, err := l.Store.Take(ctx, route+"|"+client, limit)
+	if err != nil {
+		return Result{Allowed: true}, err
+	}
+	return result, nil
+}
+
+// ParseRules reads rules in the format "ROUTE=RATE:BURST;ROUTE=RATE:BURST", e.g.
+// "POST /order=5:10;/pb.OrderService/CreateOrder=5:10".
+func ParseRules(s string) (map[string]Limit, error) {
+	rules := make(map[string]Limit)
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		route, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRule, entry)
+		}
+		limit, err := ParseLimit(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRule, entry)
+		}
+		rules[strings.TrimSpace(route)] = limit
+	}
+	return rules, nil
+}
+
+// ParseLimit reads a "RATE:BURST" pair. When BURST is omitted it defaults to RATE rounded up.
+func ParseLimit(s string) (Limit, error) {
+	rateValue, burstValue, hasBurst := strings.Cut(strings.TrimSpace(s), ":")
+	rate, err := strconv.ParseFloat(rateValue, 64)
+	if err != nil || rate < 0 {
+		return Limit{}, fmt.Errorf("%w: %q", ErrInvalidRule, s)
+	}
+	burst := 0
+	if hasBurst {
+		burst, err = strconv.Atoi(burstValue)
+		if err != nil || burst < 1 {
+			return Limit{}, fmt.Errorf("%w: %q", ErrInvalidRule, s)
+		}
+	}
+	return Limit{Rate: rate, Burst: burst}, nil
+}
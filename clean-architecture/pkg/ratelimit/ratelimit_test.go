@@ -0,0 +1,232 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestStore() (*MemoryStore, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = clock.Now
+	return store, clock
+}
+
+func TestGivenABurst_WhenTakeMoreThanBurst_ThenShouldDenyWithRetryAfter(t *testing.T) {
+	store, _ := newTestStore()
+	limit := Limit{Rate: 1, Burst: 2}
+
+	for i := 0; i < 2; i++ {
+		result, err := store.Take(context.Background(), "client", limit)
+		assert.Nil(t, err)
+		assert.True(t, result.Allowed)
+	}
+	result, err := store.Take(context.Background(), "client", limit)
+	assert.Nil(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+}
+
+func TestGivenAnEmptyBucket_WhenTimePasses_ThenShouldRefill(t *testing.T) {
+	store, clock := newTestStore()
+	limit := Limit{Rate: 2, Burst: 1}
+
+	result, _ := store.Take(context.Background(), "client", limit)
+	assert.True(t, result.Allowed)
+	result, _ = store.Take(context.Background(), "client", limit)
+	assert.False(t, result.Allowed)
+
+	clock.now = clock.now.Add(500 * time.Millisecond)
+	result, _ = store.Take(context.Background(), "client", limit)
+	assert.True(t, result.Allowed)
+}
+
+func TestGivenTwoClients_WhenOneIsLimited_ThenTheOtherShouldBeAllowed(t *testing.T) {
+	store, _ := newTestStore()
+	limiter := NewLimiter(store, Limit{Rate: 1, Burst: 1}, nil)
+
+	result, _ := limiter.Allow(context.Background(), "POST /order", "ip:1")
+	assert.True(t, result.Allowed)
+	result, _ = limiter.Allow(context.Background(), "POST /order", "ip:1")
+	assert.False(t, result.Allowed)
+	result, _ = limiter.Allow(context.Background(), "POST /order", "ip:2")
+	assert.True(t, result.Allowed)
+}
+
+func TestGivenARouteRule_WhenAllow_ThenShouldUseTheRuleInsteadOfTheDefault(t *testing.T) {
+	store, _ := newTestStore()
+	limiter := NewLimiter(store, Limit{}, map[string]Limit{"POST /order": {Rate: 1, Burst: 1}})
+
+	for i := 0; i < 5; i++ {
+		result, _ := limiter.Allow(context.Background(), "GET /list", "ip:1")
+		assert.True(t, result.Allowed)
+	}
+	result, _ := limiter.Allow(context.Background(), "POST /order", "ip:1")
+	assert.True(t, result.Allowed)
+	result, _ = limiter.Allow(context.Background(), "POST /order", "ip:1")
+	assert.False(t, result.Allowed)
+}
+
+func TestGivenARulesString_WhenParseRules_ThenShouldReturnTheLimits(t *testing.T) {
+	rules, err := ParseRules("POST /order=5:10; /pb.OrderService/CreateOrder=2")
+	assert.Nil(t, err)
+	assert.Equal(t, Limit{Rate: 5, Burst: 10}, rules["POST /order"])
+	assert.Equal(t, Limit{Rate: 2, Burst: 0}, rules["/pb.OrderService/CreateOrder"])
+	assert.Equal(t, Limit{Rate: 2, Burst: 2}, NewLimiter(nil, Limit{}, rules).LimitFor("/pb.OrderService/CreateOrder"))
+
+	_, err = ParseRules("POST /order")
+	assert.ErrorIs(t, err, ErrInvalidRule)
+	_, err = ParseRules("POST /order=abc")
+	assert.ErrorIs(t, err, ErrInvalidRule)
+}
+
+func TestGivenARotatingAPIKey_WhenRequestHTTP_ThenShouldLimitByIP(t *testing.T) {
+	store, _ := newTestStore()
+	limiter := NewLimiter(store, Limit{Rate: 0.5, Burst: 1}, nil)
+	limiter.APIKeys = ParseAPIKeys("known")
+	handler := limiter.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	codes := make([]int, 0, 3)
+	for _, apiKey := range []string{"random-1", "random-2", "random-3"} {
+		request := httptest.NewRequest(http.MethodGet, "/list", nil)
+		request.Header.Set(APIKeyHeader, apiKey)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		codes = append(codes, recorder.Code)
+	}
+	assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests, http.StatusTooManyRequests}, codes)
+
+	// a known key has its own bucket, even from the same IP
+	request := httptest.NewRequest(http.MethodGet, "/list", nil)
+	request.Header.Set(APIKeyHeader, "known")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestGivenARotatingAPIKey_WhenCallGRPC_ThenShouldLimitByIP(t *testing.T) {
+	store, _ := newTestStore()
+	limiter := NewLimiter(store, Limit{Rate: 0.5, Burst: 1}, nil)
+	interceptor := limiter.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/pb.OrderService/ListOrders"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234}})
+
+	_, err := interceptor(metadata.NewIncomingContext(ctx, metadata.Pairs(apiKeyMetadata, "random-1")), nil, info, handler)
+	assert.NoError(t, err)
+	_, err = interceptor(metadata.NewIncomingContext(ctx, metadata.Pairs(apiKeyMetadata, "random-2")), nil, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestGivenAnExhaustedClient_WhenRequestHTTP_ThenShouldReturn429WithRetryAfter(t *testing.T) {
+	store, _ := newTestStore()
+	limiter := NewLimiter(store, Limit{Rate: 0.5, Burst: 1}, nil)
+	handler := limiter.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	request := httptest.NewRequest(http.MethodPost, "/order", nil)
+	request.Header.Set(APIKeyHeader, "abc")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "2", recorder.Header().Get("Retry-After"))
+}
+
+func TestGivenTwoIDsOfARoute_WhenRequestHTTP_ThenShouldShareTheBucketOfTheRoutePattern(t *testing.T) {
+	store, _ := newTestStore()
+	limiter := NewLimiter(store, Limit{Rate: 0.5, Burst: 1}, map[string]Limit{"GET /order/{id}/history": {Rate: 0.5, Burst: 2}})
+	router := chi.NewRouter()
+	router.Use(limiter.HTTPMiddleware)
+	router.Get("/order/{id}/history", func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, target := range []string{"/order/a/history", "/order/b/history"} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/order/c/history", nil))
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+}
+
+func TestGivenAMountedHandler_WhenRequestHTTP_ThenShouldLeaveTheLimitToIt(t *testing.T) {
+	store, _ := newTestStore()
+	limiter := NewLimiter(store, Limit{Rate: 0.5, Burst: 1}, nil)
+	router := chi.NewRouter()
+	router.Use(limiter.HTTPMiddleware)
+	calls := 0
+	router.Mount("/v1", limiter.HTTPRouteMiddleware(func(r *http.Request) string {
+		calls++
+		return "/v1/orders/{id}"
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+	codes := make([]int, 0, 2)
+	for _, target := range []string{"/v1/orders/a", "/v1/orders/b"} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		codes = append(codes, recorder.Code)
+	}
+	assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests}, codes)
+	assert.Equal(t, 2, calls)
+}
+
+func TestGivenUnknownPaths_WhenRequestHTTP_ThenShouldShareOneBucket(t *testing.T) {
+	store, _ := newTestStore()
+	limiter := NewLimiter(store, Limit{Rate: 0.5, Burst: 1}, nil)
+	router := chi.NewRouter()
+	router.Use(limiter.HTTPMiddleware)
+	router.Get("/list", func(w http.ResponseWriter, r *http.Request) {})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/missing-1", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/missing-2", nil))
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+}
+
+func TestGivenAnExhaustedClient_WhenCallGRPC_ThenShouldReturnResourceExhausted(t *testing.T) {
+	store, _ := newTestStore()
+	limiter := NewLimiter(store, Limit{Rate: 1, Burst: 1}, nil)
+	interceptor := limiter.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/pb.OrderService/CreateOrder"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(apiKeyMetadata, "abc"))
+
+	response, err := interceptor(ctx, nil, info, handler)
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", response)
+
+	_, err = interceptor(ctx, nil, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
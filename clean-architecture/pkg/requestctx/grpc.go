@@ -0,0 +1,94 @@
+package requestctx
+
+import (
+	"context"
+	"crypto/x509/pkix"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// UnaryServerInterceptor takes the request ID from the x-request-id metadata, or
+// generates one, stores it in the context and sends it back as a header. The
+// actor of the x-actor metadata and the gRPC transport are stored too. When
+// the client was verified by mutual TLS, the subject of its certificate is
+// stored, and its common name replaces the declared actor.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(withIncomingRequestID(ctx), req)
+	}
+}
+
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ServerStream: ss, ctx: withIncomingRequestID(ss.Context())})
+	}
+}
+
+// UnaryTransportInterceptor overrides the transport stored by
+// UnaryServerInterceptor, for a server that only receives the calls of another
+// transport, e.g. the grpc-gateway of the REST API. It must run after it.
+func UnaryTransportInterceptor(transport string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(WithTransport(ctx, transport), req)
+	}
+}
+
+func StreamTransportInterceptor(transport string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ServerStream: ss, ctx: WithTransport(ss.Context(), transport)})
+	}
+}
+
+func withIncomingRequestID(ctx context.Context) context.Context {
+	var requestID, actor string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDMetadata); len(values) > 0 {
+			requestID = values[0]
+		}
+		if values := md.Get(ActorMetadata); len(values) > 0 {
+			actor = values[0]
+		}
+	}
+	if requestID == "" {
+		requestID = NewRequestID()
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadata, requestID))
+	ctx = WithTransport(WithRequestID(ctx, requestID), TransportGRPC)
+	if subject, ok := verifiedSubject(ctx); ok {
+		ctx = WithClientSubject(ctx, subject)
+		actor = subject.CommonName
+		if actor == "" {
+			actor = subject.String()
+		}
+	}
+	if actor != "" {
+		ctx = WithActor(ctx, actor)
+	}
+	return ctx
+}
+
+// verifiedSubject returns the subject of the client certificate, when the TLS
+// handshake verified it against the client CA.
+func verifiedSubject(ctx context.Context) (pkix.Name, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return pkix.Name{}, false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return pkix.Name{}, false
+	}
+	return info.State.VerifiedChains[0][0].Subject, true
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
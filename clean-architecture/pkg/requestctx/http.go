@@ -0,0 +1,31 @@
+package requestctx
+
+import "net/http"
+
+// HTTPMiddleware takes the request ID from the X-Request-ID header, or generates one,
+// stores it in the request context and echoes it in the response. The actor of
+// the X-Actor header is stored too.
+func HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if requestID == "" {
+			requestID = NewRequestID()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+		ctx := WithRequestID(r.Context(), requestID)
+		if actor := r.Header.Get(ActorHeader); actor != "" {
+			ctx = WithActor(ctx, actor)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// TransportMiddleware stores the transport (TransportREST, TransportGraphQL)
+// in the request context.
+func TransportMiddleware(transport string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(WithTransport(r.Context(), transport)))
+		})
+	}
+}
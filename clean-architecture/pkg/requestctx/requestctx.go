@@ -0,0 +1,87 @@
+package requestctx
+
+import (
+	"context"
+	"crypto/x509/pkix"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// RequestIDHeader is the HTTP header (and, lower-cased, the gRPC metadata and
+	// AMQP header) used to propagate the request ID.
+	RequestIDHeader   = "X-Request-ID"
+	RequestIDMetadata = "x-request-id"
+)
+
+const (
+	// ActorHeader / ActorMetadata carry who is calling, as the client declares
+	// it. Over mutual TLS the verified client certificate wins: its common name
+	// is the actor, see ClientSubject.
+	ActorHeader   = "X-Actor"
+	ActorMetadata = "x-actor"
+	// AnonymousActor is used when the caller doesn't identify itself.
+	AnonymousActor = "anonymous"
+)
+
+// Transports through which a request can arrive.
+const (
+	TransportREST    = "REST"
+	TransportGRPC    = "gRPC"
+	TransportGraphQL = "GraphQL"
+)
+
+type (
+	requestIDKey struct{}
+	actorKey     struct{}
+	transportKey struct{}
+	subjectKey   struct{}
+)
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request ID stored in ctx, or "" when there is none.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+func NewRequestID() string {
+	return uuid.NewString()
+}
+
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns the actor stored in ctx, or AnonymousActor when there is none.
+func Actor(ctx context.Context) string {
+	if actor, _ := ctx.Value(actorKey{}).(string); actor != "" {
+		return actor
+	}
+	return AnonymousActor
+}
+
+func WithTransport(ctx context.Context, transport string) context.Context {
+	return context.WithValue(ctx, transportKey{}, transport)
+}
+
+// Transport returns the transport stored in ctx, or "" when there is none.
+func Transport(ctx context.Context) string {
+	transport, _ := ctx.Value(transportKey{}).(string)
+	return transport
+}
+
+// WithClientSubject stores the subject of the verified client certificate.
+func WithClientSubject(ctx context.Context, subject pkix.Name) context.Context {
+	return context.WithValue(ctx, subjectKey{}, subject)
+}
+
+// ClientSubject returns the subject of the client certificate verified by
+// mutual TLS, and false when the client wasn't authenticated by a certificate.
+func ClientSubject(ctx context.Context) (pkix.Name, bool) {
+	subject, ok := ctx.Value(subjectKey{}).(pkix.Name)
+	return subject, ok
+}
@@ -0,0 +1,127 @@
+package requestctx
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestGivenARequestIDHeader_WhenServeHTTP_ThenShouldKeepIt(t *testing.T) {
+	var requestID string
+	handler := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID = RequestID(r.Context())
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/list", nil)
+	request.Header.Set(RequestIDHeader, "abc")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	assert.Equal(t, "abc", requestID)
+	assert.Equal(t, "abc", recorder.Header().Get(RequestIDHeader))
+}
+
+func TestGivenNoRequestIDHeader_WhenServeHTTP_ThenShouldGenerateOne(t *testing.T) {
+	var requestID string
+	handler := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID = RequestID(r.Context())
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/list", nil))
+
+	assert.NotEmpty(t, requestID)
+	assert.Equal(t, requestID, recorder.Header().Get(RequestIDHeader))
+}
+
+func TestGivenARequestIDMetadata_WhenCallGRPC_ThenShouldKeepIt(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDMetadata, "abc"))
+
+	response, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return RequestID(ctx), nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", response)
+}
+
+func TestGivenNoRequestIDMetadata_WhenCallGRPC_ThenShouldGenerateOne(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+
+	response, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return RequestID(ctx), nil
+	})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, response)
+}
+
+func TestGivenAnActorHeader_WhenServeHTTPWithTransport_ThenShouldStoreBoth(t *testing.T) {
+	var actor, transport string
+	handler := HTTPMiddleware(TransportMiddleware(TransportREST)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor, transport = Actor(r.Context()), Transport(r.Context())
+	})))
+
+	request := httptest.NewRequest(http.MethodPost, "/order", nil)
+	request.Header.Set(ActorHeader, "alice")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	assert.Equal(t, "alice", actor)
+	assert.Equal(t, TransportREST, transport)
+}
+
+func TestGivenNoActor_WhenActor_ThenShouldReturnAnonymous(t *testing.T) {
+	assert.Equal(t, AnonymousActor, Actor(context.Background()))
+	assert.Equal(t, "", Transport(context.Background()))
+}
+
+func TestGivenAnActorMetadata_WhenCallGRPC_ThenShouldStoreItWithTheGRPCTransport(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ActorMetadata, "bob"))
+
+	response, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return Actor(ctx) + "/" + Transport(ctx), nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "bob/"+TransportGRPC, response)
+}
+
+func TestGivenAVerifiedClientCertificate_WhenCallGRPC_ThenShouldUseItsSubjectAsTheActor(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	subject := pkix.Name{CommonName: "orderctl", Organization: []string{"ordersystem"}}
+	state := tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: subject}}}}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(ActorMetadata, "mallory"))
+
+	var got pkix.Name
+	var ok bool
+	response, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		got, ok = ClientSubject(ctx)
+		return Actor(ctx), nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "orderctl", response)
+	assert.True(t, ok)
+	assert.Equal(t, subject.String(), got.String())
+}
+
+func TestGivenNoClientCertificate_WhenCallGRPC_ThenShouldHaveNoSubject(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{}})
+
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		_, ok := ClientSubject(ctx)
+		assert.False(t, ok)
+		return nil, nil
+	})
+	assert.Nil(t, err)
+}
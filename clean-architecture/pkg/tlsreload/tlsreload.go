@@ -0,0 +1,188 @@
+// Package tlsreload serves TLS certificates read from files, reloading them
+// when the files change so that a renewed certificate is used without a
+// restart.
+package tlsreload
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Config names the PEM files of the server certificate and, for mutual TLS,
+// of the CA that signs the client certificates.
+type Config struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+	// Interval is how often Run checks the files for changes.
+	Interval time.Duration
+}
+
+// Reloader holds the certificate, and the client CA pool, last read from the
+// files of its Config.
+type Reloader struct {
+	config Config
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+	modTimes map[string]time.Time
+}
+
+// New reads the files of config, failing when any of them is missing or invalid.
+func New(config Config) (*Reloader, error) {
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, errors.New("tlsreload: the certificate and key files are required")
+	}
+	r := &Reloader{config: config}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the files again. On error the previous certificate and client
+// CA are kept.
+func (r *Reloader) Reload() error {
+	modTimes, err := r.stat()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("tlsreload: loading the certificate: %w", err)
+	}
+	var clientCA *x509.CertPool
+	if r.config.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.config.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("tlsreload: reading the client CA: %w", err)
+		}
+		clientCA = x509.NewCertPool()
+		if !clientCA.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("tlsreload: no certificate found in %s", r.config.ClientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert, r.clientCA, r.modTimes = &cert, clientCA, modTimes
+	return nil
+}
+
+// Run reloads the files every Interval when their modification time changed,
+// until ctx is done. A failed reload is logged, and retried on the next change.
+func (r *Reloader) Run(ctx context.Context) {
+	interval := r.config.Interval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				slog.ErrorContext(ctx, "failed to reload the TLS certificate", slog.Any("error", err))
+				r.mu.Lock()
+				r.modTimes, _ = r.stat()
+				r.mu.Unlock()
+				continue
+			}
+			slog.InfoContext(ctx, "TLS certificate reloaded", slog.String("cert_file", r.config.CertFile))
+		}
+	}
+}
+
+func (r *Reloader) files() []string {
+	files := []string{r.config.CertFile, r.config.KeyFile}
+	if r.config.ClientCAFile != "" {
+		files = append(files, r.config.ClientCAFile)
+	}
+	return files
+}
+
+func (r *Reloader) stat() (map[string]time.Time, error) {
+	modTimes := make(map[string]time.Time)
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("tlsreload: %w", err)
+		}
+		modTimes[file] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+func (r *Reloader) changed() bool {
+	modTimes, err := r.stat()
+	if err != nil {
+		// a file being replaced may be missing for a moment
+		return false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for file, modTime := range modTimes {
+		if !modTime.Equal(r.modTimes[file]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Certificate returns the current server certificate.
+func (r *Reloader) Certificate() *tls.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert
+}
+
+func (r *Reloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.Certificate(), nil
+}
+
+// TLSConfig returns a server configuration serving the current certificate,
+// without asking for a client certificate.
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.getCertificate,
+	}
+}
+
+// MutualTLSConfig returns a server configuration that also requires a client
+// certificate signed by the current client CA. It offers HTTP/2, as needed by
+// gRPC, and HTTP/1.1.
+func (r *Reloader) MutualTLSConfig() (*tls.Config, error) {
+	if r.config.ClientCAFile == "" {
+		return nil, errors.New("tlsreload: mutual TLS needs a client CA file")
+	}
+	config := r.TLSConfig()
+	config.ClientAuth = tls.RequireAndVerifyClientCert
+	config.NextProtos = []string{"h2", "http/1.1"}
+	// the client CA can only be swapped through a configuration per handshake
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		return &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: r.getCertificate,
+			ClientAuth:     tls.RequireAndVerifyClientCert,
+			ClientCAs:      r.clientCA,
+			NextProtos:     config.NextProtos,
+		}, nil
+	}
+	return config, nil
+}
@@ -0,0 +1,244 @@
+package tlsreload
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// authority is a CA generated for the test.
+type authority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+var serial int64
+
+// issue creates a certificate for commonName, signed by ca, or self-signed
+// when ca is nil.
+func issue(t *testing.T, ca *authority, commonName string, isCA bool) (*x509.Certificate, []byte, []byte, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial++
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: commonName, Organization: []string{"ordersystem"}},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	parent, signer := template, key
+	if ca != nil {
+		parent, signer = ca.cert, ca.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), key
+}
+
+func newAuthority(t *testing.T) *authority {
+	cert, certPEM, _, key := issue(t, nil, "test CA", true)
+	return &authority{cert: cert, key: key, pem: certPEM}
+}
+
+type ReloaderTestSuite struct {
+	suite.Suite
+	CA       *authority
+	CertFile string
+	KeyFile  string
+	CAFile   string
+}
+
+func (suite *ReloaderTestSuite) SetupTest() {
+	dir := suite.T().TempDir()
+	suite.CertFile = filepath.Join(dir, "cert.pem")
+	suite.KeyFile = filepath.Join(dir, "key.pem")
+	suite.CAFile = filepath.Join(dir, "ca.pem")
+	suite.CA = newAuthority(suite.T())
+	suite.writeServerCert("server-1")
+	suite.Require().NoError(os.WriteFile(suite.CAFile, suite.CA.pem, 0o600))
+}
+
+func TestReloaderSuite(t *testing.T) {
+	suite.Run(t, new(ReloaderTestSuite))
+}
+
+// writeServerCert writes a new server certificate and returns its serial number.
+func (suite *ReloaderTestSuite) writeServerCert(commonName string) *big.Int {
+	cert, certPEM, keyPEM, _ := issue(suite.T(), suite.CA, commonName, false)
+	suite.Require().NoError(os.WriteFile(suite.CertFile, certPEM, 0o600))
+	suite.Require().NoError(os.WriteFile(suite.KeyFile, keyPEM, 0o600))
+	return cert.SerialNumber
+}
+
+func (suite *ReloaderTestSuite) serial(r *Reloader) *big.Int {
+	leaf, err := x509.ParseCertificate(r.Certificate().Certificate[0])
+	suite.Require().NoError(err)
+	return leaf.SerialNumber
+}
+
+func (suite *ReloaderTestSuite) TestGivenNewFiles_WhenReload_ThenShouldServeTheNewCertificate() {
+	r, err := New(Config{CertFile: suite.CertFile, KeyFile: suite.KeyFile})
+	suite.Require().NoError(err)
+
+	renewed := suite.writeServerCert("server-2")
+	suite.NoError(r.Reload())
+	suite.Equal(renewed, suite.serial(r))
+}
+
+func (suite *ReloaderTestSuite) TestGivenInvalidFiles_WhenReload_ThenShouldKeepThePreviousCertificate() {
+	r, err := New(Config{CertFile: suite.CertFile, KeyFile: suite.KeyFile})
+	suite.Require().NoError(err)
+	previous := suite.serial(r)
+
+	suite.Require().NoError(os.WriteFile(suite.KeyFile, []byte("not a key"), 0o600))
+	suite.Error(r.Reload())
+	suite.Equal(previous, suite.serial(r))
+}
+
+func (suite *ReloaderTestSuite) TestGivenChangedFiles_WhenRun_ThenShouldReloadThem() {
+	r, err := New(Config{CertFile: suite.CertFile, KeyFile: suite.KeyFile, Interval: 5 * time.Millisecond})
+	suite.Require().NoError(err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Run(ctx)
+
+	renewed := suite.writeServerCert("server-2")
+	// the modification time may have a coarse resolution
+	later := time.Now().Add(time.Minute)
+	suite.Require().NoError(os.Chtimes(suite.CertFile, later, later))
+	suite.Eventually(func() bool { return suite.serial(r).Cmp(renewed) == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func (suite *ReloaderTestSuite) TestGivenMissingFiles_WhenNew_ThenShouldFail() {
+	_, err := New(Config{CertFile: suite.CertFile + ".missing", KeyFile: suite.KeyFile})
+	suite.Error(err)
+	_, err = New(Config{CertFile: suite.CertFile})
+	suite.Error(err)
+}
+
+// handshake connects to a server using config, presenting client when not nil.
+func (suite *ReloaderTestSuite) handshake(config *tls.Config, client *tls.Certificate) (*x509.Certificate, error) {
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	suite.Require().NoError(err)
+	defer listener.Close()
+	peer := make(chan *x509.Certificate, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tlsConn := conn.(*tls.Conn)
+		if tlsConn.Handshake() == nil && len(tlsConn.ConnectionState().VerifiedChains) > 0 {
+			peer <- tlsConn.ConnectionState().VerifiedChains[0][0]
+		}
+		close(peer)
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(suite.CA.cert)
+	clientConfig := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+	if client != nil {
+		clientConfig.Certificates = []tls.Certificate{*client}
+	}
+	conn, err := tls.Dial("tcp", listener.Addr().String(), clientConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	// with TLS 1.3 the client learns that its certificate was rejected on the first read
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err != nil && !isTimeout(err) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return <-peer, nil
+}
+
+func isTimeout(err error) bool {
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
+
+func (suite *ReloaderTestSuite) clientCert(ca *authority, commonName string) *tls.Certificate {
+	_, certPEM, keyPEM, _ := issue(suite.T(), ca, commonName, false)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	suite.Require().NoError(err)
+	return &cert
+}
+
+func (suite *ReloaderTestSuite) TestGivenMutualTLS_WhenHandshake_ThenShouldRequireAClientCertificateSignedByTheCA() {
+	r, err := New(Config{CertFile: suite.CertFile, KeyFile: suite.KeyFile, ClientCAFile: suite.CAFile})
+	suite.Require().NoError(err)
+	config, err := r.MutualTLSConfig()
+	suite.Require().NoError(err)
+
+	subject, err := suite.handshake(config, suite.clientCert(suite.CA, "orderctl"))
+	suite.Require().NoError(err)
+	suite.Require().NotNil(subject)
+	suite.Equal("orderctl", subject.Subject.CommonName)
+
+	_, err = suite.handshake(config, nil)
+	suite.Error(err)
+	_, err = suite.handshake(config, suite.clientCert(newAuthority(suite.T()), "intruder"))
+	suite.Error(err)
+}
+
+func (suite *ReloaderTestSuite) TestGivenANewClientCA_WhenReload_ThenShouldTrustItsClients() {
+	r, err := New(Config{CertFile: suite.CertFile, KeyFile: suite.KeyFile, ClientCAFile: suite.CAFile})
+	suite.Require().NoError(err)
+	config, err := r.MutualTLSConfig()
+	suite.Require().NoError(err)
+	newCA := newAuthority(suite.T())
+	client := suite.clientCert(newCA, "orderctl")
+
+	_, err = suite.handshake(config, client)
+	suite.Error(err)
+
+	suite.Require().NoError(os.WriteFile(suite.CAFile, newCA.pem, 0o600))
+	suite.Require().NoError(r.Reload())
+	subject, err := suite.handshake(config, client)
+	suite.NoError(err)
+	suite.NotNil(subject)
+}
+
+func (suite *ReloaderTestSuite) TestGivenNoClientCA_WhenMutualTLSConfig_ThenShouldFail() {
+	r, err := New(Config{CertFile: suite.CertFile, KeyFile: suite.KeyFile})
+	suite.Require().NoError(err)
+	_, err = r.MutualTLSConfig()
+	suite.Error(err)
+}
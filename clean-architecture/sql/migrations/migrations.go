@@ -0,0 +1,92 @@
+// Package migrations embeds the SQL migrations of every supported database.
+// Each database has its own directory, with files named like golang-migrate
+// creates them: 000001_init.up.sql / 000001_init.down.sql.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+//go:embed mysql/*.sql postgres/*.sql sqlite/*.sql
+var FS embed.FS
+
+// Dirs maps each database/sql driver name to its migrations directory.
+var Dirs = map[string]string{
+	"mysql":    "mysql",
+	"postgres": "postgres",
+	"sqlite3":  "sqlite",
+}
+
+// Source returns the embedded migrations of a database/sql driver.
+func Source(driver string) (fs.FS, error) {
+	dir, ok := Dirs[driver]
+	if !ok {
+		return nil, fmt.Errorf("no migrations for database driver %q", driver)
+	}
+	return fs.Sub(FS, dir)
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// ParseFileName splits a migration file name into version, name and direction.
+func ParseFileName(file string) (version uint64, name, direction string, ok bool) {
+	m := fileName.FindStringSubmatch(file)
+	if m == nil {
+		return 0, "", "", false
+	}
+	version, err := strconv.ParseUint(m[1], 10, 64)
+	if err != nil {
+		return 0, "", "", false
+	}
+	return version, m[2], m[3], true
+}
+
+var validName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Create writes empty up/down files for a new migration in the directory of
+// every database under root, using the next sequential version across all of
+// them so the databases stay in step. It returns the created files.
+func Create(root, name string) ([]string, error) {
+	if !validName.MatchString(name) {
+		return nil, fmt.Errorf("invalid migration name %q, use lowercase letters, digits and _", name)
+	}
+	dirs := make([]string, 0, len(Dirs))
+	for _, dir := range Dirs {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	var last uint64
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(filepath.Join(root, dir))
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if version, _, _, ok := ParseFileName(entry.Name()); ok && version > last {
+				last = version
+			}
+		}
+	}
+
+	var created []string
+	for _, dir := range dirs {
+		for _, direction := range []string{"up", "down"} {
+			file := filepath.Join(root, dir, fmt.Sprintf("%06d_%s.%s.sql", last+1, name, direction))
+			f, err := os.OpenFile(file, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+			if err != nil {
+				return created, err
+			}
+			f.Close()
+			created = append(created, file)
+		}
+	}
+	return created, nil
+}
@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGivenExistingMigrations_WhenCreate_ThenShouldUseNextVersionInEveryDir(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range Dirs {
+		assert.NoError(t, os.Mkdir(filepath.Join(root, dir), 0o755))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "postgres", "000002_old.up.sql"), nil, 0o644))
+
+	created, err := Create(root, "add_customer")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(root, "mysql", "000003_add_customer.up.sql"),
+		filepath.Join(root, "mysql", "000003_add_customer.down.sql"),
+		filepath.Join(root, "postgres", "000003_add_customer.up.sql"),
+		filepath.Join(root, "postgres", "000003_add_customer.down.sql"),
+		filepath.Join(root, "sqlite", "000003_add_customer.up.sql"),
+		filepath.Join(root, "sqlite", "000003_add_customer.down.sql"),
+	}, created)
+
+	_, err = Create(root, "Bad Name")
+	assert.Error(t, err)
+}
+
+func TestGivenEmbeddedMigrations_WhenSource_ThenShouldListDriverFiles(t *testing.T) {
+	source, err := Source("sqlite3")
+	assert.NoError(t, err)
+	_, err = source.Open("000001_init.up.sql")
+	assert.NoError(t, err)
+
+	_, err = Source("oracle")
+	assert.Error(t, err)
+}
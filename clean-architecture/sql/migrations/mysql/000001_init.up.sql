@@ -1,7 +1,3 @@
-CREATE DATABASE IF NOT EXISTS orders;
-
-USE orders;
-
 CREATE TABLE orders (
     id varchar(255) NOT NULL, 
     price float NOT NULL, 
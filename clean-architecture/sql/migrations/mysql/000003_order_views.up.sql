@@ -0,0 +1,9 @@
+CREATE TABLE order_views (
+    id varchar(255) NOT NULL,
+    price double NOT NULL,
+    tax double NOT NULL,
+    final_price double NOT NULL,
+    version int NOT NULL,
+    updated_at datetime(6) NOT NULL,
+    PRIMARY KEY (id)
+);
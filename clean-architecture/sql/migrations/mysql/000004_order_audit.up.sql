@@ -0,0 +1,13 @@
+CREATE TABLE order_audit (
+    id bigint NOT NULL AUTO_INCREMENT,
+    order_id varchar(255) NOT NULL,
+    action varchar(50) NOT NULL,
+    actor varchar(255) NOT NULL,
+    transport varchar(20) NOT NULL,
+    request_id varchar(255) NOT NULL,
+    before_value text NULL,
+    after_value text NULL,
+    created_at datetime(6) NOT NULL,
+    PRIMARY KEY (id),
+    INDEX order_audit_order_id (order_id)
+);
@@ -0,0 +1,22 @@
+CREATE TABLE published_events (
+    event_name varchar(100) NOT NULL,
+    order_id varchar(255) NOT NULL,
+    published_at datetime(6) NOT NULL,
+    PRIMARY KEY (event_name, order_id)
+);
+
+CREATE TABLE leases (
+    name varchar(100) NOT NULL,
+    holder varchar(255) NOT NULL,
+    expires_at datetime(6) NOT NULL,
+    PRIMARY KEY (name)
+);
+
+-- the orders saved before this migration were published when they were
+-- created; without these rows the reconciliation would republish them all
+INSERT INTO published_events (event_name, order_id, published_at)
+SELECT 'OrderCreated', id, CURRENT_TIMESTAMP FROM orders;
+
+INSERT INTO published_events (event_name, order_id, published_at)
+SELECT 'OrderCreated', stream_id, CURRENT_TIMESTAMP FROM order_events
+WHERE version = 1 AND stream_id NOT IN (SELECT order_id FROM published_events WHERE event_name = 'OrderCreated');
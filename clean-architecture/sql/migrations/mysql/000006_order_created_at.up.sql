@@ -0,0 +1,4 @@
+ALTER TABLE orders ADD COLUMN created_at datetime(6) NULL;
+ALTER TABLE order_views ADD COLUMN created_at datetime(6) NULL;
+UPDATE order_views SET created_at = updated_at;
+CREATE INDEX order_views_created_at ON order_views (created_at);
@@ -0,0 +1,14 @@
+ALTER TABLE orders ADD COLUMN deleted_at datetime(6) NULL;
+ALTER TABLE orders ADD COLUMN version int NOT NULL DEFAULT 1;
+ALTER TABLE order_views ADD COLUMN deleted_at datetime(6) NULL;
+
+CREATE TABLE orders_archive (
+    id varchar(255) NOT NULL,
+    price double NOT NULL,
+    tax double NOT NULL,
+    final_price double NOT NULL,
+    created_at datetime(6) NULL,
+    deleted_at datetime(6) NULL,
+    archived_at datetime(6) NOT NULL,
+    PRIMARY KEY (id)
+);
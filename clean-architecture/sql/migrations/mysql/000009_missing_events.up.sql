@@ -0,0 +1,8 @@
+-- when the reconciliation first found an order without its published event;
+-- it's only republished once that is older than the grace period
+CREATE TABLE missing_events (
+    event_name varchar(100) NOT NULL,
+    order_id varchar(255) NOT NULL,
+    first_seen_at datetime(6) NOT NULL,
+    PRIMARY KEY (event_name, order_id)
+);
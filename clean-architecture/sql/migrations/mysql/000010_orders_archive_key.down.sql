@@ -0,0 +1,3 @@
+-- keep the last archived copy of each order
+DELETE a FROM orders_archive a JOIN orders_archive b ON b.id = a.id AND b.archived_at > a.archived_at;
+ALTER TABLE orders_archive DROP PRIMARY KEY, ADD PRIMARY KEY (id);
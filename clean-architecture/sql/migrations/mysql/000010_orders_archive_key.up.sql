@@ -0,0 +1,3 @@
+-- an order is archived each time it's purged, so a reused ID, or a purge
+-- retried by a later run, adds a row instead of being skipped
+ALTER TABLE orders_archive DROP PRIMARY KEY, ADD PRIMARY KEY (id, archived_at);
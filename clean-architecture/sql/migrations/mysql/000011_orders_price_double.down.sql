@@ -0,0 +1 @@
+ALTER TABLE orders MODIFY price float NOT NULL, MODIFY tax float NOT NULL, MODIFY final_price float NOT NULL;
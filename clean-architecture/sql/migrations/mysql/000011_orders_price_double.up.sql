@@ -0,0 +1,3 @@
+-- a float keeps about 7 digits, so larger prices were rounded; the archive and
+-- the read model already use double
+ALTER TABLE orders MODIFY price double NOT NULL, MODIFY tax double NOT NULL, MODIFY final_price double NOT NULL;
@@ -0,0 +1,7 @@
+CREATE TABLE orders (
+    id varchar(255) NOT NULL,
+    price double precision NOT NULL,
+    tax double precision NOT NULL,
+    final_price double precision NOT NULL,
+    PRIMARY KEY (id)
+);
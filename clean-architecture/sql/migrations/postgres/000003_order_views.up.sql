@@ -0,0 +1,9 @@
+CREATE TABLE order_views (
+    id varchar(255) NOT NULL,
+    price double precision NOT NULL,
+    tax double precision NOT NULL,
+    final_price double precision NOT NULL,
+    version int NOT NULL,
+    updated_at timestamp NOT NULL,
+    PRIMARY KEY (id)
+);
@@ -0,0 +1,13 @@
+CREATE TABLE order_audit (
+    id bigserial NOT NULL,
+    order_id varchar(255) NOT NULL,
+    action varchar(50) NOT NULL,
+    actor varchar(255) NOT NULL,
+    transport varchar(20) NOT NULL,
+    request_id varchar(255) NOT NULL,
+    before_value text NULL,
+    after_value text NULL,
+    created_at timestamp NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE INDEX order_audit_order_id ON order_audit (order_id);
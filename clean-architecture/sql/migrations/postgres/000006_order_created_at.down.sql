@@ -0,0 +1,3 @@
+DROP INDEX order_views_created_at;
+ALTER TABLE order_views DROP COLUMN created_at;
+ALTER TABLE orders DROP COLUMN created_at;
@@ -0,0 +1,14 @@
+ALTER TABLE orders ADD COLUMN deleted_at timestamp NULL;
+ALTER TABLE orders ADD COLUMN version int NOT NULL DEFAULT 1;
+ALTER TABLE order_views ADD COLUMN deleted_at timestamp NULL;
+
+CREATE TABLE orders_archive (
+    id varchar(255) NOT NULL,
+    price double precision NOT NULL,
+    tax double precision NOT NULL,
+    final_price double precision NOT NULL,
+    created_at timestamp NULL,
+    deleted_at timestamp NULL,
+    archived_at timestamp NOT NULL,
+    PRIMARY KEY (id)
+);
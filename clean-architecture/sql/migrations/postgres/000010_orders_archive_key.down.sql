@@ -0,0 +1,4 @@
+-- keep the last archived copy of each order
+DELETE FROM orders_archive a USING orders_archive b WHERE b.id = a.id AND b.archived_at > a.archived_at;
+ALTER TABLE orders_archive DROP CONSTRAINT orders_archive_pkey;
+ALTER TABLE orders_archive ADD PRIMARY KEY (id);
@@ -0,0 +1 @@
+-- the orders table was created with double precision columns
@@ -0,0 +1,16 @@
+CREATE TABLE order_events (
+    stream_id varchar(255) NOT NULL,
+    version int NOT NULL,
+    type varchar(100) NOT NULL,
+    payload text NOT NULL,
+    created_at timestamp NOT NULL,
+    PRIMARY KEY (stream_id, version)
+);
+
+CREATE TABLE order_snapshots (
+    stream_id varchar(255) NOT NULL,
+    version int NOT NULL,
+    payload text NOT NULL,
+    created_at timestamp NOT NULL,
+    PRIMARY KEY (stream_id)
+);
@@ -0,0 +1,9 @@
+CREATE TABLE order_views (
+    id varchar(255) NOT NULL,
+    price real NOT NULL,
+    tax real NOT NULL,
+    final_price real NOT NULL,
+    version int NOT NULL,
+    updated_at timestamp NOT NULL,
+    PRIMARY KEY (id)
+);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS orders_archive;
+ALTER TABLE order_views DROP COLUMN deleted_at;
+ALTER TABLE orders DROP COLUMN version;
+ALTER TABLE orders DROP COLUMN deleted_at;
@@ -0,0 +1,15 @@
+-- keep the last archived copy of each order
+CREATE TABLE orders_archive_old (
+    id varchar(255) NOT NULL,
+    price real NOT NULL,
+    tax real NOT NULL,
+    final_price real NOT NULL,
+    created_at timestamp NULL,
+    deleted_at timestamp NULL,
+    archived_at timestamp NOT NULL,
+    PRIMARY KEY (id)
+);
+INSERT INTO orders_archive_old SELECT id, price, tax, final_price, created_at, deleted_at, archived_at FROM orders_archive a
+WHERE NOT EXISTS (SELECT 1 FROM orders_archive b WHERE b.id = a.id AND b.archived_at > a.archived_at);
+DROP TABLE orders_archive;
+ALTER TABLE orders_archive_old RENAME TO orders_archive;
@@ -0,0 +1,16 @@
+-- an order is archived each time it's purged, so a reused ID, or a purge
+-- retried by a later run, adds a row instead of being skipped; sqlite can't
+-- change a primary key, so the table is rebuilt
+CREATE TABLE orders_archive_new (
+    id varchar(255) NOT NULL,
+    price real NOT NULL,
+    tax real NOT NULL,
+    final_price real NOT NULL,
+    created_at timestamp NULL,
+    deleted_at timestamp NULL,
+    archived_at timestamp NOT NULL,
+    PRIMARY KEY (id, archived_at)
+);
+INSERT INTO orders_archive_new SELECT id, price, tax, final_price, created_at, deleted_at, archived_at FROM orders_archive;
+DROP TABLE orders_archive;
+ALTER TABLE orders_archive_new RENAME TO orders_archive;
@@ -0,0 +1,14 @@
+CREATE TABLE orders_old (
+    id varchar(255) NOT NULL,
+    price float NOT NULL,
+    tax float NOT NULL,
+    final_price float NOT NULL,
+    created_at timestamp NULL,
+    deleted_at timestamp NULL,
+    version int NOT NULL DEFAULT 1,
+    PRIMARY KEY (id)
+);
+INSERT INTO orders_old SELECT id, price, tax, final_price, created_at, deleted_at, version FROM orders;
+DROP TABLE orders;
+ALTER TABLE orders_old RENAME TO orders;
+CREATE INDEX orders_created_at ON orders (created_at);
@@ -0,0 +1,17 @@
+-- float and real are both stored as 8-byte floats, but the columns are
+-- declared real like those of the archive; sqlite can't change the type of a
+-- column, so the table is rebuilt
+CREATE TABLE orders_new (
+    id varchar(255) NOT NULL,
+    price real NOT NULL,
+    tax real NOT NULL,
+    final_price real NOT NULL,
+    created_at timestamp NULL,
+    deleted_at timestamp NULL,
+    version int NOT NULL DEFAULT 1,
+    PRIMARY KEY (id)
+);
+INSERT INTO orders_new SELECT id, price, tax, final_price, created_at, deleted_at, version FROM orders;
+DROP TABLE orders;
+ALTER TABLE orders_new RENAME TO orders;
+CREATE INDEX orders_created_at ON orders (created_at);